@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// faqBackendURL construye la URL de una ruta pública de FAQs en el backend de GrowDesk
+func faqBackendURL(path, rawQuery string) string {
+	apiURL := os.Getenv("GROWDESK_API_URL")
+	if apiURL == "" {
+		apiURL = "http://localhost:8080"
+	}
+	target := fmt.Sprintf("%s/widget/faqs/%s", strings.TrimSuffix(apiURL, "/"), path)
+	if rawQuery != "" {
+		target += "?" + rawQuery
+	}
+	return target
+}
+
+// proxyFAQRequest reenvía la solicitud al backend con el idioma y el widget del
+// visitante, y devuelve su respuesta tal cual
+func proxyFAQRequest(c *gin.Context, method, path string, body io.Reader) {
+	req, err := http.NewRequest(method, faqBackendURL(path, c.Request.URL.RawQuery), body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al preparar la solicitud"})
+		return
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if widgetID := c.GetHeader("X-Widget-ID"); widgetID != "" {
+		req.Header.Set("X-Widget-ID", widgetID)
+	}
+	if acceptLanguage := c.GetHeader("Accept-Language"); acceptLanguage != "" {
+		req.Header.Set("Accept-Language", acceptLanguage)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("Error al contactar con el backend para las FAQs: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "No se pudo contactar con el servidor de soporte"})
+		return
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Respuesta inválida del servidor de soporte"})
+		return
+	}
+
+	if resp.StatusCode >= 400 {
+		c.JSON(resp.StatusCode, gin.H{"error": strings.TrimSpace(string(respBody))})
+		return
+	}
+	c.Data(resp.StatusCode, "application/json", respBody)
+}
+
+// suggestFaqs sugiere FAQs a partir del borrador del visitante antes de crear un ticket
+// (?q=&sessionId=&lang=&limit=)
+func suggestFaqs(c *gin.Context) {
+	proxyFAQRequest(c, http.MethodGet, "suggest", nil)
+}
+
+// markFaqSolved registra que una FAQ sugerida resolvió el problema del visitante
+func markFaqSolved(c *gin.Context) {
+	proxyFAQBody(c, "solved")
+}
+
+// recordFaqView registra que el visitante abrió una FAQ
+func recordFaqView(c *gin.Context) {
+	proxyFAQBody(c, "view")
+}
+
+// voteFaq registra si una FAQ le resultó útil al visitante, con un comentario opcional
+func voteFaq(c *gin.Context) {
+	proxyFAQBody(c, "vote")
+}
+
+// proxyFAQBody reenvía al backend una solicitud POST con un cuerpo JSON limitado
+func proxyFAQBody(c *gin.Context, path string) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 16*1024))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Error al leer la solicitud"})
+		return
+	}
+	proxyFAQRequest(c, http.MethodPost, path, bytes.NewReader(body))
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tipos de eventos enviados por el backend de GrowDesk
+const (
+	eventMessageAdded  = "message_added"
+	eventStatusChanged = "status_changed"
+	eventTicketMerged  = "ticket_merged"
+	eventSurvey        = "survey_requested"
+)
+
+// Errores al aplicar un evento al ticket local
+var (
+	// errDuplicateMessage indica que el mensaje del evento ya fue entregado
+	errDuplicateMessage = errors.New("mensaje duplicado")
+	// errSeedFromGrowDesk indica que el ticket no existe localmente y no se pudo traer de GrowDesk
+	errSeedFromGrowDesk = errors.New("no se pudo obtener el ticket de GrowDesk")
+)
+
+// InternalEventMessage representa el mensaje incluido en un evento del backend
+type InternalEventMessage struct {
+	ID         string    `json:"id"`
+	Content    string    `json:"content"`
+	IsClient   bool      `json:"isClient"`
+	IsInternal bool      `json:"isInternal"`
+	Visibility string    `json:"visibility"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UserName   string    `json:"userName"`
+	UserEmail  string    `json:"userEmail"`
+}
+
+// InternalEvent representa un evento de ticket enviado por el backend
+type InternalEvent struct {
+	Type        string                `json:"type" binding:"required"`
+	TicketID    string                `json:"ticketId" binding:"required"`
+	Message     *InternalEventMessage `json:"message"`
+	Status      string                `json:"status"`
+	MergedInto  string                `json:"mergedInto"`
+	SurveyToken string                `json:"surveyToken"`
+	Timestamp   time.Time             `json:"timestamp"`
+}
+
+// validInternalToken compara el token recibido con INTERNAL_EVENTS_TOKEN.
+// Si la variable no está definida, el canal interno queda deshabilitado.
+func validInternalToken(token string) bool {
+	expected := os.Getenv("INTERNAL_EVENTS_TOKEN")
+	if expected == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// handleInternalEvent recibe respuestas de agentes y cambios de estado desde el backend
+// y los entrega a los visitantes conectados por WebSocket
+func handleInternalEvent(c *gin.Context) {
+	if !validInternalToken(c.GetHeader("X-Internal-Token")) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token interno inválido"})
+		return
+	}
+
+	var event InternalEvent
+	if err := c.ShouldBindJSON(&event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch event.Type {
+	case eventMessageAdded:
+		handleMessageAddedEvent(c, event)
+	case eventStatusChanged:
+		handleStatusChangedEvent(c, event)
+	case eventTicketMerged:
+		handleTicketMergedEvent(c, event)
+	case eventSurvey:
+		handleSurveyEvent(c, event)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tipo de evento desconocido"})
+	}
+}
+
+// handleMessageAddedEvent guarda la respuesta del agente en el ticket local y la envía al visitante
+func handleMessageAddedEvent(c *gin.Context, event InternalEvent) {
+	if event.Message == nil || event.Message.ID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El evento no incluye un mensaje válido"})
+		return
+	}
+
+	// Las notas internas, los eventos del sistema y los mensajes del propio cliente
+	// nunca se entregan al visitante
+	if !isPublicMessage(event.Message.IsInternal, event.Message.Visibility) || event.Message.IsClient {
+		c.JSON(http.StatusOK, gin.H{"success": true, "delivered": false})
+		return
+	}
+
+	message := Message{
+		ID:        event.Message.ID,
+		Content:   event.Message.Content,
+		IsClient:  false,
+		CreatedAt: event.Message.CreatedAt,
+		UserName:  event.Message.UserName,
+		UserEmail: event.Message.UserEmail,
+	}
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = time.Now()
+	}
+
+	// Agregar el mensaje solo si no fue entregado antes (los reintentos del backend pueden repetirlo)
+	seeded, err := updateEventTicket(event.TicketID, func(ticket *Ticket) error {
+		for _, existing := range ticket.Messages {
+			if existing.ID == message.ID {
+				return errDuplicateMessage
+			}
+		}
+		ticket.Messages = append(ticket.Messages, message)
+		ticket.UpdatedAt = time.Now()
+		return nil
+	})
+	// Un ticket traído de GrowDesk ya incluye el mensaje, pero el visitante aún no lo recibió
+	if seeded && errors.Is(err, errDuplicateMessage) {
+		err = nil
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, errDuplicateMessage):
+			c.JSON(http.StatusOK, gin.H{"success": true, "delivered": false})
+		case errors.Is(err, errSeedFromGrowDesk):
+			log.Printf("Error al traer de GrowDesk el ticket %s del evento: %v", event.TicketID, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "No se pudo obtener el ticket de GrowDesk", "details": err.Error()})
+		default:
+			log.Printf("Error al guardar mensaje del evento para ticket %s: %v", event.TicketID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al guardar mensaje", "details": err.Error()})
+		}
+		return
+	}
+
+	sendMessageToWebSocketClients(event.TicketID, message)
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "delivered": true})
+}
+
+// updateEventTicket aplica update al ticket local del evento. Si el visitante nunca escribió
+// desde este servidor el ticket no existe localmente: se trae de GrowDesk, se guarda y se
+// vuelve a aplicar update, como en AppendTicketMessage. seeded indica si el ticket se trajo
+// de GrowDesk.
+func updateEventTicket(ticketID string, update func(*Ticket) error) (seeded bool, err error) {
+	_, err = ticketStore.Update(ticketID, update)
+	if !errors.Is(err, ErrTicketNotFound) {
+		return false, err
+	}
+
+	ticket, err := getTicketFromGrowDesk(ticketID)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", errSeedFromGrowDesk, err)
+	}
+	if err := ticketStore.Seed(ticket); err != nil {
+		return false, err
+	}
+
+	_, err = ticketStore.Update(ticketID, update)
+	return true, err
+}
+
+// handleStatusChangedEvent actualiza el estado del ticket local y lo notifica al visitante
+func handleStatusChangedEvent(c *gin.Context, event InternalEvent) {
+	if event.Status == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El evento no incluye un estado"})
+		return
+	}
+
+	_, err := updateEventTicket(event.TicketID, func(ticket *Ticket) error {
+		ticket.Status = event.Status
+		ticket.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errSeedFromGrowDesk) {
+			log.Printf("Error al traer de GrowDesk el ticket %s del evento: %v", event.TicketID, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "No se pudo obtener el ticket de GrowDesk", "details": err.Error()})
+			return
+		}
+		log.Printf("Error al actualizar estado del ticket %s: %v", event.TicketID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar estado", "details": err.Error()})
+		return
+	}
+
+	broadcastWebSocketEvent(event.TicketID, map[string]interface{}{
+		"type":     "status_changed",
+		"ticketId": event.TicketID,
+		"status":   event.Status,
+		"data":     map[string]interface{}{"status": event.Status},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// handleTicketMergedEvent marca el ticket local como fusionado y traslada a sus visitantes
+// conectados al ticket destino
+func handleTicketMergedEvent(c *gin.Context, event InternalEvent) {
+	if event.MergedInto == "" || event.MergedInto == event.TicketID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El evento no incluye un ticket destino válido"})
+		return
+	}
+
+	// El ticket puede no existir localmente si el visitante nunca escribió desde este servidor
+	_, err := ticketStore.Update(event.TicketID, func(ticket *Ticket) error {
+		ticket.Status = "closed"
+		ticket.MergedInto = event.MergedInto
+		ticket.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrTicketNotFound) {
+		log.Printf("Error al marcar ticket %s como fusionado: %v", event.TicketID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al actualizar ticket", "details": err.Error()})
+		return
+	}
+
+	broadcastWebSocketEvent(event.TicketID, map[string]interface{}{
+		"type":       "ticket_merged",
+		"ticketId":   event.TicketID,
+		"mergedInto": event.MergedInto,
+		"data":       map[string]interface{}{"mergedInto": event.MergedInto},
+	})
+	moveWebSocketConnections(event.TicketID, event.MergedInto)
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// handleSurveyEvent pide al visitante conectado que valore la atención recibida
+func handleSurveyEvent(c *gin.Context, event InternalEvent) {
+	if event.SurveyToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El evento no incluye una encuesta"})
+		return
+	}
+
+	broadcastWebSocketEvent(event.TicketID, map[string]interface{}{
+		"type":     "csat_request",
+		"ticketId": event.TicketID,
+		"token":    event.SurveyToken,
+		"data":     map[string]interface{}{"token": event.SurveyToken},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// useTestTicketStore reemplaza el almacenamiento de tickets por uno en un directorio
+// temporal durante la prueba
+func useTestTicketStore(t *testing.T) TicketStore {
+	t.Helper()
+
+	store, err := newFileTicketStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("newFileTicketStore: %v", err)
+	}
+	previous := ticketStore
+	ticketStore = store
+	t.Cleanup(func() { ticketStore = previous })
+	return store
+}
+
+// postInternalEvent envía un evento al canal interno y devuelve la respuesta decodificada
+func postInternalEvent(t *testing.T, router *gin.Engine, event InternalEvent) map[string]interface{} {
+	t.Helper()
+
+	rec := sendInternalEvent(router, event)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("código %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("respuesta inválida: %v", err)
+	}
+	return response
+}
+
+// sendInternalEvent envía un evento al canal interno con el token de las pruebas
+func sendInternalEvent(router *gin.Engine, event InternalEvent) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(event)
+	req := httptest.NewRequest(http.MethodPost, "/internal/events", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Internal-Token", "secreto")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMessageAddedEventOnlyDeliversPublicReplies(t *testing.T) {
+	t.Setenv("INTERNAL_EVENTS_TOKEN", "secreto")
+	store := useTestTicketStore(t)
+	if err := store.Save(Ticket{ID: "TICKET-1", Title: "Consulta", Status: "open", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	router := gin.New()
+	router.POST("/internal/events", handleInternalEvent)
+
+	tests := []struct {
+		name      string
+		message   InternalEventMessage
+		delivered bool
+	}{
+		{"interna", InternalEventMessage{ID: "internal", Content: "Nota interna", IsInternal: true, Visibility: "internal"}, false},
+		{"sistema", InternalEventMessage{ID: "system", Content: "Ticket asignado", IsInternal: true, Visibility: "system"}, false},
+		{"nota anterior a la visibilidad", InternalEventMessage{ID: "legacy-note", Content: "Nota antigua", IsInternal: true}, false},
+		{"del cliente", InternalEventMessage{ID: "client", Content: "Gracias", IsClient: true, Visibility: "public"}, false},
+		{"pública", InternalEventMessage{ID: "public", Content: "Respuesta", Visibility: "public"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := tt.message
+			response := postInternalEvent(t, router, InternalEvent{Type: eventMessageAdded, TicketID: "TICKET-1", Message: &message})
+			if response["delivered"] != tt.delivered {
+				t.Errorf("delivered = %v, se esperaba %v", response["delivered"], tt.delivered)
+			}
+		})
+	}
+
+	// Solo la respuesta pública queda guardada en el ticket del widget
+	ticket, err := store.Load("TICKET-1")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	ids := make([]string, 0, len(ticket.Messages))
+	for _, message := range ticket.Messages {
+		ids = append(ids, message.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"public"}) {
+		t.Errorf("el ticket guardó %v, se esperaba solo [public]", ids)
+	}
+}
+
+// serveGrowDeskTicket simula la API de GrowDesk devolviendo el ticket indicado
+func serveGrowDeskTicket(t *testing.T, body string) {
+	t.Helper()
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tickets/TICKET-2" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(backend.Close)
+	t.Setenv("GROWDESK_API_URL", backend.URL)
+	t.Setenv("GROWDESK_API_KEY", "token")
+}
+
+func TestEventsSeedTicketsMissingLocally(t *testing.T) {
+	t.Setenv("INTERNAL_EVENTS_TOKEN", "secreto")
+	serveGrowDeskTicket(t, `{"id": "TICKET-2", "title": "Consulta", "status": "in_progress", "createdAt": "2026-10-01T10:00:00Z",
+		"customer": {"name": "Cliente", "email": "cliente@example.com"},
+		"messages": [{"id": "reply", "content": "Respuesta", "isClient": false, "visibility": "public"}]}`)
+
+	router := gin.New()
+	router.POST("/internal/events", handleInternalEvent)
+
+	tests := []struct {
+		name  string
+		event InternalEvent
+		check func(t *testing.T, response map[string]interface{}, ticket Ticket)
+	}{
+		{
+			"respuesta ya incluida en el ticket de GrowDesk",
+			InternalEvent{Type: eventMessageAdded, TicketID: "TICKET-2", Message: &InternalEventMessage{ID: "reply", Content: "Respuesta", Visibility: "public"}},
+			func(t *testing.T, response map[string]interface{}, ticket Ticket) {
+				if response["delivered"] != true {
+					t.Errorf("delivered = %v, se esperaba true", response["delivered"])
+				}
+				if len(ticket.Messages) != 1 || ticket.Messages[0].ID != "reply" {
+					t.Errorf("mensajes guardados %+v, se esperaba solo la respuesta", ticket.Messages)
+				}
+			},
+		},
+		{
+			"cambio de estado",
+			InternalEvent{Type: eventStatusChanged, TicketID: "TICKET-2", Status: "resolved"},
+			func(t *testing.T, response map[string]interface{}, ticket Ticket) {
+				if ticket.Status != "resolved" {
+					t.Errorf("estado %q, se esperaba resolved", ticket.Status)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := useTestTicketStore(t)
+			response := postInternalEvent(t, router, tt.event)
+
+			ticket, err := store.Load("TICKET-2")
+			if err != nil {
+				t.Fatalf("el ticket no se guardó localmente: %v", err)
+			}
+			tt.check(t, response, ticket)
+		})
+	}
+}
+
+func TestEventForUnknownTicketFailsWhenGrowDeskIsUnavailable(t *testing.T) {
+	t.Setenv("INTERNAL_EVENTS_TOKEN", "secreto")
+	serveGrowDeskTicket(t, `{}`)
+	useTestTicketStore(t)
+
+	router := gin.New()
+	router.POST("/internal/events", handleInternalEvent)
+
+	// El backend reintenta los eventos que no reciben una respuesta exitosa
+	rec := sendInternalEvent(router, InternalEvent{Type: eventStatusChanged, TicketID: "TICKET-3", Status: "resolved"})
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("código %d, se esperaba %d", rec.Code, http.StatusBadGateway)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// backendMessages es la respuesta de un backend que, por error, devolviera notas internas
+// y eventos del sistema junto con las respuestas públicas
+const backendMessages = `[
+	{"id": "public", "content": "Hola", "isClient": false, "visibility": "public"},
+	{"id": "internal", "content": "Nota interna", "isInternal": true, "visibility": "internal"},
+	{"id": "system", "content": "Ticket asignado", "isInternal": true, "visibility": "system"},
+	{"id": "legacy-note", "content": "Nota antigua", "isInternal": true},
+	{"id": "legacy-reply", "content": "Respuesta antigua", "isClient": false},
+	{"id": "client", "content": "Gracias", "isClient": true, "visibility": "public"}
+]`
+
+// publicMessageIDs son los mensajes de backendMessages que puede ver el visitante
+var publicMessageIDs = []string{"public", "legacy-reply", "client"}
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestIsPublicMessage(t *testing.T) {
+	tests := []struct {
+		name       string
+		isInternal bool
+		visibility string
+		want       bool
+	}{
+		{"pública", false, "public", true},
+		{"interna", true, "internal", false},
+		{"sistema", true, "system", false},
+		{"sistema sin IsInternal", false, "system", false},
+		{"visibilidad prioritaria sobre IsInternal", true, "public", true},
+		{"nota anterior a la visibilidad", true, "", false},
+		{"respuesta anterior a la visibilidad", false, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPublicMessage(tt.isInternal, tt.visibility); got != tt.want {
+				t.Errorf("isPublicMessage(%v, %q) = %v, se esperaba %v", tt.isInternal, tt.visibility, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetMessagesProxyDropsNonPublicMessages(t *testing.T) {
+	var requestedPath string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(backendMessages))
+	}))
+	defer backend.Close()
+	t.Setenv("GROWDESK_API_URL", backend.URL)
+
+	router := gin.New()
+	router.GET("/widget/tickets/:ticketId/messages", getMessages)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/widget/tickets/TICKET-1/messages", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("código %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// La API del widget debe usar la ruta pública del backend, no la de agentes
+	if requestedPath != "/widget/tickets/TICKET-1/messages" {
+		t.Errorf("se consultó %s, se esperaba la ruta del widget", requestedPath)
+	}
+
+	var messages []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &messages); err != nil {
+		t.Fatalf("respuesta inválida: %v", err)
+	}
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+	if !reflect.DeepEqual(ids, publicMessageIDs) {
+		t.Errorf("mensajes %v, se esperaba %v", ids, publicMessageIDs)
+	}
+}
+
+func TestGetTicketFromGrowDeskDropsNonPublicMessages(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "TICKET-1", "title": "Consulta", "status": "open", "createdAt": "2026-10-01T10:00:00Z",
+			"customer": {"name": "Cliente", "email": "cliente@example.com"}, "messages": ` + backendMessages + `}`))
+	}))
+	defer backend.Close()
+	t.Setenv("GROWDESK_API_URL", backend.URL)
+	t.Setenv("GROWDESK_API_KEY", "token")
+
+	ticket, err := getTicketFromGrowDesk("TICKET-1")
+	if err != nil {
+		t.Fatalf("getTicketFromGrowDesk: %v", err)
+	}
+
+	ids := make([]string, 0, len(ticket.Messages))
+	for _, message := range ticket.Messages {
+		ids = append(ids, message.ID)
+	}
+	if !reflect.DeepEqual(ids, publicMessageIDs) {
+		t.Errorf("el ticket guardaría %v, se esperaba %v", ids, publicMessageIDs)
+	}
+}
+
+func TestCreateTicketStoresBackendAutoReply(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/widget/tickets" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"success": true, "liveChatAvailable": false, "offlineMode": true,
+			"autoReply": "Estamos fuera de horario.", "autoReplyMessageId": "MSG-BACKEND", "expectedResponseTime": "2 horas"}`))
+	}))
+	defer backend.Close()
+	t.Setenv("GROWDESK_API_URL", backend.URL)
+	t.Setenv("GROWDESK_API_KEY", "token")
+	store := useTestTicketStore(t)
+
+	router := gin.New()
+	router.POST("/widget/tickets", createTicket)
+
+	body := `{"name": "Cliente", "email": "cliente@example.com", "message": "Hola"}`
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/widget/tickets", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("código %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		TicketID  string `json:"ticketId"`
+		AutoReply string `json:"autoReply"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("respuesta inválida: %v", err)
+	}
+	if response.AutoReply != "Estamos fuera de horario." {
+		t.Errorf("autoReply = %q, se esperaba la del backend", response.AutoReply)
+	}
+
+	// El ticket local tiene el mensaje del visitante y una única respuesta automática,
+	// con el mismo ID que en el backend
+	ticket, err := store.Load(response.TicketID)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var replies []string
+	for _, message := range ticket.Messages {
+		if !message.IsClient {
+			replies = append(replies, message.ID)
+		}
+	}
+	if !reflect.DeepEqual(replies, []string{"MSG-BACKEND"}) {
+		t.Errorf("respuestas guardadas %v, se esperaba solo [MSG-BACKEND]", replies)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreChatForm es el formulario configurado en GrowDesk que el visitante completa antes del chat
+type PreChatForm struct {
+	ID          string         `json:"id"`
+	WidgetID    string         `json:"widgetId,omitempty"`
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Fields      []PreChatField `json:"fields"`
+	Active      bool           `json:"active"`
+}
+
+// PreChatField es un campo del formulario previo al chat
+type PreChatField struct {
+	Key         string   `json:"key"`
+	Label       string   `json:"label"`
+	Type        string   `json:"type"`
+	Required    bool     `json:"required"`
+	Placeholder string   `json:"placeholder,omitempty"`
+	Options     []string `json:"options,omitempty"`
+	MinLength   int      `json:"minLength,omitempty"`
+	MaxLength   int      `json:"maxLength,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+	MapTo       string   `json:"mapTo,omitempty"`
+}
+
+// defaultPreChatForm es el formulario usado si el backend no responde (nombre, email y mensaje)
+func defaultPreChatForm() PreChatForm {
+	return PreChatForm{
+		ID:     "default",
+		Title:  "Iniciar conversación",
+		Active: true,
+		Fields: []PreChatField{
+			{Key: "name", Label: "Nombre", Type: "text", Required: true, MaxLength: 100, MapTo: "name"},
+			{Key: "email", Label: "Email", Type: "email", Required: true, MaxLength: 200, MapTo: "email"},
+			{Key: "message", Label: "Mensaje", Type: "textarea", Required: true, MaxLength: 5000, MapTo: "message"},
+		},
+	}
+}
+
+// fetchPreChatForm consulta /widget/form en el backend de GrowDesk
+func fetchPreChatForm(widgetID string) (PreChatForm, error) {
+	apiURL := os.Getenv("GROWDESK_API_URL")
+	if apiURL == "" {
+		apiURL = "http://localhost:8080"
+	}
+
+	formURL := fmt.Sprintf("%s/widget/form", strings.TrimSuffix(apiURL, "/"))
+	if widgetID != "" {
+		formURL += "?" + url.Values{"widgetId": {widgetID}}.Encode()
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(formURL)
+	if err != nil {
+		return PreChatForm{}, fmt.Errorf("error al consultar formulario del widget: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return PreChatForm{}, fmt.Errorf("respuesta no exitosa del backend: %d", resp.StatusCode)
+	}
+
+	var form PreChatForm
+	if err := json.NewDecoder(resp.Body).Decode(&form); err != nil {
+		return PreChatForm{}, fmt.Errorf("error al analizar formulario del widget: %v", err)
+	}
+	if len(form.Fields) == 0 {
+		return PreChatForm{}, fmt.Errorf("el formulario del widget no tiene campos")
+	}
+
+	return form, nil
+}
+
+// preChatFormOrDefault consulta el formulario y usa el integrado si el backend falla
+func preChatFormOrDefault(widgetID string) PreChatForm {
+	form, err := fetchPreChatForm(widgetID)
+	if err != nil {
+		log.Printf("No se pudo obtener el formulario del widget, usando el formulario por defecto: %v", err)
+		return defaultPreChatForm()
+	}
+	return form
+}
+
+// getPreChatForm devuelve el formulario previo al chat del widget
+func getPreChatForm(c *gin.Context) {
+	widgetID := c.Query("widgetId")
+	if widgetID == "" {
+		widgetID = c.GetHeader("X-Widget-ID")
+	}
+
+	c.JSON(http.StatusOK, preChatFormOrDefault(widgetID))
+}
+
+// legacyFieldValue devuelve los valores enviados fuera de "fields" por versiones anteriores del widget
+func legacyFieldValue(data *TicketData, target string) interface{} {
+	var value string
+	switch target {
+	case "name":
+		value = data.Name
+	case "email":
+		value = data.Email
+	case "subject":
+		value = data.Subject
+	case "message":
+		value = data.Message
+	case "priority":
+		value = data.Priority
+	case "department":
+		value = data.Department
+	case "category":
+		value = data.Category
+	}
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// validatePreChatValue comprueba un valor contra la definición del campo y lo normaliza
+func validatePreChatValue(field PreChatField, raw interface{}) (interface{}, string) {
+	if field.Type == "checkbox" {
+		checked := false
+		switch v := raw.(type) {
+		case nil:
+		case bool:
+			checked = v
+		case string:
+			checked = v == "true" || v == "on" || v == "1"
+		default:
+			return nil, fmt.Sprintf("El campo '%s' debe ser verdadero o falso", field.Label)
+		}
+		if field.Required && !checked {
+			return nil, fmt.Sprintf("El campo '%s' es obligatorio", field.Label)
+		}
+		return checked, ""
+	}
+
+	value := ""
+	switch v := raw.(type) {
+	case nil:
+	case string:
+		value = strings.TrimSpace(v)
+	case float64, bool:
+		value = fmt.Sprintf("%v", v)
+	default:
+		return nil, fmt.Sprintf("El campo '%s' tiene un formato inválido", field.Label)
+	}
+
+	if value == "" {
+		if field.Required {
+			return nil, fmt.Sprintf("El campo '%s' es obligatorio", field.Label)
+		}
+		return nil, ""
+	}
+
+	length := utf8.RuneCountInString(value)
+	if field.MinLength > 0 && length < field.MinLength {
+		return nil, fmt.Sprintf("El campo '%s' debe tener al menos %d caracteres", field.Label, field.MinLength)
+	}
+	if field.MaxLength > 0 && length > field.MaxLength {
+		return nil, fmt.Sprintf("El campo '%s' no puede superar los %d caracteres", field.Label, field.MaxLength)
+	}
+
+	switch field.Type {
+	case "email":
+		if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+			return nil, fmt.Sprintf("El campo '%s' debe ser un email válido", field.Label)
+		}
+	case "select":
+		valid := false
+		for _, option := range field.Options {
+			if option == value {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Sprintf("El valor del campo '%s' no es una opción válida", field.Label)
+		}
+	}
+
+	if field.Pattern != "" {
+		re, err := regexp.Compile(field.Pattern)
+		if err != nil {
+			log.Printf("Expresión regular inválida en el campo %s: %v", field.Key, err)
+		} else if !re.MatchString(value) {
+			return nil, fmt.Sprintf("El campo '%s' no tiene el formato esperado", field.Label)
+		}
+	}
+
+	return value, ""
+}
+
+// applyPreChatForm valida el envío del visitante contra el formulario y copia cada valor
+// a su destino en el ticket. Devuelve la lista de errores de validación.
+func applyPreChatForm(form PreChatForm, data *TicketData) []string {
+	errors := []string{}
+	customFields := make(map[string]interface{})
+	for key, value := range data.CustomFields {
+		customFields[key] = value
+	}
+
+	for _, field := range form.Fields {
+		raw, ok := data.Fields[field.Key]
+		if !ok || raw == nil {
+			target := field.MapTo
+			if target == "" {
+				target = field.Key
+			}
+			raw = legacyFieldValue(data, target)
+		}
+
+		value, problem := validatePreChatValue(field, raw)
+		if problem != "" {
+			errors = append(errors, problem)
+			continue
+		}
+		if value == nil {
+			continue
+		}
+
+		text, _ := value.(string)
+		switch {
+		case field.MapTo == "name":
+			data.Name = text
+		case field.MapTo == "email":
+			data.Email = text
+		case field.MapTo == "subject":
+			data.Subject = text
+		case field.MapTo == "message":
+			data.Message = text
+		case field.MapTo == "priority":
+			data.Priority = text
+		case field.MapTo == "department":
+			data.Department = text
+		case field.MapTo == "category":
+			data.Category = text
+		case field.MapTo == "tags":
+			// Un checkbox marcado aporta su clave como etiqueta; el texto se separa por comas
+			if checked, isBool := value.(bool); isBool {
+				if checked {
+					data.Tags = append(data.Tags, field.Key)
+				}
+				continue
+			}
+			for _, tag := range strings.Split(text, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					data.Tags = append(data.Tags, tag)
+				}
+			}
+		case strings.HasPrefix(field.MapTo, "customField:"):
+			customFields[strings.TrimPrefix(field.MapTo, "customField:")] = value
+		default:
+			customFields[field.Key] = value
+		}
+	}
+
+	if len(customFields) > 0 {
+		data.CustomFields = customFields
+	}
+
+	return errors
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// surveyURL construye la URL de la encuesta de satisfacción en el backend de GrowDesk
+func surveyURL(token string) string {
+	apiURL := os.Getenv("GROWDESK_API_URL")
+	if apiURL == "" {
+		apiURL = "http://localhost:8080"
+	}
+	return fmt.Sprintf("%s/widget/csat/%s", strings.TrimSuffix(apiURL, "/"), url.PathEscape(token))
+}
+
+// proxySurveyRequest reenvía la solicitud de encuesta al backend y devuelve su respuesta tal cual,
+// para que el visitante reciba los mismos códigos (encuesta respondida, caducada, etc.)
+func proxySurveyRequest(c *gin.Context, method string, body io.Reader) {
+	req, err := http.NewRequest(method, surveyURL(c.Param("token")), body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al preparar la solicitud"})
+		return
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("Error al contactar con el backend para la encuesta: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "No se pudo contactar con el servidor de soporte"})
+		return
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Respuesta inválida del servidor de soporte"})
+		return
+	}
+
+	if resp.StatusCode >= 400 {
+		c.JSON(resp.StatusCode, gin.H{"error": strings.TrimSpace(string(respBody))})
+		return
+	}
+	c.Data(resp.StatusCode, "application/json", respBody)
+}
+
+// getSurvey devuelve los datos públicos de una encuesta de satisfacción
+func getSurvey(c *gin.Context) {
+	proxySurveyRequest(c, http.MethodGet, nil)
+}
+
+// submitSurvey envía la valoración del visitante al backend
+func submitSurvey(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 64*1024))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Error al leer la valoración"})
+		return
+	}
+	proxySurveyRequest(c, http.MethodPost, bytes.NewReader(body))
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// ErrTicketNotFound se devuelve cuando un ticket no existe en el almacenamiento
+var ErrTicketNotFound = errors.New("ticket no encontrado")
+
+// TicketStore define el almacenamiento de tickets del widget
+type TicketStore interface {
+	// Load obtiene un ticket por ID o devuelve ErrTicketNotFound
+	Load(ticketID string) (Ticket, error)
+	// Save crea o reemplaza un ticket completo
+	Save(ticket Ticket) error
+	// Update aplica fn sobre el ticket de forma atómica (lectura-modificación-escritura)
+	// y guarda el resultado solo si fn no devuelve error
+	Update(ticketID string, fn func(ticket *Ticket) error) (Ticket, error)
+	// Seed guarda el ticket solo si aún no existe; si ya existe no lo modifica
+	Seed(ticket Ticket) error
+}
+
+// ticketStore es el almacenamiento de tickets usado por los handlers
+var ticketStore TicketStore
+
+// newTicketStoreFromEnv crea el almacenamiento indicado por TICKET_STORE ("file" o "postgres")
+func newTicketStoreFromEnv() (TicketStore, error) {
+	kind := strings.ToLower(os.Getenv("TICKET_STORE"))
+	if kind == "" {
+		kind = "file"
+	}
+
+	switch kind {
+	case "file":
+		dataDir := os.Getenv("DATA_DIR")
+		if dataDir == "" {
+			dataDir = "data"
+		}
+		log.Printf("Usando almacenamiento de tickets en archivos: %s", dataDir)
+		return newFileTicketStore(dataDir)
+	case "postgres":
+		log.Printf("Usando almacenamiento de tickets en PostgreSQL")
+		return newPostgresTicketStore()
+	default:
+		return nil, fmt.Errorf("TICKET_STORE desconocido: %s", kind)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileTicketStore guarda cada ticket como data/ticket_<id>.json
+type fileTicketStore struct {
+	dir string
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex // Un lock por ticket
+}
+
+// newFileTicketStore crea el almacenamiento en archivos y su directorio si no existe
+func newFileTicketStore(dir string) (*fileTicketStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error al crear directorio de datos: %v", err)
+	}
+
+	return &fileTicketStore{
+		dir:   dir,
+		locks: make(map[string]*sync.Mutex),
+	}, nil
+}
+
+// lockFor devuelve el lock asociado a un ticket, creándolo si es necesario
+func (s *fileTicketStore) lockFor(ticketID string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, exists := s.locks[ticketID]
+	if !exists {
+		lock = &sync.Mutex{}
+		s.locks[ticketID] = lock
+	}
+	return lock
+}
+
+// path devuelve la ruta del archivo de un ticket
+func (s *fileTicketStore) path(ticketID string) string {
+	return filepath.Join(s.dir, fmt.Sprintf("ticket_%s.json", filepath.Base(ticketID)))
+}
+
+// Load lee un ticket desde su archivo
+func (s *fileTicketStore) Load(ticketID string) (Ticket, error) {
+	lock := s.lockFor(ticketID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	return s.read(ticketID)
+}
+
+// Save escribe un ticket completo en su archivo
+func (s *fileTicketStore) Save(ticket Ticket) error {
+	lock := s.lockFor(ticket.ID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	return s.write(ticket)
+}
+
+// Update lee, modifica y escribe un ticket manteniendo su lock durante toda la operación
+func (s *fileTicketStore) Update(ticketID string, fn func(ticket *Ticket) error) (Ticket, error) {
+	lock := s.lockFor(ticketID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	ticket, err := s.read(ticketID)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	if err := fn(&ticket); err != nil {
+		return Ticket{}, err
+	}
+
+	if err := s.write(ticket); err != nil {
+		return Ticket{}, err
+	}
+
+	return ticket, nil
+}
+
+// Seed escribe el ticket solo si su archivo no existe, con el lock del ticket tomado
+func (s *fileTicketStore) Seed(ticket Ticket) error {
+	lock := s.lockFor(ticket.ID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if _, err := s.read(ticket.ID); !errors.Is(err, ErrTicketNotFound) {
+		return err
+	}
+
+	return s.write(ticket)
+}
+
+// read lee el archivo de un ticket; el llamador debe tener el lock del ticket
+func (s *fileTicketStore) read(ticketID string) (Ticket, error) {
+	data, err := os.ReadFile(s.path(ticketID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Ticket{}, ErrTicketNotFound
+		}
+		return Ticket{}, fmt.Errorf("error al leer archivo de ticket: %v", err)
+	}
+
+	var ticket Ticket
+	if err := json.Unmarshal(data, &ticket); err != nil {
+		return Ticket{}, fmt.Errorf("error al analizar archivo de ticket: %v", err)
+	}
+
+	return ticket, nil
+}
+
+// write escribe el ticket de forma atómica (archivo temporal + rename);
+// el llamador debe tener el lock del ticket
+func (s *fileTicketStore) write(ticket Ticket) error {
+	data, err := json.MarshalIndent(ticket, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error al serializar ticket: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, fmt.Sprintf(".ticket_%s-*.tmp", filepath.Base(ticket.ID)))
+	if err != nil {
+		return fmt.Errorf("error al crear archivo temporal: %v", err)
+	}
+	tmpName := tmp.Name()
+
+	// Eliminar el temporal si algo falla antes del rename
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error al escribir archivo temporal: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error al sincronizar archivo temporal: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error al cerrar archivo temporal: %v", err)
+	}
+	if err := os.Chmod(tmpName, 0644); err != nil {
+		return fmt.Errorf("error al establecer permisos: %v", err)
+	}
+
+	if err := os.Rename(tmpName, s.path(ticket.ID)); err != nil {
+		return fmt.Errorf("error al reemplazar archivo de ticket: %v", err)
+	}
+
+	return nil
+}
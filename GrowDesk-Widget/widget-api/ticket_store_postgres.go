@@ -0,0 +1,366 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/lib/pq"
+)
+
+// postgresTicketStore guarda los tickets en las tablas widget_tickets y widget_messages
+type postgresTicketStore struct {
+	db *sql.DB
+}
+
+// queryer agrupa los métodos comunes de *sql.DB y *sql.Tx
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// newPostgresTicketStore abre la conexión usando las variables DB_*
+func newPostgresTicketStore() (*postgresTicketStore, error) {
+	connStr := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		getEnvOrDefault("DB_HOST", "localhost"),
+		getEnvOrDefault("DB_PORT", "5432"),
+		getEnvOrDefault("DB_USER", "postgres"),
+		getEnvOrDefault("DB_PASSWORD", "postgres"),
+		getEnvOrDefault("DB_NAME", "growdesk"),
+		getEnvOrDefault("DB_SSLMODE", "disable"),
+	)
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir conexión: %v", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("error al verificar conexión: %v", err)
+	}
+
+	return &postgresTicketStore{db: db}, nil
+}
+
+// Load obtiene un ticket y sus mensajes
+func (s *postgresTicketStore) Load(ticketID string) (Ticket, error) {
+	ticket, _, err := s.load(s.db, ticketID, false)
+	return ticket, err
+}
+
+// Save crea o reemplaza un ticket y sus mensajes en una transacción
+func (s *postgresTicketStore) Save(ticket Ticket) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	rowID, err := s.resolveRowID(tx, ticket.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.save(tx, rowID, ticket); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+	return nil
+}
+
+// Update bloquea la fila del ticket (SELECT ... FOR UPDATE) mientras se aplica fn
+func (s *postgresTicketStore) Update(ticketID string, fn func(ticket *Ticket) error) (Ticket, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return Ticket{}, fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	ticket, rowID, err := s.load(tx, ticketID, true)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	if err := fn(&ticket); err != nil {
+		return Ticket{}, err
+	}
+
+	if err := s.save(tx, rowID, ticket); err != nil {
+		return Ticket{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Ticket{}, fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+	return ticket, nil
+}
+
+// Seed guarda el ticket solo si aún no existe. Un lock consultivo por ID serializa las
+// altas concurrentes del mismo ticket, que todavía no tiene fila que bloquear.
+func (s *postgresTicketStore) Seed(ticket Ticket) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, ticket.ID); err != nil {
+		return fmt.Errorf("error al bloquear ticket de widget: %v", err)
+	}
+
+	_, _, err = s.load(tx, ticket.ID, true)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, ErrTicketNotFound) {
+		return err
+	}
+
+	if err := s.save(tx, ticket.ID, ticket); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+	return nil
+}
+
+// resolveRowID devuelve el id de fila para un ticket. Los tickets migrados desde
+// archivos tienen un UUID como id y el ID del ticket en ticket_id.
+func (s *postgresTicketStore) resolveRowID(q queryer, ticketID string) (string, error) {
+	var rowID string
+	err := q.QueryRow(
+		`SELECT id FROM widget_tickets WHERE id = $1 OR ticket_id = $1 LIMIT 1`,
+		ticketID,
+	).Scan(&rowID)
+	if err == sql.ErrNoRows {
+		return ticketID, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error al consultar ticket de widget: %v", err)
+	}
+	return rowID, nil
+}
+
+// load lee un ticket y sus mensajes; con forUpdate bloquea la fila hasta el fin de la transacción
+func (s *postgresTicketStore) load(q queryer, ticketID string, forUpdate bool) (Ticket, string, error) {
+	query := `
+		SELECT id, COALESCE(ticket_id, id), title, COALESCE(subject, ''), COALESCE(description, ''),
+		       status, COALESCE(priority, ''), client_name, client_email,
+		       COALESCE(widget_id, ''), COALESCE(department, ''), metadata, custom_fields,
+		       COALESCE(merged_into, ''), created_at, updated_at
+		FROM widget_tickets
+		WHERE id = $1 OR ticket_id = $1
+		LIMIT 1
+	`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+
+	var ticket Ticket
+	var rowID string
+	var metadataJSON sql.NullString
+	var customFieldsJSON sql.NullString
+
+	err := q.QueryRow(query, ticketID).Scan(
+		&rowID,
+		&ticket.ID,
+		&ticket.Title,
+		&ticket.Subject,
+		&ticket.Description,
+		&ticket.Status,
+		&ticket.Priority,
+		&ticket.ClientName,
+		&ticket.ClientEmail,
+		&ticket.WidgetID,
+		&ticket.Department,
+		&metadataJSON,
+		&customFieldsJSON,
+		&ticket.MergedInto,
+		&ticket.CreatedAt,
+		&ticket.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return Ticket{}, "", ErrTicketNotFound
+	}
+	if err != nil {
+		return Ticket{}, "", fmt.Errorf("error al consultar ticket de widget: %v", err)
+	}
+
+	// El widget usa los mismos datos para el usuario y el cliente
+	ticket.UserName = ticket.ClientName
+	ticket.UserEmail = ticket.ClientEmail
+
+	if metadataJSON.Valid && metadataJSON.String != "" {
+		if err := json.Unmarshal([]byte(metadataJSON.String), &ticket.Metadata); err != nil {
+			return Ticket{}, "", fmt.Errorf("error al analizar metadata del ticket: %v", err)
+		}
+	}
+	if customFieldsJSON.Valid && customFieldsJSON.String != "" {
+		if err := json.Unmarshal([]byte(customFieldsJSON.String), &ticket.CustomFields); err != nil {
+			return Ticket{}, "", fmt.Errorf("error al analizar campos personalizados del ticket: %v", err)
+		}
+	}
+
+	rows, err := q.Query(`
+		SELECT id, content, is_client, COALESCE(user_name, ''), COALESCE(user_email, ''), created_at
+		FROM widget_messages
+		WHERE widget_ticket_id = $1
+		ORDER BY created_at ASC
+	`, rowID)
+	if err != nil {
+		return Ticket{}, "", fmt.Errorf("error al consultar mensajes de widget: %v", err)
+	}
+	defer rows.Close()
+
+	ticket.Messages = make([]Message, 0)
+	for rows.Next() {
+		var message Message
+		if err := rows.Scan(
+			&message.ID,
+			&message.Content,
+			&message.IsClient,
+			&message.UserName,
+			&message.UserEmail,
+			&message.CreatedAt,
+		); err != nil {
+			return Ticket{}, "", fmt.Errorf("error al escanear mensaje de widget: %v", err)
+		}
+		ticket.Messages = append(ticket.Messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return Ticket{}, "", fmt.Errorf("error al iterar mensajes de widget: %v", err)
+	}
+
+	return ticket, rowID, nil
+}
+
+// save inserta o actualiza la fila del ticket y reemplaza su lista de mensajes
+func (s *postgresTicketStore) save(q queryer, rowID string, ticket Ticket) error {
+	metadataJSON, err := json.Marshal(ticket.Metadata)
+	if err != nil {
+		return fmt.Errorf("error al serializar metadata: %v", err)
+	}
+
+	var customFieldsJSON sql.NullString
+	if len(ticket.CustomFields) > 0 {
+		data, err := json.Marshal(ticket.CustomFields)
+		if err != nil {
+			return fmt.Errorf("error al serializar campos personalizados: %v", err)
+		}
+		customFieldsJSON = sql.NullString{String: string(data), Valid: true}
+	}
+
+	clientName := ticket.ClientName
+	if clientName == "" {
+		clientName = ticket.UserName
+	}
+	clientEmail := ticket.ClientEmail
+	if clientEmail == "" {
+		clientEmail = ticket.UserEmail
+	}
+
+	// ticket_id y widget_id son claves foráneas: solo se enlazan cuando el ticket
+	// ya existe en el backend y el widget está registrado en widget_settings
+	_, err = q.Exec(`
+		INSERT INTO widget_tickets (
+			id, ticket_id, title, subject, description, status, priority,
+			client_name, client_email, widget_id, department, metadata, custom_fields, created_at, updated_at,
+			merged_into
+		) VALUES (
+			$1, (SELECT id FROM tickets WHERE id = $2), $3, $4, $5, $6, $7,
+			$8, $9, (SELECT widget_id FROM widget_settings WHERE widget_id = $10), $11, $12, $13, $14, $15,
+			NULLIF($16, '')
+		)
+		ON CONFLICT (id) DO UPDATE SET
+			ticket_id = COALESCE(EXCLUDED.ticket_id, widget_tickets.ticket_id),
+			title = EXCLUDED.title,
+			subject = EXCLUDED.subject,
+			description = EXCLUDED.description,
+			status = EXCLUDED.status,
+			priority = EXCLUDED.priority,
+			client_name = EXCLUDED.client_name,
+			client_email = EXCLUDED.client_email,
+			widget_id = COALESCE(EXCLUDED.widget_id, widget_tickets.widget_id),
+			department = EXCLUDED.department,
+			metadata = EXCLUDED.metadata,
+			custom_fields = EXCLUDED.custom_fields,
+			updated_at = EXCLUDED.updated_at,
+			merged_into = EXCLUDED.merged_into
+	`,
+		rowID,
+		ticket.ID,
+		ticket.Title,
+		ticket.Subject,
+		ticket.Description,
+		ticket.Status,
+		ticket.Priority,
+		clientName,
+		clientEmail,
+		ticket.WidgetID,
+		ticket.Department,
+		string(metadataJSON),
+		customFieldsJSON,
+		ticket.CreatedAt,
+		ticket.UpdatedAt,
+		ticket.MergedInto,
+	)
+	if err != nil {
+		return fmt.Errorf("error al guardar ticket de widget: %v", err)
+	}
+
+	messageIDs := make([]string, 0, len(ticket.Messages))
+	for _, message := range ticket.Messages {
+		messageIDs = append(messageIDs, message.ID)
+
+		_, err := q.Exec(`
+			INSERT INTO widget_messages (
+				id, widget_ticket_id, content, is_client, user_name, user_email, created_at
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7
+			)
+			ON CONFLICT (id) DO UPDATE SET
+				widget_ticket_id = EXCLUDED.widget_ticket_id,
+				content = EXCLUDED.content
+		`,
+			message.ID,
+			rowID,
+			message.Content,
+			message.IsClient,
+			message.UserName,
+			message.UserEmail,
+			message.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("error al guardar mensaje %s: %v", message.ID, err)
+		}
+	}
+
+	// Eliminar los mensajes que ya no forman parte del ticket
+	_, err = q.Exec(
+		`DELETE FROM widget_messages WHERE widget_ticket_id = $1 AND NOT (id = ANY($2))`,
+		rowID, pq.Array(messageIDs),
+	)
+	if err != nil {
+		return fmt.Errorf("error al limpiar mensajes de widget: %v", err)
+	}
+
+	return nil
+}
+
+// getEnvOrDefault obtiene una variable de entorno o un valor por defecto
+func getEnvOrDefault(key, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
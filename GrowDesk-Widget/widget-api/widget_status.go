@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WidgetStatus representa la disponibilidad del chat en vivo calculada por el backend
+type WidgetStatus struct {
+	Online               bool       `json:"online"`
+	LiveChatAvailable    bool       `json:"liveChatAvailable"`
+	WithinBusinessHours  bool       `json:"withinBusinessHours"`
+	AgentsOnline         int        `json:"agentsOnline"`
+	OfflineMode          bool       `json:"offlineMode"`
+	Message              string     `json:"message,omitempty"`
+	ExpectedResponseTime string     `json:"expectedResponseTime,omitempty"`
+	NextOpenAt           *time.Time `json:"nextOpenAt,omitempty"`
+	TimeZone             string     `json:"timeZone,omitempty"`
+}
+
+// Valores usados cuando el backend no responde: sin confirmación de agentes se usa el modo offline
+const (
+	fallbackOfflineMessage       = "En este momento no podemos confirmar la disponibilidad de agentes. Deja tu mensaje y te responderemos lo antes posible."
+	fallbackExpectedResponseTime = "24 horas"
+)
+
+// offlineWidgetStatus devuelve el estado usado cuando no se puede consultar el backend
+func offlineWidgetStatus() WidgetStatus {
+	return WidgetStatus{
+		OfflineMode:          true,
+		WithinBusinessHours:  true,
+		Message:              fallbackOfflineMessage,
+		ExpectedResponseTime: fallbackExpectedResponseTime,
+	}
+}
+
+// fetchWidgetStatus consulta /widget/status en el backend de GrowDesk
+func fetchWidgetStatus(widgetID, department string) (WidgetStatus, error) {
+	apiURL := os.Getenv("GROWDESK_API_URL")
+	if apiURL == "" {
+		apiURL = "http://localhost:8080"
+	}
+
+	query := url.Values{}
+	if widgetID != "" {
+		query.Set("widgetId", widgetID)
+	}
+	if department != "" {
+		query.Set("department", department)
+	}
+
+	statusURL := fmt.Sprintf("%s/widget/status", strings.TrimSuffix(apiURL, "/"))
+	if encoded := query.Encode(); encoded != "" {
+		statusURL += "?" + encoded
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(statusURL)
+	if err != nil {
+		return WidgetStatus{}, fmt.Errorf("error al consultar estado del widget: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return WidgetStatus{}, fmt.Errorf("respuesta no exitosa del backend: %d", resp.StatusCode)
+	}
+
+	var status WidgetStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return WidgetStatus{}, fmt.Errorf("error al analizar estado del widget: %v", err)
+	}
+
+	return status, nil
+}
+
+// widgetStatusOrOffline consulta el estado y usa el modo offline si el backend falla
+func widgetStatusOrOffline(widgetID, department string) WidgetStatus {
+	status, err := fetchWidgetStatus(widgetID, department)
+	if err != nil {
+		log.Printf("No se pudo obtener el estado del widget, usando modo offline: %v", err)
+		return offlineWidgetStatus()
+	}
+	return status
+}
+
+// getWidgetStatus devuelve el estado público del widget: horario de atención,
+// agentes conectados y si debe mostrarse el formulario offline
+func getWidgetStatus(c *gin.Context) {
+	widgetID := c.Query("widgetId")
+	if widgetID == "" {
+		widgetID = c.GetHeader("X-Widget-ID")
+	}
+
+	status := widgetStatusOrOffline(widgetID, c.Query("department"))
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":               "OK",
+		"version":              "1.0.0",
+		"online":               status.Online,
+		"liveChatAvailable":    status.LiveChatAvailable,
+		"withinBusinessHours":  status.WithinBusinessHours,
+		"agentsOnline":         status.AgentsOnline,
+		"offlineMode":          status.OfflineMode,
+		"message":              status.Message,
+		"expectedResponseTime": status.ExpectedResponseTime,
+		"nextOpenAt":           status.NextOpenAt,
+		"timeZone":             status.TimeZone,
+	})
+}
@@ -86,6 +86,7 @@ interface TicketCreateRequest {
   email: string;
   message: string;
   subject?: string;
+  fields?: Record<string, any>;
   metadata?: any;
 }
 
@@ -105,6 +106,28 @@ export interface FAQ {
   isPublished: boolean;
 }
 
+// Formulario previo al chat configurado para el widget
+export interface PreChatField {
+  key: string;
+  label: string;
+  type: 'text' | 'email' | 'select' | 'checkbox' | 'textarea';
+  required: boolean;
+  placeholder?: string;
+  options?: string[];
+  minLength?: number;
+  maxLength?: number;
+  pattern?: string;
+  mapTo?: string;
+}
+
+export interface PreChatForm {
+  id: string;
+  title: string;
+  description?: string;
+  fields: PreChatField[];
+  active: boolean;
+}
+
 // Función para guardar la sesión en cookies
 const saveSession = (data: SessionInfo) => {
   const now = Math.floor(Date.now() / 1000);
@@ -274,6 +297,7 @@ export const useWidgetApi = () => {
         priority: 'MEDIUM',              // Prioridad predeterminada
         department: 'Soporte',           // Departamento predeterminada
         widgetId: apiConfig.widgetId,    // ID del widget
+        fields: data.fields || {},       // Valores del formulario previo al chat
         metadata: {                      // Datos adicionales como estructura anidada
           url: window.location.href || "",
           userAgent: navigator.userAgent || "",
@@ -601,6 +625,38 @@ export const useWidgetApi = () => {
     }
   };
   
+  // Obtener el formulario previo al chat del widget
+  const getPreChatForm = async (): Promise<PreChatForm | null> => {
+    try {
+      const baseUrl = apiConfig.apiUrl.endsWith('/') ? apiConfig.apiUrl : `${apiConfig.apiUrl}/`;
+      const response = await axios.get(`${baseUrl}widget/form`, {
+        params: { widgetId: apiConfig.widgetId },
+        headers: { 'X-Widget-ID': apiConfig.widgetId }
+      });
+      return response.data;
+    } catch (error) {
+      console.log('[WIDGET] Error al obtener el formulario previo al chat:', error);
+      return null;
+    }
+  };
+  
+  // Enviar la valoración (1-5) de la encuesta de satisfacción
+  const submitSurvey = async (token: string, rating: number, comment: string) => {
+    const baseUrl = apiConfig.apiUrl.endsWith('/') ? apiConfig.apiUrl : `${apiConfig.apiUrl}/`;
+    const response = await axios.post(`${baseUrl}widget/csat/${encodeURIComponent(token)}`, { rating, comment }, {
+      headers: { 'X-Widget-ID': apiConfig.widgetId }
+    });
+    return response.data;
+  };
+  
+  // Actualizar el ticket de la sesión cuando el ticket fue fusionado en otro
+  const updateSessionTicket = (ticketId: string) => {
+    const session = getSession();
+    if (session && session.ticketId !== ticketId) {
+      saveSession({ name: session.name, email: session.email, ticketId });
+    }
+  };
+  
   // Cerrar sesión (logout)
   const logout = () => {
     clearSession();
@@ -613,6 +669,9 @@ export const useWidgetApi = () => {
     sendMessage,
     getMessageHistory,
     logout,
-    getFaqs
+    updateSessionTicket,
+    getFaqs,
+    getPreChatForm,
+    submitSurvey
   };
 }; 
\ No newline at end of file
@@ -14,13 +14,27 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/agents"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/automation"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/availability"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/csat"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/db"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/events"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/handlers"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/invitations"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/kb"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/mailer"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/passwords"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/presence"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/teams"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/twofactor"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/watchers"
 	"github.com/joho/godotenv"
 )
 
@@ -80,11 +94,98 @@ func main() {
 		store = data.NewStore(*dataDir)
 	}
 
+	// Crear los equipos a partir de los departamentos de los usuarios la primera vez
+	if err := seedTeams(store); err != nil {
+		log.Printf("Advertencia: Error al crear los equipos iniciales: %v", err)
+	}
+
+	// Presencia de agentes y disponibilidad del chat en vivo
+	presenceTracker := presence.NewTracker(time.Duration(getEnvInt("PRESENCE_TTL_SECONDS", 120)) * time.Second)
+	availabilityService := availability.NewService(store, presenceTracker)
+	agentService := agents.NewService(store, presenceTracker)
+
+	// Eventos hacia el widget y reglas de automatización
+	publisher := events.NewPublisher(getEnv("WIDGET_EVENTS_URL", ""), getEnv("INTERNAL_EVENTS_TOKEN", ""))
+	automationEngine := automation.NewEngine(store, publisher, availabilityService)
+
+	// Correo saliente (SMTP opcional) para encuestas, invitaciones y contraseñas
+	appMailer := mailer.New(getEnv("SMTP_HOST", ""), getEnvInt("SMTP_PORT", 587),
+		getEnv("SMTP_USER", ""), getEnv("SMTP_PASSWORD", ""), getEnv("SMTP_FROM", ""))
+
+	// Encuestas de satisfacción por el widget y por correo
+	surveyService := csat.NewService(store, publisher, appMailer, getEnv("CSAT_SURVEY_URL", ""))
+	automationEngine.Surveys = surveyService
+	automationEngine.Agents = agentService
+
+	// Invitaciones de usuarios: el enlace del correo lleva {token}
+	invitationService := invitations.NewService(store, appMailer, getEnv("INVITATION_URL", ""),
+		time.Duration(getEnvInt("INVITATION_TTL_HOURS", 168))*time.Hour)
+
+	// Restablecimiento de contraseñas: el enlace del correo lleva {token}
+	passwordService := passwords.NewService(store, appMailer, getEnv("PASSWORD_RESET_URL", ""),
+		time.Duration(getEnvInt("PASSWORD_RESET_TTL_MINUTES", 60))*time.Minute)
+
+	// Requisitos de fortaleza de las contraseñas nuevas
+	utils.SetPasswordPolicy(utils.PasswordPolicy{
+		MinLength:     getEnvInt("PASSWORD_MIN_LENGTH", utils.MinPasswordLength),
+		RequireUpper:  getEnvBool("PASSWORD_REQUIRE_UPPER", false),
+		RequireLower:  getEnvBool("PASSWORD_REQUIRE_LOWER", false),
+		RequireDigit:  getEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+		RequireSymbol: getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+	})
+
+	// Autenticación en dos pasos; TWO_FACTOR_REQUIRED_ROLES la hace obligatoria (p. ej. "admin")
+	twoFactorService := twofactor.NewService(store, getEnv("TWO_FACTOR_ISSUER", "GrowDesk"),
+		getEnv("TWO_FACTOR_REQUIRED_ROLES", ""))
+
+	// Idiomas de la base de conocimiento (siempre incluye el idioma por defecto)
+	kb.SupportedLocales = kb.ParseLocales(getEnv("KB_LOCALES", "es,en"))
+
 	// Crear handlers
-	authHandler := &handlers.AuthHandler{Store: store}
-	ticketHandler := &handlers.TicketHandler{Store: store}
+	authHandler := &handlers.AuthHandler{
+		Store:     store,
+		Passwords: passwordService,
+		TwoFactor: twoFactorService,
+		MockLogin: *useMock,
+	}
+	ticketHandler := &handlers.TicketHandler{
+		Store:        store,
+		Events:       publisher,
+		Availability: availabilityService,
+		Automation:   automationEngine,
+		Surveys:      surveyService,
+		Watchers:     watchers.NewService(store),
+	}
 	categoryHandler := &handlers.CategoryHandler{Store: store}
-	faqHandler := &handlers.FAQHandler{Store: store}
+	teamHandler := &handlers.TeamHandler{Store: store}
+	faqHandler := &handlers.FAQHandler{
+		Store:       store,
+		VoteLimiter: kb.NewVoteLimiter(getEnvInt("FAQ_VOTES_PER_HOUR", 20), time.Hour),
+		StaleMonths: getEnvInt("FAQ_STALE_MONTHS", kb.DefaultStaleMonths),
+	}
+	articleHandler := &handlers.ArticleHandler{Store: store}
+	availabilityHandler := &handlers.AvailabilityHandler{
+		Store:        store,
+		Presence:     presenceTracker,
+		Availability: availabilityService,
+	}
+	agentHandler := &handlers.AgentHandler{
+		Store:    store,
+		Agents:   agentService,
+		Presence: presenceTracker,
+	}
+	invitationHandler := &handlers.InvitationHandler{
+		Store:       store,
+		Invitations: invitationService,
+		TwoFactor:   twoFactorService,
+	}
+	preChatFormHandler := &handlers.PreChatFormHandler{Store: store}
+	customFieldHandler := &handlers.CustomFieldHandler{Store: store}
+	tagHandler := &handlers.TagHandler{Store: store}
+	macroHandler := &handlers.MacroHandler{Store: store}
+	automationHandler := &handlers.AutomationHandler{Store: store}
+	notificationHandler := &handlers.NotificationHandler{Store: store}
+	surveyHandler := &handlers.SurveyHandler{Store: store}
 
 	// Crear enrutador (usando http.ServeMux básico para simplicidad)
 	mux := http.NewServeMux()
@@ -95,7 +196,8 @@ func main() {
 		authMiddleware = middleware.MockAuth
 		log.Println("Usando autenticación de prueba para desarrollo")
 	} else {
-		authMiddleware = middleware.Auth
+		// Los tokens emitidos antes de cambiar la contraseña dejan de ser válidos
+		authMiddleware = middleware.SessionAuth(store.GetUserSessionVersion)
 	}
 
 	// Rutas de comprobación de estado
@@ -110,6 +212,51 @@ func main() {
 	mux.HandleFunc("/api/auth/login", authHandler.Login)
 	mux.HandleFunc("/api/auth/register", authHandler.Register)
 	mux.Handle("/api/auth/me", authMiddleware(http.HandlerFunc(authHandler.Me)))
+	mux.HandleFunc("/api/auth/password/forgot", authHandler.ForgotPassword)
+	mux.HandleFunc("/api/auth/password/reset", authHandler.ResetPassword)
+	mux.Handle("/api/auth/password/change", authMiddleware(http.HandlerFunc(authHandler.ChangePassword)))
+
+	// Autenticación en dos pasos; la configuración inicial también acepta el desafío del login
+	challengeMiddleware := middleware.AllowChallenge(store.GetUserSessionVersion, authMiddleware)
+	mux.Handle("/api/auth/2fa", authMiddleware(http.HandlerFunc(authHandler.GetTwoFactorStatus)))
+	mux.Handle("/api/auth/2fa/setup", challengeMiddleware(http.HandlerFunc(authHandler.SetupTwoFactor)))
+	mux.Handle("/api/auth/2fa/enable", challengeMiddleware(http.HandlerFunc(authHandler.EnableTwoFactor)))
+	mux.HandleFunc("/api/auth/2fa/verify", authHandler.VerifyTwoFactor)
+	mux.Handle("/api/auth/2fa/disable", authMiddleware(http.HandlerFunc(authHandler.DisableTwoFactor)))
+	mux.Handle("/api/auth/2fa/recovery-codes", authMiddleware(http.HandlerFunc(authHandler.RegenerateRecoveryCodes)))
+
+	// Aceptación de invitaciones (pública): el invitado crea su cuenta con el token
+	mux.HandleFunc("/api/invitations/accept", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			invitationHandler.GetInvitationByToken(w, r)
+		case http.MethodPost:
+			invitationHandler.AcceptInvitation(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Rutas de invitaciones (solo administradores)
+	mux.Handle("/api/invitations", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			invitationHandler.GetInvitations(w, r)
+		case http.MethodPost:
+			invitationHandler.CreateInvitation(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})))
+	mux.Handle("/api/invitations/", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if filepath.Base(r.URL.Path) == "resend" {
+			// Reenviar: /api/invitations/:id/resend
+			invitationHandler.ResendInvitation(w, r)
+		} else {
+			// Revocar: DELETE /api/invitations/:id
+			invitationHandler.RevokeInvitation(w, r)
+		}
+	})))
 
 	// Rutas de tickets (autenticadas)
 	mux.Handle("/api/tickets", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -124,6 +271,12 @@ func main() {
 		}
 	})))
 
+	// Operaciones masivas sobre tickets (autenticadas)
+	mux.Handle("/api/tickets/bulk", authMiddleware(http.HandlerFunc(ticketHandler.BulkTickets)))
+
+	// Tickets que sigue el usuario actual (autenticada)
+	mux.Handle("/api/tickets/watching", authMiddleware(http.HandlerFunc(ticketHandler.GetWatchedTickets)))
+
 	// Rutas de tickets individuales
 	mux.Handle("/api/tickets/", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
@@ -138,6 +291,57 @@ func main() {
 			default:
 				http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
 			}
+		} else if filepath.Base(path) == "tags" {
+			// Etiquetas del ticket: /api/tickets/:id/tags
+			tagHandler.UpdateTicketTags(w, r)
+		} else if filepath.Base(path) == "merge" {
+			// Fusionar tickets en este ticket: /api/tickets/:id/merge
+			ticketHandler.MergeTickets(w, r)
+		} else if filepath.Base(path) == "split" {
+			// Mover mensajes a un ticket nuevo: /api/tickets/:id/split
+			ticketHandler.SplitTicket(w, r)
+		} else if filepath.Base(path) == "links" {
+			// Relaciones del ticket: /api/tickets/:id/links
+			switch r.Method {
+			case http.MethodGet:
+				ticketHandler.GetTicketLinks(w, r)
+			case http.MethodPost:
+				ticketHandler.CreateTicketLink(w, r)
+			default:
+				http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+			}
+		} else if filepath.Base(path) == "watchers" {
+			// Seguidores del ticket: /api/tickets/:id/watchers
+			switch r.Method {
+			case http.MethodGet:
+				ticketHandler.GetTicketWatchers(w, r)
+			case http.MethodPost:
+				ticketHandler.AddTicketWatchers(w, r)
+			default:
+				http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+			}
+		} else if filepath.Base(filepath.Dir(path)) == "watchers" {
+			// Dejar de seguir un ticket: /api/tickets/:id/watchers/:userId
+			ticketHandler.RemoveTicketWatcher(w, r)
+		} else if filepath.Base(filepath.Dir(path)) == "links" {
+			// Eliminar una relación: /api/tickets/:id/links/:linkId
+			ticketHandler.DeleteTicketLink(w, r)
+		} else if filepath.Base(filepath.Dir(path)) == "macros" {
+			// Vista previa o aplicación de una macro: /api/tickets/:id/macros/:macroId
+			switch r.Method {
+			case http.MethodGet:
+				ticketHandler.PreviewTicketMacro(w, r)
+			case http.MethodPost:
+				ticketHandler.ApplyTicketMacro(w, r)
+			default:
+				http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+			}
+		} else if filepath.Base(path) == "resolve" {
+			// Resolver un incidente y sus tickets hijos: /api/tickets/:id/resolve
+			ticketHandler.ResolveIncident(w, r)
+		} else if filepath.Base(path) == "activity" {
+			// Registro de actividad del ticket: /api/tickets/:id/activity
+			ticketHandler.GetTicketActivity(w, r)
 		} else if filepath.Base(path) == "messages" {
 			// Esta es una ruta para mensajes de tickets como /api/tickets/:id/messages
 			switch r.Method {
@@ -156,16 +360,208 @@ func main() {
 	// Rutas de widget (públicas)
 	mux.HandleFunc("/widget/tickets", ticketHandler.CreateWidgetTicket)
 
-	// Ruta para obtener mensajes de un ticket desde el widget
+	// Ruta para obtener y agregar mensajes de un ticket desde el widget
 	mux.HandleFunc("/widget/tickets/", func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
 		if filepath.Base(path) == "messages" {
-			ticketHandler.GetTicketMessages(w, r)
+			switch r.Method {
+			case http.MethodGet:
+				ticketHandler.GetTicketMessages(w, r)
+			case http.MethodPost:
+				ticketHandler.AddWidgetTicketMessage(w, r)
+			default:
+				http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+			}
 		} else {
 			http.NotFound(w, r)
 		}
 	})
 
+	// Encuestas de satisfacción (públicas, identificadas por token)
+	mux.HandleFunc("/widget/csat/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			surveyHandler.GetPublicSurvey(w, r)
+		case http.MethodPost:
+			surveyHandler.SubmitSurvey(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Estado público del widget (horario de atención y agentes conectados)
+	mux.HandleFunc("/widget/status", availabilityHandler.GetWidgetStatus)
+
+	// Rutas de horarios de atención (autenticadas)
+	mux.Handle("/api/business-hours", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			availabilityHandler.GetAllBusinessHours(w, r)
+		case http.MethodPost:
+			availabilityHandler.CreateBusinessHours(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/api/business-hours/", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			availabilityHandler.GetBusinessHours(w, r)
+		case http.MethodPut:
+			availabilityHandler.UpdateBusinessHours(w, r)
+		case http.MethodDelete:
+			availabilityHandler.DeleteBusinessHours(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	// Rutas de presencia de agentes (autenticadas)
+	mux.Handle("/api/agents/presence", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			availabilityHandler.GetOnlineAgents(w, r)
+		case http.MethodPost:
+			availabilityHandler.Heartbeat(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	// Rutas de perfiles de agentes: estado, capacidad y habilidades (autenticadas)
+	mux.Handle("/api/agents", authMiddleware(http.HandlerFunc(agentHandler.GetAgents)))
+	mux.Handle("/api/agents/", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			agentHandler.GetAgent(w, r)
+		case http.MethodPut:
+			agentHandler.UpdateAgentProfile(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	// WebSocket de presencia de agentes: el agente está en línea mientras esté conectado
+	mux.Handle("/api/ws/agents", middleware.TokenFromQuery(authMiddleware(http.HandlerFunc(agentHandler.PresenceSocket))))
+
+	// Formulario previo al chat del widget (público)
+	mux.HandleFunc("/widget/form", preChatFormHandler.GetWidgetForm)
+
+	// Rutas de formularios previos al chat (autenticadas)
+	mux.Handle("/api/prechat-forms", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			preChatFormHandler.GetAllForms(w, r)
+		case http.MethodPost:
+			preChatFormHandler.CreateForm(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/api/prechat-forms/", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			preChatFormHandler.GetForm(w, r)
+		case http.MethodPut:
+			preChatFormHandler.UpdateForm(w, r)
+		case http.MethodDelete:
+			preChatFormHandler.DeleteForm(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	// Rutas de campos personalizados de tickets (autenticadas)
+	mux.Handle("/api/custom-fields", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			customFieldHandler.GetAllCustomFields(w, r)
+		case http.MethodPost:
+			customFieldHandler.CreateCustomField(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/api/custom-fields/", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			customFieldHandler.GetCustomField(w, r)
+		case http.MethodPut:
+			customFieldHandler.UpdateCustomField(w, r)
+		case http.MethodDelete:
+			customFieldHandler.DeleteCustomField(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	// Rutas de macros de agentes (autenticadas)
+	mux.Handle("/api/macros", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			macroHandler.GetAllMacros(w, r)
+		case http.MethodPost:
+			macroHandler.CreateMacro(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/api/macros/", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			macroHandler.GetMacro(w, r)
+		case http.MethodPut:
+			macroHandler.UpdateMacro(w, r)
+		case http.MethodDelete:
+			macroHandler.DeleteMacro(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	// Rutas de reglas de automatización (solo administradores)
+	mux.Handle("/api/automation/rules", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			automationHandler.GetAllAutomationRules(w, r)
+		case http.MethodPost:
+			automationHandler.CreateAutomationRule(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	mux.Handle("/api/automation/rules/", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			automationHandler.GetAutomationRule(w, r)
+		case http.MethodPut:
+			automationHandler.UpdateAutomationRule(w, r)
+		case http.MethodDelete:
+			automationHandler.DeleteAutomationRule(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	// Rutas de notificaciones del usuario (autenticadas)
+	mux.Handle("/api/notifications", authMiddleware(http.HandlerFunc(notificationHandler.GetNotifications)))
+	mux.Handle("/api/notifications/read", authMiddleware(http.HandlerFunc(notificationHandler.MarkNotificationsRead)))
+
+	// Informe de satisfacción de clientes (solo administradores)
+	mux.Handle("/api/reports/csat", authMiddleware(http.HandlerFunc(surveyHandler.GetCSATReport)))
+	mux.Handle("/api/reports/faq-deflection", authMiddleware(http.HandlerFunc(faqHandler.GetDeflectionReport)))
+	mux.Handle("/api/reports/faqs", authMiddleware(http.HandlerFunc(faqHandler.GetFAQAnalytics)))
+
+	// Rutas de etiquetas (autenticadas)
+	mux.Handle("/api/tags", authMiddleware(http.HandlerFunc(tagHandler.SearchTags)))
+	mux.Handle("/api/tags/report", authMiddleware(http.HandlerFunc(tagHandler.GetTagReport)))
+	mux.Handle("/api/tags/bulk", authMiddleware(http.HandlerFunc(tagHandler.BulkTagTickets)))
+
 	// Rutas de categorías (autenticadas)
 	mux.Handle("/api/categories", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Manejar basado en el método HTTP
@@ -194,6 +590,45 @@ func main() {
 		}
 	})))
 
+	// Rutas de equipos
+	mux.Handle("/api/teams", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Manejar basado en el método HTTP
+		switch r.Method {
+		case http.MethodGet:
+			teamHandler.GetTeams(w, r)
+		case http.MethodPost:
+			teamHandler.CreateTeam(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	// Rutas de equipos individuales, sus miembros y su cola de tickets
+	mux.Handle("/api/teams/", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if filepath.Base(path) == "queue" {
+			// Cola de tickets del equipo: /api/teams/:id/queue
+			teamHandler.GetTeamQueue(w, r)
+		} else if filepath.Base(path) == "members" {
+			// Agregar un miembro: /api/teams/:id/members
+			teamHandler.AddTeamMember(w, r)
+		} else if filepath.Base(filepath.Dir(path)) == "members" {
+			// Quitar un miembro: /api/teams/:id/members/:userId
+			teamHandler.RemoveTeamMember(w, r)
+		} else {
+			switch r.Method {
+			case http.MethodGet:
+				teamHandler.GetTeam(w, r)
+			case http.MethodPut:
+				teamHandler.UpdateTeam(w, r)
+			case http.MethodDelete:
+				teamHandler.DeleteTeam(w, r)
+			default:
+				http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+			}
+		}
+	})))
+
 	// Rutas de FAQ (autenticadas para operaciones de administrador)
 	mux.Handle("/api/faqs", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Manejar basado en el método HTTP
@@ -236,9 +671,97 @@ func main() {
 	mux.HandleFunc("/widget/faqs", faqHandler.GetPublishedFAQs)
 	mux.HandleFunc("/faqs", faqHandler.GetPublishedFAQs) // Endpoint alternativo
 
+	// Sugerencias de FAQs antes de crear un ticket y registro de "esto resolvió mi problema"
+	mux.HandleFunc("/widget/faqs/suggest", faqHandler.SuggestFAQs)
+	mux.HandleFunc("/widget/faqs/solved", faqHandler.MarkFAQSolved)
+	mux.HandleFunc("/widget/faqs/view", faqHandler.RecordFAQView)
+	mux.HandleFunc("/widget/faqs/vote", faqHandler.VoteFAQ)
+
 	// Rutas de compatibilidad de widget (para manejar las rutas duplicadas /widget/widget/...)
 	mux.HandleFunc("/widget/widget/faqs", faqHandler.GetPublishedFAQs)
 
+	// Rutas de la base de conocimiento (autenticadas)
+	mux.Handle("/api/kb/articles", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			articleHandler.GetArticles(w, r)
+		case http.MethodPost:
+			articleHandler.CreateArticle(w, r)
+		default:
+			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		}
+	})))
+
+	// Rutas de artículos individuales: /api/kb/articles/:id[/status|unpublish|revisions[/:rev]|diff|rollback]
+	// y sus traducciones: /api/kb/articles/:id/translations[/:locale[/status|unpublish]]
+	mux.Handle("/api/kb/articles/", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		segments := strings.Split(strings.TrimPrefix(strings.TrimSuffix(r.URL.Path, "/"), "/api/kb/articles/"), "/")
+		action := ""
+		if len(segments) > 1 {
+			action = segments[1]
+		}
+
+		switch action {
+		case "":
+			switch r.Method {
+			case http.MethodGet:
+				articleHandler.GetArticle(w, r)
+			case http.MethodPut:
+				articleHandler.UpdateArticle(w, r)
+			case http.MethodDelete:
+				articleHandler.DeleteArticle(w, r)
+			default:
+				http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+			}
+		case "status":
+			articleHandler.ChangeArticleStatus(w, r)
+		case "unpublish":
+			articleHandler.UnpublishArticle(w, r)
+		case "revisions":
+			if len(segments) > 2 {
+				articleHandler.GetArticleRevision(w, r)
+			} else {
+				articleHandler.GetArticleRevisions(w, r)
+			}
+		case "diff":
+			articleHandler.GetArticleDiff(w, r)
+		case "rollback":
+			articleHandler.RollbackArticle(w, r)
+		case "translations":
+			switch {
+			case len(segments) == 2:
+				articleHandler.GetArticleTranslations(w, r)
+			case len(segments) == 4 && segments[3] == "status":
+				articleHandler.ChangeTranslationStatus(w, r)
+			case len(segments) == 4 && segments[3] == "unpublish":
+				articleHandler.UnpublishTranslation(w, r)
+			case len(segments) == 3:
+				switch r.Method {
+				case http.MethodGet:
+					articleHandler.GetArticleTranslation(w, r)
+				case http.MethodPut:
+					articleHandler.SaveArticleTranslation(w, r)
+				case http.MethodDelete:
+					articleHandler.DeleteArticleTranslation(w, r)
+				default:
+					http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+				}
+			default:
+				http.Error(w, "Ruta no encontrada", http.StatusNotFound)
+			}
+		default:
+			http.Error(w, "Ruta no encontrada", http.StatusNotFound)
+		}
+	})))
+
+	// Idiomas y traducciones pendientes de la base de conocimiento
+	mux.Handle("/api/kb/locales", authMiddleware(http.HandlerFunc(articleHandler.GetLocales)))
+	mux.Handle("/api/kb/translations/missing", authMiddleware(http.HandlerFunc(articleHandler.GetMissingTranslations)))
+
+	// Rutas públicas de la base de conocimiento (solo revisiones publicadas)
+	mux.HandleFunc("/widget/kb/articles", articleHandler.GetPublishedArticles)
+	mux.HandleFunc("/widget/kb/articles/", articleHandler.GetPublishedArticle)
+
 	// Rutas de usuarios (autenticadas)
 	mux.Handle("/api/users", authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Configurar CORS explícitamente
@@ -259,6 +782,9 @@ func main() {
 				http.Error(w, "Error al obtener usuarios", http.StatusInternalServerError)
 				return
 			}
+			for i := range users {
+				users[i].Password = ""
+			}
 			utils.WriteJSON(w, http.StatusOK, users)
 		case http.MethodPost:
 			// Crear un nuevo usuario
@@ -278,18 +804,44 @@ func main() {
 				return
 			}
 
-			// Generar ID único
-			id := fmt.Sprintf("%d", time.Now().UnixNano())
+			// El departamento debe ser un equipo activo
+			department, err := resolveDepartment(store, user.Department)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			// El email debe ser válido y no pertenecer a otro usuario
+			email, err := invitations.NormalizeEmail(user.Email)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if _, err := store.GetUserByEmail(email); err == nil {
+				http.Error(w, "Ya existe un usuario con el email "+email, http.StatusConflict)
+				return
+			}
+
+			// Guardar solo el hash de la contraseña
+			if err := utils.ValidatePassword(user.Password); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			passwordHash, err := utils.HashPassword(user.Password)
+			if err != nil {
+				http.Error(w, "Error al guardar usuario", http.StatusInternalServerError)
+				return
+			}
 
 			// Crear nuevo usuario
 			newUser := models.User{
-				ID:         id,
+				ID:         uuid.New().String(),
 				FirstName:  user.FirstName,
 				LastName:   user.LastName,
-				Email:      user.Email,
-				Password:   user.Password, // esto debería ser hasheado
+				Email:      email,
+				Password:   passwordHash,
 				Role:       user.Role,
-				Department: user.Department,
+				Department: department,
 				Active:     user.Active,
 				CreatedAt:  time.Now(),
 				UpdatedAt:  time.Now(),
@@ -302,6 +854,7 @@ func main() {
 			}
 
 			// Devolver el usuario creado
+			newUser.Password = ""
 			utils.WriteJSON(w, http.StatusCreated, newUser)
 		default:
 			http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
@@ -329,6 +882,12 @@ func main() {
 
 		userID := segments[3]
 
+		// Restablecer el segundo factor de un usuario (solo administradores)
+		if len(segments) > 4 && segments[4] == "2fa" {
+			authHandler.ResetUserTwoFactor(w, r)
+			return
+		}
+
 		// Manejar basado en el método HTTP
 		switch r.Method {
 		case http.MethodGet:
@@ -339,6 +898,7 @@ func main() {
 				return
 			}
 
+			user.Password = ""
 			utils.WriteJSON(w, http.StatusOK, user)
 		case http.MethodPut:
 			// Actualizar un usuario
@@ -363,16 +923,40 @@ func main() {
 				user.LastName = updates.LastName
 			}
 			if updates.Email != "" {
-				user.Email = updates.Email
+				email, err := invitations.NormalizeEmail(updates.Email)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				if existing, err := store.GetUserByEmail(email); err == nil && existing.ID != user.ID {
+					http.Error(w, "Ya existe un usuario con el email "+email, http.StatusConflict)
+					return
+				}
+				user.Email = email
 			}
 			if updates.Role != "" {
 				user.Role = updates.Role
 			}
 			if updates.Department != "" {
-				user.Department = updates.Department
+				department, err := resolveDepartment(store, updates.Department)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				user.Department = department
 			}
+			var newPasswordHash string
 			if updates.Password != "" {
-				user.Password = updates.Password // En producción real, esto debería ser hasheado
+				if err := utils.ValidatePassword(updates.Password); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				passwordHash, err := utils.HashPassword(updates.Password)
+				if err != nil {
+					http.Error(w, "Error al actualizar usuario", http.StatusInternalServerError)
+					return
+				}
+				newPasswordHash = passwordHash
 			}
 
 			// Marcar como actualizado
@@ -384,7 +968,16 @@ func main() {
 				return
 			}
 
+			// Cambiar la contraseña cierra las sesiones abiertas del usuario
+			if newPasswordHash != "" {
+				if err := store.UpdateUserPassword(user.ID, newPasswordHash); err != nil {
+					http.Error(w, "Error al actualizar usuario", http.StatusInternalServerError)
+					return
+				}
+			}
+
 			// Devolver la respuesta actualizada
+			user.Password = ""
 			utils.WriteJSON(w, http.StatusOK, user)
 		case http.MethodDelete:
 			// Eliminar un usuario
@@ -400,7 +993,71 @@ func main() {
 	})))
 
 	// Ruta de WebSocket para el chat de tickets
-	mux.HandleFunc("/api/ws/chat/", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/ws/chat/", chatSocketHandler(store))
+
+	// Middleware de CORS
+	corsMiddleware := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Establecer encabezados CORS
+			utils.SetCORS(w)
+
+			// Manejar preflight requests
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			// Llamar al manejador envolvente
+			h.ServeHTTP(w, r)
+		})
+	}
+
+	// Crear servidor con manejador envolvente
+	srv := &http.Server{
+		Addr:         fmt.Sprintf(":%d", *port),
+		Handler:      corsMiddleware(mux),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	// Iniciar servidor en una goroutine
+	go func() {
+		log.Printf("Iniciando servidor en el puerto %d", *port)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Error al iniciar servidor: %v", err)
+		}
+	}()
+
+	// Reglas de automatización programadas (cierre automático, recordatorios, escalados)
+	stopScheduler := make(chan struct{})
+	if interval := getEnvInt("AUTOMATION_INTERVAL_SECONDS", 60); interval > 0 {
+		automation.NewScheduler(automationEngine, time.Duration(interval)*time.Second).Start(stopScheduler)
+	}
+
+	// Esperar a la señal de interrupción para cerrar el servidor de maneragraceful
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("Servidor se está cerrando...")
+	close(stopScheduler)
+
+	// Crear contexto con timeout para cerrar el servidor
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Intentar cerrar el servidor de maneragraceful
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Fatalf("Servidor forzado a cerrarse: %v", err)
+	}
+
+	log.Println("Servidor cerrado de maneragraceful")
+}
+
+// chatSocketHandler atiende las conexiones WebSocket del chat de un ticket
+// (/api/ws/chat/:ticketID). La conexión es del cliente: solo recibe respuestas públicas.
+func chatSocketHandler(store data.DataStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
 		// Configurar CORS para WebSocket
 		utils.SetCORS(w)
 
@@ -436,14 +1093,14 @@ func main() {
 			store.RemoveWSConnection(ticketID, connID)
 		}()
 
-		// Enviar mensajes existentes del ticket
-		ticket, err := store.GetTicket(ticketID)
-		if err == nil && ticket != nil {
+		// Enviar mensajes existentes del ticket (la conexión es del cliente: sin notas internas)
+		messages, err := store.GetTicketMessages(ticketID, models.MessageAudienceCustomer)
+		if err == nil {
 			// Enviar mensajes existentes
 			wsMessage := models.WebSocketMessage{
 				Type:     "init_messages",
 				TicketID: ticketID,
-				Messages: ticket.Messages,
+				Messages: messages,
 			}
 
 			conn.WriteJSON(wsMessage)
@@ -461,58 +1118,40 @@ func main() {
 				conn.WriteMessage(websocket.PongMessage, []byte{})
 			}
 		}
-	})
-
-	// Middleware de CORS
-	corsMiddleware := func(h http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Establecer encabezados CORS
-			utils.SetCORS(w)
-
-			// Manejar preflight requests
-			if r.Method == http.MethodOptions {
-				w.WriteHeader(http.StatusNoContent)
-				return
-			}
-
-			// Llamar al manejador envolvente
-			h.ServeHTTP(w, r)
-		})
 	}
+}
 
-	// Crear servidor con manejador envolvente
-	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%d", *port),
-		Handler:      corsMiddleware(mux),
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+// seedTeams crea un equipo por cada departamento de los usuarios, más el equipo por
+// defecto de los tickets del widget, si todavía no hay equipos
+func seedTeams(store data.DataStore) error {
+	existing, err := store.GetTeams()
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return nil
 	}
 
-	// Iniciar servidor en una goroutine
-	go func() {
-		log.Printf("Iniciando servidor en el puerto %d", *port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Error al iniciar servidor: %v", err)
+	users, err := store.GetUsers()
+	if err != nil {
+		return err
+	}
+	for _, team := range teams.FromDepartments(users, []string{teams.DefaultTeamID}, time.Now()) {
+		if err := store.CreateTeam(team); err != nil {
+			return err
 		}
-	}()
-
-	// Esperar a la señal de interrupción para cerrar el servidor de maneragraceful
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Println("Servidor se está cerrando...")
-
-	// Crear contexto con timeout para cerrar el servidor
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Intentar cerrar el servidor de maneragraceful
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Servidor forzado a cerrarse: %v", err)
+		log.Printf("Equipo inicial creado: %s (%d miembros)", team.ID, len(team.Members))
 	}
+	return nil
+}
 
-	log.Println("Servidor cerrado de maneragraceful")
+// resolveDepartment devuelve el ID del equipo activo que corresponde al departamento
+func resolveDepartment(store data.DataStore, department string) (string, error) {
+	allTeams, err := store.GetTeams()
+	if err != nil {
+		return "", fmt.Errorf("Error al obtener equipos")
+	}
+	return teams.Resolve(allTeams, department)
 }
 
 // Helper para obtener variables de entorno con valor por defecto
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+func TestChatSocketInitMessagesOnlyIncludesPublicMessages(t *testing.T) {
+	store := data.NewStore(t.TempDir())
+	now := time.Now()
+	err := store.CreateTicket(models.Ticket{
+		ID:     "TICKET-TEST-1",
+		Title:  "Consulta",
+		Status: "open",
+		Source: "widget",
+		Messages: []models.Message{
+			{ID: "public", Content: "Hola, ¿en qué podemos ayudarle?", Visibility: models.MessageVisibilityPublic, Timestamp: now},
+			{ID: "internal", Content: "Nota interna", Visibility: models.MessageVisibilityInternal, Timestamp: now},
+			{ID: "system", Content: "Ticket asignado", Visibility: models.MessageVisibilitySystem, Timestamp: now},
+			{ID: "legacy-note", Content: "Nota antigua", IsInternal: true, Timestamp: now},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTicket: %v", err)
+	}
+
+	server := httptest.NewServer(chatSocketHandler(store))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/ws/chat/TICKET-TEST-1"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var initial models.WebSocketMessage
+	if err := conn.ReadJSON(&initial); err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+	if initial.Type != "init_messages" {
+		t.Fatalf("tipo %q, se esperaba init_messages", initial.Type)
+	}
+
+	ids := make([]string, 0, len(initial.Messages))
+	for _, message := range initial.Messages {
+		ids = append(ids, message.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"public"}) {
+		t.Errorf("init_messages incluye %v, se esperaba solo [public]", ids)
+	}
+
+	// Una nota agregada después tampoco se difunde a la conexión del cliente
+	store.BroadcastMessage("TICKET-TEST-1", models.Message{ID: "late-note", Visibility: models.MessageVisibilityInternal, Timestamp: now})
+	conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	if _, data, err := conn.ReadMessage(); err == nil {
+		t.Errorf("se recibió un mensaje inesperado: %s", data)
+	}
+}
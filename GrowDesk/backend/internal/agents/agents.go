@@ -0,0 +1,304 @@
+// Package agents reúne los perfiles de los agentes: su estado (explícito o derivado de
+// la presencia), su capacidad de chats y tickets, sus habilidades y la elección del
+// agente disponible para asignar un ticket.
+package agents
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/presence"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/teams"
+)
+
+// AutoAssign es el valor de assignedTo de una regla de automatización que asigna el
+// ticket al agente disponible con menos carga de su equipo
+const AutoAssign = "auto"
+
+// unlimitedCapacity es la capacidad con la que se compara la carga de un agente sin
+// límite de tickets
+const unlimitedCapacity = 20
+
+// Orígenes del estado de un agente
+const (
+	StatusSourceManual   = "manual"
+	StatusSourcePresence = "presence"
+)
+
+// Niveles de habilidad
+const (
+	MinSkillLevel = 1
+	MaxSkillLevel = 5
+)
+
+// ValidStatus indica si el estado es válido para fijarlo a mano; "" vuelve al estado
+// derivado de la presencia
+func ValidStatus(status string) bool {
+	switch status {
+	case "", models.AgentStatusOnline, models.AgentStatusAway, models.AgentStatusMeeting, models.AgentStatusOffline:
+		return true
+	}
+	return false
+}
+
+// Validate comprueba y normaliza la capacidad y las habilidades de un perfil
+func Validate(profile *models.AgentProfile) error {
+	if !ValidStatus(profile.Status) {
+		return fmt.Errorf("Estado inválido: %s", profile.Status)
+	}
+	if profile.MaxChats < 0 || profile.MaxTickets < 0 {
+		return fmt.Errorf("La capacidad no puede ser negativa")
+	}
+
+	seen := make(map[string]bool)
+	skills := make([]models.AgentSkill, 0, len(profile.Skills))
+	for _, skill := range profile.Skills {
+		skill.Name = strings.TrimSpace(skill.Name)
+		if skill.Name == "" {
+			return fmt.Errorf("El nombre de la habilidad es requerido")
+		}
+		if skill.Kind != models.SkillKindLanguage && skill.Kind != models.SkillKindProduct {
+			return fmt.Errorf("Tipo de habilidad inválido: %s", skill.Kind)
+		}
+		if skill.Level < MinSkillLevel || skill.Level > MaxSkillLevel {
+			return fmt.Errorf("El nivel de %s debe estar entre %d y %d", skill.Name, MinSkillLevel, MaxSkillLevel)
+		}
+		key := skill.Kind + ":" + strings.ToLower(skill.Name)
+		if seen[key] {
+			return fmt.Errorf("Habilidad duplicada: %s", skill.Name)
+		}
+		seen[key] = true
+		skills = append(skills, skill)
+	}
+	profile.Skills = skills
+	return nil
+}
+
+// Build arma la vista de los agentes (usuarios que no son clientes) a partir de sus
+// perfiles, la presencia de los conectados y los tickets asignados
+func Build(users []models.User, profiles []models.AgentProfile, online []models.AgentPresence, tickets []models.Ticket) []models.Agent {
+	byUser := make(map[string]models.AgentProfile, len(profiles))
+	for _, profile := range profiles {
+		byUser[profile.UserID] = profile
+	}
+	lastSeen := make(map[string]time.Time, len(online))
+	for _, agent := range online {
+		lastSeen[agent.UserID] = agent.LastSeen
+	}
+
+	chats := make(map[string]int)
+	open := make(map[string]int)
+	for _, ticket := range tickets {
+		if ticket.AssignedTo == "" || ticket.MergedInto != "" ||
+			ticket.Status == "resolved" || ticket.Status == "closed" {
+			continue
+		}
+		open[ticket.AssignedTo]++
+		if ticket.Source == "widget" {
+			chats[ticket.AssignedTo]++
+		}
+	}
+
+	list := make([]models.Agent, 0)
+	for _, user := range users {
+		if user.Role == "customer" {
+			continue
+		}
+		profile := byUser[user.ID]
+		agent := models.Agent{
+			UserID:      user.ID,
+			Name:        strings.TrimSpace(user.FirstName + " " + user.LastName),
+			Email:       user.Email,
+			Role:        user.Role,
+			Department:  user.Department,
+			MaxChats:    profile.MaxChats,
+			MaxTickets:  profile.MaxTickets,
+			ActiveChats: chats[user.ID],
+			OpenTickets: open[user.ID],
+			Skills:      profile.Skills,
+		}
+		if agent.Skills == nil {
+			agent.Skills = make([]models.AgentSkill, 0)
+		}
+
+		seen, connected := lastSeen[user.ID]
+		if connected {
+			agent.LastSeen = &seen
+		}
+		agent.Status, agent.StatusSource = Status(profile, connected)
+		agent.Available = agent.Status == models.AgentStatusOnline && HasCapacity(agent)
+		list = append(list, agent)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].UserID < list[j].UserID
+	})
+	return list
+}
+
+// Status devuelve el estado efectivo del agente y su origen. El estado fijado a mano
+// tiene prioridad; si no hay, el agente está en línea mientras esté conectado.
+func Status(profile models.AgentProfile, connected bool) (string, string) {
+	if profile.Status != "" {
+		return profile.Status, StatusSourceManual
+	}
+	if connected {
+		return models.AgentStatusOnline, StatusSourcePresence
+	}
+	return models.AgentStatusOffline, StatusSourcePresence
+}
+
+// HasCapacity indica si el agente puede recibir otro chat y otro ticket
+func HasCapacity(agent models.Agent) bool {
+	if agent.MaxChats > 0 && agent.ActiveChats >= agent.MaxChats {
+		return false
+	}
+	if agent.MaxTickets > 0 && agent.OpenTickets >= agent.MaxTickets {
+		return false
+	}
+	return true
+}
+
+// SkillLevel devuelve el nivel del agente en la habilidad (sin distinguir mayúsculas),
+// o 0 si no la tiene
+func SkillLevel(agent models.Agent, name string) int {
+	for _, skill := range agent.Skills {
+		if strings.EqualFold(skill.Name, strings.TrimSpace(name)) {
+			return skill.Level
+		}
+	}
+	return 0
+}
+
+// Filter son los criterios de búsqueda de agentes
+type Filter struct {
+	Department string
+	Status     string
+	Skill      string
+	MinLevel   int // Nivel mínimo en Skill; 0 acepta cualquier nivel
+	Available  bool
+}
+
+// Matches indica si el agente cumple el filtro
+func (f Filter) Matches(agent models.Agent) bool {
+	if f.Department != "" && teams.Key(agent.Department) != teams.Key(f.Department) {
+		return false
+	}
+	if f.Status != "" && agent.Status != f.Status {
+		return false
+	}
+	if f.Skill != "" {
+		level := SkillLevel(agent, f.Skill)
+		if level == 0 || level < f.MinLevel {
+			return false
+		}
+	}
+	return !f.Available || agent.Available
+}
+
+// Pick elige, entre los agentes disponibles que cumplen el filtro, el de menor carga:
+// primero la proporción de tickets abiertos sobre su capacidad, luego el número de
+// chats y tickets. Devuelve nil si no hay ninguno.
+func Pick(list []models.Agent, filter Filter) *models.Agent {
+	filter.Available = true
+	var best *models.Agent
+	for i := range list {
+		if !filter.Matches(list[i]) {
+			continue
+		}
+		if best == nil || lessLoaded(list[i], *best) {
+			best = &list[i]
+		}
+	}
+	return best
+}
+
+// lessLoaded indica si a tiene menos carga que b
+func lessLoaded(a, b models.Agent) bool {
+	if la, lb := load(a), load(b); la != lb {
+		return la < lb
+	}
+	if a.ActiveChats != b.ActiveChats {
+		return a.ActiveChats < b.ActiveChats
+	}
+	if a.OpenTickets != b.OpenTickets {
+		return a.OpenTickets < b.OpenTickets
+	}
+	return a.UserID < b.UserID
+}
+
+// load devuelve la proporción de tickets abiertos sobre la capacidad del agente; sin
+// límite se compara como si tuviera capacidad para unlimitedCapacity tickets
+func load(agent models.Agent) float64 {
+	capacity := agent.MaxTickets
+	if capacity == 0 {
+		capacity = unlimitedCapacity
+	}
+	return float64(agent.OpenTickets) / float64(capacity)
+}
+
+// Service combina los perfiles guardados con la presencia y la carga de los agentes
+type Service struct {
+	Store    data.DataStore
+	Presence *presence.Tracker
+}
+
+// NewService crea un servicio de agentes
+func NewService(store data.DataStore, tracker *presence.Tracker) *Service {
+	return &Service{Store: store, Presence: tracker}
+}
+
+// List devuelve todos los agentes con su estado y carga actuales
+func (s *Service) List() ([]models.Agent, error) {
+	users, err := s.Store.GetUsers()
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := s.Store.GetAgentProfiles()
+	if err != nil {
+		return nil, err
+	}
+	tickets, err := s.Store.GetTickets()
+	if err != nil {
+		return nil, err
+	}
+
+	online := make([]models.AgentPresence, 0)
+	if s.Presence != nil {
+		online = s.Presence.Online("")
+	}
+	return Build(users, profiles, online, tickets), nil
+}
+
+// Get devuelve un agente por el ID de su usuario
+func (s *Service) Get(userID string) (*models.Agent, error) {
+	list, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i].UserID == userID {
+			return &list[i], nil
+		}
+	}
+	return nil, fmt.Errorf("agente con ID %s no encontrado", userID)
+}
+
+// Assignee devuelve el agente disponible con menos carga del departamento, o "" si no hay
+func (s *Service) Assignee(department string) (string, error) {
+	list, err := s.List()
+	if err != nil {
+		return "", err
+	}
+	if agent := Pick(list, Filter{Department: department}); agent != nil {
+		return agent.UserID, nil
+	}
+	return "", nil
+}
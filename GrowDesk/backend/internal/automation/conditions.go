@@ -0,0 +1,411 @@
+package automation
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// Operadores de condición
+const (
+	OperatorEquals      = "eq"
+	OperatorNotEquals   = "neq"
+	OperatorContains    = "contains"
+	OperatorNotContains = "not_contains"
+	OperatorIn          = "in"
+	OperatorNotIn       = "not_in"
+	OperatorEmpty       = "empty"
+	OperatorNotEmpty    = "not_empty"
+	OperatorGreaterOrEq = "gte" // Comparación numérica
+	OperatorLessOrEq    = "lte" // Comparación numérica
+)
+
+const (
+	customFieldPrefix      = "customFields."
+	businessHoursField     = "businessHours"
+	businessHoursOpen      = "open"
+	businessHoursClosed    = "closed"
+	hoursSinceCreated      = "hoursSinceCreated"
+	hoursSinceUpdated      = "hoursSinceUpdated"
+	matchAll               = "all"
+	matchAny               = "any"
+	maxConditionsPerRule   = 20
+	maxConditionValueChars = 500
+)
+
+// Fields son los campos admitidos en las condiciones, además de customFields.<clave>.
+// "message" es el texto del mensaje que disparó el evento, "sender" su autor (client o
+// agent) y "businessHours" vale open o closed según el horario del widget y departamento
+// del ticket. hoursSinceCreated y hoursSinceUpdated son las horas transcurridas desde la
+// creación y la última actualización (incluidos los mensajes) del ticket.
+var Fields = []string{
+	"status",
+	"priority",
+	"category",
+	"categoryId",
+	"department",
+	"source",
+	"widgetId",
+	"assignedTo",
+	"title",
+	"description",
+	"customer.name",
+	"customer.email",
+	"tags",
+	"message",
+	"sender",
+	businessHoursField,
+	hoursSinceCreated,
+	hoursSinceUpdated,
+}
+
+// Operators son los operadores admitidos en las condiciones
+var Operators = []string{
+	OperatorEquals,
+	OperatorNotEquals,
+	OperatorContains,
+	OperatorNotContains,
+	OperatorIn,
+	OperatorNotIn,
+	OperatorEmpty,
+	OperatorNotEmpty,
+	OperatorGreaterOrEq,
+	OperatorLessOrEq,
+}
+
+// Events son los eventos que pueden disparar una regla
+var Events = []string{
+	models.AutomationEventTicketCreated,
+	models.AutomationEventTicketUpdated,
+	models.AutomationEventMessageAdded,
+	models.AutomationEventScheduled,
+}
+
+// ValidateRule comprueba la estructura de una regla (eventos, coincidencia y condiciones)
+// y la normaliza. Las acciones que dependen de otros datos las valida el llamador.
+func ValidateRule(rule *models.AutomationRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return fmt.Errorf("El nombre de la regla es requerido")
+	}
+
+	if len(rule.Events) == 0 {
+		return fmt.Errorf("La regla debe tener al menos un evento")
+	}
+	events := make([]string, 0, len(rule.Events))
+	seen := make(map[string]bool)
+	for _, event := range rule.Events {
+		if !contains(Events, event) {
+			return fmt.Errorf("Evento inválido: %s", event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+	rule.Events = events
+
+	if rule.Match == "" {
+		rule.Match = matchAll
+	}
+	if rule.Match != matchAll && rule.Match != matchAny {
+		return fmt.Errorf("Coincidencia inválida: %s (use all o any)", rule.Match)
+	}
+
+	if rule.Conditions == nil {
+		rule.Conditions = []models.AutomationCondition{}
+	}
+	if err := ValidateConditions(rule.Conditions); err != nil {
+		return err
+	}
+
+	// Sin una condición de tiempo, una regla programada se aplicaría a todos los tickets
+	// en cuanto se crea
+	if contains(rule.Events, models.AutomationEventScheduled) {
+		timed := false
+		for _, condition := range rule.Conditions {
+			if isTimeField(condition.Field) && condition.Operator == OperatorGreaterOrEq {
+				timed = true
+			}
+		}
+		if !timed || rule.Match == matchAny {
+			return fmt.Errorf("Las reglas programadas requieren coincidencia all y una condición hoursSinceCreated o hoursSinceUpdated con gte")
+		}
+	}
+
+	return nil
+}
+
+// ValidateConditions comprueba que cada condición use un campo y operador admitidos
+func ValidateConditions(conditions []models.AutomationCondition) error {
+	if len(conditions) > maxConditionsPerRule {
+		return fmt.Errorf("Una regla admite como máximo %d condiciones", maxConditionsPerRule)
+	}
+
+	for i, condition := range conditions {
+		if !validField(condition.Field) {
+			return fmt.Errorf("Condición %d: campo inválido: %s", i+1, condition.Field)
+		}
+		if !contains(Operators, condition.Operator) {
+			return fmt.Errorf("Condición %d: operador inválido: %s", i+1, condition.Operator)
+		}
+		if len(condition.Value) > maxConditionValueChars {
+			return fmt.Errorf("Condición %d: el valor es demasiado largo", i+1)
+		}
+
+		switch condition.Operator {
+		case OperatorEmpty, OperatorNotEmpty:
+			if condition.Field == businessHoursField || isTimeField(condition.Field) {
+				return fmt.Errorf("Condición %d: el campo %s no admite el operador %s", i+1, condition.Field, condition.Operator)
+			}
+			continue
+		}
+		if strings.TrimSpace(condition.Value) == "" {
+			return fmt.Errorf("Condición %d: el valor es requerido para el operador %s", i+1, condition.Operator)
+		}
+
+		if condition.Operator == OperatorGreaterOrEq || condition.Operator == OperatorLessOrEq {
+			if _, err := strconv.ParseFloat(strings.TrimSpace(condition.Value), 64); err != nil {
+				return fmt.Errorf("Condición %d: el operador %s requiere un valor numérico", i+1, condition.Operator)
+			}
+		} else if isTimeField(condition.Field) {
+			return fmt.Errorf("Condición %d: %s solo admite gte o lte", i+1, condition.Field)
+		}
+
+		if condition.Field == businessHoursField {
+			if condition.Operator != OperatorEquals && condition.Operator != OperatorNotEquals {
+				return fmt.Errorf("Condición %d: el horario de atención solo admite eq o neq", i+1)
+			}
+			value := strings.ToLower(strings.TrimSpace(condition.Value))
+			if value != businessHoursOpen && value != businessHoursClosed {
+				return fmt.Errorf("Condición %d: el horario de atención debe ser open o closed", i+1)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateWebhookURL comprueba que la URL de webhook sea http o https absoluta
+func ValidateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("URL de webhook inválida: %s", raw)
+	}
+	return nil
+}
+
+// isTimeField indica si el campo es un tiempo transcurrido en horas
+func isTimeField(field string) bool {
+	return field == hoursSinceCreated || field == hoursSinceUpdated
+}
+
+// validField indica si el campo de una condición es admitido
+func validField(field string) bool {
+	if strings.HasPrefix(field, customFieldPrefix) {
+		return len(field) > len(customFieldPrefix)
+	}
+	return contains(Fields, field)
+}
+
+// subject es lo que evalúan las condiciones: el ticket y el mensaje que disparó el evento
+type subject struct {
+	ticket        models.Ticket
+	message       *models.Message
+	businessHours func() string
+	now           time.Time
+}
+
+// values devuelve los valores de un campo. Los campos de lista (tags y campos
+// multi_enum) devuelven un valor por elemento y list=true.
+func (s subject) values(field string) (values []string, list bool) {
+	t := s.ticket
+	switch field {
+	case "status":
+		return []string{t.Status}, false
+	case "priority":
+		return []string{t.Priority}, false
+	case "category":
+		return []string{t.Category}, false
+	case "categoryId":
+		return []string{t.CategoryID}, false
+	case "department":
+		return []string{t.Department}, false
+	case "source":
+		return []string{t.Source}, false
+	case "widgetId":
+		return []string{t.WidgetID}, false
+	case "assignedTo":
+		return []string{t.AssignedTo}, false
+	case "title":
+		return []string{t.Title}, false
+	case "description":
+		return []string{t.Description}, false
+	case "customer.name":
+		return []string{t.Customer.Name}, false
+	case "customer.email":
+		return []string{t.Customer.Email}, false
+	case "tags":
+		return t.Tags, true
+	case "message":
+		if s.message == nil {
+			return nil, false
+		}
+		return []string{s.message.Content}, false
+	case "sender":
+		if s.message == nil {
+			return nil, false
+		}
+		if s.message.IsClient {
+			return []string{"client"}, false
+		}
+		return []string{"agent"}, false
+	case businessHoursField:
+		return []string{s.businessHours()}, false
+	case hoursSinceCreated:
+		return []string{formatHours(s.now.Sub(t.CreatedAt))}, false
+	case hoursSinceUpdated:
+		return []string{formatHours(s.now.Sub(t.UpdatedAt))}, false
+	}
+
+	if key := strings.TrimPrefix(field, customFieldPrefix); key != field {
+		switch value := t.CustomFields[key].(type) {
+		case nil:
+			return nil, false
+		case []interface{}:
+			result := make([]string, 0, len(value))
+			for _, item := range value {
+				result = append(result, fmt.Sprint(item))
+			}
+			return result, true
+		case []string:
+			return value, true
+		default:
+			return []string{fmt.Sprint(value)}, false
+		}
+	}
+
+	return nil, false
+}
+
+// matches evalúa una condición. Las comparaciones no distinguen mayúsculas; en los
+// campos de lista, eq y contains significan que algún elemento es igual al valor.
+func (s subject) matches(condition models.AutomationCondition) bool {
+	raw, list := s.values(condition.Field)
+	values := nonEmpty(raw)
+	expected := strings.ToLower(strings.TrimSpace(condition.Value))
+
+	switch condition.Operator {
+	case OperatorEmpty:
+		return len(values) == 0
+	case OperatorNotEmpty:
+		return len(values) > 0
+	case OperatorEquals:
+		return anyValue(values, func(v string) bool { return v == expected })
+	case OperatorNotEquals:
+		return !anyValue(values, func(v string) bool { return v == expected })
+	case OperatorContains:
+		if list {
+			return anyValue(values, func(v string) bool { return v == expected })
+		}
+		return anyValue(values, func(v string) bool { return strings.Contains(v, expected) })
+	case OperatorNotContains:
+		if list {
+			return !anyValue(values, func(v string) bool { return v == expected })
+		}
+		return !anyValue(values, func(v string) bool { return strings.Contains(v, expected) })
+	case OperatorGreaterOrEq, OperatorLessOrEq:
+		limit, err := strconv.ParseFloat(expected, 64)
+		if err != nil {
+			return false
+		}
+		return anyValue(values, func(v string) bool {
+			number, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return false
+			}
+			if condition.Operator == OperatorGreaterOrEq {
+				return number >= limit
+			}
+			return number <= limit
+		})
+	case OperatorIn, OperatorNotIn:
+		options := splitList(expected)
+		found := anyValue(values, func(v string) bool { return contains(options, v) })
+		return found == (condition.Operator == OperatorIn)
+	}
+
+	return false
+}
+
+// matchesRule indica si el sujeto cumple las condiciones de la regla. Una regla sin
+// condiciones se cumple siempre.
+func (s subject) matchesRule(rule models.AutomationRule) bool {
+	if len(rule.Conditions) == 0 {
+		return true
+	}
+
+	for _, condition := range rule.Conditions {
+		matched := s.matches(condition)
+		if rule.Match == matchAny && matched {
+			return true
+		}
+		if rule.Match != matchAny && !matched {
+			return false
+		}
+	}
+
+	return rule.Match != matchAny
+}
+
+// formatHours expresa una duración en horas
+func formatHours(d time.Duration) string {
+	return strconv.FormatFloat(d.Hours(), 'f', 2, 64)
+}
+
+// nonEmpty normaliza los valores a minúsculas y descarta los vacíos
+func nonEmpty(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// splitList separa un valor de condición "a, b, c" en sus elementos
+func splitList(value string) []string {
+	parts := strings.Split(value, ",")
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// anyValue indica si algún valor cumple la función
+func anyValue(values []string, fn func(string) bool) bool {
+	for _, value := range values {
+		if fn(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains indica si una lista de cadenas incluye un valor
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,449 @@
+// Package automation evalúa las reglas definidas por los administradores cuando ocurre un
+// evento de ticket (creación, actualización o mensaje nuevo) o periódicamente, en el caso
+// de las reglas programadas, y ejecuta sus acciones.
+package automation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/agents"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/availability"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/csat"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/customfields"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/events"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/macros"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// NotifyAssignee es el destinatario de notificación que representa al agente asignado
+const NotifyAssignee = "assignee"
+
+// maxChainDepth limita cuántas veces se reevalúan las reglas por los cambios que
+// hacen otras reglas. Junto con aplicar cada regla una sola vez por evento, evita bucles.
+const maxChainDepth = 3
+
+// Engine evalúa las reglas de automatización. Un Engine nil no hace nada.
+type Engine struct {
+	Store        data.DataStore
+	Events       *events.Publisher
+	Availability *availability.Service
+	Surveys      *csat.Service   // Envía encuestas cuando una regla resuelve un ticket (opcional)
+	Agents       *agents.Service // Elige el agente de la asignación automática (opcional)
+	client       *http.Client
+}
+
+// NewEngine crea un motor de reglas
+func NewEngine(store data.DataStore, publisher *events.Publisher, availabilityService *availability.Service) *Engine {
+	return &Engine{
+		Store:        store,
+		Events:       publisher,
+		Availability: availabilityService,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Run evalúa las reglas activas del evento, en orden de posición, para un ticket.
+// message es el mensaje que disparó el evento, si lo hay. Los cambios que hace una
+// regla disparan a su vez un evento ticket_updated, hasta maxChainDepth niveles; cada
+// regla se aplica como máximo una vez por evento original.
+func (e *Engine) Run(event, ticketID string, message *models.Message) {
+	if e == nil {
+		return
+	}
+
+	rules, err := e.Store.GetAutomationRules()
+	if err != nil {
+		log.Printf("Error al obtener reglas de automatización: %v", err)
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	fired := make(map[string]bool)
+	for depth := 0; depth < maxChainDepth; depth++ {
+		if !e.evaluate(rules, event, ticketID, message, fired) {
+			return
+		}
+		event, message = models.AutomationEventTicketUpdated, nil
+	}
+}
+
+// evaluate aplica las reglas que correspondan a un evento e indica si alguna cambió el ticket
+func (e *Engine) evaluate(rules []models.AutomationRule, event, ticketID string, message *models.Message, fired map[string]bool) bool {
+	changed := false
+	for _, rule := range rules {
+		if !rule.Active || fired[rule.ID] || !contains(rule.Events, event) {
+			continue
+		}
+
+		ticket, err := e.Store.GetTicket(ticketID)
+		if err != nil {
+			return changed
+		}
+
+		if !e.subject(*ticket, message, time.Now()).matchesRule(rule) {
+			continue
+		}
+
+		fired[rule.ID] = true
+		if e.apply(rule, event, *ticket, message) {
+			changed = true
+		}
+		if rule.StopProcessing {
+			break
+		}
+	}
+	return changed
+}
+
+// RunScheduled evalúa las reglas programadas sobre todos los tickets. Cada regla se
+// aplica una sola vez por ticket mientras este siga cumpliendo sus condiciones; el
+// registro de ejecución se guarda antes de aplicar las acciones, de modo que ni un
+// reinicio ni otra réplica la repiten. Cuando el ticket deja de cumplir las condiciones
+// el registro se elimina y la regla puede volver a aplicarse.
+func (e *Engine) RunScheduled(now time.Time) {
+	if e == nil {
+		return
+	}
+
+	rules, err := e.Store.GetAutomationRules()
+	if err != nil {
+		log.Printf("Error al obtener reglas de automatización: %v", err)
+		return
+	}
+	scheduled := make([]models.AutomationRule, 0)
+	for _, rule := range rules {
+		if rule.Active && contains(rule.Events, models.AutomationEventScheduled) {
+			scheduled = append(scheduled, rule)
+		}
+	}
+
+	runs, err := e.Store.GetAutomationRuleRuns()
+	if err != nil {
+		log.Printf("Error al obtener ejecuciones de reglas: %v", err)
+		return
+	}
+	if len(scheduled) == 0 && len(runs) == 0 {
+		return
+	}
+
+	claimed := make(map[string]bool, len(runs))
+	for _, run := range runs {
+		claimed[run.RuleID+"|"+run.TicketID] = true
+	}
+
+	tickets, err := e.Store.GetTickets()
+	if err != nil {
+		log.Printf("Error al obtener tickets: %v", err)
+		return
+	}
+
+	matching := make(map[string]bool)
+	for _, ticket := range tickets {
+		if ticket.MergedInto != "" {
+			continue
+		}
+
+		for _, rule := range scheduled {
+			if !e.subject(ticket, nil, now).matchesRule(rule) {
+				continue
+			}
+
+			key := rule.ID + "|" + ticket.ID
+			matching[key] = true
+			if claimed[key] {
+				continue
+			}
+
+			ok, err := e.Store.ClaimAutomationRuleRun(rule.ID, ticket.ID)
+			if err != nil {
+				log.Printf("Regla %s: error al registrar ejecución en el ticket %s: %v", rule.ID, ticket.ID, err)
+				continue
+			}
+			if !ok {
+				// Otra réplica la aplicó
+				continue
+			}
+
+			if e.apply(rule, models.AutomationEventScheduled, ticket, nil) {
+				e.Run(models.AutomationEventTicketUpdated, ticket.ID, nil)
+			}
+			if updated, err := e.Store.GetTicket(ticket.ID); err == nil {
+				ticket = *updated
+			}
+			if rule.StopProcessing {
+				break
+			}
+		}
+	}
+
+	// Liberar los registros de tickets que ya no cumplen las condiciones
+	stale := make([]models.AutomationRuleRun, 0)
+	for _, run := range runs {
+		if !matching[run.RuleID+"|"+run.TicketID] {
+			stale = append(stale, run)
+		}
+	}
+	if err := e.Store.DeleteAutomationRuleRuns(stale); err != nil {
+		log.Printf("Error al eliminar ejecuciones de reglas: %v", err)
+	}
+}
+
+// subject prepara lo que evalúan las condiciones de una regla
+func (e *Engine) subject(ticket models.Ticket, message *models.Message, now time.Time) subject {
+	return subject{
+		ticket:        ticket,
+		message:       message,
+		businessHours: func() string { return e.businessHours(ticket) },
+		now:           now,
+	}
+}
+
+// businessHours devuelve open o closed según el horario del widget y departamento del ticket
+func (e *Engine) businessHours(ticket models.Ticket) string {
+	if e.Availability == nil || e.Availability.Status(ticket.WidgetID, ticket.Department, time.Now()).WithinBusinessHours {
+		return businessHoursOpen
+	}
+	return businessHoursClosed
+}
+
+// autoAssignee elige al agente disponible con menos carga del equipo del ticket. Devuelve
+// nil, sin cambiar la asignación, si el ticket ya está asignado o no hay agentes disponibles.
+func (e *Engine) autoAssignee(rule models.AutomationRule, ticket models.Ticket) *string {
+	if e.Agents == nil || ticket.AssignedTo != "" {
+		return nil
+	}
+	assignee, err := e.Agents.Assignee(ticket.Department)
+	if err != nil {
+		log.Printf("Regla %s: error al elegir agente para el ticket %s: %v", rule.ID, ticket.ID, err)
+		return nil
+	}
+	if assignee == "" {
+		log.Printf("Regla %s: no hay agentes disponibles para el ticket %s", rule.ID, ticket.ID)
+		return nil
+	}
+	return &assignee
+}
+
+// apply ejecuta las acciones de una regla, registra la actividad en el ticket e indica
+// si cambió algún campo del ticket
+func (e *Engine) apply(rule models.AutomationRule, event string, ticket models.Ticket, message *models.Message) bool {
+	actions := rule.Actions
+	activity := models.Activity{
+		Type:        models.ActivityAutomationRuleApplied,
+		Description: fmt.Sprintf("Regla «%s» aplicada", rule.Name),
+		Metadata:    map[string]any{"ruleId": rule.ID, "event": event},
+	}
+
+	// Campos simples y etiquetas, con el mismo mecanismo que las operaciones masivas
+	changes := models.BulkTicketChanges{
+		Status:     actions.Status,
+		Priority:   actions.Priority,
+		AssignedTo: actions.AssignedTo,
+		CategoryID: actions.CategoryID,
+		AddTags:    actions.AddTags,
+		RemoveTags: actions.RemoveTags,
+	}
+	if actions.AssignedTo != nil && *actions.AssignedTo == agents.AutoAssign {
+		changes.AssignedTo = e.autoAssignee(rule, ticket)
+	}
+	if changes.CategoryID != "" {
+		if category, err := e.Store.GetCategory(changes.CategoryID); err == nil {
+			changes.Category = category.Name
+		} else {
+			log.Printf("Regla %s: categoría %s no encontrada", rule.ID, changes.CategoryID)
+			changes.CategoryID = ""
+		}
+	}
+
+	logged := false
+	if changes.Status != "" || changes.Priority != "" || changes.AssignedTo != nil ||
+		changes.CategoryID != "" || len(changes.AddTags) > 0 || len(changes.RemoveTags) > 0 {
+		results, err := e.Store.BulkUpdateTickets([]string{ticket.ID}, changes, activity, true)
+		if err != nil || len(results) == 0 || !results[0].Success {
+			log.Printf("Regla %s: error al actualizar el ticket %s: %v", rule.ID, ticket.ID, err)
+		} else {
+			logged = true
+		}
+	}
+
+	if actions.Department != "" || len(actions.CustomFields) > 0 {
+		if err := e.updateFields(ticket.ID, actions); err != nil {
+			log.Printf("Regla %s: error al actualizar el ticket %s: %v", rule.ID, ticket.ID, err)
+		}
+	}
+
+	if !logged {
+		if err := e.Store.AddActivity(ticket.ID, activity); err != nil {
+			log.Printf("Regla %s: error al registrar actividad: %v", rule.ID, err)
+		}
+	}
+
+	updated, err := e.Store.GetTicket(ticket.ID)
+	if err != nil {
+		return false
+	}
+
+	// Notificar al visitante del widget si cambió el estado
+	if updated.Source == "widget" && updated.Status != ticket.Status {
+		e.Events.PublishStatus(updated.ID, updated.Status)
+	}
+	e.Surveys.StatusChanged(updated.ID, ticket.Status, updated.Status)
+
+	if actions.MacroID != "" {
+		e.sendMacroReply(rule, *updated)
+	}
+	if len(actions.Notify) > 0 {
+		e.notify(rule, *updated)
+	}
+	if actions.WebhookURL != "" {
+		payload := models.AutomationWebhookPayload{
+			Event:     event,
+			RuleID:    rule.ID,
+			RuleName:  rule.Name,
+			Ticket:    *updated,
+			Message:   message,
+			Timestamp: time.Now(),
+		}
+		go e.sendWebhook(actions.WebhookURL, payload)
+	}
+
+	return ticketChanged(ticket, *updated)
+}
+
+// updateFields aplica el departamento y los campos personalizados de una regla
+func (e *Engine) updateFields(ticketID string, actions models.AutomationActions) error {
+	ticket, err := e.Store.GetTicket(ticketID)
+	if err != nil {
+		return err
+	}
+
+	if actions.Department != "" {
+		ticket.Department = actions.Department
+	}
+	if len(actions.CustomFields) > 0 {
+		defs, err := e.Store.GetCustomFieldDefinitions()
+		if err != nil {
+			return err
+		}
+		merged, err := customfields.Merge(defs, ticket.CategoryID, ticket.CustomFields, actions.CustomFields)
+		if err != nil {
+			return err
+		}
+		ticket.CustomFields = merged
+	}
+
+	ticket.UpdatedAt = time.Now()
+	return e.Store.UpdateTicket(*ticket)
+}
+
+// sendMacroReply responde al ticket con el texto de una macro compartida, firmado por
+// el agente asignado si lo hay
+func (e *Engine) sendMacroReply(rule models.AutomationRule, ticket models.Ticket) {
+	macro, err := e.Store.GetMacro(rule.Actions.MacroID)
+	if err != nil || !macro.Shared {
+		log.Printf("Regla %s: macro %s no disponible", rule.ID, rule.Actions.MacroID)
+		return
+	}
+
+	var agent *models.User
+	if ticket.AssignedTo != "" {
+		agent, _ = e.Store.GetUser(ticket.AssignedTo)
+	}
+
+	content := strings.TrimSpace(macros.Render(macro.Content, ticket, agent))
+	if content == "" {
+		return
+	}
+
+	now := time.Now()
+	message := models.Message{
+		ID:         utils.GenerateMessageID(),
+		Content:    content,
+		IsClient:   false,
+		IsInternal: macro.IsInternal,
+		Timestamp:  now,
+		CreatedAt:  now,
+	}
+	if agent != nil {
+		message.UserID = agent.ID
+		message.UserName = strings.TrimSpace(agent.FirstName + " " + agent.LastName)
+		message.UserEmail = agent.Email
+	}
+
+	if err := e.Store.AddTicketMessage(ticket.ID, message); err != nil {
+		log.Printf("Regla %s: error al agregar respuesta al ticket %s: %v", rule.ID, ticket.ID, err)
+		return
+	}
+	e.Store.BroadcastMessage(ticket.ID, message)
+	if ticket.Source == "widget" {
+		e.Events.PublishMessage(ticket.ID, message)
+	}
+}
+
+// notify crea una notificación para cada destinatario de la regla
+func (e *Engine) notify(rule models.AutomationRule, ticket models.Ticket) {
+	seen := make(map[string]bool)
+	for _, userID := range rule.Actions.Notify {
+		if userID == NotifyAssignee {
+			userID = ticket.AssignedTo
+		}
+		if userID == "" || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		notification := models.Notification{
+			UserID:      userID,
+			Message:     fmt.Sprintf("Regla «%s»: ticket %s - %s", rule.Name, ticket.ID, ticket.Title),
+			Type:        models.NotificationTypeAutomation,
+			RelatedID:   ticket.ID,
+			RelatedType: "ticket",
+		}
+		if err := e.Store.CreateNotification(notification); err != nil {
+			log.Printf("Regla %s: error al notificar a %s: %v", rule.ID, userID, err)
+		}
+	}
+}
+
+// sendWebhook envía el evento a la URL de webhook de la regla. No se reintenta: el
+// receptor puede consultar el ticket si pierde un evento.
+func (e *Engine) sendWebhook(url string, payload models.AutomationWebhookPayload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Regla %s: error al serializar webhook: %v", payload.RuleID, err)
+		return
+	}
+
+	resp, err := e.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("Regla %s: error al enviar webhook: %v", payload.RuleID, err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.Printf("Regla %s: el webhook respondió %d", payload.RuleID, resp.StatusCode)
+	}
+}
+
+// ticketChanged indica si cambió algún campo que las reglas pueden evaluar
+func ticketChanged(before, after models.Ticket) bool {
+	return before.Status != after.Status ||
+		before.Priority != after.Priority ||
+		before.AssignedTo != after.AssignedTo ||
+		before.CategoryID != after.CategoryID ||
+		before.Department != after.Department ||
+		!reflect.DeepEqual(before.Tags, after.Tags) ||
+		!reflect.DeepEqual(before.CustomFields, after.CustomFields)
+}
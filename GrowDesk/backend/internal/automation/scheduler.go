@@ -0,0 +1,65 @@
+package automation
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// schedulerLease es el nombre del turno que coordina las reglas programadas entre réplicas
+const schedulerLease = "automation_scheduler"
+
+// Scheduler ejecuta periódicamente las reglas programadas. Con varias réplicas del
+// servidor solo evalúa las reglas la que tiene el turno; las demás lo toman si deja
+// de renovarlo.
+type Scheduler struct {
+	Engine   *Engine
+	Interval time.Duration
+	holder   string
+}
+
+// NewScheduler crea un planificador que evalúa las reglas cada interval
+func NewScheduler(engine *Engine, interval time.Duration) *Scheduler {
+	hostname, _ := os.Hostname()
+	return &Scheduler{
+		Engine:   engine,
+		Interval: interval,
+		holder:   fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.New().String()[:8]),
+	}
+}
+
+// Start inicia el planificador en una goroutine hasta que se cierre stop
+func (s *Scheduler) Start(stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(s.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.tick()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// tick evalúa las reglas si esta réplica obtiene el turno
+func (s *Scheduler) tick() {
+	// El turno dura dos intervalos: se renueva en cada pasada y otra réplica solo lo
+	// toma si esta deja de ejecutarse
+	acquired, err := s.Engine.Store.AcquireLease(schedulerLease, s.holder, 2*s.Interval)
+	if err != nil {
+		log.Printf("Error al obtener el turno de reglas programadas: %v", err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	s.Engine.RunScheduled(time.Now())
+}
@@ -0,0 +1,294 @@
+package availability
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // Zonas horarias embebidas para imágenes sin tzdata
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/presence"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/teams"
+)
+
+// Mensajes por defecto cuando no hay chat en vivo
+const (
+	DefaultClosedMessage        = "Estamos fuera del horario de atención. Deja tu mensaje y te responderemos lo antes posible."
+	DefaultNoAgentsMessage      = "En este momento no hay agentes disponibles. Deja tu mensaje y te responderemos lo antes posible."
+	DefaultExpectedResponseTime = "24 horas"
+)
+
+// Service combina horarios de atención y presencia de agentes para
+// determinar si el chat en vivo está disponible
+type Service struct {
+	Store    data.DataStore
+	Presence *presence.Tracker
+}
+
+// NewService crea un servicio de disponibilidad
+func NewService(store data.DataStore, tracker *presence.Tracker) *Service {
+	return &Service{Store: store, Presence: tracker}
+}
+
+// Status calcula el estado del chat para un widget y departamento en el instante now
+func (s *Service) Status(widgetID, department string, now time.Time) models.WidgetStatus {
+	status := models.WidgetStatus{
+		WithinBusinessHours:  true,
+		ExpectedResponseTime: DefaultExpectedResponseTime,
+	}
+
+	var schedule *models.BusinessHours
+	if hours, err := s.Store.GetBusinessHours(); err == nil {
+		schedule = FindSchedule(hours, widgetID, department)
+		// Sin un horario propio del widget o del departamento se usa el del equipo
+		if schedule == nil || (schedule.WidgetID == "" && schedule.Department == "") {
+			if teamHours := s.teamSchedule(hours, department); teamHours != nil {
+				schedule = teamHours
+			}
+		}
+	} else {
+		fmt.Printf("Error al obtener horarios de atención: %v\n", err)
+	}
+
+	if schedule != nil {
+		status.TimeZone = schedule.TimeZone
+		if schedule.ExpectedResponseTime != "" {
+			status.ExpectedResponseTime = schedule.ExpectedResponseTime
+		}
+
+		// Un horario que no se puede evaluar se trata como cerrado
+		open, err := IsOpen(*schedule, now)
+		if err != nil {
+			fmt.Printf("Error al evaluar horario %s: %v\n", schedule.ID, err)
+		}
+		status.WithinBusinessHours = open
+
+		if err == nil && !open {
+			if next, ok := NextOpen(*schedule, now); ok {
+				status.NextOpenAt = &next
+			}
+		}
+	}
+
+	if s.Presence != nil {
+		status.AgentsOnline = len(s.Presence.Online(department))
+	}
+
+	status.LiveChatAvailable = status.WithinBusinessHours && status.AgentsOnline > 0
+	status.Online = status.LiveChatAvailable
+	status.OfflineMode = !status.LiveChatAvailable
+
+	switch {
+	case !status.WithinBusinessHours:
+		status.Message = DefaultClosedMessage
+		if schedule != nil && schedule.OfflineMessage != "" {
+			status.Message = schedule.OfflineMessage
+		}
+	case status.AgentsOnline == 0:
+		status.Message = DefaultNoAgentsMessage
+	}
+
+	return status
+}
+
+// teamSchedule devuelve el horario de atención asignado al equipo del departamento, o nil
+func (s *Service) teamSchedule(hours []models.BusinessHours, department string) *models.BusinessHours {
+	list, err := s.Store.GetTeams()
+	if err != nil {
+		fmt.Printf("Error al obtener equipos: %v\n", err)
+		return nil
+	}
+	team := teams.Find(list, department)
+	if team == nil || team.BusinessHoursID == "" {
+		return nil
+	}
+	for i := range hours {
+		if hours[i].ID == team.BusinessHoursID {
+			return &hours[i]
+		}
+	}
+	return nil
+}
+
+// AutoReply construye la respuesta automática para un ticket creado sin chat en vivo
+func AutoReply(status models.WidgetStatus) string {
+	message := status.Message
+	if message == "" {
+		message = DefaultNoAgentsMessage
+	}
+	return fmt.Sprintf("%s Tiempo estimado de respuesta: %s.", message, status.ExpectedResponseTime)
+}
+
+// FindSchedule elige el horario más específico: widget y departamento, solo widget,
+// solo departamento y finalmente el horario general (sin widget ni departamento)
+func FindSchedule(hours []models.BusinessHours, widgetID, department string) *models.BusinessHours {
+	var best *models.BusinessHours
+	bestScore := -1
+
+	for i := range hours {
+		h := &hours[i]
+		if h.WidgetID != "" && h.WidgetID != widgetID {
+			continue
+		}
+		if h.Department != "" && teams.Key(h.Department) != teams.Key(department) {
+			continue
+		}
+
+		score := 0
+		if h.WidgetID != "" {
+			score += 2
+		}
+		if h.Department != "" {
+			score++
+		}
+		if score > bestScore {
+			best = h
+			bestScore = score
+		}
+	}
+
+	return best
+}
+
+// Validate comprueba la zona horaria, los tramos diarios y los feriados de un horario
+func Validate(hours models.BusinessHours) error {
+	if _, err := loadLocation(hours.TimeZone); err != nil {
+		return fmt.Errorf("zona horaria inválida: %s", hours.TimeZone)
+	}
+
+	for _, day := range hours.Schedule {
+		if day.Day < 0 || day.Day > 6 {
+			return fmt.Errorf("día inválido: %d (debe ser entre 0 y 6)", day.Day)
+		}
+		open, err := parseClock(day.Open)
+		if err != nil {
+			return err
+		}
+		closing, err := parseClock(day.Close)
+		if err != nil {
+			return err
+		}
+		if open == closing {
+			return fmt.Errorf("el horario del día %d no puede abrir y cerrar a la misma hora", day.Day)
+		}
+	}
+
+	for _, holiday := range hours.Holidays {
+		if _, err := time.Parse("2006-01-02", holiday); err != nil {
+			return fmt.Errorf("feriado inválido: %s (formato esperado YYYY-MM-DD)", holiday)
+		}
+	}
+
+	return nil
+}
+
+// IsOpen indica si el horario está abierto en el instante t. Un tramo cuyo cierre
+// es anterior a su apertura continúa hasta el día siguiente.
+func IsOpen(hours models.BusinessHours, t time.Time) (bool, error) {
+	loc, err := loadLocation(hours.TimeZone)
+	if err != nil {
+		return false, err
+	}
+
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	previous := local.AddDate(0, 0, -1)
+
+	for _, day := range hours.Schedule {
+		open, err := parseClock(day.Open)
+		if err != nil {
+			return false, err
+		}
+		closing, err := parseClock(day.Close)
+		if err != nil {
+			return false, err
+		}
+
+		// Tramo que comienza hoy
+		if day.Day == int(local.Weekday()) && !isHoliday(hours, local) {
+			if closing > open && minute >= open && minute < closing {
+				return true, nil
+			}
+			if closing < open && minute >= open {
+				return true, nil
+			}
+		}
+
+		// Tramo nocturno iniciado ayer
+		if closing < open && day.Day == int(previous.Weekday()) && !isHoliday(hours, previous) && minute < closing {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// NextOpen busca la próxima apertura dentro de los siguientes 14 días
+func NextOpen(hours models.BusinessHours, t time.Time) (time.Time, bool) {
+	loc, err := loadLocation(hours.TimeZone)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	local := t.In(loc)
+	var next time.Time
+
+	for offset := 0; offset < 14; offset++ {
+		date := local.AddDate(0, 0, offset)
+		if isHoliday(hours, date) {
+			continue
+		}
+
+		for _, day := range hours.Schedule {
+			if day.Day != int(date.Weekday()) {
+				continue
+			}
+			open, err := parseClock(day.Open)
+			if err != nil {
+				continue
+			}
+
+			candidate := time.Date(date.Year(), date.Month(), date.Day(), open/60, open%60, 0, 0, loc)
+			if candidate.After(local) && (next.IsZero() || candidate.Before(next)) {
+				next = candidate
+			}
+		}
+
+		if !next.IsZero() {
+			return next, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// isHoliday indica si la fecha local es feriado
+func isHoliday(hours models.BusinessHours, local time.Time) bool {
+	date := local.Format("2006-01-02")
+	for _, holiday := range hours.Holidays {
+		if holiday == date {
+			return true
+		}
+	}
+	return false
+}
+
+// loadLocation carga la zona horaria; una zona vacía equivale a UTC
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(name)
+}
+
+// parseClock convierte HH:MM en minutos desde la medianoche (se admite 24:00)
+func parseClock(value string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hour, &minute); err != nil {
+		return 0, fmt.Errorf("hora inválida: %s (formato esperado HH:MM)", value)
+	}
+	if hour < 0 || hour > 24 || minute < 0 || minute > 59 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("hora inválida: %s", value)
+	}
+	return hour*60 + minute, nil
+}
@@ -0,0 +1,142 @@
+package availability
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// testHours abre de lunes a viernes de 9 a 18 y el sábado de 22 a 2 del domingo, en
+// Bogotá (UTC-5, sin horario de verano). El lunes 12 de octubre de 2026 es feriado.
+var testHours = models.BusinessHours{
+	ID:       "general",
+	TimeZone: "America/Bogota",
+	Schedule: []models.DaySchedule{
+		{Day: 1, Open: "09:00", Close: "18:00"},
+		{Day: 2, Open: "09:00", Close: "18:00"},
+		{Day: 3, Open: "09:00", Close: "18:00"},
+		{Day: 4, Open: "09:00", Close: "18:00"},
+		{Day: 5, Open: "09:00", Close: "18:00"},
+		{Day: 6, Open: "22:00", Close: "02:00"},
+	},
+	Holidays: []string{"2026-10-12"},
+}
+
+// bogota devuelve el instante de la fecha y hora local de Bogotá
+func bogota(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	loc, err := time.LoadLocation("America/Bogota")
+	if err != nil {
+		t.Skipf("zona horaria no disponible: %v", err)
+	}
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	if err != nil {
+		t.Fatalf("fecha inválida %q: %v", value, err)
+	}
+	return parsed
+}
+
+func TestIsOpen(t *testing.T) {
+	tests := []struct {
+		name string
+		at   string
+		want bool
+	}{
+		{"lunes en horario", "2026-10-19 10:00", true},
+		{"apertura", "2026-10-19 09:00", true},
+		{"antes de abrir", "2026-10-19 08:59", false},
+		{"cierre", "2026-10-19 18:00", false},
+		{"domingo", "2026-10-18 12:00", false},
+		{"feriado", "2026-10-12 10:00", false},
+		{"turno nocturno del sábado", "2026-10-24 23:00", true},
+		{"madrugada del domingo", "2026-10-25 01:30", true},
+		{"fin del turno nocturno", "2026-10-25 02:00", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, err := IsOpen(testHours, bogota(t, tt.at))
+			if err != nil {
+				t.Fatalf("IsOpen: %v", err)
+			}
+			if open != tt.want {
+				t.Errorf("IsOpen(%s) = %v, se esperaba %v", tt.at, open, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsOpenUsesScheduleTimeZone(t *testing.T) {
+	// 15:00 UTC son las 10:00 en Bogotá
+	open, err := IsOpen(testHours, time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("IsOpen: %v", err)
+	}
+	if !open {
+		t.Error("se esperaba abierto a las 10:00 de Bogotá")
+	}
+}
+
+func TestIsOpenInvalidSchedule(t *testing.T) {
+	tests := []struct {
+		name  string
+		hours models.BusinessHours
+	}{
+		{"zona horaria inválida", models.BusinessHours{TimeZone: "America/Ninguna", Schedule: testHours.Schedule}},
+		{"hora inválida", models.BusinessHours{Schedule: []models.DaySchedule{{Day: 1, Open: "9am", Close: "18:00"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, err := IsOpen(tt.hours, time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC))
+			if err == nil || open {
+				t.Errorf("IsOpen = (%v, %v), se esperaba cerrado con error", open, err)
+			}
+		})
+	}
+}
+
+func TestNextOpen(t *testing.T) {
+	tests := []struct {
+		name string
+		at   string
+		want string
+	}{
+		{"antes de abrir", "2026-10-19 08:00", "2026-10-19 09:00"},
+		{"después de cerrar", "2026-10-19 19:00", "2026-10-20 09:00"},
+		{"viernes por la noche", "2026-10-23 19:00", "2026-10-24 22:00"},
+		{"domingo antes del feriado", "2026-10-11 12:00", "2026-10-13 09:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, ok := NextOpen(testHours, bogota(t, tt.at))
+			if !ok {
+				t.Fatalf("NextOpen(%s) no encontró apertura", tt.at)
+			}
+			if want := bogota(t, tt.want); !next.Equal(want) {
+				t.Errorf("NextOpen(%s) = %s, se esperaba %s", tt.at, next, want)
+			}
+		})
+	}
+}
+
+func TestNextOpenWithoutOpening(t *testing.T) {
+	tests := []struct {
+		name  string
+		hours models.BusinessHours
+	}{
+		{"sin horario", models.BusinessHours{TimeZone: "America/Bogota"}},
+		{"zona horaria inválida", models.BusinessHours{TimeZone: "America/Ninguna", Schedule: testHours.Schedule}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if next, ok := NextOpen(tt.hours, time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)); ok {
+				t.Errorf("NextOpen = %s, no se esperaba apertura", next)
+			}
+		})
+	}
+}
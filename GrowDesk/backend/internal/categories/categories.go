@@ -0,0 +1,202 @@
+// Package categories organiza las categorías de tickets en un árbol y resuelve los
+// valores por defecto que cada categoría aplica a los tickets nuevos.
+package categories
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// MaxDepth es el número máximo de niveles del árbol de categorías
+const MaxDepth = 5
+
+// byID indexa las categorías por ID
+func byID(list []models.Category) map[string]models.Category {
+	index := make(map[string]models.Category, len(list))
+	for _, category := range list {
+		index[category.ID] = category
+	}
+	return index
+}
+
+// less ordena las categorías hermanas por posición y luego por nombre
+func less(a, b models.Category) bool {
+	if a.Position != b.Position {
+		return a.Position < b.Position
+	}
+	return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+}
+
+// Tree organiza las categorías en un árbol. Las categorías cuyo padre no existe se
+// muestran en la raíz.
+func Tree(list []models.Category) []models.CategoryNode {
+	index := byID(list)
+	children := make(map[string][]models.Category)
+	for _, category := range list {
+		parentID := category.ParentID
+		if _, ok := index[parentID]; !ok {
+			parentID = ""
+		}
+		children[parentID] = append(children[parentID], category)
+	}
+
+	var build func(parentID string, depth int) []models.CategoryNode
+	build = func(parentID string, depth int) []models.CategoryNode {
+		siblings := children[parentID]
+		sort.SliceStable(siblings, func(i, j int) bool { return less(siblings[i], siblings[j]) })
+
+		nodes := make([]models.CategoryNode, 0, len(siblings))
+		for _, category := range siblings {
+			node := models.CategoryNode{Category: category, Children: []models.CategoryNode{}}
+			if depth < MaxDepth {
+				node.Children = build(category.ID, depth+1)
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	return build("", 1)
+}
+
+// Flatten devuelve las categorías en el orden del árbol (cada padre seguido de sus
+// subcategorías)
+func Flatten(list []models.Category) []models.Category {
+	flat := make([]models.Category, 0, len(list))
+	var walk func(nodes []models.CategoryNode)
+	walk = func(nodes []models.CategoryNode) {
+		for _, node := range nodes {
+			flat = append(flat, node.Category)
+			walk(node.Children)
+		}
+	}
+	walk(Tree(list))
+	return flat
+}
+
+// Ancestors devuelve los ancestros de la categoría, del padre a la raíz
+func Ancestors(list []models.Category, id string) []models.Category {
+	index := byID(list)
+	ancestors := make([]models.Category, 0)
+	seen := map[string]bool{id: true}
+
+	current, ok := index[id]
+	for ok && current.ParentID != "" && !seen[current.ParentID] {
+		seen[current.ParentID] = true
+		current, ok = index[current.ParentID]
+		if ok {
+			ancestors = append(ancestors, current)
+		}
+	}
+	return ancestors
+}
+
+// Children devuelve las subcategorías directas de la categoría
+func Children(list []models.Category, id string) []models.Category {
+	children := make([]models.Category, 0)
+	for _, category := range list {
+		if category.ParentID == id {
+			children = append(children, category)
+		}
+	}
+	return children
+}
+
+// IsDescendant indica si candidate está dentro del subárbol de la categoría id
+func IsDescendant(list []models.Category, id, candidate string) bool {
+	for _, ancestor := range Ancestors(list, candidate) {
+		if ancestor.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// height devuelve el número de niveles del subárbol de la categoría, incluida ella
+func height(list []models.Category, id string, depth int) int {
+	if depth > MaxDepth {
+		return depth
+	}
+	highest := 1
+	for _, child := range Children(list, id) {
+		if h := 1 + height(list, child.ID, depth+1); h > highest {
+			highest = h
+		}
+	}
+	return highest
+}
+
+// ValidateParent comprueba que la categoría id pueda colgar de parentID: el padre
+// debe existir, no puede ser ella misma ni una de sus subcategorías y el árbol no puede
+// superar MaxDepth niveles. id vacío indica una categoría nueva.
+func ValidateParent(list []models.Category, id, parentID string) error {
+	if parentID == "" {
+		return nil
+	}
+	if parentID == id {
+		return fmt.Errorf("Una categoría no puede ser su propia categoría padre")
+	}
+
+	index := byID(list)
+	if _, ok := index[parentID]; !ok {
+		return fmt.Errorf("Categoría padre no encontrada: %s", parentID)
+	}
+	if id != "" && IsDescendant(list, id, parentID) {
+		return fmt.Errorf("Una categoría no puede colgar de una de sus subcategorías")
+	}
+
+	levels := 1
+	if id != "" {
+		levels = height(list, id, 1)
+	}
+	if len(Ancestors(list, parentID))+1+levels > MaxDepth {
+		return fmt.Errorf("El árbol de categorías no puede tener más de %d niveles", MaxDepth)
+	}
+	return nil
+}
+
+// Defaults devuelve los valores por defecto de la categoría para los tickets nuevos;
+// los que la categoría deja vacíos se heredan del ancestro más cercano que los define
+func Defaults(list []models.Category, id string) models.CategoryDefaults {
+	category, ok := byID(list)[id]
+	if !ok {
+		return models.CategoryDefaults{}
+	}
+
+	defaults := category.Defaults
+	for _, ancestor := range Ancestors(list, id) {
+		if defaults.Department == "" {
+			defaults.Department = ancestor.Defaults.Department
+		}
+		if defaults.Priority == "" {
+			defaults.Priority = ancestor.Defaults.Priority
+		}
+		if defaults.AssigneeGroup == "" {
+			defaults.AssigneeGroup = ancestor.Defaults.AssigneeGroup
+		}
+		if defaults.SLAPolicy == "" {
+			defaults.SLAPolicy = ancestor.Defaults.SLAPolicy
+		}
+	}
+	return defaults
+}
+
+// Apply completa el ticket con los valores por defecto de su categoría. El
+// departamento y la prioridad elegidos al crear el ticket tienen prioridad.
+func Apply(ticket *models.Ticket, defaults models.CategoryDefaults) {
+	if ticket.Department == "" {
+		ticket.Department = defaults.Department
+	}
+	if ticket.Priority == "" {
+		ticket.Priority = defaults.Priority
+	}
+	if ticket.AssigneeGroup == "" {
+		ticket.AssigneeGroup = defaults.AssigneeGroup
+	}
+	if ticket.SLAPolicy == "" {
+		ticket.SLAPolicy = defaults.SLAPolicy
+	}
+}
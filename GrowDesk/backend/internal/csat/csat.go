@@ -0,0 +1,295 @@
+// Package csat envía encuestas de satisfacción al resolverse un ticket y resume sus
+// respuestas por agente, categoría o período.
+package csat
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/events"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/mailer"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// Límites de las respuestas
+const (
+	MinRating        = 1
+	MaxRating        = 5
+	MaxCommentLength = 2000
+)
+
+// DefaultTTL es el tiempo durante el que se puede responder una encuesta
+const DefaultTTL = 7 * 24 * time.Hour
+
+// Agrupaciones admitidas en el informe
+const (
+	GroupByAgent    = "agent"
+	GroupByCategory = "category"
+	GroupByDay      = "day"
+	GroupByWeek     = "week"
+	GroupByMonth    = "month"
+)
+
+// TokenPlaceholder se reemplaza por el token en la URL de la encuesta
+const TokenPlaceholder = "{token}"
+
+// anonymousEmail es el correo que el widget asigna a los visitantes sin correo
+const anonymousEmail = "anonymous@example.com"
+
+// Service crea y entrega las encuestas. Un Service nil no envía encuestas.
+type Service struct {
+	Store     data.DataStore
+	Events    *events.Publisher
+	Mailer    *mailer.Mailer
+	SurveyURL string // URL de la encuesta para el correo, con {token}; vacía no envía correos
+	TTL       time.Duration
+}
+
+// NewService crea un servicio de encuestas
+func NewService(store data.DataStore, publisher *events.Publisher, m *mailer.Mailer, surveyURL string) *Service {
+	return &Service{Store: store, Events: publisher, Mailer: m, SurveyURL: surveyURL, TTL: DefaultTTL}
+}
+
+// StatusChanged envía una encuesta cuando un ticket pasa a resolved. Si el ticket ya
+// tiene una encuesta vigente sin responder se reenvía esa en lugar de crear otra, con un
+// token nuevo porque del anterior solo se guarda el hash.
+func (s *Service) StatusChanged(ticketID, previous, status string) {
+	if s == nil || status != "resolved" || previous == "resolved" {
+		return
+	}
+
+	ticket, err := s.Store.GetTicket(ticketID)
+	if err != nil || ticket.MergedInto != "" {
+		return
+	}
+
+	now := time.Now()
+	survey, err := s.pendingSurvey(ticket.ID, now)
+	if err != nil {
+		log.Printf("Error al consultar encuestas del ticket %s: %v", ticket.ID, err)
+		return
+	}
+
+	token, err := newToken()
+	if err != nil {
+		log.Printf("Error al generar token de encuesta: %v", err)
+		return
+	}
+
+	if survey != nil {
+		if err := s.Store.UpdateSurveyTokenHash(survey.ID, HashToken(token)); err != nil {
+			log.Printf("Error al renovar encuesta del ticket %s: %v", ticket.ID, err)
+			return
+		}
+	} else {
+		survey = &models.SatisfactionSurvey{
+			TokenHash:  HashToken(token),
+			TicketID:   ticket.ID,
+			AgentID:    ticket.AssignedTo,
+			CategoryID: ticket.CategoryID,
+			Category:   ticket.Category,
+			CreatedAt:  now,
+			ExpiresAt:  now.Add(s.TTL),
+		}
+		if survey.Category == "" && survey.CategoryID != "" {
+			if category, err := s.Store.GetCategory(survey.CategoryID); err == nil {
+				survey.Category = category.Name
+			}
+		}
+		if err := s.Store.CreateSurvey(*survey); err != nil {
+			log.Printf("Error al crear encuesta del ticket %s: %v", ticket.ID, err)
+			return
+		}
+	}
+
+	s.deliver(*ticket, *survey, token)
+}
+
+// pendingSurvey devuelve la encuesta vigente sin responder del ticket, si la hay
+func (s *Service) pendingSurvey(ticketID string, now time.Time) (*models.SatisfactionSurvey, error) {
+	surveys, err := s.Store.GetSurveys(models.SurveyFilter{TicketID: ticketID})
+	if err != nil {
+		return nil, err
+	}
+	for _, survey := range surveys {
+		if survey.Rating == nil && !Expired(survey, now) {
+			result := survey
+			return &result, nil
+		}
+	}
+	return nil, nil
+}
+
+// deliver envía el token de la encuesta por el chat del widget y por correo
+func (s *Service) deliver(ticket models.Ticket, survey models.SatisfactionSurvey, token string) {
+	if ticket.Source == "widget" {
+		s.Events.PublishSurvey(ticket.ID, token)
+	}
+
+	email := strings.TrimSpace(ticket.Customer.Email)
+	if email == "" || email == anonymousEmail || !s.Mailer.Enabled() || s.SurveyURL == "" {
+		return
+	}
+
+	link := strings.ReplaceAll(s.SurveyURL, TokenPlaceholder, token)
+	name := ticket.Customer.Name
+	if name == "" {
+		name = "cliente"
+	}
+	subject := fmt.Sprintf("¿Cómo fue tu experiencia? Ticket %s", ticket.ID)
+	body := fmt.Sprintf("Hola %s,\n\nTu ticket «%s» fue marcado como resuelto. Nos ayudaría mucho conocer tu opinión sobre la atención recibida:\n\n%s\n\nLa encuesta estará disponible hasta el %s.\n\nGracias,\nEl equipo de soporte\n",
+		name, ticket.Title, link, survey.ExpiresAt.Format("02/01/2006"))
+
+	// El envío puede tardar; no se bloquea la solicitud que resolvió el ticket
+	go func() {
+		if err := s.Mailer.Send(email, subject, body); err != nil {
+			log.Printf("Error al enviar encuesta del ticket %s: %v", ticket.ID, err)
+		}
+	}()
+}
+
+// Expired indica si una encuesta ya no admite respuestas
+func Expired(survey models.SatisfactionSurvey, now time.Time) bool {
+	return !survey.ExpiresAt.IsZero() && now.After(survey.ExpiresAt)
+}
+
+// ValidateResponse comprueba y normaliza la respuesta de un cliente
+func ValidateResponse(req *models.SurveyResponseRequest) error {
+	if req.Rating < MinRating || req.Rating > MaxRating {
+		return fmt.Errorf("La valoración debe estar entre %d y %d", MinRating, MaxRating)
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+	if len([]rune(req.Comment)) > MaxCommentLength {
+		return fmt.Errorf("El comentario no puede superar los %d caracteres", MaxCommentLength)
+	}
+	return nil
+}
+
+// ValidGroupBy indica si la agrupación del informe es admitida
+func ValidGroupBy(groupBy string) bool {
+	switch groupBy {
+	case GroupByAgent, GroupByCategory, GroupByDay, GroupByWeek, GroupByMonth:
+		return true
+	}
+	return false
+}
+
+// Report resume las encuestas en total y por grupo. agentNames traduce los IDs de
+// agentes a nombres; loc es la zona horaria de los períodos.
+func Report(surveys []models.SatisfactionSurvey, groupBy string, agentNames map[string]string, loc *time.Location) (models.CSATSummary, []models.CSATSummary) {
+	overall := &models.CSATSummary{Key: "all", Label: "Total"}
+	groups := make(map[string]*models.CSATSummary)
+	ratings := make(map[*models.CSATSummary]int)
+	satisfied := make(map[*models.CSATSummary]int)
+
+	for _, survey := range surveys {
+		key, label := groupKey(survey, groupBy, agentNames, loc)
+		group, ok := groups[key]
+		if !ok {
+			group = &models.CSATSummary{Key: key, Label: label}
+			groups[key] = group
+		}
+
+		for _, summary := range []*models.CSATSummary{overall, group} {
+			summary.Sent++
+			if survey.Rating != nil {
+				summary.Responses++
+				ratings[summary] += *survey.Rating
+				if *survey.Rating >= 4 {
+					satisfied[summary]++
+				}
+			}
+		}
+	}
+
+	finish := func(summary *models.CSATSummary) {
+		if summary.Sent > 0 {
+			summary.ResponseRate = round(float64(summary.Responses) * 100 / float64(summary.Sent))
+		}
+		if summary.Responses > 0 {
+			summary.AverageRating = round(float64(ratings[summary]) / float64(summary.Responses))
+			summary.Score = round(float64(satisfied[summary]) * 100 / float64(summary.Responses))
+		}
+	}
+
+	finish(overall)
+	result := make([]models.CSATSummary, 0, len(groups))
+	for _, group := range groups {
+		finish(group)
+		result = append(result, *group)
+	}
+
+	// Los períodos se ordenan cronológicamente; los demás grupos, por nombre
+	sort.Slice(result, func(i, j int) bool {
+		if groupBy == GroupByDay || groupBy == GroupByWeek || groupBy == GroupByMonth {
+			return result[i].Key < result[j].Key
+		}
+		return strings.ToLower(result[i].Label) < strings.ToLower(result[j].Label)
+	})
+
+	return *overall, result
+}
+
+// groupKey devuelve la clave y el nombre del grupo de una encuesta
+func groupKey(survey models.SatisfactionSurvey, groupBy string, agentNames map[string]string, loc *time.Location) (string, string) {
+	switch groupBy {
+	case GroupByAgent:
+		if survey.AgentID == "" {
+			return "", "Sin asignar"
+		}
+		if name, ok := agentNames[survey.AgentID]; ok && name != "" {
+			return survey.AgentID, name
+		}
+		return survey.AgentID, survey.AgentID
+	case GroupByCategory:
+		if survey.CategoryID == "" && survey.Category == "" {
+			return "", "Sin categoría"
+		}
+		key := survey.CategoryID
+		if key == "" {
+			key = survey.Category
+		}
+		label := survey.Category
+		if label == "" {
+			label = key
+		}
+		return key, label
+	case GroupByWeek:
+		year, week := survey.CreatedAt.In(loc).ISOWeek()
+		key := fmt.Sprintf("%d-W%02d", year, week)
+		return key, key
+	case GroupByMonth:
+		key := survey.CreatedAt.In(loc).Format("2006-01")
+		return key, key
+	default:
+		key := survey.CreatedAt.In(loc).Format("2006-01-02")
+		return key, key
+	}
+}
+
+// HashToken devuelve el hash con el que se guarda y se busca el token de una encuesta
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newToken genera un token aleatorio para la URL de la encuesta
+func newToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// round redondea a dos decimales
+func round(value float64) float64 {
+	return float64(int64(value*100+0.5)) / 100
+}
@@ -0,0 +1,334 @@
+// Package customfields valida y compara los valores de los campos personalizados de tickets
+// según las definiciones creadas por los administradores.
+package customfields
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// DateLayout es el formato con el que se guardan los campos de tipo fecha
+const DateLayout = "2006-01-02"
+
+// keyPattern restringe las claves a identificadores simples, seguros en rutas JSON y consultas
+var keyPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,49}$`)
+
+// ValidateDefinition comprueba que una definición de campo sea coherente
+func ValidateDefinition(def models.CustomFieldDefinition) error {
+	if !keyPattern.MatchString(def.Key) {
+		return fmt.Errorf("la clave %q no es válida: use letras, números y guiones bajos", def.Key)
+	}
+	if strings.TrimSpace(def.Label) == "" {
+		return fmt.Errorf("la etiqueta del campo es requerida")
+	}
+
+	switch def.Type {
+	case models.CustomFieldString, models.CustomFieldNumber, models.CustomFieldDate, models.CustomFieldBoolean:
+		if len(def.Options) > 0 {
+			return fmt.Errorf("solo los campos enum y multi_enum admiten opciones")
+		}
+	case models.CustomFieldEnum, models.CustomFieldMultiEnum:
+		if len(def.Options) == 0 {
+			return fmt.Errorf("los campos %s requieren al menos una opción", def.Type)
+		}
+		seen := make(map[string]bool)
+		for _, option := range def.Options {
+			if strings.TrimSpace(option) == "" {
+				return fmt.Errorf("las opciones no pueden estar vacías")
+			}
+			if seen[option] {
+				return fmt.Errorf("la opción %q está duplicada", option)
+			}
+			seen[option] = true
+		}
+	default:
+		return fmt.Errorf("tipo de campo %q no soportado", def.Type)
+	}
+
+	return nil
+}
+
+// Applicable devuelve las definiciones activas que aplican a una categoría, ordenadas por posición
+func Applicable(defs []models.CustomFieldDefinition, categoryID string) []models.CustomFieldDefinition {
+	result := make([]models.CustomFieldDefinition, 0)
+	for _, def := range defs {
+		if def.Active && (def.CategoryID == "" || def.CategoryID == categoryID) {
+			result = append(result, def)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Position < result[j].Position
+	})
+
+	return result
+}
+
+// Find busca una definición por clave
+func Find(defs []models.CustomFieldDefinition, key string) (*models.CustomFieldDefinition, bool) {
+	for i := range defs {
+		if defs[i].Key == key {
+			return &defs[i], true
+		}
+	}
+	return nil, false
+}
+
+// Normalize convierte un valor recibido (JSON o texto de una consulta) al tipo del campo
+func Normalize(def models.CustomFieldDefinition, raw interface{}) (interface{}, error) {
+	switch def.Type {
+	case models.CustomFieldString:
+		switch v := raw.(type) {
+		case string:
+			return v, nil
+		case float64, bool:
+			return fmt.Sprintf("%v", v), nil
+		}
+
+	case models.CustomFieldNumber:
+		switch v := raw.(type) {
+		case float64:
+			return v, nil
+		case string:
+			number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err == nil {
+				return number, nil
+			}
+		}
+		return nil, fmt.Errorf("el campo %s debe ser numérico", def.Label)
+
+	case models.CustomFieldDate:
+		if v, ok := raw.(string); ok {
+			v = strings.TrimSpace(v)
+			if date, err := time.Parse(DateLayout, v); err == nil {
+				return date.Format(DateLayout), nil
+			}
+			if date, err := time.Parse(time.RFC3339, v); err == nil {
+				return date.Format(DateLayout), nil
+			}
+		}
+		return nil, fmt.Errorf("el campo %s debe ser una fecha con formato AAAA-MM-DD", def.Label)
+
+	case models.CustomFieldBoolean:
+		switch v := raw.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if parsed, err := strconv.ParseBool(v); err == nil {
+				return parsed, nil
+			}
+		}
+		return nil, fmt.Errorf("el campo %s debe ser verdadero o falso", def.Label)
+
+	case models.CustomFieldEnum:
+		if v, ok := raw.(string); ok {
+			if hasOption(def, v) {
+				return v, nil
+			}
+			return nil, fmt.Errorf("el valor %q no es una opción válida para %s", v, def.Label)
+		}
+
+	case models.CustomFieldMultiEnum:
+		var items []interface{}
+		switch v := raw.(type) {
+		case []interface{}:
+			items = v
+		case []string:
+			for _, item := range v {
+				items = append(items, item)
+			}
+		case string:
+			items = []interface{}{v}
+		}
+		if items == nil {
+			break
+		}
+
+		values := make([]string, 0, len(items))
+		seen := make(map[string]bool)
+		for _, item := range items {
+			option, ok := item.(string)
+			if !ok || !hasOption(def, option) {
+				return nil, fmt.Errorf("el valor %v no es una opción válida para %s", item, def.Label)
+			}
+			if !seen[option] {
+				seen[option] = true
+				values = append(values, option)
+			}
+		}
+		return values, nil
+	}
+
+	return nil, fmt.Errorf("el campo %s tiene un formato inválido", def.Label)
+}
+
+// Validate valida y normaliza los campos personalizados de un ticket nuevo, exigiendo
+// todos los campos requeridos de su categoría
+func Validate(defs []models.CustomFieldDefinition, categoryID string, values map[string]interface{}) (map[string]interface{}, error) {
+	return apply(defs, categoryID, nil, values, true)
+}
+
+// Merge valida los valores enviados y los combina con los actuales del ticket. Un valor
+// nulo elimina el campo, salvo que sea requerido; las claves actuales sin definición se
+// conservan sin cambios.
+func Merge(defs []models.CustomFieldDefinition, categoryID string, current, updates map[string]interface{}) (map[string]interface{}, error) {
+	return apply(defs, categoryID, current, updates, false)
+}
+
+// apply implementa Validate y Merge
+func apply(defs []models.CustomFieldDefinition, categoryID string, current, updates map[string]interface{}, requireAll bool) (map[string]interface{}, error) {
+	applicable := Applicable(defs, categoryID)
+
+	result := make(map[string]interface{}, len(current)+len(updates))
+	for key, value := range current {
+		result[key] = value
+	}
+
+	for key, raw := range updates {
+		def, ok := Find(applicable, key)
+		if !ok {
+			return nil, fmt.Errorf("el campo personalizado %q no existe para esta categoría", key)
+		}
+
+		if isEmpty(raw) {
+			if def.Required {
+				return nil, fmt.Errorf("el campo %s es requerido", def.Label)
+			}
+			delete(result, key)
+			continue
+		}
+
+		value, err := Normalize(*def, raw)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+
+	if requireAll {
+		for _, def := range applicable {
+			if def.Required && isEmpty(result[def.Key]) {
+				return nil, fmt.Errorf("el campo %s es requerido", def.Label)
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+// Matches indica si el valor de un ticket cumple un filtro
+func Matches(value interface{}, filter models.CustomFieldFilter) bool {
+	if value == nil {
+		return false
+	}
+
+	if filter.Type == models.CustomFieldMultiEnum && filter.Op == models.CustomFieldFilterEq {
+		items, _ := value.([]interface{})
+		for _, item := range items {
+			if item == filter.Value {
+				return true
+			}
+		}
+		if list, ok := value.([]string); ok {
+			for _, item := range list {
+				if item == filter.Value {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	cmp, ok := Compare(filter.Type, value, filter.Value)
+	if !ok {
+		return false
+	}
+
+	switch filter.Op {
+	case models.CustomFieldFilterMin:
+		return cmp >= 0
+	case models.CustomFieldFilterMax:
+		return cmp <= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// Compare compara dos valores de un campo del tipo indicado. Devuelve false si alguno
+// de los valores no es del tipo esperado.
+func Compare(fieldType string, a, b interface{}) (int, bool) {
+	switch fieldType {
+	case models.CustomFieldNumber:
+		x, okA := a.(float64)
+		y, okB := b.(float64)
+		if !okA || !okB {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+
+	case models.CustomFieldBoolean:
+		x, okA := a.(bool)
+		y, okB := b.(bool)
+		if !okA || !okB {
+			return 0, false
+		}
+		switch {
+		case x == y:
+			return 0, true
+		case !x:
+			return -1, true
+		}
+		return 1, true
+
+	case models.CustomFieldMultiEnum:
+		return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b)), true
+	}
+
+	// string, enum y date (AAAA-MM-DD se ordena lexicográficamente)
+	x, okA := a.(string)
+	y, okB := b.(string)
+	if !okA || !okB {
+		return 0, false
+	}
+	return strings.Compare(x, y), true
+}
+
+// hasOption indica si el valor es una de las opciones del campo
+func hasOption(def models.CustomFieldDefinition, value string) bool {
+	for _, option := range def.Options {
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmpty indica si un valor recibido debe tratarse como ausente
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	}
+	return false
+}
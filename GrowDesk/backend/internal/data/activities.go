@@ -0,0 +1,59 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadActivities carga el registro de actividad desde archivo
+func (s *Store) loadActivities() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Activities = make([]models.Activity, 0)
+	if loadJSONFile(s.ActivitiesFile, &s.Activities) {
+		fmt.Printf("Cargadas %d actividades desde archivo\n", len(s.Activities))
+	}
+}
+
+// appendActivityLocked registra una actividad sobre targetID a partir de una plantilla.
+// El llamador debe tener el mutex y guardar el archivo de actividades.
+func (s *Store) appendActivityLocked(activity models.Activity, targetID string, now time.Time) {
+	activity.ID = uuid.New().String()
+	activity.TargetID = targetID
+	activity.Timestamp = now
+	s.Activities = append(s.Activities, activity)
+}
+
+// GetActivities devuelve las actividades de un elemento, las más recientes primero
+func (s *Store) GetActivities(targetID string) ([]models.Activity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.Activity, 0)
+	for _, activity := range s.Activities {
+		if activity.TargetID == targetID {
+			result = append(result, activity)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.After(result[j].Timestamp)
+	})
+
+	return result, nil
+}
+
+// AddActivity registra una actividad sobre targetID
+func (s *Store) AddActivity(targetID string, activity models.Activity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.appendActivityLocked(activity, targetID, time.Now())
+	return writeJSONFile(s.ActivitiesFile, s.Activities)
+}
@@ -0,0 +1,106 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadAgentProfiles carga los perfiles de agentes desde archivo
+func (s *Store) loadAgentProfiles() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.AgentProfiles = make([]models.AgentProfile, 0)
+	if loadJSONFile(s.AgentsFile, &s.AgentProfiles) {
+		fmt.Printf("Cargados %d perfiles de agentes desde archivo\n", len(s.AgentProfiles))
+	}
+}
+
+// GetAgentProfiles devuelve los perfiles guardados de los agentes
+func (s *Store) GetAgentProfiles() ([]models.AgentProfile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]models.AgentProfile, 0, len(s.AgentProfiles))
+	for _, profile := range s.AgentProfiles {
+		list = append(list, copyAgentProfile(profile))
+	}
+	return list, nil
+}
+
+// GetAgentProfile obtiene el perfil de un agente. Un agente sin perfil guardado recibe
+// un perfil vacío: estado derivado de la presencia, sin límites y sin habilidades.
+func (s *Store) GetAgentProfile(userID string) (*models.AgentProfile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.findUserLocked(userID) < 0 {
+		return nil, fmt.Errorf("usuario con ID %s no encontrado", userID)
+	}
+	for _, profile := range s.AgentProfiles {
+		if profile.UserID == userID {
+			profile = copyAgentProfile(profile)
+			return &profile, nil
+		}
+	}
+	return &models.AgentProfile{UserID: userID, Skills: make([]models.AgentSkill, 0)}, nil
+}
+
+// SaveAgentProfile crea o reemplaza el perfil de un agente
+func (s *Store) SaveAgentProfile(profile models.AgentProfile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findUserLocked(profile.UserID) < 0 {
+		return fmt.Errorf("usuario con ID %s no encontrado", profile.UserID)
+	}
+	profile.UpdatedAt = time.Now()
+	profile = copyAgentProfile(profile)
+
+	previous := make([]models.AgentProfile, len(s.AgentProfiles))
+	copy(previous, s.AgentProfiles)
+
+	found := false
+	for i := range s.AgentProfiles {
+		if s.AgentProfiles[i].UserID == profile.UserID {
+			s.AgentProfiles[i] = profile
+			found = true
+		}
+	}
+	if !found {
+		s.AgentProfiles = append(s.AgentProfiles, profile)
+	}
+
+	if err := s.saveAgentProfilesLocked(); err != nil {
+		s.AgentProfiles = previous
+		return err
+	}
+	return nil
+}
+
+// removeAgentProfileLocked elimina el perfil del agente si existe; el llamador debe
+// tener s.mu
+func (s *Store) removeAgentProfileLocked(userID string) error {
+	for i, profile := range s.AgentProfiles {
+		if profile.UserID == userID {
+			s.AgentProfiles = append(s.AgentProfiles[:i], s.AgentProfiles[i+1:]...)
+			return s.saveAgentProfilesLocked()
+		}
+	}
+	return nil
+}
+
+// saveAgentProfilesLocked guarda los perfiles de agentes; el llamador debe tener s.mu
+func (s *Store) saveAgentProfilesLocked() error {
+	return writeJSONFile(s.AgentsFile, s.AgentProfiles)
+}
+
+// copyAgentProfile copia el perfil con su propia lista de habilidades
+func copyAgentProfile(profile models.AgentProfile) models.AgentProfile {
+	skills := make([]models.AgentSkill, len(profile.Skills))
+	copy(skills, profile.Skills)
+	profile.Skills = skills
+	return profile
+}
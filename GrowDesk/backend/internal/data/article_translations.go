@@ -0,0 +1,110 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// GetArticleTranslations devuelve las traducciones de un artículo ordenadas por idioma
+func (s *Store) GetArticleTranslations(articleID int) ([]models.ArticleTranslation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.findArticleLocked(articleID) < 0 {
+		return nil, fmt.Errorf("artículo con ID %d no encontrado", articleID)
+	}
+
+	translations := make([]models.ArticleTranslation, 0)
+	for _, translation := range s.Translations {
+		if translation.ArticleID == articleID {
+			translations = append(translations, translation)
+		}
+	}
+
+	sort.Slice(translations, func(i, j int) bool { return translations[i].Locale < translations[j].Locale })
+	return translations, nil
+}
+
+// GetTranslationsByLocale devuelve las traducciones de todos los artículos a un idioma
+func (s *Store) GetTranslationsByLocale(locale string) ([]models.ArticleTranslation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	translations := make([]models.ArticleTranslation, 0)
+	for _, translation := range s.Translations {
+		if translation.Locale == locale {
+			translations = append(translations, translation)
+		}
+	}
+
+	sort.Slice(translations, func(i, j int) bool { return translations[i].ArticleID < translations[j].ArticleID })
+	return translations, nil
+}
+
+// GetArticleTranslation obtiene la traducción de un artículo a un idioma
+func (s *Store) GetArticleTranslation(articleID int, locale string) (*models.ArticleTranslation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.findTranslationLocked(articleID, locale)
+	if i < 0 {
+		return nil, fmt.Errorf("traducción %s del artículo %d no encontrada", locale, articleID)
+	}
+	translation := s.Translations[i]
+	return &translation, nil
+}
+
+// SaveArticleTranslation crea o reemplaza la traducción de un artículo a un idioma
+func (s *Store) SaveArticleTranslation(translation models.ArticleTranslation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findArticleLocked(translation.ArticleID) < 0 {
+		return fmt.Errorf("artículo con ID %d no encontrado", translation.ArticleID)
+	}
+
+	i := s.findTranslationLocked(translation.ArticleID, translation.Locale)
+	if i < 0 {
+		s.Translations = append(s.Translations, translation)
+		if err := writeJSONFile(s.TranslationsFile, s.Translations); err != nil {
+			s.Translations = s.Translations[:len(s.Translations)-1]
+			return err
+		}
+		return nil
+	}
+
+	existing := s.Translations[i]
+	translation.CreatedAt = existing.CreatedAt
+	s.Translations[i] = translation
+	if err := writeJSONFile(s.TranslationsFile, s.Translations); err != nil {
+		s.Translations[i] = existing
+		return err
+	}
+	return nil
+}
+
+// DeleteArticleTranslation elimina la traducción de un artículo a un idioma
+func (s *Store) DeleteArticleTranslation(articleID int, locale string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findTranslationLocked(articleID, locale)
+	if i < 0 {
+		return fmt.Errorf("traducción %s del artículo %d no encontrada", locale, articleID)
+	}
+
+	s.Translations = append(s.Translations[:i], s.Translations[i+1:]...)
+	return writeJSONFile(s.TranslationsFile, s.Translations)
+}
+
+// findTranslationLocked devuelve la posición de la traducción o -1
+func (s *Store) findTranslationLocked(articleID int, locale string) int {
+	for i, translation := range s.Translations {
+		if translation.ArticleID == articleID && translation.Locale == locale {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,373 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/kb"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadArticles carga los artículos de la base de conocimiento. La primera vez migra
+// las FAQs del archivo anterior (o las de ejemplo si tampoco existe).
+func (s *Store) loadArticles() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Articles = make([]models.Article, 0)
+	s.ArticleRevisions = make([]models.ArticleRevision, 0)
+	s.Translations = make([]models.ArticleTranslation, 0)
+	if loadJSONFile(s.ArticlesFile, &s.Articles) {
+		loadJSONFile(s.ArticleRevisionsFile, &s.ArticleRevisions)
+		loadJSONFile(s.TranslationsFile, &s.Translations)
+		// Los artículos anteriores a las traducciones no indican idioma
+		for i := range s.Articles {
+			if s.Articles[i].Locale == "" {
+				s.Articles[i].Locale = kb.DefaultLocale
+			}
+		}
+		fmt.Printf("Cargados %d artículos desde archivo\n", len(s.Articles))
+		return
+	}
+
+	faqs := defaultFAQs()
+	loadJSONFile(s.FAQsFile, &faqs)
+
+	for _, faq := range faqs {
+		article, revision := kb.FromFAQ(faq, s.Categories)
+		if article.ID <= 0 || s.findArticleLocked(article.ID) >= 0 {
+			article.ID = s.nextArticleIDLocked()
+		}
+		article.Slug = kb.UniqueSlug(article.Slug, func(slug string) bool {
+			return s.findArticleBySlugLocked(slug) >= 0
+		})
+		revision.ArticleID = article.ID
+		s.Articles = append(s.Articles, article)
+		s.ArticleRevisions = append(s.ArticleRevisions, revision)
+	}
+
+	if err := s.saveArticlesLocked(); err != nil {
+		fmt.Printf("Error al guardar artículos migrados: %v\n", err)
+		return
+	}
+	fmt.Printf("Migradas %d FAQs a la base de conocimiento\n", len(faqs))
+}
+
+// defaultFAQs son las FAQs de ejemplo de una instalación nueva
+func defaultFAQs() []models.FAQ {
+	now := time.Now()
+	return []models.FAQ{
+		{
+			ID:          1,
+			Question:    "¿Cómo puedo crear un ticket?",
+			Answer:      "Para crear un ticket, haz clic en el botón 'Nuevo Ticket' y completa el formulario.",
+			IsPublished: true,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+		{
+			ID:          2,
+			Question:    "¿Cómo puedo ver mis tickets?",
+			Answer:      "Puedes ver tus tickets en la sección 'Mis Tickets' del panel de control.",
+			IsPublished: true,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+		{
+			ID:          3,
+			Question:    "¿Cómo puedo actualizar mi perfil?",
+			Answer:      "Para actualizar tu perfil, ve a la sección 'Configuración' y haz clic en 'Editar Perfil'.",
+			IsPublished: true,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+	}
+}
+
+// GetArticles devuelve los artículos (versión de trabajo) que cumplen el filtro
+func (s *Store) GetArticles(filter models.ArticleFilter) ([]models.Article, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	articles := make([]models.Article, 0)
+	for _, article := range s.Articles {
+		if filter.Status != "" && article.Status != filter.Status {
+			continue
+		}
+		if filter.CategoryID != "" && article.CategoryID != filter.CategoryID {
+			continue
+		}
+		articles = append(articles, article)
+	}
+
+	sort.Slice(articles, func(i, j int) bool { return articles[i].ID < articles[j].ID })
+	return articles, nil
+}
+
+// GetPublishedArticles devuelve los artículos publicados con el contenido de su
+// revisión publicada
+func (s *Store) GetPublishedArticles() ([]models.Article, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	articles := make([]models.Article, 0)
+	for _, article := range s.Articles {
+		if article.PublishedRevision == 0 {
+			continue
+		}
+		i := s.findArticleRevisionLocked(article.ID, article.PublishedRevision)
+		if i < 0 {
+			continue
+		}
+		revision := s.ArticleRevisions[i]
+		article.Title = revision.Title
+		article.Body = revision.Body
+		article.CategoryID = revision.CategoryID
+		articles = append(articles, article)
+	}
+
+	sort.Slice(articles, func(i, j int) bool { return articles[i].ID < articles[j].ID })
+	return articles, nil
+}
+
+// GetArticle obtiene un artículo por ID
+func (s *Store) GetArticle(id int) (*models.Article, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.findArticleLocked(id)
+	if i < 0 {
+		return nil, fmt.Errorf("artículo con ID %d no encontrado", id)
+	}
+	article := s.Articles[i]
+	return &article, nil
+}
+
+// GetArticleBySlug obtiene un artículo por su slug
+func (s *Store) GetArticleBySlug(slug string) (*models.Article, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.findArticleBySlugLocked(slug)
+	if i < 0 {
+		return nil, fmt.Errorf("artículo %s no encontrado", slug)
+	}
+	article := s.Articles[i]
+	return &article, nil
+}
+
+// CreateArticle crea un artículo con su primera revisión
+func (s *Store) CreateArticle(article models.Article, revision models.ArticleRevision) (*models.Article, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findArticleBySlugLocked(article.Slug) >= 0 {
+		return nil, fmt.Errorf("el slug %s ya está en uso", article.Slug)
+	}
+
+	article.ID = s.nextArticleIDLocked()
+	revision.ArticleID = article.ID
+	s.Articles = append(s.Articles, article)
+	s.ArticleRevisions = append(s.ArticleRevisions, revision)
+
+	if err := s.saveArticlesLocked(); err != nil {
+		s.Articles = s.Articles[:len(s.Articles)-1]
+		s.ArticleRevisions = s.ArticleRevisions[:len(s.ArticleRevisions)-1]
+		return nil, err
+	}
+	return &article, nil
+}
+
+// UpdateArticle guarda un artículo y, si se indica, su nueva revisión. La revisión del
+// artículo debe seguir a la guardada; si otra edición se adelantó, se rechaza.
+func (s *Store) UpdateArticle(article models.Article, revision *models.ArticleRevision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findArticleLocked(article.ID)
+	if i < 0 {
+		return fmt.Errorf("artículo con ID %d no encontrado", article.ID)
+	}
+	existing := s.Articles[i]
+
+	expected := existing.Revision
+	if revision != nil {
+		expected++
+		if revision.Revision != expected {
+			return fmt.Errorf("el artículo %d fue modificado por otra edición", article.ID)
+		}
+	}
+	if article.Revision != expected {
+		return fmt.Errorf("el artículo %d fue modificado por otra edición", article.ID)
+	}
+	if j := s.findArticleBySlugLocked(article.Slug); j >= 0 && j != i {
+		return fmt.Errorf("el slug %s ya está en uso", article.Slug)
+	}
+
+	article.CreatedAt = existing.CreatedAt
+	article.AuthorID = existing.AuthorID
+	s.Articles[i] = article
+	if revision != nil {
+		revision.ArticleID = article.ID
+		s.ArticleRevisions = append(s.ArticleRevisions, *revision)
+	}
+
+	if err := s.saveArticlesLocked(); err != nil {
+		s.Articles[i] = existing
+		if revision != nil {
+			s.ArticleRevisions = s.ArticleRevisions[:len(s.ArticleRevisions)-1]
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteArticle elimina un artículo, su historial, sus traducciones, sus sugerencias,
+// sus visitas y sus votos
+func (s *Store) DeleteArticle(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findArticleLocked(id)
+	if i < 0 {
+		return fmt.Errorf("artículo con ID %d no encontrado", id)
+	}
+
+	s.Articles = append(s.Articles[:i], s.Articles[i+1:]...)
+	revisions := s.ArticleRevisions[:0]
+	for _, revision := range s.ArticleRevisions {
+		if revision.ArticleID != id {
+			revisions = append(revisions, revision)
+		}
+	}
+	s.ArticleRevisions = revisions
+
+	translations := s.Translations[:0]
+	for _, translation := range s.Translations {
+		if translation.ArticleID != id {
+			translations = append(translations, translation)
+		}
+	}
+	s.Translations = translations
+
+	deflections := s.Deflections[:0]
+	for _, deflection := range s.Deflections {
+		if deflection.ArticleID != id {
+			deflections = append(deflections, deflection)
+		}
+	}
+	s.Deflections = deflections
+
+	views := s.FAQViews[:0]
+	for _, view := range s.FAQViews {
+		if view.ArticleID != id {
+			views = append(views, view)
+		}
+	}
+	s.FAQViews = views
+
+	votes := s.FAQVotes[:0]
+	for _, vote := range s.FAQVotes {
+		if vote.ArticleID != id {
+			votes = append(votes, vote)
+		}
+	}
+	s.FAQVotes = votes
+
+	if err := s.saveArticlesLocked(); err != nil {
+		return err
+	}
+	if err := writeJSONFile(s.DeflectionsFile, s.Deflections); err != nil {
+		return err
+	}
+	if err := writeJSONFile(s.FAQViewsFile, s.FAQViews); err != nil {
+		return err
+	}
+	if err := writeJSONFile(s.FAQVotesFile, s.FAQVotes); err != nil {
+		return err
+	}
+	return writeJSONFile(s.TranslationsFile, s.Translations)
+}
+
+// GetArticleRevisions devuelve el historial de un artículo, de la más reciente a la más antigua
+func (s *Store) GetArticleRevisions(articleID int) ([]models.ArticleRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.findArticleLocked(articleID) < 0 {
+		return nil, fmt.Errorf("artículo con ID %d no encontrado", articleID)
+	}
+
+	revisions := make([]models.ArticleRevision, 0)
+	for _, revision := range s.ArticleRevisions {
+		if revision.ArticleID == articleID {
+			revisions = append(revisions, revision)
+		}
+	}
+
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision > revisions[j].Revision })
+	return revisions, nil
+}
+
+// GetArticleRevision obtiene una revisión concreta de un artículo
+func (s *Store) GetArticleRevision(articleID, revision int) (*models.ArticleRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.findArticleRevisionLocked(articleID, revision)
+	if i < 0 {
+		return nil, fmt.Errorf("revisión %d del artículo %d no encontrada", revision, articleID)
+	}
+	result := s.ArticleRevisions[i]
+	return &result, nil
+}
+
+// findArticleLocked devuelve la posición del artículo o -1
+func (s *Store) findArticleLocked(id int) int {
+	for i, article := range s.Articles {
+		if article.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// findArticleBySlugLocked devuelve la posición del artículo con el slug o -1
+func (s *Store) findArticleBySlugLocked(slug string) int {
+	for i, article := range s.Articles {
+		if article.Slug == slug {
+			return i
+		}
+	}
+	return -1
+}
+
+// findArticleRevisionLocked devuelve la posición de la revisión o -1
+func (s *Store) findArticleRevisionLocked(articleID, revision int) int {
+	for i, r := range s.ArticleRevisions {
+		if r.ArticleID == articleID && r.Revision == revision {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextArticleIDLocked devuelve el siguiente ID de artículo libre
+func (s *Store) nextArticleIDLocked() int {
+	next := 1
+	for _, article := range s.Articles {
+		if article.ID >= next {
+			next = article.ID + 1
+		}
+	}
+	return next
+}
+
+// saveArticlesLocked guarda los artículos y su historial
+func (s *Store) saveArticlesLocked() error {
+	if err := writeJSONFile(s.ArticlesFile, s.Articles); err != nil {
+		return err
+	}
+	return writeJSONFile(s.ArticleRevisionsFile, s.ArticleRevisions)
+}
@@ -0,0 +1,197 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadAutomationRules carga las reglas de automatización desde archivo
+func (s *Store) loadAutomationRules() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.AutomationRules = make([]models.AutomationRule, 0)
+	if loadJSONFile(s.AutomationRulesFile, &s.AutomationRules) {
+		fmt.Printf("Cargadas %d reglas de automatización desde archivo\n", len(s.AutomationRules))
+	}
+}
+
+// GetAutomationRules devuelve todas las reglas en orden de evaluación
+func (s *Store) GetAutomationRules() ([]models.AutomationRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rules := make([]models.AutomationRule, len(s.AutomationRules))
+	copy(rules, s.AutomationRules)
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Position < rules[j].Position
+	})
+
+	return rules, nil
+}
+
+// GetAutomationRule obtiene una regla por ID
+func (s *Store) GetAutomationRule(id string) (*models.AutomationRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, rule := range s.AutomationRules {
+		if rule.ID == id {
+			result := rule
+			return &result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("regla con ID %s no encontrada", id)
+}
+
+// CreateAutomationRule crea una nueva regla
+func (s *Store) CreateAutomationRule(rule models.AutomationRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = now
+	}
+	if rule.UpdatedAt.IsZero() {
+		rule.UpdatedAt = now
+	}
+
+	s.AutomationRules = append(s.AutomationRules, rule)
+	return writeJSONFile(s.AutomationRulesFile, s.AutomationRules)
+}
+
+// UpdateAutomationRule actualiza una regla existente
+func (s *Store) UpdateAutomationRule(rule models.AutomationRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.AutomationRules {
+		if existing.ID == rule.ID {
+			rule.CreatedAt = existing.CreatedAt
+			rule.UpdatedAt = time.Now()
+			s.AutomationRules[i] = rule
+			return writeJSONFile(s.AutomationRulesFile, s.AutomationRules)
+		}
+	}
+
+	return fmt.Errorf("regla con ID %s no encontrada", rule.ID)
+}
+
+// DeleteAutomationRule elimina una regla por ID
+func (s *Store) DeleteAutomationRule(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, rule := range s.AutomationRules {
+		if rule.ID == id {
+			s.AutomationRules = append(s.AutomationRules[:i], s.AutomationRules[i+1:]...)
+			if err := writeJSONFile(s.AutomationRulesFile, s.AutomationRules); err != nil {
+				return err
+			}
+
+			// Eliminar los registros de ejecución de la regla
+			runs := make([]models.AutomationRuleRun, 0, len(s.AutomationRuleRuns))
+			for _, run := range s.AutomationRuleRuns {
+				if run.RuleID != id {
+					runs = append(runs, run)
+				}
+			}
+			if len(runs) != len(s.AutomationRuleRuns) {
+				s.AutomationRuleRuns = runs
+				return writeJSONFile(s.AutomationRuleRunsFile, s.AutomationRuleRuns)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("regla con ID %s no encontrada", id)
+}
+
+// loadAutomationRuleRuns carga los registros de ejecución de reglas programadas
+func (s *Store) loadAutomationRuleRuns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.AutomationRuleRuns = make([]models.AutomationRuleRun, 0)
+	loadJSONFile(s.AutomationRuleRunsFile, &s.AutomationRuleRuns)
+}
+
+// GetAutomationRuleRuns devuelve los registros de ejecución de reglas programadas
+func (s *Store) GetAutomationRuleRuns() ([]models.AutomationRuleRun, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	runs := make([]models.AutomationRuleRun, len(s.AutomationRuleRuns))
+	copy(runs, s.AutomationRuleRuns)
+
+	return runs, nil
+}
+
+// ClaimAutomationRuleRun registra la ejecución de una regla sobre un ticket. Devuelve
+// false si ya estaba registrada, en cuyo caso la regla no debe aplicarse de nuevo.
+func (s *Store) ClaimAutomationRuleRun(ruleID, ticketID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, run := range s.AutomationRuleRuns {
+		if run.RuleID == ruleID && run.TicketID == ticketID {
+			return false, nil
+		}
+	}
+
+	s.AutomationRuleRuns = append(s.AutomationRuleRuns, models.AutomationRuleRun{
+		RuleID:   ruleID,
+		TicketID: ticketID,
+		FiredAt:  time.Now(),
+	})
+	if err := writeJSONFile(s.AutomationRuleRunsFile, s.AutomationRuleRuns); err != nil {
+		s.AutomationRuleRuns = s.AutomationRuleRuns[:len(s.AutomationRuleRuns)-1]
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DeleteAutomationRuleRuns elimina registros de ejecución para que las reglas puedan
+// volver a aplicarse a esos tickets
+func (s *Store) DeleteAutomationRuleRuns(runs []models.AutomationRuleRun) error {
+	if len(runs) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remove := make(map[string]bool, len(runs))
+	for _, run := range runs {
+		remove[run.RuleID+"|"+run.TicketID] = true
+	}
+
+	remaining := make([]models.AutomationRuleRun, 0, len(s.AutomationRuleRuns))
+	for _, run := range s.AutomationRuleRuns {
+		if !remove[run.RuleID+"|"+run.TicketID] {
+			remaining = append(remaining, run)
+		}
+	}
+	s.AutomationRuleRuns = remaining
+
+	return writeJSONFile(s.AutomationRuleRunsFile, s.AutomationRuleRuns)
+}
+
+// AcquireLease siempre concede el turno: el almacenamiento en archivos solo admite
+// una instancia del servidor
+func (s *Store) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,105 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadBusinessHours carga los horarios de atención desde archivo
+func (s *Store) loadBusinessHours() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.BusinessHours = make([]models.BusinessHours, 0)
+	if loadJSONFile(s.BusinessHoursFile, &s.BusinessHours) {
+		fmt.Printf("Cargados %d horarios de atención desde archivo\n", len(s.BusinessHours))
+	}
+}
+
+// GetBusinessHours devuelve todos los horarios de atención
+func (s *Store) GetBusinessHours() ([]models.BusinessHours, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Crear una copia para evitar problemas de concurrencia
+	hours := make([]models.BusinessHours, len(s.BusinessHours))
+	copy(hours, s.BusinessHours)
+
+	return hours, nil
+}
+
+// GetBusinessHoursByID obtiene un horario de atención por ID
+func (s *Store) GetBusinessHoursByID(id string) (*models.BusinessHours, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, hours := range s.BusinessHours {
+		if hours.ID == id {
+			hoursCopy := hours
+			return &hoursCopy, nil
+		}
+	}
+
+	return nil, fmt.Errorf("horario de atención con ID %s no encontrado", id)
+}
+
+// CreateBusinessHours crea un nuevo horario de atención
+func (s *Store) CreateBusinessHours(hours models.BusinessHours) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if hours.ID == "" {
+		hours.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	if hours.CreatedAt.IsZero() {
+		hours.CreatedAt = now
+	}
+	if hours.UpdatedAt.IsZero() {
+		hours.UpdatedAt = now
+	}
+
+	s.BusinessHours = append(s.BusinessHours, hours)
+	return writeJSONFile(s.BusinessHoursFile, s.BusinessHours)
+}
+
+// UpdateBusinessHours actualiza un horario de atención existente
+func (s *Store) UpdateBusinessHours(hours models.BusinessHours) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.BusinessHours {
+		if existing.ID == hours.ID {
+			hours.CreatedAt = existing.CreatedAt
+			hours.UpdatedAt = time.Now()
+			s.BusinessHours[i] = hours
+			return writeJSONFile(s.BusinessHoursFile, s.BusinessHours)
+		}
+	}
+
+	return fmt.Errorf("horario de atención con ID %s no encontrado", hours.ID)
+}
+
+// DeleteBusinessHours elimina un horario de atención por ID
+func (s *Store) DeleteBusinessHours(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, hours := range s.BusinessHours {
+		if hours.ID == id {
+			s.BusinessHours = append(s.BusinessHours[:i], s.BusinessHours[i+1:]...)
+			if err := writeJSONFile(s.BusinessHoursFile, s.BusinessHours); err != nil {
+				return err
+			}
+			// Los equipos que usaban el horario quedan sin horario propio
+			return s.clearTeamBusinessHoursLocked(id)
+		}
+	}
+
+	return fmt.Errorf("horario de atención con ID %s no encontrado", id)
+}
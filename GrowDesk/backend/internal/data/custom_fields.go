@@ -0,0 +1,107 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadCustomFields carga las definiciones de campos personalizados desde archivo
+func (s *Store) loadCustomFields() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.CustomFields = make([]models.CustomFieldDefinition, 0)
+	if loadJSONFile(s.CustomFieldsFile, &s.CustomFields) {
+		fmt.Printf("Cargados %d campos personalizados desde archivo\n", len(s.CustomFields))
+	}
+}
+
+// GetCustomFieldDefinitions devuelve todas las definiciones de campos personalizados
+func (s *Store) GetCustomFieldDefinitions() ([]models.CustomFieldDefinition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	defs := make([]models.CustomFieldDefinition, len(s.CustomFields))
+	copy(defs, s.CustomFields)
+
+	return defs, nil
+}
+
+// GetCustomFieldDefinition obtiene una definición de campo personalizado por ID
+func (s *Store) GetCustomFieldDefinition(id string) (*models.CustomFieldDefinition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, def := range s.CustomFields {
+		if def.ID == id {
+			defCopy := def
+			return &defCopy, nil
+		}
+	}
+
+	return nil, fmt.Errorf("campo personalizado con ID %s no encontrado", id)
+}
+
+// CreateCustomFieldDefinition crea una nueva definición de campo personalizado
+func (s *Store) CreateCustomFieldDefinition(def models.CustomFieldDefinition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.CustomFields {
+		if existing.Key == def.Key {
+			return fmt.Errorf("ya existe un campo personalizado con la clave %s", def.Key)
+		}
+	}
+
+	if def.ID == "" {
+		def.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	if def.CreatedAt.IsZero() {
+		def.CreatedAt = now
+	}
+	if def.UpdatedAt.IsZero() {
+		def.UpdatedAt = now
+	}
+
+	s.CustomFields = append(s.CustomFields, def)
+	return writeJSONFile(s.CustomFieldsFile, s.CustomFields)
+}
+
+// UpdateCustomFieldDefinition actualiza una definición de campo personalizado existente
+func (s *Store) UpdateCustomFieldDefinition(def models.CustomFieldDefinition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.CustomFields {
+		if existing.ID == def.ID {
+			def.CreatedAt = existing.CreatedAt
+			def.UpdatedAt = time.Now()
+			s.CustomFields[i] = def
+			return writeJSONFile(s.CustomFieldsFile, s.CustomFields)
+		}
+	}
+
+	return fmt.Errorf("campo personalizado con ID %s no encontrado", def.ID)
+}
+
+// DeleteCustomFieldDefinition elimina una definición de campo personalizado por ID.
+// Los valores ya guardados en los tickets se conservan.
+func (s *Store) DeleteCustomFieldDefinition(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, def := range s.CustomFields {
+		if def.ID == id {
+			s.CustomFields = append(s.CustomFields[:i], s.CustomFields[i+1:]...)
+			return writeJSONFile(s.CustomFieldsFile, s.CustomFields)
+		}
+	}
+
+	return fmt.Errorf("campo personalizado con ID %s no encontrado", id)
+}
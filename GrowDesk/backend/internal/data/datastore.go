@@ -1,6 +1,8 @@
 package data
 
 import (
+	"time"
+
 	"github.com/gorilla/websocket"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
 )
@@ -17,11 +19,16 @@ type DataStore interface {
 
 	// Métodos para tickets
 	GetTickets() ([]models.Ticket, error)
+	QueryTickets(filter models.TicketFilter) ([]models.Ticket, error)
 	GetTicket(id string) (*models.Ticket, error)
 	CreateTicket(ticket models.Ticket) error
 	UpdateTicket(ticket models.Ticket) error
 	DeleteTicket(id string) error
 	AddTicketMessage(ticketID string, message models.Message) error
+	GetTicketMessages(ticketID, audience string) ([]models.Message, error)
+	MergeTickets(targetID string, sourceIDs []string, activity models.Activity) (*models.Ticket, error)
+	SplitTicket(sourceID string, messageIDs []string, ticket models.Ticket, activity models.Activity) (*models.Ticket, error)
+	BulkUpdateTickets(ticketIDs []string, changes models.BulkTicketChanges, activity models.Activity, transactional bool) ([]models.BulkTicketResult, error)
 
 	// Métodos para categorías
 	GetCategories() ([]models.Category, error)
@@ -29,15 +36,149 @@ type DataStore interface {
 	CreateCategory(category models.Category) error
 	UpdateCategory(category models.Category) error
 	DeleteCategory(id string) error
+	CountCategoryTickets(id string) (int, error)
+	DeleteCategoryReassigning(id string, target models.Category) (int, error)
+
+	// Métodos para equipos de agentes (el ID del equipo es el departamento de usuarios y tickets)
+	GetTeams() ([]models.Team, error)
+	GetTeam(id string) (*models.Team, error)
+	CreateTeam(team models.Team) error
+	UpdateTeam(team models.Team) error
+	DeleteTeam(id string) error
+	CountTeamReferences(id string) (int, int, error) // Tickets y usuarios del equipo
+	DeleteTeamReassigning(id string, target models.Team) (int, error)
+	AddTeamMember(teamID string, member models.TeamMember) error
+	RemoveTeamMember(teamID, userID string) error
+
+	// Métodos para perfiles de agentes (estado, capacidad y habilidades)
+	GetAgentProfiles() ([]models.AgentProfile, error)
+	GetAgentProfile(userID string) (*models.AgentProfile, error)
+	SaveAgentProfile(profile models.AgentProfile) error
+
+	// Métodos para invitaciones de usuarios
+	GetInvitations() ([]models.Invitation, error)
+	GetInvitation(id string) (*models.Invitation, error)
+	GetInvitationByTokenHash(hash string) (*models.Invitation, error)
+	CreateInvitation(invitation models.Invitation) error
+	UpdateInvitation(invitation models.Invitation) error
+	AcceptInvitation(id string, user models.User) error // Crea el usuario y consume la invitación
+
+	// Métodos para contraseñas y sesiones de usuarios
+	GetUserPasswordHash(id string) (string, error)
+	UpdateUserPassword(id, passwordHash string) error // Cambia la contraseña y cierra las sesiones abiertas
+	GetUserSessionVersion(id string) (int, error)
+	CreatePasswordReset(reset models.PasswordReset) error // Anula los restablecimientos pendientes del usuario
+	GetPasswordResetByTokenHash(hash string) (*models.PasswordReset, error)
+	ResetPassword(resetID, passwordHash string) error // Consume el token, cambia la contraseña y cierra las sesiones
+
+	// Métodos para autenticación en dos pasos
+	GetTwoFactor(userID string) (*models.TwoFactor, error) // Sin configuración guardada devuelve una desactivada
+	SaveTwoFactor(twoFactor models.TwoFactor) error
+	DeleteTwoFactor(userID string) error
+	ConsumeTwoFactorStep(userID string, step int64) error // Falla si el intervalo TOTP ya se usó
+	ConsumeRecoveryCode(userID, codeHash string) error    // Falla si el código no existe o ya se usó
+
+	// Métodos para la base de conocimiento (las FAQs son una vista de los artículos)
+	GetArticles(filter models.ArticleFilter) ([]models.Article, error)
+	GetPublishedArticles() ([]models.Article, error)
+	GetArticle(id int) (*models.Article, error)
+	GetArticleBySlug(slug string) (*models.Article, error)
+	CreateArticle(article models.Article, revision models.ArticleRevision) (*models.Article, error)
+	UpdateArticle(article models.Article, revision *models.ArticleRevision) error
+	DeleteArticle(id int) error
+	GetArticleRevisions(articleID int) ([]models.ArticleRevision, error)
+	GetArticleRevision(articleID, revision int) (*models.ArticleRevision, error)
+	GetArticleTranslations(articleID int) ([]models.ArticleTranslation, error)
+	GetTranslationsByLocale(locale string) ([]models.ArticleTranslation, error)
+	GetArticleTranslation(articleID int, locale string) (*models.ArticleTranslation, error)
+	SaveArticleTranslation(translation models.ArticleTranslation) error
+	DeleteArticleTranslation(articleID int, locale string) error
+
+	// Métodos para las FAQs sugeridas en el widget antes de crear un ticket
+	RecordFAQSuggestions(deflections []models.FAQDeflection) error
+	MarkFAQSolved(sessionID string, articleID int, solvedAt time.Time) (*models.FAQDeflection, error)
+	GetFAQDeflections(filter models.DeflectionFilter) ([]models.FAQDeflection, error)
+
+	// Métodos para las visitas y los votos de utilidad de las FAQs en el widget
+	RecordFAQView(view models.FAQView) (*models.FAQView, error)
+	LinkFAQViewsToTicket(sessionID, ticketID string) error
+	GetFAQViews(filter models.FAQActivityFilter) ([]models.FAQView, error)
+	SaveFAQVote(vote models.FAQVote) (*models.FAQVote, error)
+	GetFAQVotes(filter models.FAQActivityFilter) ([]models.FAQVote, error)
+
+	// Métodos para horarios de atención
+	GetBusinessHours() ([]models.BusinessHours, error)
+	GetBusinessHoursByID(id string) (*models.BusinessHours, error)
+	CreateBusinessHours(hours models.BusinessHours) error
+	UpdateBusinessHours(hours models.BusinessHours) error
+	DeleteBusinessHours(id string) error
+
+	// Métodos para formularios previos al chat
+	GetPreChatForms() ([]models.PreChatForm, error)
+	GetPreChatForm(id string) (*models.PreChatForm, error)
+	GetPreChatFormByWidget(widgetID string) (*models.PreChatForm, error)
+	CreatePreChatForm(form models.PreChatForm) error
+	UpdatePreChatForm(form models.PreChatForm) error
+	DeletePreChatForm(id string) error
+
+	// Métodos para campos personalizados de tickets
+	GetCustomFieldDefinitions() ([]models.CustomFieldDefinition, error)
+	GetCustomFieldDefinition(id string) (*models.CustomFieldDefinition, error)
+	CreateCustomFieldDefinition(def models.CustomFieldDefinition) error
+	UpdateCustomFieldDefinition(def models.CustomFieldDefinition) error
+	DeleteCustomFieldDefinition(id string) error
+
+	// Métodos para etiquetas de tickets
+	GetTags(prefix string, limit int) ([]models.Tag, error)
+	AddTicketTags(ticketID string, names []string) ([]string, error)
+	RemoveTicketTags(ticketID string, names []string) ([]string, error)
+	GetTagReport() ([]models.TagReport, error)
+
+	// Métodos para relaciones entre tickets
+	GetTicketLinks(ticketID string) ([]models.TicketLink, error)
+	CreateTicketLink(link models.TicketLink) error
+	DeleteTicketLink(id string) error
+
+	// Métodos para seguidores de tickets
+	GetTicketWatchers(ticketID string) ([]models.TicketWatcher, error)
+	AddTicketWatchers(ticketID string, userIDs []string, addedBy string) ([]string, error)
+	RemoveTicketWatcher(ticketID, userID string) error
+
+	// Métodos para macros de agentes
+	GetMacros() ([]models.Macro, error)
+	GetMacro(id string) (*models.Macro, error)
+	CreateMacro(macro models.Macro) error
+	UpdateMacro(macro models.Macro) error
+	DeleteMacro(id string) error
+
+	// Métodos para reglas de automatización
+	GetAutomationRules() ([]models.AutomationRule, error)
+	GetAutomationRule(id string) (*models.AutomationRule, error)
+	CreateAutomationRule(rule models.AutomationRule) error
+	UpdateAutomationRule(rule models.AutomationRule) error
+	DeleteAutomationRule(id string) error
+	GetAutomationRuleRuns() ([]models.AutomationRuleRun, error)
+	ClaimAutomationRuleRun(ruleID, ticketID string) (bool, error)
+	DeleteAutomationRuleRuns(runs []models.AutomationRuleRun) error
+
+	// Métodos para coordinar tareas periódicas entre réplicas del servidor
+	AcquireLease(name, holder string, ttl time.Duration) (bool, error)
+
+	// Métodos para el registro de actividad
+	GetActivities(targetID string) ([]models.Activity, error)
+	AddActivity(targetID string, activity models.Activity) error
+
+	// Métodos para notificaciones de usuarios
+	GetNotifications(userID string, unreadOnly bool) ([]models.Notification, error)
+	CreateNotification(notification models.Notification) error
+	MarkNotificationsRead(userID string, ids []string) error
 
-	// Métodos para FAQs
-	GetFAQs() ([]models.FAQ, error)
-	GetFAQsByStatus(published bool) ([]models.FAQ, error)
-	GetFAQ(id int) (*models.FAQ, error)
-	CreateFAQ(faq models.FAQ) error
-	UpdateFAQ(faq models.FAQ) error
-	DeleteFAQ(id int) error
-	ToggleFAQPublish(id int) error
+	// Métodos para encuestas de satisfacción
+	GetSurveys(filter models.SurveyFilter) ([]models.SatisfactionSurvey, error)
+	GetSurveyByTokenHash(tokenHash string) (*models.SatisfactionSurvey, error)
+	CreateSurvey(survey models.SatisfactionSurvey) error
+	UpdateSurveyTokenHash(id, tokenHash string) error
+	AnswerSurvey(tokenHash string, rating int, comment string) (*models.SatisfactionSurvey, error)
 
 	// Métodos para WebSocket
 	AddWSConnection(ticketID string, conn *websocket.Conn) string
@@ -0,0 +1,102 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadDeflections carga el registro de FAQs sugeridas desde archivo
+func (s *Store) loadDeflections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Deflections = make([]models.FAQDeflection, 0)
+	if loadJSONFile(s.DeflectionsFile, &s.Deflections) {
+		fmt.Printf("Cargadas %d sugerencias de FAQs desde archivo\n", len(s.Deflections))
+	}
+}
+
+// RecordFAQSuggestions registra las FAQs sugeridas en una sesión del widget. Cada FAQ
+// se cuenta una sola vez por sesión aunque se vuelva a sugerir.
+func (s *Store) RecordFAQSuggestions(deflections []models.FAQDeflection) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	added := 0
+	for _, deflection := range deflections {
+		if s.findDeflectionLocked(deflection.SessionID, deflection.ArticleID) >= 0 {
+			continue
+		}
+		s.Deflections = append(s.Deflections, deflection)
+		added++
+	}
+	if added == 0 {
+		return nil
+	}
+
+	if err := writeJSONFile(s.DeflectionsFile, s.Deflections); err != nil {
+		s.Deflections = s.Deflections[:len(s.Deflections)-added]
+		return err
+	}
+	return nil
+}
+
+// MarkFAQSolved indica que una FAQ sugerida en la sesión resolvió el problema del
+// visitante. Marcarla de nuevo no cambia la fecha original.
+func (s *Store) MarkFAQSolved(sessionID string, articleID int, solvedAt time.Time) (*models.FAQDeflection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findDeflectionLocked(sessionID, articleID)
+	if i < 0 {
+		return nil, fmt.Errorf("la FAQ %d no se sugirió en la sesión %s", articleID, sessionID)
+	}
+
+	if s.Deflections[i].SolvedAt == nil {
+		s.Deflections[i].SolvedAt = &solvedAt
+		if err := writeJSONFile(s.DeflectionsFile, s.Deflections); err != nil {
+			s.Deflections[i].SolvedAt = nil
+			return nil, err
+		}
+	}
+
+	deflection := s.Deflections[i]
+	return &deflection, nil
+}
+
+// GetFAQDeflections devuelve las sugerencias registradas en el período, las más
+// recientes primero
+func (s *Store) GetFAQDeflections(filter models.DeflectionFilter) ([]models.FAQDeflection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deflections := make([]models.FAQDeflection, 0)
+	for _, deflection := range s.Deflections {
+		if !filter.From.IsZero() && deflection.ShownAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !deflection.ShownAt.Before(filter.To) {
+			continue
+		}
+		deflections = append(deflections, deflection)
+	}
+
+	sort.SliceStable(deflections, func(i, j int) bool {
+		return deflections[i].ShownAt.After(deflections[j].ShownAt)
+	})
+
+	return deflections, nil
+}
+
+// findDeflectionLocked devuelve la posición del registro de la sesión y la FAQ o -1
+func (s *Store) findDeflectionLocked(sessionID string, articleID int) int {
+	for i, deflection := range s.Deflections {
+		if deflection.SessionID == sessionID && deflection.ArticleID == articleID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,174 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadFAQFeedback carga las visitas y los votos de FAQs desde archivo
+func (s *Store) loadFAQFeedback() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.FAQViews = make([]models.FAQView, 0)
+	if loadJSONFile(s.FAQViewsFile, &s.FAQViews) {
+		fmt.Printf("Cargadas %d visitas de FAQs desde archivo\n", len(s.FAQViews))
+	}
+
+	s.FAQVotes = make([]models.FAQVote, 0)
+	if loadJSONFile(s.FAQVotesFile, &s.FAQVotes) {
+		fmt.Printf("Cargados %d votos de FAQs desde archivo\n", len(s.FAQVotes))
+	}
+}
+
+// RecordFAQView suma una visita de la sesión a la FAQ. Las visitas de una misma sesión
+// se acumulan en un solo registro.
+func (s *Store) RecordFAQView(view models.FAQView) (*models.FAQView, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findFAQViewLocked(view.SessionID, view.ArticleID)
+	if i < 0 {
+		view.Count = 1
+		view.LastViewedAt = view.FirstViewedAt
+		s.FAQViews = append(s.FAQViews, view)
+		if err := writeJSONFile(s.FAQViewsFile, s.FAQViews); err != nil {
+			s.FAQViews = s.FAQViews[:len(s.FAQViews)-1]
+			return nil, err
+		}
+		return &view, nil
+	}
+
+	previous := s.FAQViews[i]
+	s.FAQViews[i].Count++
+	s.FAQViews[i].LastViewedAt = view.FirstViewedAt
+	if err := writeJSONFile(s.FAQViewsFile, s.FAQViews); err != nil {
+		s.FAQViews[i] = previous
+		return nil, err
+	}
+
+	updated := s.FAQViews[i]
+	return &updated, nil
+}
+
+// LinkFAQViewsToTicket asocia a un ticket las FAQs vistas en la sesión del widget que
+// aún no tenían ticket
+func (s *Store) LinkFAQViewsToTicket(sessionID, ticketID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	linked := make([]int, 0)
+	for i := range s.FAQViews {
+		if s.FAQViews[i].SessionID == sessionID && s.FAQViews[i].TicketID == "" {
+			s.FAQViews[i].TicketID = ticketID
+			linked = append(linked, i)
+		}
+	}
+	if len(linked) == 0 {
+		return nil
+	}
+
+	if err := writeJSONFile(s.FAQViewsFile, s.FAQViews); err != nil {
+		for _, i := range linked {
+			s.FAQViews[i].TicketID = ""
+		}
+		return err
+	}
+	return nil
+}
+
+// GetFAQViews devuelve las visitas de las sesiones que empezaron en el período, las
+// más recientes primero
+func (s *Store) GetFAQViews(filter models.FAQActivityFilter) ([]models.FAQView, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	views := make([]models.FAQView, 0)
+	for _, view := range s.FAQViews {
+		if !filter.From.IsZero() && view.FirstViewedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !view.FirstViewedAt.Before(filter.To) {
+			continue
+		}
+		views = append(views, view)
+	}
+
+	sort.SliceStable(views, func(i, j int) bool {
+		return views[i].FirstViewedAt.After(views[j].FirstViewedAt)
+	})
+
+	return views, nil
+}
+
+// SaveFAQVote guarda el voto de la sesión para la FAQ; si ya había votado, lo
+// reemplaza y conserva la fecha del primer voto
+func (s *Store) SaveFAQVote(vote models.FAQVote) (*models.FAQVote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findFAQVoteLocked(vote.SessionID, vote.ArticleID)
+	if i < 0 {
+		s.FAQVotes = append(s.FAQVotes, vote)
+		if err := writeJSONFile(s.FAQVotesFile, s.FAQVotes); err != nil {
+			s.FAQVotes = s.FAQVotes[:len(s.FAQVotes)-1]
+			return nil, err
+		}
+		return &vote, nil
+	}
+
+	previous := s.FAQVotes[i]
+	vote.CreatedAt = previous.CreatedAt
+	s.FAQVotes[i] = vote
+	if err := writeJSONFile(s.FAQVotesFile, s.FAQVotes); err != nil {
+		s.FAQVotes[i] = previous
+		return nil, err
+	}
+	return &vote, nil
+}
+
+// GetFAQVotes devuelve los votos emitidos o cambiados en el período, los más
+// recientes primero
+func (s *Store) GetFAQVotes(filter models.FAQActivityFilter) ([]models.FAQVote, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	votes := make([]models.FAQVote, 0)
+	for _, vote := range s.FAQVotes {
+		if !filter.From.IsZero() && vote.UpdatedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !vote.UpdatedAt.Before(filter.To) {
+			continue
+		}
+		votes = append(votes, vote)
+	}
+
+	sort.SliceStable(votes, func(i, j int) bool {
+		return votes[i].UpdatedAt.After(votes[j].UpdatedAt)
+	})
+
+	return votes, nil
+}
+
+// findFAQViewLocked devuelve la posición de las visitas de la sesión a la FAQ o -1
+func (s *Store) findFAQViewLocked(sessionID string, articleID int) int {
+	for i, view := range s.FAQViews {
+		if view.SessionID == sessionID && view.ArticleID == articleID {
+			return i
+		}
+	}
+	return -1
+}
+
+// findFAQVoteLocked devuelve la posición del voto de la sesión a la FAQ o -1
+func (s *Store) findFAQVoteLocked(sessionID string, articleID int) int {
+	for i, vote := range s.FAQVotes {
+		if vote.SessionID == sessionID && vote.ArticleID == articleID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,49 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// loadJSONFile lee un archivo JSON en v. Devuelve false si el archivo no existe
+// o no se pudo analizar, dejando v sin cambios.
+func loadJSONFile(path string, v interface{}) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		fmt.Printf("Error al analizar archivo %s: %v\n", filepath.Base(path), err)
+		return false
+	}
+
+	return true
+}
+
+// writeJSONFile escribe v como JSON de forma atómica (archivo temporal + rename).
+// No toma el mutex del almacén: el llamador debe tenerlo si v es estado compartido.
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error al serializar %s: %w", filepath.Base(path), err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error al crear directorio: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("error al escribir %s: %w", filepath.Base(path), err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error al reemplazar %s: %w", filepath.Base(path), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadInvitations carga las invitaciones desde archivo
+func (s *Store) loadInvitations() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Invitations = make([]models.Invitation, 0)
+	if loadJSONFile(s.InvitationsFile, &s.Invitations) {
+		fmt.Printf("Cargadas %d invitaciones desde archivo\n", len(s.Invitations))
+	}
+}
+
+// GetInvitations devuelve todas las invitaciones, de la más reciente a la más antigua
+func (s *Store) GetInvitations() ([]models.Invitation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]models.Invitation, len(s.Invitations))
+	copy(list, s.Invitations)
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].CreatedAt.After(list[j].CreatedAt)
+	})
+	return list, nil
+}
+
+// GetInvitation obtiene una invitación por ID
+func (s *Store) GetInvitation(id string) (*models.Invitation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, invitation := range s.Invitations {
+		if invitation.ID == id {
+			return &invitation, nil
+		}
+	}
+	return nil, fmt.Errorf("invitación con ID %s no encontrada", id)
+}
+
+// GetInvitationByTokenHash obtiene la invitación cuyo token tiene el hash indicado
+func (s *Store) GetInvitationByTokenHash(hash string) (*models.Invitation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, invitation := range s.Invitations {
+		if hash != "" && invitation.TokenHash == hash {
+			return &invitation, nil
+		}
+	}
+	return nil, fmt.Errorf("invitación no encontrada")
+}
+
+// CreateInvitation guarda una invitación nueva
+func (s *Store) CreateInvitation(invitation models.Invitation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Invitations = append(s.Invitations, invitation)
+	if err := s.saveInvitationsLocked(); err != nil {
+		s.Invitations = s.Invitations[:len(s.Invitations)-1]
+		return err
+	}
+	return nil
+}
+
+// UpdateInvitation reemplaza una invitación existente
+func (s *Store) UpdateInvitation(invitation models.Invitation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.Invitations {
+		if s.Invitations[i].ID == invitation.ID {
+			previous := s.Invitations[i]
+			s.Invitations[i] = invitation
+			if err := s.saveInvitationsLocked(); err != nil {
+				s.Invitations[i] = previous
+				return err
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("invitación con ID %s no encontrada", invitation.ID)
+}
+
+// AcceptInvitation crea el usuario del invitado y marca la invitación como aceptada en
+// una sola operación. Falla si la invitación ya no está pendiente o si el email ya
+// pertenece a otro usuario.
+func (s *Store) AcceptInvitation(id string, user models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := -1
+	for j := range s.Invitations {
+		if s.Invitations[j].ID == id {
+			i = j
+		}
+	}
+	if i < 0 {
+		return fmt.Errorf("invitación con ID %s no encontrada", id)
+	}
+
+	now := time.Now()
+	invitation := s.Invitations[i]
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil || now.After(invitation.ExpiresAt) {
+		return fmt.Errorf("la invitación ya no está pendiente")
+	}
+	for _, existing := range s.Users {
+		if strings.EqualFold(existing.Email, user.Email) {
+			return fmt.Errorf("ya existe un usuario con el email %s", user.Email)
+		}
+	}
+
+	s.Users = append(s.Users, user)
+	if err := s.saveUsersLocked(); err != nil {
+		s.Users = s.Users[:len(s.Users)-1]
+		return err
+	}
+
+	invitation.AcceptedAt = &now
+	invitation.UserID = user.ID
+	invitation.UpdatedAt = now
+	s.Invitations[i] = invitation
+	return s.saveInvitationsLocked()
+}
+
+// saveInvitationsLocked guarda las invitaciones; el llamador debe tener s.mu
+func (s *Store) saveInvitationsLocked() error {
+	return writeJSONFile(s.InvitationsFile, s.Invitations)
+}
@@ -0,0 +1,100 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadMacros carga las macros de agentes desde archivo
+func (s *Store) loadMacros() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Macros = make([]models.Macro, 0)
+	if loadJSONFile(s.MacrosFile, &s.Macros) {
+		fmt.Printf("Cargadas %d macros desde archivo\n", len(s.Macros))
+	}
+}
+
+// GetMacros devuelve todas las macros
+func (s *Store) GetMacros() ([]models.Macro, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	macros := make([]models.Macro, len(s.Macros))
+	copy(macros, s.Macros)
+
+	return macros, nil
+}
+
+// GetMacro obtiene una macro por ID
+func (s *Store) GetMacro(id string) (*models.Macro, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, macro := range s.Macros {
+		if macro.ID == id {
+			result := macro
+			return &result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("macro con ID %s no encontrada", id)
+}
+
+// CreateMacro crea una nueva macro
+func (s *Store) CreateMacro(macro models.Macro) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if macro.ID == "" {
+		macro.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	if macro.CreatedAt.IsZero() {
+		macro.CreatedAt = now
+	}
+	if macro.UpdatedAt.IsZero() {
+		macro.UpdatedAt = now
+	}
+
+	s.Macros = append(s.Macros, macro)
+	return writeJSONFile(s.MacrosFile, s.Macros)
+}
+
+// UpdateMacro actualiza una macro existente
+func (s *Store) UpdateMacro(macro models.Macro) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.Macros {
+		if existing.ID == macro.ID {
+			macro.CreatedAt = existing.CreatedAt
+			macro.UpdatedAt = time.Now()
+			s.Macros[i] = macro
+			return writeJSONFile(s.MacrosFile, s.Macros)
+		}
+	}
+
+	return fmt.Errorf("macro con ID %s no encontrada", macro.ID)
+}
+
+// DeleteMacro elimina una macro por ID
+func (s *Store) DeleteMacro(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, macro := range s.Macros {
+		if macro.ID == id {
+			s.Macros = append(s.Macros[:i], s.Macros[i+1:]...)
+			return writeJSONFile(s.MacrosFile, s.Macros)
+		}
+	}
+
+	return fmt.Errorf("macro con ID %s no encontrada", id)
+}
@@ -0,0 +1,86 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadNotifications carga las notificaciones de usuarios desde archivo
+func (s *Store) loadNotifications() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Notifications = make([]models.Notification, 0)
+	if loadJSONFile(s.NotificationsFile, &s.Notifications) {
+		fmt.Printf("Cargadas %d notificaciones desde archivo\n", len(s.Notifications))
+	}
+}
+
+// GetNotifications devuelve las notificaciones de un usuario, las más recientes primero
+func (s *Store) GetNotifications(userID string, unreadOnly bool) ([]models.Notification, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.Notification, 0)
+	for _, notification := range s.Notifications {
+		if notification.UserID == userID && (!unreadOnly || !notification.Read) {
+			result = append(result, notification)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+// CreateNotification guarda una notificación para un usuario
+func (s *Store) CreateNotification(notification models.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	s.Notifications = append(s.Notifications, notification)
+	return writeJSONFile(s.NotificationsFile, s.Notifications)
+}
+
+// MarkNotificationsRead marca como leídas las notificaciones indicadas de un usuario,
+// o todas si ids está vacío
+func (s *Store) MarkNotificationsRead(userID string, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	selected := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	changed := false
+	for i := range s.Notifications {
+		notification := &s.Notifications[i]
+		if notification.UserID != userID || notification.Read {
+			continue
+		}
+		if len(ids) == 0 || selected[notification.ID] {
+			notification.Read = true
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return writeJSONFile(s.NotificationsFile, s.Notifications)
+}
@@ -0,0 +1,165 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadPasswordResets carga los restablecimientos de contraseña desde archivo
+func (s *Store) loadPasswordResets() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.PasswordResets = make([]models.PasswordReset, 0)
+	if loadJSONFile(s.ResetsFile, &s.PasswordResets) {
+		fmt.Printf("Cargados %d restablecimientos de contraseña desde archivo\n", len(s.PasswordResets))
+	}
+}
+
+// GetUserPasswordHash devuelve el hash de la contraseña del usuario
+func (s *Store) GetUserPasswordHash(id string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.Users {
+		if user.ID == id {
+			return user.Password, nil
+		}
+	}
+	return "", fmt.Errorf("usuario con ID %s no encontrado", id)
+}
+
+// UpdateUserPassword cambia la contraseña del usuario y cierra sus sesiones abiertas
+func (s *Store) UpdateUserPassword(id, passwordHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.setPasswordLocked(id, passwordHash, time.Now())
+}
+
+// GetUserSessionVersion devuelve la versión de sesión vigente del usuario
+func (s *Store) GetUserSessionVersion(id string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.Users {
+		if user.ID == id {
+			return user.SessionVersion, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", models.ErrUserNotFound, id)
+}
+
+// CreatePasswordReset guarda un restablecimiento nuevo y anula los pendientes del
+// mismo usuario, de modo que solo funcione el último enlace enviado
+func (s *Store) CreatePasswordReset(reset models.PasswordReset) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := make([]models.PasswordReset, len(s.PasswordResets))
+	copy(previous, s.PasswordResets)
+
+	now := time.Now()
+	for i := range s.PasswordResets {
+		if s.PasswordResets[i].UserID == reset.UserID && s.PasswordResets[i].UsedAt == nil {
+			s.PasswordResets[i].UsedAt = &now
+		}
+	}
+	s.PasswordResets = append(s.PasswordResets, reset)
+
+	if err := s.savePasswordResetsLocked(); err != nil {
+		s.PasswordResets = previous
+		return err
+	}
+	return nil
+}
+
+// GetPasswordResetByTokenHash obtiene el restablecimiento cuyo token tiene el hash indicado
+func (s *Store) GetPasswordResetByTokenHash(hash string) (*models.PasswordReset, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, reset := range s.PasswordResets {
+		if hash != "" && reset.TokenHash == hash {
+			return &reset, nil
+		}
+	}
+	return nil, fmt.Errorf("restablecimiento de contraseña no encontrado")
+}
+
+// ResetPassword consume el token del restablecimiento, cambia la contraseña del usuario
+// y cierra sus sesiones en una sola operación. Falla si el token ya se usó o caducó.
+func (s *Store) ResetPassword(resetID, passwordHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := -1
+	for j := range s.PasswordResets {
+		if s.PasswordResets[j].ID == resetID {
+			i = j
+		}
+	}
+	if i < 0 {
+		return fmt.Errorf("restablecimiento de contraseña con ID %s no encontrado", resetID)
+	}
+
+	now := time.Now()
+	reset := s.PasswordResets[i]
+	if reset.UsedAt != nil || now.After(reset.ExpiresAt) {
+		return fmt.Errorf("el restablecimiento de contraseña ya no es válido")
+	}
+
+	if err := s.setPasswordLocked(reset.UserID, passwordHash, now); err != nil {
+		return err
+	}
+
+	reset.UsedAt = &now
+	s.PasswordResets[i] = reset
+	return s.savePasswordResetsLocked()
+}
+
+// setPasswordLocked cambia la contraseña y aumenta la versión de sesión del usuario;
+// el llamador debe tener s.mu
+func (s *Store) setPasswordLocked(id, passwordHash string, now time.Time) error {
+	for i := range s.Users {
+		if s.Users[i].ID == id {
+			previous := s.Users[i]
+			s.Users[i].Password = passwordHash
+			s.Users[i].SessionVersion++
+			s.Users[i].UpdatedAt = now
+			if err := s.saveUsersLocked(); err != nil {
+				s.Users[i] = previous
+				return err
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("usuario con ID %s no encontrado", id)
+}
+
+// removePasswordResetsLocked elimina los restablecimientos del usuario; el llamador
+// debe tener s.mu
+func (s *Store) removePasswordResetsLocked(userID string) error {
+	kept := s.PasswordResets[:0]
+	removed := false
+	for _, reset := range s.PasswordResets {
+		if reset.UserID == userID {
+			removed = true
+			continue
+		}
+		kept = append(kept, reset)
+	}
+	s.PasswordResets = kept
+	if !removed {
+		return nil
+	}
+	return s.savePasswordResetsLocked()
+}
+
+// savePasswordResetsLocked guarda los restablecimientos de contraseña; el llamador debe
+// tener s.mu
+func (s *Store) savePasswordResetsLocked() error {
+	return writeJSONFile(s.ResetsFile, s.PasswordResets)
+}
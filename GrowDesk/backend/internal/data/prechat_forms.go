@@ -0,0 +1,127 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadPreChatForms carga los formularios previos al chat desde archivo
+func (s *Store) loadPreChatForms() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.PreChatForms = make([]models.PreChatForm, 0)
+	if loadJSONFile(s.PreChatFormsFile, &s.PreChatForms) {
+		fmt.Printf("Cargados %d formularios previos al chat desde archivo\n", len(s.PreChatForms))
+	}
+}
+
+// GetPreChatForms devuelve todos los formularios previos al chat
+func (s *Store) GetPreChatForms() ([]models.PreChatForm, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	forms := make([]models.PreChatForm, len(s.PreChatForms))
+	copy(forms, s.PreChatForms)
+
+	return forms, nil
+}
+
+// GetPreChatForm obtiene un formulario previo al chat por ID
+func (s *Store) GetPreChatForm(id string) (*models.PreChatForm, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, form := range s.PreChatForms {
+		if form.ID == id {
+			formCopy := form
+			return &formCopy, nil
+		}
+	}
+
+	return nil, fmt.Errorf("formulario con ID %s no encontrado", id)
+}
+
+// GetPreChatFormByWidget obtiene el formulario asignado a un widget
+func (s *Store) GetPreChatFormByWidget(widgetID string) (*models.PreChatForm, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, form := range s.PreChatForms {
+		if form.WidgetID == widgetID {
+			formCopy := form
+			return &formCopy, nil
+		}
+	}
+
+	return nil, fmt.Errorf("formulario para el widget %s no encontrado", widgetID)
+}
+
+// CreatePreChatForm crea un nuevo formulario previo al chat
+func (s *Store) CreatePreChatForm(form models.PreChatForm) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.PreChatForms {
+		if existing.WidgetID == form.WidgetID {
+			return fmt.Errorf("ya existe un formulario para el widget %s", form.WidgetID)
+		}
+	}
+
+	if form.ID == "" {
+		form.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	if form.CreatedAt.IsZero() {
+		form.CreatedAt = now
+	}
+	if form.UpdatedAt.IsZero() {
+		form.UpdatedAt = now
+	}
+
+	s.PreChatForms = append(s.PreChatForms, form)
+	return writeJSONFile(s.PreChatFormsFile, s.PreChatForms)
+}
+
+// UpdatePreChatForm actualiza un formulario previo al chat existente
+func (s *Store) UpdatePreChatForm(form models.PreChatForm) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index := -1
+	for i, existing := range s.PreChatForms {
+		if existing.ID == form.ID {
+			index = i
+		} else if existing.WidgetID == form.WidgetID {
+			return fmt.Errorf("ya existe un formulario para el widget %s", form.WidgetID)
+		}
+	}
+	if index == -1 {
+		return fmt.Errorf("formulario con ID %s no encontrado", form.ID)
+	}
+
+	form.CreatedAt = s.PreChatForms[index].CreatedAt
+	form.UpdatedAt = time.Now()
+	s.PreChatForms[index] = form
+	return writeJSONFile(s.PreChatFormsFile, s.PreChatForms)
+}
+
+// DeletePreChatForm elimina un formulario previo al chat por ID
+func (s *Store) DeletePreChatForm(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, form := range s.PreChatForms {
+		if form.ID == id {
+			s.PreChatForms = append(s.PreChatForms[:i], s.PreChatForms[i+1:]...)
+			return writeJSONFile(s.PreChatFormsFile, s.PreChatForms)
+		}
+	}
+
+	return fmt.Errorf("formulario con ID %s no encontrado", id)
+}
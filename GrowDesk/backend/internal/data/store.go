@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,7 +20,33 @@ type Store struct {
 	Tickets    []models.Ticket
 	Users      []models.User
 	Categories []models.Category
-	FAQs       []models.FAQ
+	Teams      []models.Team
+
+	AgentProfiles  []models.AgentProfile
+	Invitations    []models.Invitation
+	PasswordResets []models.PasswordReset
+	TwoFactors     []models.TwoFactor
+
+	BusinessHours  []models.BusinessHours
+	PreChatForms   []models.PreChatForm
+	CustomFields   []models.CustomFieldDefinition
+	Tags           []models.Tag
+	Activities     []models.Activity
+	TicketLinks    []models.TicketLink
+	TicketWatchers []models.TicketWatcher
+	Macros         []models.Macro
+	Notifications  []models.Notification
+
+	AutomationRules    []models.AutomationRule
+	AutomationRuleRuns []models.AutomationRuleRun
+	Surveys            []models.SatisfactionSurvey
+
+	Articles         []models.Article
+	ArticleRevisions []models.ArticleRevision
+	Translations     []models.ArticleTranslation
+	Deflections      []models.FAQDeflection
+	FAQViews         []models.FAQView
+	FAQVotes         []models.FAQVote
 
 	// Conexiones WebSocket por ID de ticket
 	// Map de ID de ticket a lista de conexiones
@@ -29,10 +56,36 @@ type Store struct {
 	mu sync.RWMutex // Para seguridad de hilos
 
 	// Rutas de archivo para persistencia de datos
-	TicketsFile    string
-	UsersFile      string
-	CategoriesFile string
-	FAQsFile       string
+	TicketsFile     string
+	UsersFile       string
+	CategoriesFile  string
+	TeamsFile       string
+	AgentsFile      string
+	InvitationsFile string
+	ResetsFile      string
+	TwoFactorFile   string
+	FAQsFile        string // FAQs anteriores a la base de conocimiento; solo se leen para migrarlas
+
+	BusinessHoursFile  string
+	PreChatFormsFile   string
+	CustomFieldsFile   string
+	TagsFile           string
+	ActivitiesFile     string
+	TicketLinksFile    string
+	TicketWatchersFile string
+	MacrosFile         string
+	NotificationsFile  string
+
+	AutomationRulesFile    string
+	AutomationRuleRunsFile string
+	SurveysFile            string
+
+	ArticlesFile         string
+	ArticleRevisionsFile string
+	TranslationsFile     string
+	DeflectionsFile      string
+	FAQViewsFile         string
+	FAQVotesFile         string
 }
 
 // WebSocketConnection representa una conexión WebSocket
@@ -51,20 +104,61 @@ func NewStore(dataDir string) *Store {
 		Tickets:                make([]models.Ticket, 0),
 		Users:                  make([]models.User, 0),
 		Categories:             make([]models.Category, 0),
-		FAQs:                   make([]models.FAQ, 0),
 		TicketConnections:      make(map[string][]WebSocketConnection),
 		AlternateConnectionMap: make(map[string]string),
 		TicketsFile:            filepath.Join(dataDir, "tickets.json"),
 		UsersFile:              filepath.Join(dataDir, "users.json"),
 		CategoriesFile:         filepath.Join(dataDir, "categories.json"),
+		TeamsFile:              filepath.Join(dataDir, "teams.json"),
+		AgentsFile:             filepath.Join(dataDir, "agent_profiles.json"),
+		InvitationsFile:        filepath.Join(dataDir, "invitations.json"),
+		ResetsFile:             filepath.Join(dataDir, "password_resets.json"),
+		TwoFactorFile:          filepath.Join(dataDir, "two_factor.json"),
 		FAQsFile:               filepath.Join(dataDir, "faqs.json"),
+		BusinessHoursFile:      filepath.Join(dataDir, "business_hours.json"),
+		PreChatFormsFile:       filepath.Join(dataDir, "prechat_forms.json"),
+		CustomFieldsFile:       filepath.Join(dataDir, "custom_fields.json"),
+		TagsFile:               filepath.Join(dataDir, "tags.json"),
+		ActivitiesFile:         filepath.Join(dataDir, "activities.json"),
+		TicketLinksFile:        filepath.Join(dataDir, "ticket_links.json"),
+		TicketWatchersFile:     filepath.Join(dataDir, "ticket_watchers.json"),
+		MacrosFile:             filepath.Join(dataDir, "macros.json"),
+		NotificationsFile:      filepath.Join(dataDir, "notifications.json"),
+		AutomationRulesFile:    filepath.Join(dataDir, "automation_rules.json"),
+		AutomationRuleRunsFile: filepath.Join(dataDir, "automation_rule_runs.json"),
+		SurveysFile:            filepath.Join(dataDir, "surveys.json"),
+		ArticlesFile:           filepath.Join(dataDir, "kb_articles.json"),
+		ArticleRevisionsFile:   filepath.Join(dataDir, "kb_article_revisions.json"),
+		TranslationsFile:       filepath.Join(dataDir, "kb_article_translations.json"),
+		DeflectionsFile:        filepath.Join(dataDir, "faq_deflections.json"),
+		FAQViewsFile:           filepath.Join(dataDir, "faq_views.json"),
+		FAQVotesFile:           filepath.Join(dataDir, "faq_votes.json"),
 	}
 
 	// Cargar datos desde archivos o inicializar con valores por defecto
 	store.loadTickets()
 	store.loadUsers()
 	store.loadCategories()
-	store.loadFAQs()
+	store.loadTeams()
+	store.loadAgentProfiles()
+	store.loadInvitations()
+	store.loadPasswordResets()
+	store.loadTwoFactors()
+	store.loadArticles()
+	store.loadBusinessHours()
+	store.loadPreChatForms()
+	store.loadCustomFields()
+	store.loadTags()
+	store.loadActivities()
+	store.loadTicketLinks()
+	store.loadTicketWatchers()
+	store.loadMacros()
+	store.loadNotifications()
+	store.loadAutomationRules()
+	store.loadAutomationRuleRuns()
+	store.loadSurveys()
+	store.loadDeflections()
+	store.loadFAQFeedback()
 
 	return store
 }
@@ -87,6 +181,11 @@ func (s *Store) loadTickets() {
 		return
 	}
 
+	// Los mensajes guardados antes de existir la visibilidad solo tienen IsInternal
+	for i := range s.Tickets {
+		normalizeMessages(s.Tickets[i].Messages)
+	}
+
 	fmt.Printf("Cargados %d tickets desde archivo\n", len(s.Tickets))
 }
 
@@ -190,56 +289,6 @@ func (s *Store) loadCategories() {
 	fmt.Printf("Cargados %d categorías desde archivo\n", len(categories))
 }
 
-// LoadFAQs carga FAQs desde archivo o inicializa con valores por defecto
-func (s *Store) loadFAQs() error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	data, err := os.ReadFile(s.FAQsFile)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// Si el archivo no existe, inicializar con FAQs por defecto
-			s.FAQs = []models.FAQ{
-				{
-					ID:          1,
-					Question:    "¿Cómo puedo crear un ticket?",
-					Answer:      "Para crear un ticket, haz clic en el botón 'Nuevo Ticket' y completa el formulario.",
-					IsPublished: true,
-					CreatedAt:   time.Now(),
-					UpdatedAt:   time.Now(),
-				},
-				{
-					ID:          2,
-					Question:    "¿Cómo puedo ver mis tickets?",
-					Answer:      "Puedes ver tus tickets en la sección 'Mis Tickets' del panel de control.",
-					IsPublished: true,
-					CreatedAt:   time.Now(),
-					UpdatedAt:   time.Now(),
-				},
-				{
-					ID:          3,
-					Question:    "¿Cómo puedo actualizar mi perfil?",
-					Answer:      "Para actualizar tu perfil, ve a la sección 'Configuración' y haz clic en 'Editar Perfil'.",
-					IsPublished: true,
-					CreatedAt:   time.Now(),
-					UpdatedAt:   time.Now(),
-				},
-			}
-			// Guardar las FAQs por defecto
-			return s.SaveFAQs()
-		}
-		return fmt.Errorf("error al leer archivo de FAQs: %v", err)
-	}
-
-	var faqs []models.FAQ
-	if err := json.Unmarshal(data, &faqs); err != nil {
-		return fmt.Errorf("error al analizar archivo de FAQs: %v", err)
-	}
-
-	s.FAQs = faqs
-	return nil
-}
-
 // SaveTickets guarda tickets en archivo
 func (s *Store) SaveTickets() error {
 	s.mu.Lock()
@@ -260,6 +309,11 @@ func (s *Store) SaveTickets() error {
 	return nil
 }
 
+// saveTicketsLocked guarda los tickets en archivo; el llamador debe tener el mutex
+func (s *Store) saveTicketsLocked() error {
+	return writeJSONFile(s.TicketsFile, s.Tickets)
+}
+
 // SaveUsers guarda usuarios en archivo
 func (s *Store) SaveUsers() error {
 	s.mu.Lock()
@@ -280,6 +334,11 @@ func (s *Store) SaveUsers() error {
 	return nil
 }
 
+// saveUsersLocked guarda los usuarios; el llamador debe tener s.mu
+func (s *Store) saveUsersLocked() error {
+	return writeJSONFile(s.UsersFile, s.Users)
+}
+
 // SaveCategories guarda categorías en archivo
 func (s *Store) SaveCategories() error {
 	s.mu.Lock()
@@ -300,28 +359,9 @@ func (s *Store) SaveCategories() error {
 	return nil
 }
 
-// SaveFAQs guarda FAQs en archivo
-func (s *Store) SaveFAQs() error {
-	s.mu.Lock()
-	faqsCopy := make([]models.FAQ, len(s.FAQs))
-	copy(faqsCopy, s.FAQs)
-	s.mu.Unlock()
-
-	// Asegurarse de que el directorio existe
-	if err := os.MkdirAll(filepath.Dir(s.FAQsFile), 0755); err != nil {
-		return fmt.Errorf("Error al crear directorio: %v", err)
-	}
-
-	data, err := json.MarshalIndent(faqsCopy, "", "  ")
-	if err != nil {
-		return fmt.Errorf("Error al serializar FAQs: %v", err)
-	}
-
-	if err := os.WriteFile(s.FAQsFile, data, 0644); err != nil {
-		return fmt.Errorf("Error al escribir archivo de FAQs: %v", err)
-	}
-
-	return nil
+// saveCategoriesLocked guarda las categorías; el llamador debe tener s.mu
+func (s *Store) saveCategoriesLocked() error {
+	return writeJSONFile(s.CategoriesFile, s.Categories)
 }
 
 // InitializeDefaultUsers inicializa el almacén con usuarios por defecto
@@ -359,72 +399,8 @@ func (s *Store) initializeDefaultUsers() {
 		},
 	}
 
-	// Guardar en archivo
-	s.SaveUsers()
-}
-
-// InitializeDefaultFAQs inicializa el almacén con FAQs por defecto
-func (s *Store) initializeDefaultFAQs() {
-	now := time.Now()
-	s.FAQs = []models.FAQ{
-		{
-			ID:          1,
-			Question:    "¿Cómo puedo restablecer mi contraseña?",
-			Answer:      "Para restablecer su contraseña, haga clic en el enlace \"¿Olvidó su contraseña?\" en la pantalla de inicio de sesión y siga las instrucciones enviadas a su correo electrónico.",
-			Category:    "Cuenta",
-			IsPublished: true,
-			CreatedAt:   now,
-			UpdatedAt:   now,
-		},
-		{
-			ID:          2,
-			Question:    "¿Cómo puedo actualizar mi información de contacto?",
-			Answer:      "Inicie sesión en su cuenta, vaya a \"Mi Perfil\" y haga clic en \"Editar Información\". Allí podrá actualizar su dirección de correo electrónico, número de teléfono y dirección postal.",
-			Category:    "Cuenta",
-			IsPublished: true,
-			CreatedAt:   now,
-			UpdatedAt:   now,
-		},
-		{
-			ID:          3,
-			Question:    "¿Cómo puedo reportar un problema técnico?",
-			Answer:      "Para reportar un problema técnico, vaya a la sección \"Soporte\", haga clic en \"Crear Ticket\" y complete el formulario con los detalles del problema. Un técnico se pondrá en contacto con usted lo antes posible.",
-			Category:    "Soporte Técnico",
-			IsPublished: true,
-			CreatedAt:   now,
-			UpdatedAt:   now,
-		},
-		{
-			ID:          4,
-			Question:    "¿Cuáles son los horarios de atención al cliente?",
-			Answer:      "Nuestro equipo de atención al cliente está disponible de lunes a viernes, de 9:00 a.m. a 6:00 p.m. (hora local). Para asistencia de emergencia fuera de este horario, por favor envíe un correo electrónico a soporte@ejemplo.com.",
-			Category:    "General",
-			IsPublished: false,
-			CreatedAt:   now,
-			UpdatedAt:   now,
-		},
-		{
-			ID:          5,
-			Question:    "¿Cómo funciona el sistema de tickets?",
-			Answer:      "El sistema de tickets le permite enviar consultas o reportar problemas. Cada ticket recibe un número único de seguimiento. Puede ver el estado de sus tickets en cualquier momento iniciando sesión en su cuenta y accediendo a la sección \"Mis Tickets\".",
-			Category:    "Soporte Técnico",
-			IsPublished: true,
-			CreatedAt:   now,
-			UpdatedAt:   now,
-		},
-		{
-			ID:          6,
-			Question:    "¿Puedo cambiar el idioma de la plataforma?",
-			Answer:      "Sí, puede cambiar el idioma de la plataforma accediendo a \"Configuración\" desde su perfil. Actualmente ofrecemos soporte para inglés y español.",
-			Category:    "Configuración",
-			IsPublished: true,
-			CreatedAt:   now,
-			UpdatedAt:   now,
-		},
-	}
-
-	// Guardar en archivo
-	s.SaveFAQs()
+	// Guardar en archivo (loadUsers ya tiene el mutex)
+	s.saveUsersLocked()
 }
 
 // AddTicket agrega un nuevo ticket al almacén
@@ -437,7 +413,7 @@ func (s *Store) AddTicket(ticket models.Ticket) {
 	}
 
 	s.Tickets = append(s.Tickets, ticket)
-	s.SaveTickets()
+	s.saveTicketsLocked()
 }
 
 // GetTicket recupera un ticket por ID
@@ -445,15 +421,49 @@ func (s *Store) GetTicket(id string) (*models.Ticket, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	for i, ticket := range s.Tickets {
+	for _, ticket := range s.Tickets {
 		if ticket.ID == id {
-			return &s.Tickets[i], nil
+			// Devolver una copia: quien la recibe puede filtrar sus mensajes o completar
+			// campos de consulta sin alterar el ticket guardado
+			ticket = copyTicket(ticket)
+			return &ticket, nil
 		}
 	}
 
 	return nil, fmt.Errorf("Ticket no encontrado: %s", id)
 }
 
+// copyTicket devuelve una copia del ticket que no comparte slices, mapas ni metadatos
+// con el ticket guardado
+func copyTicket(ticket models.Ticket) models.Ticket {
+	ticket.Messages = append([]models.Message(nil), ticket.Messages...)
+	ticket.Tags = append([]string(nil), ticket.Tags...)
+	ticket.Links = append([]models.TicketLink(nil), ticket.Links...)
+	ticket.Watchers = append([]models.TicketWatcher(nil), ticket.Watchers...)
+
+	if ticket.Metadata != nil {
+		metadata := *ticket.Metadata
+		ticket.Metadata = &metadata
+	}
+
+	if ticket.CustomFields != nil {
+		fields := make(map[string]interface{}, len(ticket.CustomFields))
+		for key, value := range ticket.CustomFields {
+			// Los campos de selección múltiple guardan una lista
+			switch list := value.(type) {
+			case []interface{}:
+				value = append([]interface{}(nil), list...)
+			case []string:
+				value = append([]string(nil), list...)
+			}
+			fields[key] = value
+		}
+		ticket.CustomFields = fields
+	}
+
+	return ticket
+}
+
 // UpdateTicket actualiza un ticket existente
 func (s *Store) UpdateTicket(ticket models.Ticket) error {
 	s.mu.Lock()
@@ -462,16 +472,22 @@ func (s *Store) UpdateTicket(ticket models.Ticket) error {
 	for i, existingTicket := range s.Tickets {
 		if existingTicket.ID == ticket.ID {
 			// Preservar mensajes existentes si no se proporcionan
+			normalizeMessages(ticket.Messages)
 			if len(ticket.Messages) == 0 {
 				ticket.Messages = existingTicket.Messages
 			}
 
+			// Las etiquetas solo cambian con AddTicketTags y RemoveTicketTags, y la
+			// redirección de fusión solo con MergeTickets
+			ticket.Tags = existingTicket.Tags
+			ticket.MergedInto = existingTicket.MergedInto
+
 			// Asegurar que la fecha de actualización se establece
 			ticket.UpdatedAt = time.Now()
 
 			// Actualizar el ticket
 			s.Tickets[i] = ticket
-			return s.SaveTickets()
+			return s.saveTicketsLocked()
 		}
 	}
 
@@ -494,10 +510,11 @@ func (s *Store) AddMessageToTicket(ticketID string, message models.Message) (*mo
 			if message.CreatedAt.IsZero() {
 				message.CreatedAt = time.Now()
 			}
+			message.NormalizeVisibility()
 
 			s.Tickets[i].Messages = append(s.Tickets[i].Messages, message)
 			s.Tickets[i].UpdatedAt = time.Now()
-			s.SaveTickets()
+			s.saveTicketsLocked()
 			return &message, nil
 		}
 	}
@@ -505,61 +522,6 @@ func (s *Store) AddMessageToTicket(ticketID string, message models.Message) (*mo
 	return nil, fmt.Errorf("Ticket no encontrado: %s", ticketID)
 }
 
-// GetAllFAQs devuelve todas las FAQs
-func (s *Store) GetAllFAQs() []models.FAQ {
-	s.mu.RLock()
-	faqs := s.FAQs
-	s.mu.RUnlock()
-
-	// Si no hay FAQs, intentar recargar del archivo
-	if len(faqs) == 0 {
-		// Liberar el mutex antes de recargar
-		s.loadFAQs()
-
-		// Obtener las FAQs actualizadas
-		s.mu.RLock()
-		faqs = s.FAQs
-		s.mu.RUnlock()
-
-		// Si aún no hay FAQs, inicializar con valores por defecto
-		if len(faqs) == 0 {
-			s.mu.Lock()
-			s.initializeDefaultFAQs()
-			faqs = s.FAQs
-			s.mu.Unlock()
-		}
-	}
-
-	// Crear una copia para evitar problemas de concurrencia
-	result := make([]models.FAQ, len(faqs))
-	copy(result, faqs)
-	return result
-}
-
-// GetPublishedFAQs devuelve solo las FAQs publicadas
-func (s *Store) GetPublishedFAQs() []models.FAQ {
-	s.mu.RLock()
-	if len(s.FAQs) == 0 {
-		s.mu.RUnlock()
-		if err := s.loadFAQs(); err != nil {
-			fmt.Printf("Error al cargar FAQs: %v\n", err)
-			return []models.FAQ{}
-		}
-		s.mu.RLock()
-	}
-
-	// Crear una copia de las FAQs publicadas
-	publishedFAQs := make([]models.FAQ, 0)
-	for _, faq := range s.FAQs {
-		if faq.IsPublished {
-			publishedFAQs = append(publishedFAQs, faq)
-		}
-	}
-	s.mu.RUnlock()
-
-	return publishedFAQs
-}
-
 // AddWSConnection agrega una conexión WebSocket para un ticket
 func (s *Store) AddWSConnection(ticketID string, conn *websocket.Conn) string {
 	s.mu.Lock()
@@ -611,6 +573,11 @@ func (s *Store) RemoveWSConnection(ticketID, connectionID string) {
 
 // BroadcastMessage envía un mensaje a todos los clientes conectados para un ticket
 func (s *Store) BroadcastMessage(ticketID string, message models.Message) {
+	// Las conexiones del chat son del cliente; las notas internas no se difunden
+	if !message.VisibleTo(models.MessageAudienceCustomer) {
+		return
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -686,40 +653,6 @@ func (s *Store) BroadcastMessage(ticketID string, message models.Message) {
 	}
 }
 
-// CreateFAQ crea una nueva FAQ
-func (s *Store) createFAQInternal(faq *models.FAQ) (*models.FAQ, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	// Generar nuevo ID
-	newID := 1
-	if len(s.FAQs) > 0 {
-		newID = s.FAQs[len(s.FAQs)-1].ID + 1
-	}
-
-	// Establecer marcas de tiempo
-	now := time.Now()
-	faq.ID = newID
-	faq.CreatedAt = now
-	faq.UpdatedAt = now
-
-	// Agregar al almacén
-	s.FAQs = append(s.FAQs, *faq)
-
-	// Guardar en archivo
-	if err := s.SaveFAQs(); err != nil {
-		return nil, fmt.Errorf("error al guardar FAQs: %v", err)
-	}
-
-	return faq, nil
-}
-
-// CreateFAQ crea una nueva FAQ (implementación para la interfaz)
-func (s *Store) CreateFAQ(faq models.FAQ) error {
-	_, err := s.createFAQInternal(&faq)
-	return err
-}
-
 // GetUsers devuelve todos los usuarios
 func (s *Store) GetUsers() ([]models.User, error) {
 	s.mu.RLock()
@@ -766,7 +699,7 @@ func (s *Store) UpdateUser(user models.User) error {
 			// Update user fields
 			user.UpdatedAt = time.Now()
 			s.Users[i] = user
-			return s.SaveUsers()
+			return s.saveUsersLocked()
 		}
 	}
 
@@ -780,9 +713,22 @@ func (s *Store) DeleteUser(id string) error {
 
 	for i, user := range s.Users {
 		if user.ID == id {
-			// Eliminar usuario
+			// Eliminar usuario, su perfil de agente, sus restablecimientos de contraseña,
+			// su segundo factor y sus pertenencias a equipos
 			s.Users = append(s.Users[:i], s.Users[i+1:]...)
-			return s.SaveUsers()
+			if err := s.saveUsersLocked(); err != nil {
+				return err
+			}
+			if err := s.removeAgentProfileLocked(id); err != nil {
+				return err
+			}
+			if err := s.removePasswordResetsLocked(id); err != nil {
+				return err
+			}
+			if err := s.removeTwoFactorLocked(id); err != nil {
+				return err
+			}
+			return s.removeUserFromTeamsLocked(id)
 		}
 	}
 
@@ -795,7 +741,7 @@ func (s *Store) GetUserByEmail(email string) (*models.User, error) {
 	defer s.mu.RUnlock()
 
 	for _, user := range s.Users {
-		if user.Email == email {
+		if strings.EqualFold(user.Email, email) {
 			// Crear una copia para evitar problemas de concurrencia
 			userCopy := user
 			return &userCopy, nil
@@ -824,7 +770,7 @@ func (s *Store) CreateUser(user models.User) error {
 	}
 
 	s.Users = append(s.Users, user)
-	return s.SaveUsers()
+	return s.saveUsersLocked()
 }
 
 // GetTickets devuelve todos los tickets
@@ -834,7 +780,9 @@ func (s *Store) GetTickets() ([]models.Ticket, error) {
 
 	// Crear una copia para evitar problemas de concurrencia
 	tickets := make([]models.Ticket, len(s.Tickets))
-	copy(tickets, s.Tickets)
+	for i, ticket := range s.Tickets {
+		tickets[i] = copyTicket(ticket)
+	}
 
 	return tickets, nil
 }
@@ -856,9 +804,21 @@ func (s *Store) CreateTicket(ticket models.Ticket) error {
 	if ticket.UpdatedAt.IsZero() {
 		ticket.UpdatedAt = time.Now()
 	}
+	normalizeMessages(ticket.Messages)
 
 	s.Tickets = append(s.Tickets, ticket)
-	return s.SaveTickets()
+	if err := s.saveTicketsLocked(); err != nil {
+		return err
+	}
+
+	// Contabilizar las etiquetas iniciales (p. ej. las del formulario previo al chat)
+	if len(ticket.Tags) > 0 {
+		for _, name := range ticket.Tags {
+			s.adjustTagUsageLocked(name, 1)
+		}
+		return writeJSONFile(s.TagsFile, s.Tags)
+	}
+	return nil
 }
 
 // DeleteTicket elimina un ticket por ID
@@ -871,7 +831,28 @@ func (s *Store) DeleteTicket(id string) error {
 		if ticket.ID == id {
 			// Eliminar ticket
 			s.Tickets = append(s.Tickets[:i], s.Tickets[i+1:]...)
-			return s.SaveTickets()
+			if err := s.saveTicketsLocked(); err != nil {
+				return err
+			}
+
+			if s.removeTicketLinksLocked(map[string]bool{id: true}) {
+				if err := writeJSONFile(s.TicketLinksFile, s.TicketLinks); err != nil {
+					return err
+				}
+			}
+			if s.removeTicketWatchersLocked(map[string]bool{id: true}) {
+				if err := writeJSONFile(s.TicketWatchersFile, s.TicketWatchers); err != nil {
+					return err
+				}
+			}
+
+			if len(ticket.Tags) > 0 {
+				for _, name := range ticket.Tags {
+					s.adjustTagUsageLocked(name, -1)
+				}
+				return writeJSONFile(s.TagsFile, s.Tags)
+			}
+			return nil
 		}
 	}
 
@@ -932,7 +913,7 @@ func (s *Store) CreateCategory(category models.Category) error {
 	}
 
 	s.Categories = append(s.Categories, category)
-	return s.SaveCategories()
+	return s.saveCategoriesLocked()
 }
 
 // UpdateCategory actualiza una categoría existente
@@ -945,113 +926,102 @@ func (s *Store) UpdateCategory(category models.Category) error {
 			// Actualizar marca de tiempo
 			category.UpdatedAt = time.Now()
 			s.Categories[i] = category
-			return s.SaveCategories()
+			return s.saveCategoriesLocked()
 		}
 	}
 
 	return fmt.Errorf("categoría con ID %s no encontrada", category.ID)
 }
 
-// DeleteCategory elimina una categoría por ID
+// DeleteCategory elimina una categoría por ID. Sus subcategorías pasan a su categoría
+// padre y los artículos que la usaban quedan sin categoría.
 func (s *Store) DeleteCategory(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for i, category := range s.Categories {
-		if category.ID == id {
-			// Eliminar categoría
-			s.Categories = append(s.Categories[:i], s.Categories[i+1:]...)
-			return s.SaveCategories()
-		}
-	}
-
-	return fmt.Errorf("categoría con ID %s no encontrada", id)
+	return s.deleteCategoryLocked(id)
 }
 
-// GetFAQs devuelve todas las FAQs
-func (s *Store) GetFAQs() ([]models.FAQ, error) {
-	faqs := s.GetAllFAQs()
-	return faqs, nil
-}
-
-// GetFAQsByStatus devuelve FAQs filtradas por estado de publicación
-func (s *Store) GetFAQsByStatus(published bool) ([]models.FAQ, error) {
+// CountCategoryTickets devuelve cuántos tickets usan la categoría
+func (s *Store) CountCategoryTickets(id string) (int, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	// Crear una copia filtrada
-	filteredFAQs := make([]models.FAQ, 0)
-	for _, faq := range s.FAQs {
-		if faq.IsPublished == published {
-			filteredFAQs = append(filteredFAQs, faq)
+	count := 0
+	for _, ticket := range s.Tickets {
+		if ticket.CategoryID == id {
+			count++
 		}
 	}
-
-	return filteredFAQs, nil
+	return count, nil
 }
 
-// GetFAQ obtiene una FAQ por ID
-func (s *Store) GetFAQ(id int) (*models.FAQ, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	for _, faq := range s.FAQs {
-		if faq.ID == id {
-			// Crear una copia para evitar problemas de concurrencia
-			faqCopy := faq
-			return &faqCopy, nil
-		}
-	}
-
-	return nil, fmt.Errorf("FAQ con ID %d no encontrada", id)
-}
-
-// UpdateFAQ actualiza una FAQ existente
-func (s *Store) UpdateFAQ(faq models.FAQ) error {
+// DeleteCategoryReassigning mueve los tickets de la categoría a target y la elimina.
+// Devuelve el número de tickets reasignados.
+func (s *Store) DeleteCategoryReassigning(id string, target models.Category) (int, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for i, existingFAQ := range s.FAQs {
-		if existingFAQ.ID == faq.ID {
-			// Actualizar marca de tiempo
-			faq.UpdatedAt = time.Now()
-			s.FAQs[i] = faq
-			return s.SaveFAQs()
+	if s.findCategoryLocked(id) < 0 {
+		return 0, fmt.Errorf("categoría con ID %s no encontrada", id)
+	}
+
+	moved := 0
+	now := time.Now()
+	for i := range s.Tickets {
+		if s.Tickets[i].CategoryID == id {
+			s.Tickets[i].CategoryID = target.ID
+			s.Tickets[i].Category = target.Name
+			s.Tickets[i].UpdatedAt = now
+			moved++
+		}
+	}
+	if moved > 0 {
+		if err := s.saveTicketsLocked(); err != nil {
+			return 0, err
 		}
 	}
 
-	return fmt.Errorf("FAQ con ID %d no encontrada", faq.ID)
+	return moved, s.deleteCategoryLocked(id)
 }
 
-// DeleteFAQ elimina una FAQ por ID
-func (s *Store) DeleteFAQ(id int) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// deleteCategoryLocked elimina la categoría; el llamador debe tener s.mu
+func (s *Store) deleteCategoryLocked(id string) error {
+	i := s.findCategoryLocked(id)
+	if i < 0 {
+		return fmt.Errorf("categoría con ID %s no encontrada", id)
+	}
 
-	for i, faq := range s.FAQs {
-		if faq.ID == id {
-			// Eliminar FAQ
-			s.FAQs = append(s.FAQs[:i], s.FAQs[i+1:]...)
-			return s.SaveFAQs()
+	parentID := s.Categories[i].ParentID
+	s.Categories = append(s.Categories[:i], s.Categories[i+1:]...)
+	for j := range s.Categories {
+		if s.Categories[j].ParentID == id {
+			s.Categories[j].ParentID = parentID
 		}
 	}
+	if err := s.saveCategoriesLocked(); err != nil {
+		return err
+	}
 
-	return fmt.Errorf("FAQ con ID %d no encontrada", id)
+	articlesChanged := false
+	for j := range s.Articles {
+		if s.Articles[j].CategoryID == id {
+			s.Articles[j].CategoryID = ""
+			articlesChanged = true
+		}
+	}
+	if articlesChanged {
+		return s.saveArticlesLocked()
+	}
+	return nil
 }
 
-// ToggleFAQPublish cambia el estado de publicación de una FAQ
-func (s *Store) ToggleFAQPublish(id int) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	for i, faq := range s.FAQs {
-		if faq.ID == id {
-			// Cambiar estado de publicación
-			s.FAQs[i].IsPublished = !s.FAQs[i].IsPublished
-			s.FAQs[i].UpdatedAt = time.Now()
-			return s.SaveFAQs()
+// findCategoryLocked devuelve la posición de la categoría o -1
+func (s *Store) findCategoryLocked(id string) int {
+	for i, category := range s.Categories {
+		if category.ID == id {
+			return i
 		}
 	}
-
-	return fmt.Errorf("FAQ con ID %d no encontrada", id)
+	return -1
 }
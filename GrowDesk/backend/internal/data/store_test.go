@@ -0,0 +1,140 @@
+package data
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// newTestStore crea un almacén vacío en un directorio temporal. Los archivos vacíos de
+// usuarios y FAQs evitan que se creen los datos por defecto.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, name := range []string{"users.json", "faqs.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("[]"), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	return NewStore(dir)
+}
+
+// dialTicketSocket registra en el almacén una conexión WebSocket de cliente para el
+// ticket y devuelve el extremo del cliente
+func dialTicketSocket(t *testing.T, store *Store, ticketID string) *websocket.Conn {
+	t.Helper()
+
+	registered := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Upgrade: %v", err)
+			return
+		}
+		store.AddWSConnection(ticketID, conn)
+		close(registered)
+	}))
+	t.Cleanup(server.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	select {
+	case <-registered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("la conexión no se registró en el almacén")
+	}
+	return client
+}
+
+func TestBroadcastMessageOnlySendsPublicMessages(t *testing.T) {
+	store := newMessagesTestStore(t)
+	client := dialTicketSocket(t, store, testTicketID)
+
+	now := time.Now()
+	store.BroadcastMessage(testTicketID, models.Message{ID: "internal", Content: "Nota interna", Visibility: models.MessageVisibilityInternal, Timestamp: now})
+	store.BroadcastMessage(testTicketID, models.Message{ID: "system", Content: "Ticket asignado", Visibility: models.MessageVisibilitySystem, Timestamp: now})
+	store.BroadcastMessage(testTicketID, models.Message{ID: "legacy-note", Content: "Nota antigua", IsInternal: true, Timestamp: now})
+	store.BroadcastMessage(testTicketID, models.Message{ID: "public", Content: "Respuesta", Visibility: models.MessageVisibilityPublic, Timestamp: now})
+
+	// Los mensajes llegan en orden: el primero debe ser la respuesta pública
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := client.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+
+	var received models.WebSocketMessage
+	if err := json.Unmarshal(data, &received); err != nil {
+		t.Fatalf("mensaje WebSocket inválido: %v", err)
+	}
+	payload, _ := received.Data.(map[string]interface{})
+	if received.Type != "new_message" || payload["id"] != "public" {
+		t.Fatalf("se recibió %s, se esperaba solo la respuesta pública", data)
+	}
+
+	// No debe llegar nada más
+	client.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	if _, data, err := client.ReadMessage(); err == nil {
+		t.Errorf("se recibió un mensaje inesperado: %s", data)
+	}
+}
+
+func TestGetTicketReturnsCopy(t *testing.T) {
+	store := newTestStore(t)
+	err := store.CreateTicket(models.Ticket{
+		ID:           testTicketID,
+		Title:        "Consulta",
+		Status:       "open",
+		Messages:     []models.Message{{ID: "internal", Content: "Nota interna", Visibility: models.MessageVisibilityInternal}},
+		Tags:         []string{"facturacion"},
+		Metadata:     &models.Metadata{URL: "https://example.com/precios"},
+		CustomFields: map[string]interface{}{"plan": "pro", "modulos": []interface{}{"ventas"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateTicket: %v", err)
+	}
+
+	// Modificar todo lo que el ticket devuelto podría compartir con el guardado
+	ticket, err := store.GetTicket(testTicketID)
+	if err != nil {
+		t.Fatalf("GetTicket: %v", err)
+	}
+	ticket.Messages[0].Content = "modificado"
+	ticket.Messages = models.FilterMessages(ticket.Messages, models.MessageAudienceCustomer)
+	ticket.Tags[0] = "modificado"
+	ticket.Metadata.URL = "modificado"
+	ticket.CustomFields["plan"] = "modificado"
+	ticket.CustomFields["modulos"].([]interface{})[0] = "modificado"
+
+	stored, err := store.GetTicket(testTicketID)
+	if err != nil {
+		t.Fatalf("GetTicket: %v", err)
+	}
+	if len(stored.Messages) != 1 || stored.Messages[0].Content != "Nota interna" {
+		t.Errorf("mensajes guardados %+v, se esperaba la nota interna sin cambios", stored.Messages)
+	}
+	if stored.Tags[0] != "facturacion" {
+		t.Errorf("etiquetas guardadas %v", stored.Tags)
+	}
+	if stored.Metadata.URL != "https://example.com/precios" {
+		t.Errorf("metadatos guardados %+v", stored.Metadata)
+	}
+	if stored.CustomFields["plan"] != "pro" || stored.CustomFields["modulos"].([]interface{})[0] != "ventas" {
+		t.Errorf("campos personalizados guardados %v", stored.CustomFields)
+	}
+}
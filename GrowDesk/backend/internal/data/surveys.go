@@ -0,0 +1,158 @@
+package data
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// legacySurvey lee el token en claro que guardaban las versiones anteriores
+type legacySurvey struct {
+	models.SatisfactionSurvey
+	Token string `json:"token,omitempty"`
+}
+
+// loadSurveys carga las encuestas de satisfacción desde archivo. Los tokens guardados en
+// claro se reemplazan por su hash.
+func (s *Store) loadSurveys() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Surveys = make([]models.SatisfactionSurvey, 0)
+	var stored []legacySurvey
+	if !loadJSONFile(s.SurveysFile, &stored) {
+		return
+	}
+
+	migrated := 0
+	for _, survey := range stored {
+		if survey.Token != "" && survey.TokenHash == "" {
+			sum := sha256.Sum256([]byte(survey.Token))
+			survey.TokenHash = hex.EncodeToString(sum[:])
+			migrated++
+		}
+		s.Surveys = append(s.Surveys, survey.SatisfactionSurvey)
+	}
+	fmt.Printf("Cargadas %d encuestas de satisfacción desde archivo\n", len(s.Surveys))
+
+	if migrated > 0 {
+		if err := writeJSONFile(s.SurveysFile, s.Surveys); err != nil {
+			fmt.Printf("Error al guardar los hashes de %d tokens de encuesta: %v\n", migrated, err)
+		}
+	}
+}
+
+// GetSurveys devuelve las encuestas que cumplen el filtro, las más recientes primero
+func (s *Store) GetSurveys(filter models.SurveyFilter) ([]models.SatisfactionSurvey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	surveys := make([]models.SatisfactionSurvey, 0)
+	for _, survey := range s.Surveys {
+		if filter.TicketID != "" && survey.TicketID != filter.TicketID {
+			continue
+		}
+		if !filter.From.IsZero() && survey.CreatedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !survey.CreatedAt.Before(filter.To) {
+			continue
+		}
+		surveys = append(surveys, survey)
+	}
+
+	sort.SliceStable(surveys, func(i, j int) bool {
+		return surveys[i].CreatedAt.After(surveys[j].CreatedAt)
+	})
+
+	return surveys, nil
+}
+
+// GetSurveyByTokenHash obtiene una encuesta por el hash de su token
+func (s *Store) GetSurveyByTokenHash(tokenHash string) (*models.SatisfactionSurvey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, survey := range s.Surveys {
+		if tokenHash != "" && survey.TokenHash == tokenHash {
+			result := survey
+			return &result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("encuesta no encontrada")
+}
+
+// CreateSurvey guarda una nueva encuesta
+func (s *Store) CreateSurvey(survey models.SatisfactionSurvey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if survey.ID == "" {
+		survey.ID = uuid.New().String()
+	}
+	if survey.CreatedAt.IsZero() {
+		survey.CreatedAt = time.Now()
+	}
+
+	s.Surveys = append(s.Surveys, survey)
+	return writeJSONFile(s.SurveysFile, s.Surveys)
+}
+
+// UpdateSurveyTokenHash reemplaza el token de una encuesta; el enlace anterior deja de servir
+func (s *Store) UpdateSurveyTokenHash(id, tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.Surveys {
+		if s.Surveys[i].ID != id {
+			continue
+		}
+
+		previous := s.Surveys[i].TokenHash
+		s.Surveys[i].TokenHash = tokenHash
+		if err := writeJSONFile(s.SurveysFile, s.Surveys); err != nil {
+			s.Surveys[i].TokenHash = previous
+			return err
+		}
+		return nil
+	}
+
+	return fmt.Errorf("encuesta no encontrada")
+}
+
+// AnswerSurvey guarda la respuesta del cliente. Falla si la encuesta ya fue respondida.
+func (s *Store) AnswerSurvey(tokenHash string, rating int, comment string) (*models.SatisfactionSurvey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.Surveys {
+		survey := &s.Surveys[i]
+		if tokenHash == "" || survey.TokenHash != tokenHash {
+			continue
+		}
+		if survey.Rating != nil {
+			return nil, fmt.Errorf("la encuesta ya fue respondida")
+		}
+
+		now := time.Now()
+		survey.Rating = &rating
+		survey.Comment = comment
+		survey.RespondedAt = &now
+		if err := writeJSONFile(s.SurveysFile, s.Surveys); err != nil {
+			survey.Rating, survey.Comment, survey.RespondedAt = nil, "", nil
+			return nil, err
+		}
+
+		result := *survey
+		return &result, nil
+	}
+
+	return nil, fmt.Errorf("encuesta no encontrada")
+}
@@ -0,0 +1,182 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/tags"
+)
+
+// loadTags carga las etiquetas desde archivo
+func (s *Store) loadTags() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Tags = make([]models.Tag, 0)
+	if loadJSONFile(s.TagsFile, &s.Tags) {
+		fmt.Printf("Cargadas %d etiquetas desde archivo\n", len(s.Tags))
+	}
+}
+
+// adjustTagUsageLocked suma delta al contador de uso de una etiqueta, creándola si no existe.
+// El llamador debe tener el mutex.
+func (s *Store) adjustTagUsageLocked(name string, delta int) {
+	now := time.Now()
+	for i := range s.Tags {
+		if s.Tags[i].Name == name {
+			s.Tags[i].UsageCount += delta
+			if s.Tags[i].UsageCount < 0 {
+				s.Tags[i].UsageCount = 0
+			}
+			s.Tags[i].UpdatedAt = now
+			return
+		}
+	}
+
+	if delta > 0 {
+		s.Tags = append(s.Tags, models.Tag{
+			ID:         uuid.New().String(),
+			Name:       name,
+			UsageCount: delta,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+	}
+}
+
+// GetTags devuelve las etiquetas que empiezan por prefix, las más usadas primero.
+// Con limit <= 0 no se limita el número de resultados.
+func (s *Store) GetTags(prefix string, limit int) ([]models.Tag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prefix = tags.Normalize(prefix)
+	result := make([]models.Tag, 0)
+	for _, tag := range s.Tags {
+		if strings.HasPrefix(tag.Name, prefix) {
+			result = append(result, tag)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].UsageCount != result[j].UsageCount {
+			return result[i].UsageCount > result[j].UsageCount
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+// AddTicketTags añade etiquetas a un ticket y devuelve sus etiquetas resultantes
+func (s *Store) AddTicketTags(ticketID string, names []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.Tickets {
+		if s.Tickets[i].ID != ticketID {
+			continue
+		}
+
+		changed := false
+		for _, name := range names {
+			if tags.Contains(s.Tickets[i].Tags, name) {
+				continue
+			}
+			s.Tickets[i].Tags = append(s.Tickets[i].Tags, name)
+			s.adjustTagUsageLocked(name, 1)
+			changed = true
+		}
+
+		if changed {
+			s.Tickets[i].UpdatedAt = time.Now()
+			if err := s.saveTicketsLocked(); err != nil {
+				return nil, err
+			}
+			if err := writeJSONFile(s.TagsFile, s.Tags); err != nil {
+				return nil, err
+			}
+		}
+
+		return append([]string{}, s.Tickets[i].Tags...), nil
+	}
+
+	return nil, fmt.Errorf("Ticket no encontrado: %s", ticketID)
+}
+
+// RemoveTicketTags quita etiquetas de un ticket y devuelve sus etiquetas resultantes
+func (s *Store) RemoveTicketTags(ticketID string, names []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.Tickets {
+		if s.Tickets[i].ID != ticketID {
+			continue
+		}
+
+		remaining := make([]string, 0, len(s.Tickets[i].Tags))
+		for _, name := range s.Tickets[i].Tags {
+			if tags.Contains(names, name) {
+				s.adjustTagUsageLocked(name, -1)
+				continue
+			}
+			remaining = append(remaining, name)
+		}
+
+		if len(remaining) != len(s.Tickets[i].Tags) {
+			s.Tickets[i].Tags = remaining
+			s.Tickets[i].UpdatedAt = time.Now()
+			if err := s.saveTicketsLocked(); err != nil {
+				return nil, err
+			}
+			if err := writeJSONFile(s.TagsFile, s.Tags); err != nil {
+				return nil, err
+			}
+		}
+
+		return append([]string{}, remaining...), nil
+	}
+
+	return nil, fmt.Errorf("Ticket no encontrado: %s", ticketID)
+}
+
+// GetTagReport resume los tickets de cada etiqueta por estado
+func (s *Store) GetTagReport() ([]models.TagReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reports := make(map[string]*models.TagReport)
+	for _, ticket := range s.Tickets {
+		for _, name := range ticket.Tags {
+			report, ok := reports[name]
+			if !ok {
+				report = &models.TagReport{Tag: name, ByStatus: make(map[string]int)}
+				reports[name] = report
+			}
+			report.Total++
+			report.ByStatus[ticket.Status]++
+		}
+	}
+
+	result := make([]models.TagReport, 0, len(reports))
+	for _, report := range reports {
+		result = append(result, *report)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total != result[j].Total {
+			return result[i].Total > result[j].Total
+		}
+		return result[i].Tag < result[j].Tag
+	})
+
+	return result, nil
+}
@@ -0,0 +1,341 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/teams"
+)
+
+// loadTeams carga los equipos desde archivo
+func (s *Store) loadTeams() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Teams = make([]models.Team, 0)
+	if loadJSONFile(s.TeamsFile, &s.Teams) {
+		fmt.Printf("Cargados %d equipos desde archivo\n", len(s.Teams))
+	}
+}
+
+// GetTeams devuelve todos los equipos con sus miembros
+func (s *Store) GetTeams() ([]models.Team, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]models.Team, 0, len(s.Teams))
+	for _, team := range s.Teams {
+		list = append(list, copyTeam(team))
+	}
+	return list, nil
+}
+
+// GetTeam obtiene un equipo por ID
+func (s *Store) GetTeam(id string) (*models.Team, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.findTeamLocked(id)
+	if i < 0 {
+		return nil, fmt.Errorf("equipo con ID %s no encontrado", id)
+	}
+	team := copyTeam(s.Teams[i])
+	return &team, nil
+}
+
+// CreateTeam crea un equipo con sus miembros iniciales
+func (s *Store) CreateTeam(team models.Team) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findTeamLocked(team.ID) >= 0 {
+		return fmt.Errorf("ya existe un equipo con ID %s", team.ID)
+	}
+
+	now := time.Now()
+	if team.CreatedAt.IsZero() {
+		team.CreatedAt = now
+	}
+	if team.UpdatedAt.IsZero() {
+		team.UpdatedAt = now
+	}
+	if team.Members == nil {
+		team.Members = make([]models.TeamMember, 0)
+	}
+
+	s.Teams = append(s.Teams, copyTeam(team))
+	if err := s.saveTeamsLocked(); err != nil {
+		s.Teams = s.Teams[:len(s.Teams)-1]
+		return err
+	}
+	return nil
+}
+
+// UpdateTeam actualiza los datos y la configuración de un equipo; los miembros se
+// gestionan con AddTeamMember y RemoveTeamMember
+func (s *Store) UpdateTeam(team models.Team) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findTeamLocked(team.ID)
+	if i < 0 {
+		return fmt.Errorf("equipo con ID %s no encontrado", team.ID)
+	}
+
+	previous := s.Teams[i]
+	team.Members = previous.Members
+	team.CreatedAt = previous.CreatedAt
+	team.UpdatedAt = time.Now()
+	s.Teams[i] = team
+	if err := s.saveTeamsLocked(); err != nil {
+		s.Teams[i] = previous
+		return err
+	}
+	return nil
+}
+
+// DeleteTeam elimina un equipo y quita el departamento por defecto de las categorías
+// que lo usaban
+func (s *Store) DeleteTeam(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.deleteTeamLocked(id, "")
+}
+
+// CountTeamReferences devuelve cuántos tickets y usuarios pertenecen al equipo
+func (s *Store) CountTeamReferences(id string) (int, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tickets, users := 0, 0
+	for _, ticket := range s.Tickets {
+		if teams.Key(ticket.Department) == id {
+			tickets++
+		}
+	}
+	for _, user := range s.Users {
+		if teams.Key(user.Department) == id {
+			users++
+		}
+	}
+	return tickets, users, nil
+}
+
+// DeleteTeamReassigning mueve los tickets, los usuarios, los miembros y las categorías
+// del equipo a target y lo elimina. Devuelve el número de tickets reasignados.
+func (s *Store) DeleteTeamReassigning(id string, target models.Team) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findTeamLocked(id)
+	if i < 0 {
+		return 0, fmt.Errorf("equipo con ID %s no encontrado", id)
+	}
+	t := s.findTeamLocked(target.ID)
+	if t < 0 {
+		return 0, fmt.Errorf("equipo con ID %s no encontrado", target.ID)
+	}
+
+	// Los miembros que no estaban en el equipo destino se suman como miembros
+	now := time.Now()
+	for _, member := range s.Teams[i].Members {
+		if teams.MemberRole(s.Teams[t], member.UserID) == "" {
+			s.Teams[t].Members = append(s.Teams[t].Members, models.TeamMember{
+				UserID:   member.UserID,
+				Role:     models.TeamRoleMember,
+				JoinedAt: now,
+			})
+		}
+	}
+
+	moved := 0
+	for j := range s.Tickets {
+		if teams.Key(s.Tickets[j].Department) == id {
+			s.Tickets[j].Department = target.ID
+			s.Tickets[j].UpdatedAt = now
+			moved++
+		}
+	}
+	for j := range s.Users {
+		if teams.Key(s.Users[j].Department) == id {
+			s.Users[j].Department = target.ID
+			s.Users[j].UpdatedAt = now
+		}
+	}
+
+	if err := s.deleteTeamLocked(id, target.ID); err != nil {
+		return 0, err
+	}
+	if err := s.saveTicketsLocked(); err != nil {
+		return 0, err
+	}
+	if err := s.saveUsersLocked(); err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+// AddTeamMember agrega un usuario al equipo o cambia su rol si ya es miembro
+func (s *Store) AddTeamMember(teamID string, member models.TeamMember) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findTeamLocked(teamID)
+	if i < 0 {
+		return fmt.Errorf("equipo con ID %s no encontrado", teamID)
+	}
+	if s.findUserLocked(member.UserID) < 0 {
+		return fmt.Errorf("usuario con ID %s no encontrado", member.UserID)
+	}
+
+	previous := copyTeam(s.Teams[i])
+	found := false
+	for j := range s.Teams[i].Members {
+		if s.Teams[i].Members[j].UserID == member.UserID {
+			s.Teams[i].Members[j].Role = member.Role
+			found = true
+		}
+	}
+	if !found {
+		if member.JoinedAt.IsZero() {
+			member.JoinedAt = time.Now()
+		}
+		s.Teams[i].Members = append(s.Teams[i].Members, member)
+	}
+	s.Teams[i].UpdatedAt = time.Now()
+
+	if err := s.saveTeamsLocked(); err != nil {
+		s.Teams[i] = previous
+		return err
+	}
+	return nil
+}
+
+// RemoveTeamMember quita a un usuario del equipo
+func (s *Store) RemoveTeamMember(teamID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findTeamLocked(teamID)
+	if i < 0 {
+		return fmt.Errorf("equipo con ID %s no encontrado", teamID)
+	}
+
+	previous := copyTeam(s.Teams[i])
+	members := make([]models.TeamMember, 0, len(previous.Members))
+	for _, member := range previous.Members {
+		if member.UserID != userID {
+			members = append(members, member)
+		}
+	}
+	if len(members) == len(previous.Members) {
+		return fmt.Errorf("el usuario %s no es miembro del equipo %s", userID, teamID)
+	}
+
+	s.Teams[i].Members = members
+	s.Teams[i].UpdatedAt = time.Now()
+	if err := s.saveTeamsLocked(); err != nil {
+		s.Teams[i] = previous
+		return err
+	}
+	return nil
+}
+
+// deleteTeamLocked elimina el equipo y cambia el departamento por defecto de las
+// categorías que lo usaban por replacement; el llamador debe tener s.mu
+func (s *Store) deleteTeamLocked(id, replacement string) error {
+	i := s.findTeamLocked(id)
+	if i < 0 {
+		return fmt.Errorf("equipo con ID %s no encontrado", id)
+	}
+	s.Teams = append(s.Teams[:i], s.Teams[i+1:]...)
+
+	categoriesChanged := false
+	for j := range s.Categories {
+		if teams.Key(s.Categories[j].Defaults.Department) == id {
+			s.Categories[j].Defaults.Department = replacement
+			categoriesChanged = true
+		}
+	}
+
+	if err := s.saveTeamsLocked(); err != nil {
+		return err
+	}
+	if categoriesChanged {
+		return s.saveCategoriesLocked()
+	}
+	return nil
+}
+
+// removeUserFromTeamsLocked quita al usuario de todos los equipos; el llamador debe
+// tener s.mu
+func (s *Store) removeUserFromTeamsLocked(userID string) error {
+	changed := false
+	for i := range s.Teams {
+		members := s.Teams[i].Members[:0]
+		for _, member := range s.Teams[i].Members {
+			if member.UserID != userID {
+				members = append(members, member)
+			}
+		}
+		if len(members) != len(s.Teams[i].Members) {
+			changed = true
+		}
+		s.Teams[i].Members = members
+	}
+	if !changed {
+		return nil
+	}
+	return s.saveTeamsLocked()
+}
+
+// clearTeamBusinessHoursLocked quita el horario de atención de los equipos que lo
+// usaban; el llamador debe tener s.mu
+func (s *Store) clearTeamBusinessHoursLocked(hoursID string) error {
+	changed := false
+	for i := range s.Teams {
+		if s.Teams[i].BusinessHoursID == hoursID {
+			s.Teams[i].BusinessHoursID = ""
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return s.saveTeamsLocked()
+}
+
+// saveTeamsLocked guarda los equipos; el llamador debe tener s.mu
+func (s *Store) saveTeamsLocked() error {
+	return writeJSONFile(s.TeamsFile, s.Teams)
+}
+
+// findTeamLocked devuelve la posición del equipo o -1
+func (s *Store) findTeamLocked(id string) int {
+	for i, team := range s.Teams {
+		if team.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// findUserLocked devuelve la posición del usuario o -1
+func (s *Store) findUserLocked(id string) int {
+	for i, user := range s.Users {
+		if user.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// copyTeam copia el equipo con su propia lista de miembros
+func copyTeam(team models.Team) models.Team {
+	members := make([]models.TeamMember, len(team.Members))
+	copy(members, team.Members)
+	team.Members = members
+	return team
+}
@@ -0,0 +1,144 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/tags"
+)
+
+// BulkUpdateTickets aplica los mismos cambios a varios tickets y registra una actividad por
+// ticket afectado. En modo transaccional, si algún ticket falla no se aplica ningún cambio
+// y ningún resultado se marca como exitoso.
+func (s *Store) BulkUpdateTickets(ticketIDs []string, changes models.BulkTicketChanges, activity models.Activity, transactional bool) ([]models.BulkTicketResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index := make(map[string]int, len(s.Tickets))
+	for i := range s.Tickets {
+		index[s.Tickets[i].ID] = i
+	}
+
+	results := make([]models.BulkTicketResult, len(ticketIDs))
+	failed := false
+	for i, id := range ticketIDs {
+		results[i].TicketID = id
+		if _, ok := index[id]; !ok {
+			results[i].Error = fmt.Sprintf("Ticket no encontrado: %s", id)
+			failed = true
+		}
+	}
+	if transactional && failed {
+		return results, nil
+	}
+
+	// Copias del estado previo para restaurarlo si falla la escritura
+	previousTickets := make([]models.Ticket, len(s.Tickets))
+	copy(previousTickets, s.Tickets)
+	previousTags := make([]models.Tag, len(s.Tags))
+	copy(previousTags, s.Tags)
+	previousActivities := s.Activities
+	previousLinks := s.TicketLinks
+	previousWatchers := s.TicketWatchers
+
+	now := time.Now()
+	deleted := make(map[string]bool)
+	for i, id := range ticketIDs {
+		if results[i].Error != "" || deleted[id] {
+			continue
+		}
+
+		ticket := &s.Tickets[index[id]]
+		if changes.Delete {
+			for _, name := range ticket.Tags {
+				s.adjustTagUsageLocked(name, -1)
+			}
+			deleted[id] = true
+		} else {
+			s.applyBulkChangesLocked(ticket, changes, now)
+		}
+
+		s.appendActivityLocked(activity, id, now)
+		results[i].Success = true
+	}
+
+	if len(deleted) > 0 {
+		remaining := make([]models.Ticket, 0, len(s.Tickets)-len(deleted))
+		for _, ticket := range s.Tickets {
+			if !deleted[ticket.ID] {
+				remaining = append(remaining, ticket)
+			}
+		}
+		s.Tickets = remaining
+		s.removeTicketLinksLocked(deleted)
+		s.removeTicketWatchersLocked(deleted)
+	}
+
+	if err := s.saveBulkLocked(); err != nil {
+		s.Tickets, s.Tags, s.Activities, s.TicketLinks = previousTickets, previousTags, previousActivities, previousLinks
+		s.TicketWatchers = previousWatchers
+		s.saveBulkLocked()
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// applyBulkChangesLocked aplica los cambios de una operación masiva a un ticket.
+// El llamador debe tener el mutex.
+func (s *Store) applyBulkChangesLocked(ticket *models.Ticket, changes models.BulkTicketChanges, now time.Time) {
+	if changes.Status != "" {
+		ticket.Status = changes.Status
+	}
+	if changes.Priority != "" {
+		ticket.Priority = changes.Priority
+	}
+	if changes.AssignedTo != nil {
+		ticket.AssignedTo = *changes.AssignedTo
+	}
+	if changes.CategoryID != "" {
+		ticket.CategoryID = changes.CategoryID
+		ticket.Category = changes.Category
+	}
+
+	// Se crea un slice nuevo para no modificar la copia del estado previo
+	if len(changes.AddTags) > 0 || len(changes.RemoveTags) > 0 {
+		updated := append([]string{}, ticket.Tags...)
+		for _, name := range changes.AddTags {
+			if !tags.Contains(updated, name) {
+				updated = append(updated, name)
+				s.adjustTagUsageLocked(name, 1)
+			}
+		}
+
+		remaining := make([]string, 0, len(updated))
+		for _, name := range updated {
+			if tags.Contains(changes.RemoveTags, name) {
+				s.adjustTagUsageLocked(name, -1)
+				continue
+			}
+			remaining = append(remaining, name)
+		}
+		ticket.Tags = remaining
+	}
+
+	ticket.UpdatedAt = now
+}
+
+// saveBulkLocked guarda los archivos que puede modificar una operación masiva
+func (s *Store) saveBulkLocked() error {
+	if err := s.saveTicketsLocked(); err != nil {
+		return err
+	}
+	if err := writeJSONFile(s.TagsFile, s.Tags); err != nil {
+		return err
+	}
+	if err := writeJSONFile(s.TicketLinksFile, s.TicketLinks); err != nil {
+		return err
+	}
+	if err := writeJSONFile(s.TicketWatchersFile, s.TicketWatchers); err != nil {
+		return err
+	}
+	return writeJSONFile(s.ActivitiesFile, s.Activities)
+}
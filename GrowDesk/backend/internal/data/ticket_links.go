@@ -0,0 +1,97 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadTicketLinks carga las relaciones entre tickets desde archivo
+func (s *Store) loadTicketLinks() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.TicketLinks = make([]models.TicketLink, 0)
+	if loadJSONFile(s.TicketLinksFile, &s.TicketLinks) {
+		fmt.Printf("Cargadas %d relaciones entre tickets desde archivo\n", len(s.TicketLinks))
+	}
+}
+
+// GetTicketLinks devuelve las relaciones en las que participa un ticket, en cualquier dirección
+func (s *Store) GetTicketLinks(ticketID string) ([]models.TicketLink, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.TicketLink, 0)
+	for _, link := range s.TicketLinks {
+		if link.TicketID == ticketID || link.LinkedTicketID == ticketID {
+			result = append(result, link)
+		}
+	}
+
+	return result, nil
+}
+
+// CreateTicketLink guarda una relación entre dos tickets existentes
+func (s *Store) CreateTicketLink(link models.TicketLink) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range []string{link.TicketID, link.LinkedTicketID} {
+		if s.findTicketLocked(id) < 0 {
+			return fmt.Errorf("Ticket no encontrado: %s", id)
+		}
+	}
+
+	for _, existing := range s.TicketLinks {
+		if existing.TicketID == link.TicketID && existing.LinkedTicketID == link.LinkedTicketID && existing.Type == link.Type {
+			return fmt.Errorf("la relación ya existe")
+		}
+		if link.Type == models.TicketLinkChildOf && existing.Type == models.TicketLinkChildOf && existing.TicketID == link.TicketID {
+			return fmt.Errorf("el ticket %s ya tiene un ticket padre", link.TicketID)
+		}
+	}
+
+	if link.ID == "" {
+		link.ID = uuid.New().String()
+	}
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = time.Now()
+	}
+
+	s.TicketLinks = append(s.TicketLinks, link)
+	return writeJSONFile(s.TicketLinksFile, s.TicketLinks)
+}
+
+// DeleteTicketLink elimina una relación entre tickets
+func (s *Store) DeleteTicketLink(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, link := range s.TicketLinks {
+		if link.ID == id {
+			s.TicketLinks = append(s.TicketLinks[:i], s.TicketLinks[i+1:]...)
+			return writeJSONFile(s.TicketLinksFile, s.TicketLinks)
+		}
+	}
+
+	return fmt.Errorf("relación no encontrada: %s", id)
+}
+
+// removeTicketLinksLocked quita las relaciones de los tickets eliminados y devuelve si hubo cambios.
+// El llamador debe tener el mutex y guardar el archivo de relaciones.
+func (s *Store) removeTicketLinksLocked(deleted map[string]bool) bool {
+	remaining := make([]models.TicketLink, 0, len(s.TicketLinks))
+	for _, link := range s.TicketLinks {
+		if !deleted[link.TicketID] && !deleted[link.LinkedTicketID] {
+			remaining = append(remaining, link)
+		}
+	}
+
+	changed := len(remaining) != len(s.TicketLinks)
+	s.TicketLinks = remaining
+	return changed
+}
@@ -0,0 +1,183 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/tags"
+)
+
+// findTicketLocked devuelve el índice de un ticket o -1. El llamador debe tener el mutex.
+func (s *Store) findTicketLocked(id string) int {
+	for i := range s.Tickets {
+		if s.Tickets[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// MergeTickets mueve los mensajes y etiquetas de los tickets origen al ticket destino.
+// Los orígenes quedan cerrados y apuntando al destino para que sus IDs sigan resolviendo.
+func (s *Store) MergeTickets(targetID string, sourceIDs []string, activity models.Activity) (*models.Ticket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target := s.findTicketLocked(targetID)
+	if target < 0 {
+		return nil, fmt.Errorf("Ticket no encontrado: %s", targetID)
+	}
+	if s.Tickets[target].MergedInto != "" {
+		return nil, fmt.Errorf("el ticket %s ya fue fusionado en %s", targetID, s.Tickets[target].MergedInto)
+	}
+
+	sources := make([]int, 0, len(sourceIDs))
+	seen := make(map[string]bool, len(sourceIDs))
+	for _, id := range sourceIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		i := s.findTicketLocked(id)
+		switch {
+		case i < 0:
+			return nil, fmt.Errorf("Ticket no encontrado: %s", id)
+		case i == target:
+			return nil, fmt.Errorf("un ticket no se puede fusionar consigo mismo")
+		case s.Tickets[i].MergedInto != "":
+			return nil, fmt.Errorf("el ticket %s ya fue fusionado en %s", id, s.Tickets[i].MergedInto)
+		}
+		sources = append(sources, i)
+	}
+
+	now := time.Now()
+	merged := s.Tickets[target]
+	merged.Messages = append([]models.Message{}, merged.Messages...)
+	merged.Tags = append([]string{}, merged.Tags...)
+
+	for _, i := range sources {
+		source := &s.Tickets[i]
+		merged.Messages = append(merged.Messages, source.Messages...)
+
+		for _, name := range source.Tags {
+			s.adjustTagUsageLocked(name, -1)
+			if !tags.Contains(merged.Tags, name) {
+				merged.Tags = append(merged.Tags, name)
+				s.adjustTagUsageLocked(name, 1)
+			}
+		}
+
+		source.Messages = nil
+		source.Tags = nil
+		source.Status = "closed"
+		source.MergedInto = targetID
+		source.UpdatedAt = now
+
+		sourceActivity := activity
+		sourceActivity.Description = fmt.Sprintf("Ticket fusionado en %s", targetID)
+		s.appendActivityLocked(sourceActivity, source.ID, now)
+
+		targetActivity := activity
+		targetActivity.Description = fmt.Sprintf("Ticket %s fusionado en este ticket", source.ID)
+		s.appendActivityLocked(targetActivity, targetID, now)
+	}
+
+	sort.SliceStable(merged.Messages, func(i, j int) bool {
+		return merged.Messages[i].Timestamp.Before(merged.Messages[j].Timestamp)
+	})
+	merged.UpdatedAt = now
+	s.Tickets[target] = merged
+
+	// Quienes seguían un ticket origen pasan a seguir el destino
+	s.moveTicketWatchersLocked(targetID, sourceIDs, now)
+
+	// Los tickets fusionados antes en un origen pasan a apuntar directamente al destino
+	for i := range s.Tickets {
+		for _, id := range sourceIDs {
+			if s.Tickets[i].MergedInto == id {
+				s.Tickets[i].MergedInto = targetID
+			}
+		}
+	}
+
+	if err := s.saveBulkLocked(); err != nil {
+		return nil, err
+	}
+
+	result := copyTicket(s.Tickets[target])
+	return &result, nil
+}
+
+// SplitTicket mueve los mensajes indicados de un ticket a un ticket nuevo creado a partir de ticket
+func (s *Store) SplitTicket(sourceID string, messageIDs []string, ticket models.Ticket, activity models.Activity) (*models.Ticket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	source := s.findTicketLocked(sourceID)
+	if source < 0 {
+		return nil, fmt.Errorf("Ticket no encontrado: %s", sourceID)
+	}
+	if s.Tickets[source].MergedInto != "" {
+		return nil, fmt.Errorf("el ticket %s fue fusionado en %s", sourceID, s.Tickets[source].MergedInto)
+	}
+
+	selected := make(map[string]bool, len(messageIDs))
+	for _, id := range messageIDs {
+		selected[id] = true
+	}
+
+	remaining := make([]models.Message, 0, len(s.Tickets[source].Messages))
+	moved := make([]models.Message, 0, len(messageIDs))
+	for _, message := range s.Tickets[source].Messages {
+		if selected[message.ID] {
+			moved = append(moved, message)
+			delete(selected, message.ID)
+			continue
+		}
+		remaining = append(remaining, message)
+	}
+	for id := range selected {
+		return nil, fmt.Errorf("el mensaje %s no pertenece al ticket %s", id, sourceID)
+	}
+	if len(moved) == 0 {
+		return nil, fmt.Errorf("debe indicar al menos un mensaje")
+	}
+	if len(remaining) == 0 {
+		return nil, fmt.Errorf("el ticket original debe conservar al menos un mensaje")
+	}
+
+	now := time.Now()
+	if ticket.ID == "" {
+		ticket.ID = uuid.New().String()
+	}
+	if s.findTicketLocked(ticket.ID) >= 0 {
+		return nil, fmt.Errorf("ya existe un ticket con ID %s", ticket.ID)
+	}
+	ticket.Messages = moved
+	ticket.Tags = nil
+	ticket.CreatedAt = now
+	ticket.UpdatedAt = now
+
+	s.Tickets[source].Messages = remaining
+	s.Tickets[source].UpdatedAt = now
+	s.Tickets = append(s.Tickets, ticket)
+
+	sourceActivity := activity
+	sourceActivity.Description = fmt.Sprintf("%d mensajes movidos al ticket %s", len(moved), ticket.ID)
+	s.appendActivityLocked(sourceActivity, sourceID, now)
+
+	targetActivity := activity
+	targetActivity.Description = fmt.Sprintf("Ticket creado a partir de %d mensajes de %s", len(moved), sourceID)
+	s.appendActivityLocked(targetActivity, ticket.ID, now)
+
+	if err := s.saveBulkLocked(); err != nil {
+		return nil, err
+	}
+
+	return &ticket, nil
+}
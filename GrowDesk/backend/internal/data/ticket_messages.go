@@ -0,0 +1,29 @@
+package data
+
+import (
+	"fmt"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// GetTicketMessages devuelve los mensajes de un ticket visibles para el destinatario.
+// Los clientes solo reciben respuestas públicas; las notas internas y los eventos del
+// sistema quedan reservados a los agentes.
+func (s *Store) GetTicketMessages(ticketID, audience string) ([]models.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.findTicketLocked(ticketID)
+	if i < 0 {
+		return nil, fmt.Errorf("Ticket no encontrado: %s", ticketID)
+	}
+
+	return models.FilterMessages(s.Tickets[i].Messages, audience), nil
+}
+
+// normalizeMessages completa la visibilidad de los mensajes indicados
+func normalizeMessages(messages []models.Message) {
+	for i := range messages {
+		messages[i].NormalizeVisibility()
+	}
+}
@@ -0,0 +1,100 @@
+package data
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// testTicketID es el ticket que crea newMessagesTestStore
+const testTicketID = "TICKET-TEST-1"
+
+// newMessagesTestStore crea un almacén en un directorio temporal con un ticket que tiene
+// una respuesta pública, una nota interna, un evento del sistema y una nota anterior al
+// campo de visibilidad
+func newMessagesTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	store := newTestStore(t)
+	now := time.Now()
+	err := store.CreateTicket(models.Ticket{
+		ID:     testTicketID,
+		Title:  "Consulta",
+		Status: "open",
+		Source: "widget",
+		Messages: []models.Message{
+			{ID: "public", Content: "Hola, ¿en qué podemos ayudarle?", Visibility: models.MessageVisibilityPublic, Timestamp: now},
+			{ID: "internal", Content: "Nota interna", Visibility: models.MessageVisibilityInternal, Timestamp: now},
+			{ID: "system", Content: "Ticket asignado", Visibility: models.MessageVisibilitySystem, Timestamp: now},
+			{ID: "legacy-note", Content: "Nota antigua", IsInternal: true, Timestamp: now},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTicket: %v", err)
+	}
+	return store
+}
+
+// messageIDs devuelve los IDs de los mensajes en orden
+func messageIDs(messages []models.Message) []string {
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+	return ids
+}
+
+func TestGetTicketMessagesAudience(t *testing.T) {
+	store := newMessagesTestStore(t)
+
+	tests := []struct {
+		audience string
+		want     []string
+	}{
+		{models.MessageAudienceCustomer, []string{"public"}},
+		{"", []string{"public"}},
+		{models.MessageAudienceAgent, []string{"public", "internal", "system", "legacy-note"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.audience, func(t *testing.T) {
+			messages, err := store.GetTicketMessages(testTicketID, tt.audience)
+			if err != nil {
+				t.Fatalf("GetTicketMessages: %v", err)
+			}
+			if got := messageIDs(messages); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetTicketMessages(%q) = %v, se esperaba %v", tt.audience, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddTicketMessageKeepsNotesFromCustomers(t *testing.T) {
+	store := newMessagesTestStore(t)
+
+	note := models.Message{ID: "new-note", Content: "Otra nota", Visibility: models.MessageVisibilityInternal, Timestamp: time.Now()}
+	if err := store.AddTicketMessage(testTicketID, note); err != nil {
+		t.Fatalf("AddTicketMessage: %v", err)
+	}
+
+	// Tras recargar desde archivo la nota sigue oculta para el cliente
+	reloaded := NewStore(filepath.Dir(store.TicketsFile))
+	messages, err := reloaded.GetTicketMessages(testTicketID, models.MessageAudienceCustomer)
+	if err != nil {
+		t.Fatalf("GetTicketMessages: %v", err)
+	}
+	if got := messageIDs(messages); !reflect.DeepEqual(got, []string{"public"}) {
+		t.Errorf("el cliente recibió %v, se esperaba solo [public]", got)
+	}
+
+	agent, err := reloaded.GetTicketMessages(testTicketID, models.MessageAudienceAgent)
+	if err != nil {
+		t.Fatalf("GetTicketMessages: %v", err)
+	}
+	if got := messageIDs(agent); len(got) != 5 || got[4] != "new-note" {
+		t.Errorf("el agente recibió %v, se esperaba la nota nueva al final", got)
+	}
+}
@@ -0,0 +1,126 @@
+package data
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/customfields"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/tags"
+)
+
+// priorityRank ordena las prioridades de menor a mayor urgencia
+var priorityRank = map[string]int{
+	"low":    1,
+	"medium": 2,
+	"high":   3,
+	"urgent": 4,
+}
+
+// QueryTickets devuelve los tickets que cumplen el filtro, en el orden solicitado
+func (s *Store) QueryTickets(filter models.TicketFilter) ([]models.Ticket, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var watched map[string]bool
+	if filter.WatchedBy != "" {
+		watched = s.watchedTicketsLocked(filter.WatchedBy)
+	}
+
+	tickets := make([]models.Ticket, 0)
+	for _, ticket := range s.Tickets {
+		if watched != nil && !watched[ticket.ID] {
+			continue
+		}
+		if matchesTicketFilter(ticket, filter) {
+			tickets = append(tickets, copyTicket(ticket))
+		}
+	}
+
+	sortTickets(tickets, filter)
+
+	return tickets, nil
+}
+
+// matchesTicketFilter indica si un ticket cumple todos los criterios del filtro
+func matchesTicketFilter(ticket models.Ticket, filter models.TicketFilter) bool {
+	if filter.Status != "" && ticket.Status != filter.Status {
+		return false
+	}
+	if filter.Priority != "" && !strings.EqualFold(ticket.Priority, filter.Priority) {
+		return false
+	}
+	if filter.CategoryID != "" && ticket.CategoryID != filter.CategoryID {
+		return false
+	}
+	if filter.AssignedTo != "" && ticket.AssignedTo != filter.AssignedTo {
+		return false
+	}
+	if filter.Department != "" && ticket.Department != filter.Department {
+		return false
+	}
+	if filter.Source != "" && ticket.Source != filter.Source {
+		return false
+	}
+
+	for _, name := range filter.Tags {
+		if !tags.Contains(ticket.Tags, name) {
+			return false
+		}
+	}
+
+	for _, cf := range filter.CustomFields {
+		if !customfields.Matches(ticket.CustomFields[cf.Key], cf) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sortTickets ordena los tickets según el filtro; por defecto, los más recientes primero
+func sortTickets(tickets []models.Ticket, filter models.TicketFilter) {
+	sortBy := filter.SortBy
+	desc := filter.SortDesc
+	if sortBy == "" {
+		sortBy = "createdAt"
+		desc = true
+	}
+
+	less := func(a, b models.Ticket) (int, bool) {
+		if filter.SortField != nil {
+			x, okA := a.CustomFields[filter.SortField.Key]
+			y, okB := b.CustomFields[filter.SortField.Key]
+			// Los tickets sin valor van siempre al final
+			if !okA || !okB {
+				return 0, false
+			}
+			return customfields.Compare(filter.SortField.Type, x, y)
+		}
+
+		switch sortBy {
+		case "updatedAt":
+			return a.UpdatedAt.Compare(b.UpdatedAt), true
+		case "priority":
+			return priorityRank[strings.ToLower(a.Priority)] - priorityRank[strings.ToLower(b.Priority)], true
+		case "status":
+			return strings.Compare(a.Status, b.Status), true
+		case "title":
+			return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)), true
+		}
+		return a.CreatedAt.Compare(b.CreatedAt), true
+	}
+
+	sort.SliceStable(tickets, func(i, j int) bool {
+		cmp, ok := less(tickets[i], tickets[j])
+		if !ok {
+			_, hasI := tickets[i].CustomFields[filter.SortField.Key]
+			_, hasJ := tickets[j].CustomFields[filter.SortField.Key]
+			return hasI && !hasJ
+		}
+		if desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+}
@@ -0,0 +1,143 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadTicketWatchers carga los seguidores de tickets desde archivo
+func (s *Store) loadTicketWatchers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.TicketWatchers = make([]models.TicketWatcher, 0)
+	if loadJSONFile(s.TicketWatchersFile, &s.TicketWatchers) {
+		fmt.Printf("Cargados %d seguidores de tickets desde archivo\n", len(s.TicketWatchers))
+	}
+}
+
+// GetTicketWatchers devuelve los seguidores de un ticket en el orden en que se añadieron
+func (s *Store) GetTicketWatchers(ticketID string) ([]models.TicketWatcher, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.TicketWatcher, 0)
+	for _, watcher := range s.TicketWatchers {
+		if watcher.TicketID == ticketID {
+			result = append(result, watcher)
+		}
+	}
+
+	return result, nil
+}
+
+// AddTicketWatchers añade seguidores a un ticket y devuelve los que no lo seguían ya
+func (s *Store) AddTicketWatchers(ticketID string, userIDs []string, addedBy string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findTicketLocked(ticketID) < 0 {
+		return nil, fmt.Errorf("Ticket no encontrado: %s", ticketID)
+	}
+
+	added := s.addTicketWatchersLocked(ticketID, userIDs, addedBy, time.Now())
+	if len(added) == 0 {
+		return added, nil
+	}
+	return added, writeJSONFile(s.TicketWatchersFile, s.TicketWatchers)
+}
+
+// RemoveTicketWatcher deja de seguir un ticket
+func (s *Store) RemoveTicketWatcher(ticketID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, watcher := range s.TicketWatchers {
+		if watcher.TicketID == ticketID && watcher.UserID == userID {
+			s.TicketWatchers = append(s.TicketWatchers[:i], s.TicketWatchers[i+1:]...)
+			return writeJSONFile(s.TicketWatchersFile, s.TicketWatchers)
+		}
+	}
+
+	return fmt.Errorf("el usuario %s no sigue el ticket %s", userID, ticketID)
+}
+
+// addTicketWatchersLocked añade los seguidores que falten y los devuelve.
+// El llamador debe tener el mutex y guardar el archivo de seguidores.
+func (s *Store) addTicketWatchersLocked(ticketID string, userIDs []string, addedBy string, now time.Time) []string {
+	existing := make(map[string]bool)
+	for _, watcher := range s.TicketWatchers {
+		if watcher.TicketID == ticketID {
+			existing[watcher.UserID] = true
+		}
+	}
+
+	added := make([]string, 0)
+	for _, userID := range userIDs {
+		if userID == "" || existing[userID] {
+			continue
+		}
+		existing[userID] = true
+		s.TicketWatchers = append(s.TicketWatchers, models.TicketWatcher{
+			TicketID:  ticketID,
+			UserID:    userID,
+			AddedBy:   addedBy,
+			CreatedAt: now,
+		})
+		added = append(added, userID)
+	}
+	return added
+}
+
+// watchedTicketsLocked devuelve el conjunto de tickets que sigue un usuario.
+// El llamador debe tener el mutex.
+func (s *Store) watchedTicketsLocked(userID string) map[string]bool {
+	watched := make(map[string]bool)
+	for _, watcher := range s.TicketWatchers {
+		if watcher.UserID == userID {
+			watched[watcher.TicketID] = true
+		}
+	}
+	return watched
+}
+
+// moveTicketWatchersLocked pasa los seguidores de los tickets origen al destino de una
+// fusión. El llamador debe tener el mutex y guardar el archivo de seguidores.
+func (s *Store) moveTicketWatchersLocked(targetID string, sourceIDs []string, now time.Time) {
+	sources := make(map[string]bool, len(sourceIDs))
+	for _, id := range sourceIDs {
+		sources[id] = true
+	}
+
+	remaining := make([]models.TicketWatcher, 0, len(s.TicketWatchers))
+	moved := make([]models.TicketWatcher, 0)
+	for _, watcher := range s.TicketWatchers {
+		if sources[watcher.TicketID] {
+			moved = append(moved, watcher)
+			continue
+		}
+		remaining = append(remaining, watcher)
+	}
+	s.TicketWatchers = remaining
+
+	for _, watcher := range moved {
+		s.addTicketWatchersLocked(targetID, []string{watcher.UserID}, watcher.AddedBy, now)
+	}
+}
+
+// removeTicketWatchersLocked quita los seguidores de los tickets eliminados y devuelve si hubo
+// cambios. El llamador debe tener el mutex y guardar el archivo de seguidores.
+func (s *Store) removeTicketWatchersLocked(deleted map[string]bool) bool {
+	remaining := make([]models.TicketWatcher, 0, len(s.TicketWatchers))
+	for _, watcher := range s.TicketWatchers {
+		if !deleted[watcher.TicketID] {
+			remaining = append(remaining, watcher)
+		}
+	}
+
+	changed := len(remaining) != len(s.TicketWatchers)
+	s.TicketWatchers = remaining
+	return changed
+}
@@ -0,0 +1,165 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// loadTwoFactors carga la autenticación en dos pasos de los usuarios desde archivo
+func (s *Store) loadTwoFactors() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.TwoFactors = make([]models.TwoFactor, 0)
+	if loadJSONFile(s.TwoFactorFile, &s.TwoFactors) {
+		fmt.Printf("Cargadas %d configuraciones de dos pasos desde archivo\n", len(s.TwoFactors))
+	}
+}
+
+// GetTwoFactor obtiene la autenticación en dos pasos del usuario. Un usuario sin
+// configuración guardada la recibe desactivada.
+func (s *Store) GetTwoFactor(userID string) (*models.TwoFactor, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.findUserLocked(userID) < 0 {
+		return nil, fmt.Errorf("usuario con ID %s no encontrado", userID)
+	}
+	if i := s.findTwoFactorLocked(userID); i >= 0 {
+		twoFactor := copyTwoFactor(s.TwoFactors[i])
+		return &twoFactor, nil
+	}
+	return &models.TwoFactor{UserID: userID}, nil
+}
+
+// SaveTwoFactor crea o reemplaza la autenticación en dos pasos de un usuario
+func (s *Store) SaveTwoFactor(twoFactor models.TwoFactor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findUserLocked(twoFactor.UserID) < 0 {
+		return fmt.Errorf("usuario con ID %s no encontrado", twoFactor.UserID)
+	}
+
+	twoFactor = copyTwoFactor(twoFactor)
+	twoFactor.UpdatedAt = time.Now()
+
+	if i := s.findTwoFactorLocked(twoFactor.UserID); i >= 0 {
+		previous := s.TwoFactors[i]
+		s.TwoFactors[i] = twoFactor
+		if err := s.saveTwoFactorsLocked(); err != nil {
+			s.TwoFactors[i] = previous
+			return err
+		}
+		return nil
+	}
+
+	s.TwoFactors = append(s.TwoFactors, twoFactor)
+	if err := s.saveTwoFactorsLocked(); err != nil {
+		s.TwoFactors = s.TwoFactors[:len(s.TwoFactors)-1]
+		return err
+	}
+	return nil
+}
+
+// DeleteTwoFactor elimina la autenticación en dos pasos de un usuario si existe
+func (s *Store) DeleteTwoFactor(userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.removeTwoFactorLocked(userID)
+}
+
+// ConsumeTwoFactorStep registra el intervalo TOTP de un código aceptado. Falla si ese
+// intervalo o uno posterior ya se usó, para que un código no sirva dos veces.
+func (s *Store) ConsumeTwoFactorStep(userID string, step int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findTwoFactorLocked(userID)
+	if i < 0 {
+		return fmt.Errorf("el usuario %s no tiene autenticación en dos pasos", userID)
+	}
+	if step <= s.TwoFactors[i].LastUsedStep {
+		return fmt.Errorf("el código ya se usó")
+	}
+
+	previous := s.TwoFactors[i]
+	s.TwoFactors[i].LastUsedStep = step
+	s.TwoFactors[i].UpdatedAt = time.Now()
+	if err := s.saveTwoFactorsLocked(); err != nil {
+		s.TwoFactors[i] = previous
+		return err
+	}
+	return nil
+}
+
+// ConsumeRecoveryCode elimina el código de recuperación con ese hash. Falla si no existe.
+func (s *Store) ConsumeRecoveryCode(userID, codeHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.findTwoFactorLocked(userID)
+	if i < 0 {
+		return fmt.Errorf("el usuario %s no tiene autenticación en dos pasos", userID)
+	}
+
+	previous := s.TwoFactors[i]
+	codes := make([]string, 0, len(previous.RecoveryCodes))
+	found := false
+	for _, code := range previous.RecoveryCodes {
+		if !found && code == codeHash {
+			found = true
+			continue
+		}
+		codes = append(codes, code)
+	}
+	if !found {
+		return fmt.Errorf("código de recuperación no encontrado")
+	}
+
+	s.TwoFactors[i].RecoveryCodes = codes
+	s.TwoFactors[i].UpdatedAt = time.Now()
+	if err := s.saveTwoFactorsLocked(); err != nil {
+		s.TwoFactors[i] = previous
+		return err
+	}
+	return nil
+}
+
+// findTwoFactorLocked devuelve la posición de la configuración del usuario o -1; el
+// llamador debe tener s.mu
+func (s *Store) findTwoFactorLocked(userID string) int {
+	for i, twoFactor := range s.TwoFactors {
+		if twoFactor.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeTwoFactorLocked elimina la configuración del usuario si existe; el llamador debe
+// tener s.mu
+func (s *Store) removeTwoFactorLocked(userID string) error {
+	i := s.findTwoFactorLocked(userID)
+	if i < 0 {
+		return nil
+	}
+	s.TwoFactors = append(s.TwoFactors[:i], s.TwoFactors[i+1:]...)
+	return s.saveTwoFactorsLocked()
+}
+
+// saveTwoFactorsLocked guarda la autenticación en dos pasos; el llamador debe tener s.mu
+func (s *Store) saveTwoFactorsLocked() error {
+	return writeJSONFile(s.TwoFactorFile, s.TwoFactors)
+}
+
+// copyTwoFactor copia la configuración con su propia lista de códigos de recuperación
+func copyTwoFactor(twoFactor models.TwoFactor) models.TwoFactor {
+	codes := make([]string, len(twoFactor.RecoveryCodes))
+	copy(codes, twoFactor.RecoveryCodes)
+	twoFactor.RecoveryCodes = codes
+	return twoFactor
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/kb"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
 )
 
@@ -132,9 +133,9 @@ func MigrateTicketsFromJSON(db *sql.DB, jsonPath string) error {
 	// Preparar statement para mensajes
 	messageStmt, err := tx.Prepare(`
 		INSERT INTO messages (
-			id, ticket_id, content, is_client, is_internal, created_at, user_id, user_name, user_email
+			id, ticket_id, content, is_client, is_internal, visibility, created_at, user_id, user_name, user_email
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 		) ON CONFLICT (id) DO NOTHING
 	`)
 	if err != nil {
@@ -182,6 +183,7 @@ func MigrateTicketsFromJSON(db *sql.DB, jsonPath string) error {
 			if message.ID == "" {
 				message.ID = uuid.New().String()
 			}
+			message.NormalizeVisibility()
 
 			// Usar timestamp como createdAt si está disponible
 			createdAt := message.CreatedAt
@@ -195,6 +197,7 @@ func MigrateTicketsFromJSON(db *sql.DB, jsonPath string) error {
 				message.Content,
 				message.IsClient,
 				message.IsInternal,
+				message.Visibility,
 				createdAt,
 				stringOrNil(message.UserID),
 				stringOrNil(message.UserName),
@@ -284,7 +287,8 @@ func MigrateCategoriesFromJSON(db *sql.DB, jsonPath string) error {
 	return nil
 }
 
-// MigrateFAQsFromJSON migra las FAQs desde el archivo JSON a la base de datos
+// MigrateFAQsFromJSON migra las FAQs del archivo JSON a la base de conocimiento. Solo se
+// aplica mientras no haya artículos, para no duplicarlos en cada arranque.
 func MigrateFAQsFromJSON(db *sql.DB, jsonPath string) error {
 	// Leer el archivo JSON
 	data, err := os.ReadFile(jsonPath)
@@ -303,50 +307,183 @@ func MigrateFAQsFromJSON(db *sql.DB, jsonPath string) error {
 	if err != nil {
 		return fmt.Errorf("error al iniciar transacción: %v", err)
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-			return
-		}
-	}()
+	defer tx.Rollback()
 
-	// Insertar FAQs en la base de datos
-	stmt, err := tx.Prepare(`
-		INSERT INTO faqs (
-			question, answer, category, is_published, created_at, updated_at
-		) VALUES (
-			$1, $2, $3, $4, $5, $6
-		) RETURNING id
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM kb_articles)").Scan(&exists); err != nil {
+		return fmt.Errorf("error al verificar artículos: %v", err)
+	}
+	if exists {
+		log.Println("La base de conocimiento ya tiene artículos; se omite la migración de faqs.json")
+		return nil
+	}
+
+	if _, err := insertLegacyFAQs(tx, faqs); err != nil {
+		return err
+	}
+
+	// Commit de la transacción
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	log.Printf("Migradas %d FAQs desde JSON a la base de conocimiento", len(faqs))
+	return nil
+}
+
+// MigrateLegacyFAQs convierte las filas de la tabla faqs en artículos de la base de
+// conocimiento. Las filas de faqs no se modifican: cada FAQ convertida se registra en
+// kb_legacy_faqs y no se vuelve a migrar en los arranques siguientes.
+func MigrateLegacyFAQs(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Evitar que dos réplicas que arrancan a la vez migren las mismas FAQs
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('kb_legacy_faqs'))"); err != nil {
+		return fmt.Errorf("error al bloquear migración de FAQs: %v", err)
+	}
+
+	rows, err := tx.Query(`
+		SELECT id, question, answer, category, is_published, created_at, updated_at
+		FROM faqs
+		WHERE id NOT IN (SELECT faq_id FROM kb_legacy_faqs)
+		ORDER BY id ASC
 	`)
 	if err != nil {
-		return fmt.Errorf("error al preparar statement: %v", err)
+		return fmt.Errorf("error al consultar FAQs: %v", err)
 	}
-	defer stmt.Close()
 
-	for _, faq := range faqs {
-		var id int
-		err := stmt.QueryRow(
-			faq.Question,
-			faq.Answer,
-			faq.Category,
-			faq.IsPublished,
-			faq.CreatedAt,
-			faq.UpdatedAt,
-		).Scan(&id)
+	faqs := make([]models.FAQ, 0)
+	for rows.Next() {
+		var faq models.FAQ
+		var category sql.NullString
+		var isPublished sql.NullBool
+		if err := rows.Scan(&faq.ID, &faq.Question, &faq.Answer, &category, &isPublished, &faq.CreatedAt, &faq.UpdatedAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("error al escanear FAQ: %v", err)
+		}
+		faq.Category = category.String
+		faq.IsPublished = isPublished.Bool
+		faqs = append(faqs, faq)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error al iterar FAQs: %v", err)
+	}
+	if len(faqs) == 0 {
+		return nil
+	}
+
+	articleIDs, err := insertLegacyFAQs(tx, faqs)
+	if err != nil {
+		return err
+	}
+	for i, faq := range faqs {
+		_, err := tx.Exec("INSERT INTO kb_legacy_faqs (faq_id, article_id) VALUES ($1, $2)", faq.ID, articleIDs[i])
 		if err != nil {
-			return fmt.Errorf("error al insertar FAQ %s: %v", faq.Question, err)
+			return fmt.Errorf("error al registrar FAQ migrada %d: %v", faq.ID, err)
 		}
 	}
 
-	// Commit de la transacción
-	if err = tx.Commit(); err != nil {
+	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("error al confirmar transacción: %v", err)
 	}
 
-	log.Printf("Migradas %d FAQs desde JSON a PostgreSQL", len(faqs))
+	log.Printf("Migradas %d FAQs a la base de conocimiento", len(faqs))
 	return nil
 }
 
+// insertLegacyFAQs guarda FAQs como artículos con su primera revisión y devuelve los IDs
+// de los artículos en el mismo orden. Conservan su ID salvo que ya esté ocupado, y el
+// slug se hace único añadiendo un sufijo.
+func insertLegacyFAQs(tx *sql.Tx, faqs []models.FAQ) ([]int, error) {
+	categories := make([]models.Category, 0)
+	rows, err := tx.Query("SELECT id, name FROM categories")
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar categorías: %v", err)
+	}
+	for rows.Next() {
+		var category models.Category
+		if err := rows.Scan(&category.ID, &category.Name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error al escanear categoría: %v", err)
+		}
+		categories = append(categories, category)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar categorías: %v", err)
+	}
+
+	articleIDs := make([]int, 0, len(faqs))
+	for _, faq := range faqs {
+		article, revision := kb.FromFAQ(faq, categories)
+
+		var queryErr error
+		article.Slug = kb.UniqueSlug(article.Slug, func(slug string) bool {
+			var taken bool
+			if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM kb_articles WHERE slug = $1)", slug).Scan(&taken); err != nil {
+				queryErr = err
+				return false
+			}
+			return taken
+		})
+		if queryErr != nil {
+			return nil, fmt.Errorf("error al verificar slug: %v", queryErr)
+		}
+
+		var idTaken bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM kb_articles WHERE id = $1)", article.ID).Scan(&idTaken); err != nil {
+			return nil, fmt.Errorf("error al verificar artículo: %v", err)
+		}
+		id := sql.NullInt64{Int64: int64(article.ID), Valid: article.ID > 0 && !idTaken}
+
+		err := tx.QueryRow(`
+			INSERT INTO kb_articles (
+				id, slug, title, body, category_id, legacy_category, status, revision,
+				published_revision, published_at, created_at, updated_at
+			) VALUES (COALESCE($1, nextval(pg_get_serial_sequence('kb_articles', 'id'))), $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING id
+		`,
+			id,
+			article.Slug,
+			article.Title,
+			article.Body,
+			stringOrNil(article.CategoryID),
+			stringOrNil(article.LegacyCategory),
+			article.Status,
+			article.Revision,
+			article.PublishedRevision,
+			article.PublishedAt,
+			article.CreatedAt,
+			article.UpdatedAt,
+		).Scan(&article.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error al migrar FAQ %s: %v", faq.Question, err)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO kb_article_revisions (article_id, revision, title, body, category_id, note, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, article.ID, revision.Revision, revision.Title, revision.Body, stringOrNil(revision.CategoryID), revision.Note, revision.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error al migrar revisión de la FAQ %s: %v", faq.Question, err)
+		}
+		articleIDs = append(articleIDs, article.ID)
+	}
+
+	// Los IDs conservados no avanzan la secuencia
+	_, err = tx.Exec(`SELECT setval(pg_get_serial_sequence('kb_articles', 'id'), COALESCE((SELECT MAX(id) FROM kb_articles), 0) + 1, false)`)
+	if err != nil {
+		return nil, fmt.Errorf("error al actualizar secuencia de artículos: %v", err)
+	}
+
+	return articleIDs, nil
+}
+
 // MigrateAllFromJSON migra todos los datos desde los archivos JSON a PostgreSQL
 func MigrateAllFromJSON(db *sql.DB, dataDir string) error {
 	// Verificar que el directorio existe
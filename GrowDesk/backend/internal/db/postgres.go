@@ -61,6 +61,11 @@ func InitializeSchema(db *sql.DB) error {
 	}
 
 	log.Println("Esquema de base de datos inicializado exitosamente")
+
+	// Convertir las FAQs anteriores en artículos de la base de conocimiento
+	if err := MigrateLegacyFAQs(db); err != nil {
+		return fmt.Errorf("error al migrar FAQs a la base de conocimiento: %v", err)
+	}
 	return nil
 }
 
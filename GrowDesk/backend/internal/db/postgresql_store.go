@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
@@ -17,7 +18,27 @@ type PostgreSQLStore struct {
 	userRepo       *repository.UserRepository
 	ticketRepo     *repository.TicketRepository
 	categoryRepo   *repository.CategoryRepository
-	faqRepo        *repository.FAQRepository
+	teamRepo       *repository.TeamRepository
+	agentRepo      *repository.AgentProfileRepository
+	inviteRepo     *repository.InvitationRepository
+	resetRepo      *repository.PasswordResetRepository
+	twoFactorRepo  *repository.TwoFactorRepository
+	articleRepo    *repository.ArticleRepository
+	translateRepo  *repository.ArticleTranslationRepository
+	deflectRepo    *repository.DeflectionRepository
+	feedbackRepo   *repository.FAQFeedbackRepository
+	hoursRepo      *repository.BusinessHoursRepository
+	formRepo       *repository.PreChatFormRepository
+	fieldRepo      *repository.CustomFieldRepository
+	tagRepo        *repository.TagRepository
+	activityRepo   *repository.ActivityRepository
+	linkRepo       *repository.TicketLinkRepository
+	watcherRepo    *repository.TicketWatcherRepository
+	macroRepo      *repository.MacroRepository
+	ruleRepo       *repository.AutomationRuleRepository
+	notifyRepo     *repository.NotificationRepository
+	leaseRepo      *repository.LeaseRepository
+	surveyRepo     *repository.SurveyRepository
 	wsConnections  map[string]map[string]*websocket.Conn
 	wsConnectionMu sync.Mutex
 }
@@ -29,7 +50,27 @@ func NewPostgreSQLStore(db *sql.DB) *PostgreSQLStore {
 		userRepo:      repository.NewUserRepository(db),
 		ticketRepo:    repository.NewTicketRepository(db),
 		categoryRepo:  repository.NewCategoryRepository(db),
-		faqRepo:       repository.NewFAQRepository(db),
+		teamRepo:      repository.NewTeamRepository(db),
+		agentRepo:     repository.NewAgentProfileRepository(db),
+		inviteRepo:    repository.NewInvitationRepository(db),
+		resetRepo:     repository.NewPasswordResetRepository(db),
+		twoFactorRepo: repository.NewTwoFactorRepository(db),
+		articleRepo:   repository.NewArticleRepository(db),
+		translateRepo: repository.NewArticleTranslationRepository(db),
+		deflectRepo:   repository.NewDeflectionRepository(db),
+		feedbackRepo:  repository.NewFAQFeedbackRepository(db),
+		hoursRepo:     repository.NewBusinessHoursRepository(db),
+		formRepo:      repository.NewPreChatFormRepository(db),
+		fieldRepo:     repository.NewCustomFieldRepository(db),
+		tagRepo:       repository.NewTagRepository(db),
+		activityRepo:  repository.NewActivityRepository(db),
+		linkRepo:      repository.NewTicketLinkRepository(db),
+		watcherRepo:   repository.NewTicketWatcherRepository(db),
+		macroRepo:     repository.NewMacroRepository(db),
+		ruleRepo:      repository.NewAutomationRuleRepository(db),
+		notifyRepo:    repository.NewNotificationRepository(db),
+		leaseRepo:     repository.NewLeaseRepository(db),
+		surveyRepo:    repository.NewSurveyRepository(db),
 		wsConnections: make(map[string]map[string]*websocket.Conn),
 	}
 }
@@ -65,6 +106,10 @@ func (s *PostgreSQLStore) GetTickets() ([]models.Ticket, error) {
 	return s.ticketRepo.GetAll()
 }
 
+func (s *PostgreSQLStore) QueryTickets(filter models.TicketFilter) ([]models.Ticket, error) {
+	return s.ticketRepo.Query(filter)
+}
+
 func (s *PostgreSQLStore) GetTicket(id string) (*models.Ticket, error) {
 	return s.ticketRepo.GetByID(id)
 }
@@ -87,6 +132,22 @@ func (s *PostgreSQLStore) AddTicketMessage(ticketID string, message models.Messa
 	return err
 }
 
+func (s *PostgreSQLStore) GetTicketMessages(ticketID, audience string) ([]models.Message, error) {
+	return s.ticketRepo.GetMessages(ticketID, audience)
+}
+
+func (s *PostgreSQLStore) BulkUpdateTickets(ticketIDs []string, changes models.BulkTicketChanges, activity models.Activity, transactional bool) ([]models.BulkTicketResult, error) {
+	return s.ticketRepo.BulkUpdate(ticketIDs, changes, activity, transactional)
+}
+
+func (s *PostgreSQLStore) MergeTickets(targetID string, sourceIDs []string, activity models.Activity) (*models.Ticket, error) {
+	return s.ticketRepo.Merge(targetID, sourceIDs, activity)
+}
+
+func (s *PostgreSQLStore) SplitTicket(sourceID string, messageIDs []string, ticket models.Ticket, activity models.Activity) (*models.Ticket, error) {
+	return s.ticketRepo.Split(sourceID, messageIDs, ticket, activity)
+}
+
 // Implementación de métodos para categorías
 func (s *PostgreSQLStore) GetCategories() ([]models.Category, error) {
 	return s.categoryRepo.GetAll()
@@ -109,34 +170,441 @@ func (s *PostgreSQLStore) DeleteCategory(id string) error {
 	return s.categoryRepo.Delete(id)
 }
 
-// Implementación de métodos para FAQs
-func (s *PostgreSQLStore) GetFAQs() ([]models.FAQ, error) {
-	return s.faqRepo.GetAll()
+func (s *PostgreSQLStore) CountCategoryTickets(id string) (int, error) {
+	return s.categoryRepo.CountTickets(id)
+}
+
+func (s *PostgreSQLStore) DeleteCategoryReassigning(id string, target models.Category) (int, error) {
+	return s.categoryRepo.DeleteReassigning(id, target)
+}
+
+// Implementación de métodos para equipos de agentes
+func (s *PostgreSQLStore) GetTeams() ([]models.Team, error) {
+	return s.teamRepo.GetAll()
+}
+
+func (s *PostgreSQLStore) GetTeam(id string) (*models.Team, error) {
+	return s.teamRepo.GetByID(id)
+}
+
+func (s *PostgreSQLStore) CreateTeam(team models.Team) error {
+	return s.teamRepo.Create(team)
+}
+
+func (s *PostgreSQLStore) UpdateTeam(team models.Team) error {
+	return s.teamRepo.Update(team)
+}
+
+func (s *PostgreSQLStore) DeleteTeam(id string) error {
+	return s.teamRepo.Delete(id)
+}
+
+func (s *PostgreSQLStore) CountTeamReferences(id string) (int, int, error) {
+	return s.teamRepo.CountReferences(id)
+}
+
+func (s *PostgreSQLStore) DeleteTeamReassigning(id string, target models.Team) (int, error) {
+	return s.teamRepo.DeleteReassigning(id, target)
+}
+
+func (s *PostgreSQLStore) AddTeamMember(teamID string, member models.TeamMember) error {
+	return s.teamRepo.AddMember(teamID, member)
+}
+
+func (s *PostgreSQLStore) RemoveTeamMember(teamID, userID string) error {
+	return s.teamRepo.RemoveMember(teamID, userID)
+}
+
+// Implementación de métodos para perfiles de agentes
+func (s *PostgreSQLStore) GetAgentProfiles() ([]models.AgentProfile, error) {
+	return s.agentRepo.GetAll()
+}
+
+func (s *PostgreSQLStore) GetAgentProfile(userID string) (*models.AgentProfile, error) {
+	return s.agentRepo.GetByUserID(userID)
+}
+
+func (s *PostgreSQLStore) SaveAgentProfile(profile models.AgentProfile) error {
+	return s.agentRepo.Save(profile)
+}
+
+// Implementación de métodos para invitaciones de usuarios
+func (s *PostgreSQLStore) GetInvitations() ([]models.Invitation, error) {
+	return s.inviteRepo.GetAll()
+}
+
+func (s *PostgreSQLStore) GetInvitation(id string) (*models.Invitation, error) {
+	return s.inviteRepo.GetByID(id)
+}
+
+func (s *PostgreSQLStore) GetInvitationByTokenHash(hash string) (*models.Invitation, error) {
+	return s.inviteRepo.GetByTokenHash(hash)
+}
+
+func (s *PostgreSQLStore) CreateInvitation(invitation models.Invitation) error {
+	return s.inviteRepo.Create(invitation)
+}
+
+func (s *PostgreSQLStore) UpdateInvitation(invitation models.Invitation) error {
+	return s.inviteRepo.Update(invitation)
+}
+
+func (s *PostgreSQLStore) AcceptInvitation(id string, user models.User) error {
+	return s.inviteRepo.Accept(id, user)
+}
+
+// Implementación de métodos para contraseñas y sesiones de usuarios
+func (s *PostgreSQLStore) GetUserPasswordHash(id string) (string, error) {
+	return s.userRepo.GetPasswordHash(id)
+}
+
+func (s *PostgreSQLStore) UpdateUserPassword(id, passwordHash string) error {
+	return s.userRepo.UpdatePassword(id, passwordHash)
+}
+
+func (s *PostgreSQLStore) GetUserSessionVersion(id string) (int, error) {
+	return s.userRepo.GetSessionVersion(id)
+}
+
+func (s *PostgreSQLStore) CreatePasswordReset(reset models.PasswordReset) error {
+	return s.resetRepo.Create(reset)
+}
+
+func (s *PostgreSQLStore) GetPasswordResetByTokenHash(hash string) (*models.PasswordReset, error) {
+	return s.resetRepo.GetByTokenHash(hash)
+}
+
+func (s *PostgreSQLStore) ResetPassword(resetID, passwordHash string) error {
+	return s.resetRepo.Consume(resetID, passwordHash)
+}
+
+// Implementación de métodos para autenticación en dos pasos
+func (s *PostgreSQLStore) GetTwoFactor(userID string) (*models.TwoFactor, error) {
+	return s.twoFactorRepo.GetByUserID(userID)
+}
+
+func (s *PostgreSQLStore) SaveTwoFactor(twoFactor models.TwoFactor) error {
+	return s.twoFactorRepo.Save(twoFactor)
+}
+
+func (s *PostgreSQLStore) DeleteTwoFactor(userID string) error {
+	return s.twoFactorRepo.Delete(userID)
+}
+
+func (s *PostgreSQLStore) ConsumeTwoFactorStep(userID string, step int64) error {
+	return s.twoFactorRepo.ConsumeStep(userID, step)
+}
+
+func (s *PostgreSQLStore) ConsumeRecoveryCode(userID, codeHash string) error {
+	return s.twoFactorRepo.ConsumeRecoveryCode(userID, codeHash)
+}
+
+// Implementación de métodos para la base de conocimiento
+func (s *PostgreSQLStore) GetArticles(filter models.ArticleFilter) ([]models.Article, error) {
+	return s.articleRepo.GetAll(filter)
+}
+
+func (s *PostgreSQLStore) GetPublishedArticles() ([]models.Article, error) {
+	return s.articleRepo.GetPublished()
+}
+
+func (s *PostgreSQLStore) GetArticle(id int) (*models.Article, error) {
+	return s.articleRepo.GetByID(id)
+}
+
+func (s *PostgreSQLStore) GetArticleBySlug(slug string) (*models.Article, error) {
+	return s.articleRepo.GetBySlug(slug)
+}
+
+func (s *PostgreSQLStore) CreateArticle(article models.Article, revision models.ArticleRevision) (*models.Article, error) {
+	return s.articleRepo.Create(article, revision)
+}
+
+func (s *PostgreSQLStore) UpdateArticle(article models.Article, revision *models.ArticleRevision) error {
+	return s.articleRepo.Update(article, revision)
+}
+
+func (s *PostgreSQLStore) DeleteArticle(id int) error {
+	return s.articleRepo.Delete(id)
+}
+
+func (s *PostgreSQLStore) GetArticleRevisions(articleID int) ([]models.ArticleRevision, error) {
+	return s.articleRepo.GetRevisions(articleID)
+}
+
+func (s *PostgreSQLStore) GetArticleRevision(articleID, revision int) (*models.ArticleRevision, error) {
+	return s.articleRepo.GetRevision(articleID, revision)
+}
+
+func (s *PostgreSQLStore) GetArticleTranslations(articleID int) ([]models.ArticleTranslation, error) {
+	return s.translateRepo.GetByArticle(articleID)
+}
+
+func (s *PostgreSQLStore) GetTranslationsByLocale(locale string) ([]models.ArticleTranslation, error) {
+	return s.translateRepo.GetByLocale(locale)
+}
+
+func (s *PostgreSQLStore) GetArticleTranslation(articleID int, locale string) (*models.ArticleTranslation, error) {
+	return s.translateRepo.GetByID(articleID, locale)
+}
+
+func (s *PostgreSQLStore) SaveArticleTranslation(translation models.ArticleTranslation) error {
+	return s.translateRepo.Save(translation)
+}
+
+func (s *PostgreSQLStore) DeleteArticleTranslation(articleID int, locale string) error {
+	return s.translateRepo.Delete(articleID, locale)
+}
+
+// Implementación de métodos para las FAQs sugeridas en el widget
+func (s *PostgreSQLStore) RecordFAQSuggestions(deflections []models.FAQDeflection) error {
+	return s.deflectRepo.Record(deflections)
+}
+
+func (s *PostgreSQLStore) MarkFAQSolved(sessionID string, articleID int, solvedAt time.Time) (*models.FAQDeflection, error) {
+	return s.deflectRepo.MarkSolved(sessionID, articleID, solvedAt)
+}
+
+func (s *PostgreSQLStore) GetFAQDeflections(filter models.DeflectionFilter) ([]models.FAQDeflection, error) {
+	return s.deflectRepo.GetAll(filter)
 }
 
-func (s *PostgreSQLStore) GetFAQsByStatus(published bool) ([]models.FAQ, error) {
-	return s.faqRepo.GetByStatus(published)
+// Implementación de métodos para las visitas y los votos de las FAQs
+func (s *PostgreSQLStore) RecordFAQView(view models.FAQView) (*models.FAQView, error) {
+	return s.feedbackRepo.RecordView(view)
 }
 
-func (s *PostgreSQLStore) GetFAQ(id int) (*models.FAQ, error) {
-	return s.faqRepo.GetByID(id)
+func (s *PostgreSQLStore) LinkFAQViewsToTicket(sessionID, ticketID string) error {
+	return s.feedbackRepo.LinkViewsToTicket(sessionID, ticketID)
 }
 
-func (s *PostgreSQLStore) CreateFAQ(faq models.FAQ) error {
-	_, err := s.faqRepo.Create(faq)
+func (s *PostgreSQLStore) GetFAQViews(filter models.FAQActivityFilter) ([]models.FAQView, error) {
+	return s.feedbackRepo.GetViews(filter)
+}
+
+func (s *PostgreSQLStore) SaveFAQVote(vote models.FAQVote) (*models.FAQVote, error) {
+	return s.feedbackRepo.SaveVote(vote)
+}
+
+func (s *PostgreSQLStore) GetFAQVotes(filter models.FAQActivityFilter) ([]models.FAQVote, error) {
+	return s.feedbackRepo.GetVotes(filter)
+}
+
+// Implementación de métodos para horarios de atención
+func (s *PostgreSQLStore) GetBusinessHours() ([]models.BusinessHours, error) {
+	return s.hoursRepo.GetAll()
+}
+
+func (s *PostgreSQLStore) GetBusinessHoursByID(id string) (*models.BusinessHours, error) {
+	return s.hoursRepo.GetByID(id)
+}
+
+func (s *PostgreSQLStore) CreateBusinessHours(hours models.BusinessHours) error {
+	_, err := s.hoursRepo.Create(hours)
+	return err
+}
+
+func (s *PostgreSQLStore) UpdateBusinessHours(hours models.BusinessHours) error {
+	return s.hoursRepo.Update(hours)
+}
+
+func (s *PostgreSQLStore) DeleteBusinessHours(id string) error {
+	return s.hoursRepo.Delete(id)
+}
+
+// Implementación de métodos para formularios previos al chat
+func (s *PostgreSQLStore) GetPreChatForms() ([]models.PreChatForm, error) {
+	return s.formRepo.GetAll()
+}
+
+func (s *PostgreSQLStore) GetPreChatForm(id string) (*models.PreChatForm, error) {
+	return s.formRepo.GetByID(id)
+}
+
+func (s *PostgreSQLStore) GetPreChatFormByWidget(widgetID string) (*models.PreChatForm, error) {
+	return s.formRepo.GetByWidget(widgetID)
+}
+
+func (s *PostgreSQLStore) CreatePreChatForm(form models.PreChatForm) error {
+	_, err := s.formRepo.Create(form)
+	return err
+}
+
+func (s *PostgreSQLStore) UpdatePreChatForm(form models.PreChatForm) error {
+	return s.formRepo.Update(form)
+}
+
+func (s *PostgreSQLStore) DeletePreChatForm(id string) error {
+	return s.formRepo.Delete(id)
+}
+
+// Implementación de métodos para campos personalizados de tickets
+func (s *PostgreSQLStore) GetCustomFieldDefinitions() ([]models.CustomFieldDefinition, error) {
+	return s.fieldRepo.GetAll()
+}
+
+func (s *PostgreSQLStore) GetCustomFieldDefinition(id string) (*models.CustomFieldDefinition, error) {
+	return s.fieldRepo.GetByID(id)
+}
+
+func (s *PostgreSQLStore) CreateCustomFieldDefinition(def models.CustomFieldDefinition) error {
+	_, err := s.fieldRepo.Create(def)
+	return err
+}
+
+func (s *PostgreSQLStore) UpdateCustomFieldDefinition(def models.CustomFieldDefinition) error {
+	return s.fieldRepo.Update(def)
+}
+
+func (s *PostgreSQLStore) DeleteCustomFieldDefinition(id string) error {
+	return s.fieldRepo.Delete(id)
+}
+
+// Implementación de métodos para etiquetas de tickets
+func (s *PostgreSQLStore) GetTags(prefix string, limit int) ([]models.Tag, error) {
+	return s.tagRepo.Search(prefix, limit)
+}
+
+func (s *PostgreSQLStore) AddTicketTags(ticketID string, names []string) ([]string, error) {
+	return s.tagRepo.AddToTicket(ticketID, names)
+}
+
+func (s *PostgreSQLStore) RemoveTicketTags(ticketID string, names []string) ([]string, error) {
+	return s.tagRepo.RemoveFromTicket(ticketID, names)
+}
+
+func (s *PostgreSQLStore) GetTagReport() ([]models.TagReport, error) {
+	return s.tagRepo.Report()
+}
+
+// Implementación de métodos para relaciones entre tickets
+func (s *PostgreSQLStore) GetTicketLinks(ticketID string) ([]models.TicketLink, error) {
+	return s.linkRepo.GetByTicket(ticketID)
+}
+
+func (s *PostgreSQLStore) CreateTicketLink(link models.TicketLink) error {
+	return s.linkRepo.Create(link)
+}
+
+func (s *PostgreSQLStore) DeleteTicketLink(id string) error {
+	return s.linkRepo.Delete(id)
+}
+
+// Implementación de métodos para seguidores de tickets
+func (s *PostgreSQLStore) GetTicketWatchers(ticketID string) ([]models.TicketWatcher, error) {
+	return s.watcherRepo.GetByTicket(ticketID)
+}
+
+func (s *PostgreSQLStore) AddTicketWatchers(ticketID string, userIDs []string, addedBy string) ([]string, error) {
+	return s.watcherRepo.Add(ticketID, userIDs, addedBy)
+}
+
+func (s *PostgreSQLStore) RemoveTicketWatcher(ticketID, userID string) error {
+	return s.watcherRepo.Remove(ticketID, userID)
+}
+
+// Implementación de métodos para macros de agentes
+func (s *PostgreSQLStore) GetMacros() ([]models.Macro, error) {
+	return s.macroRepo.GetAll()
+}
+
+func (s *PostgreSQLStore) GetMacro(id string) (*models.Macro, error) {
+	return s.macroRepo.GetByID(id)
+}
+
+func (s *PostgreSQLStore) CreateMacro(macro models.Macro) error {
+	_, err := s.macroRepo.Create(macro)
 	return err
 }
 
-func (s *PostgreSQLStore) UpdateFAQ(faq models.FAQ) error {
-	return s.faqRepo.Update(faq)
+func (s *PostgreSQLStore) UpdateMacro(macro models.Macro) error {
+	return s.macroRepo.Update(macro)
+}
+
+func (s *PostgreSQLStore) DeleteMacro(id string) error {
+	return s.macroRepo.Delete(id)
+}
+
+// Implementación de métodos para reglas de automatización
+func (s *PostgreSQLStore) GetAutomationRules() ([]models.AutomationRule, error) {
+	return s.ruleRepo.GetAll()
+}
+
+func (s *PostgreSQLStore) GetAutomationRule(id string) (*models.AutomationRule, error) {
+	return s.ruleRepo.GetByID(id)
+}
+
+func (s *PostgreSQLStore) CreateAutomationRule(rule models.AutomationRule) error {
+	_, err := s.ruleRepo.Create(rule)
+	return err
+}
+
+func (s *PostgreSQLStore) UpdateAutomationRule(rule models.AutomationRule) error {
+	return s.ruleRepo.Update(rule)
+}
+
+func (s *PostgreSQLStore) DeleteAutomationRule(id string) error {
+	return s.ruleRepo.Delete(id)
 }
 
-func (s *PostgreSQLStore) DeleteFAQ(id int) error {
-	return s.faqRepo.Delete(id)
+func (s *PostgreSQLStore) GetAutomationRuleRuns() ([]models.AutomationRuleRun, error) {
+	return s.ruleRepo.GetRuns()
 }
 
-func (s *PostgreSQLStore) ToggleFAQPublish(id int) error {
-	return s.faqRepo.TogglePublish(id)
+func (s *PostgreSQLStore) ClaimAutomationRuleRun(ruleID, ticketID string) (bool, error) {
+	return s.ruleRepo.ClaimRun(ruleID, ticketID)
+}
+
+func (s *PostgreSQLStore) DeleteAutomationRuleRuns(runs []models.AutomationRuleRun) error {
+	return s.ruleRepo.DeleteRuns(runs)
+}
+
+// Implementación de métodos para encuestas de satisfacción
+func (s *PostgreSQLStore) GetSurveys(filter models.SurveyFilter) ([]models.SatisfactionSurvey, error) {
+	return s.surveyRepo.GetAll(filter)
+}
+
+func (s *PostgreSQLStore) GetSurveyByTokenHash(tokenHash string) (*models.SatisfactionSurvey, error) {
+	return s.surveyRepo.GetByTokenHash(tokenHash)
+}
+
+func (s *PostgreSQLStore) CreateSurvey(survey models.SatisfactionSurvey) error {
+	return s.surveyRepo.Create(survey)
+}
+
+func (s *PostgreSQLStore) UpdateSurveyTokenHash(id, tokenHash string) error {
+	return s.surveyRepo.UpdateTokenHash(id, tokenHash)
+}
+
+func (s *PostgreSQLStore) AnswerSurvey(tokenHash string, rating int, comment string) (*models.SatisfactionSurvey, error) {
+	return s.surveyRepo.Answer(tokenHash, rating, comment)
+}
+
+// Implementación de métodos para coordinar tareas periódicas entre réplicas
+func (s *PostgreSQLStore) AcquireLease(name, holder string, ttl time.Duration) (bool, error) {
+	return s.leaseRepo.Acquire(name, holder, ttl)
+}
+
+// Implementación de métodos para el registro de actividad
+func (s *PostgreSQLStore) GetActivities(targetID string) ([]models.Activity, error) {
+	return s.activityRepo.GetByTarget(targetID)
+}
+
+func (s *PostgreSQLStore) AddActivity(targetID string, activity models.Activity) error {
+	return s.activityRepo.Create(targetID, activity)
+}
+
+// Implementación de métodos para notificaciones de usuarios
+func (s *PostgreSQLStore) GetNotifications(userID string, unreadOnly bool) ([]models.Notification, error) {
+	return s.notifyRepo.GetByUser(userID, unreadOnly)
+}
+
+func (s *PostgreSQLStore) CreateNotification(notification models.Notification) error {
+	return s.notifyRepo.Create(notification)
+}
+
+func (s *PostgreSQLStore) MarkNotificationsRead(userID string, ids []string) error {
+	return s.notifyRepo.MarkRead(userID, ids)
 }
 
 // Implementación de métodos para WebSocket
@@ -165,6 +633,11 @@ func (s *PostgreSQLStore) RemoveWSConnection(ticketID, connectionID string) {
 }
 
 func (s *PostgreSQLStore) BroadcastMessage(ticketID string, message models.Message) {
+	// Las conexiones del chat son del cliente; las notas internas no se difunden
+	if !message.VisibleTo(models.MessageAudienceCustomer) {
+		return
+	}
+
 	s.wsConnectionMu.Lock()
 	defer s.wsConnectionMu.Unlock()
 
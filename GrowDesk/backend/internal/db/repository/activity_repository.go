@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// ActivityRepository maneja las operaciones de base de datos para el registro de actividad
+type ActivityRepository struct {
+	db *sql.DB
+}
+
+// NewActivityRepository crea un nuevo repositorio de actividad
+func NewActivityRepository(db *sql.DB) *ActivityRepository {
+	return &ActivityRepository{db: db}
+}
+
+// GetByTarget obtiene las actividades de un elemento, las más recientes primero
+func (r *ActivityRepository) GetByTarget(targetID string) ([]models.Activity, error) {
+	rows, err := r.db.Query(`
+		SELECT id, user_id, type, target_id, description, metadata, timestamp
+		FROM activities
+		WHERE target_id = $1
+		ORDER BY timestamp DESC
+	`, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar actividades: %v", err)
+	}
+	defer rows.Close()
+
+	activities := make([]models.Activity, 0)
+	for rows.Next() {
+		var activity models.Activity
+		var userID, target sql.NullString
+		var metadataJSON []byte
+
+		if err := rows.Scan(&activity.ID, &userID, &activity.Type, &target, &activity.Description, &metadataJSON, &activity.Timestamp); err != nil {
+			return nil, fmt.Errorf("error al escanear actividad: %v", err)
+		}
+
+		activity.UserID = userID.String
+		activity.TargetID = target.String
+		if len(metadataJSON) > 0 {
+			if err := json.Unmarshal(metadataJSON, &activity.Metadata); err != nil {
+				return nil, fmt.Errorf("error al analizar metadata de actividad: %v", err)
+			}
+		}
+
+		activities = append(activities, activity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar actividades: %v", err)
+	}
+
+	return activities, nil
+}
+
+// insertActivity registra una actividad sobre targetID a partir de una plantilla. Si el
+// usuario no existe en la base de datos (p. ej. en modo desarrollo) se guarda sin usuario.
+func insertActivity(q sqlRunner, activity models.Activity, targetID string) error {
+	var metadataJSON sql.NullString
+	if activity.Metadata != nil {
+		data, err := json.Marshal(activity.Metadata)
+		if err != nil {
+			return fmt.Errorf("error al serializar metadata de actividad: %v", err)
+		}
+		metadataJSON = sql.NullString{String: string(data), Valid: true}
+	}
+
+	_, err := q.Exec(`
+		INSERT INTO activities (id, user_id, type, target_id, description, metadata, timestamp)
+		VALUES ($1, (SELECT id FROM users WHERE id = $2), $3, $4, $5, $6, NOW())
+	`, uuid.New().String(), activity.UserID, activity.Type, targetID, activity.Description, metadataJSON)
+	if err != nil {
+		return fmt.Errorf("error al registrar actividad: %v", err)
+	}
+
+	return nil
+}
+
+// Create registra una actividad sobre targetID
+func (r *ActivityRepository) Create(targetID string, activity models.Activity) error {
+	return insertActivity(r.db, activity, targetID)
+}
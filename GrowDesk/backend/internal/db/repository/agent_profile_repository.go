@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// AgentProfileRepository maneja las operaciones de base de datos para los perfiles de agentes
+type AgentProfileRepository struct {
+	db *sql.DB
+}
+
+// NewAgentProfileRepository crea un nuevo repositorio de perfiles de agentes
+func NewAgentProfileRepository(db *sql.DB) *AgentProfileRepository {
+	return &AgentProfileRepository{db: db}
+}
+
+// scanAgentProfile escanea una fila de agent_profiles
+func scanAgentProfile(row rowScanner) (models.AgentProfile, error) {
+	var profile models.AgentProfile
+	var status sql.NullString
+	var skillsJSON []byte
+
+	err := row.Scan(
+		&profile.UserID,
+		&status,
+		&profile.MaxChats,
+		&profile.MaxTickets,
+		&skillsJSON,
+		&profile.UpdatedAt,
+	)
+	if err != nil {
+		return profile, err
+	}
+
+	// Asignar valores nulos
+	profile.Status = status.String
+	profile.Skills = make([]models.AgentSkill, 0)
+	if len(skillsJSON) > 0 {
+		if err := json.Unmarshal(skillsJSON, &profile.Skills); err != nil {
+			return profile, fmt.Errorf("error al analizar habilidades: %v", err)
+		}
+	}
+
+	return profile, nil
+}
+
+// GetAll obtiene todos los perfiles de agentes guardados
+func (r *AgentProfileRepository) GetAll() ([]models.AgentProfile, error) {
+	rows, err := r.db.Query(`
+		SELECT user_id, status, max_chats, max_tickets, skills, updated_at
+		FROM agent_profiles
+		ORDER BY user_id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar perfiles de agentes: %v", err)
+	}
+	defer rows.Close()
+
+	profiles := make([]models.AgentProfile, 0)
+	for rows.Next() {
+		profile, err := scanAgentProfile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear perfil de agente: %v", err)
+		}
+		profiles = append(profiles, profile)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar perfiles de agentes: %v", err)
+	}
+
+	return profiles, nil
+}
+
+// GetByUserID obtiene el perfil de un agente; sin perfil guardado devuelve uno vacío
+func (r *AgentProfileRepository) GetByUserID(userID string) (*models.AgentProfile, error) {
+	var exists bool
+	if err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("error al consultar usuario: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("usuario con ID %s no encontrado", userID)
+	}
+
+	profile, err := scanAgentProfile(r.db.QueryRow(`
+		SELECT user_id, status, max_chats, max_tickets, skills, updated_at
+		FROM agent_profiles
+		WHERE user_id = $1
+	`, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &models.AgentProfile{UserID: userID, Skills: make([]models.AgentSkill, 0)}, nil
+		}
+		return nil, fmt.Errorf("error al consultar perfil de agente: %v", err)
+	}
+
+	return &profile, nil
+}
+
+// Save crea o reemplaza el perfil de un agente
+func (r *AgentProfileRepository) Save(profile models.AgentProfile) error {
+	skills := profile.Skills
+	if skills == nil {
+		skills = make([]models.AgentSkill, 0)
+	}
+	skillsJSON, err := json.Marshal(skills)
+	if err != nil {
+		return fmt.Errorf("error al serializar habilidades: %v", err)
+	}
+
+	query := `
+		INSERT INTO agent_profiles (user_id, status, max_chats, max_tickets, skills, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			max_chats = EXCLUDED.max_chats,
+			max_tickets = EXCLUDED.max_tickets,
+			skills = EXCLUDED.skills,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err = r.db.Exec(
+		query,
+		profile.UserID,
+		nullString(profile.Status),
+		profile.MaxChats,
+		profile.MaxTickets,
+		skillsJSON,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("error al guardar perfil de agente: %v", err)
+	}
+
+	return nil
+}
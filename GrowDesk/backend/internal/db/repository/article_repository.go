@@ -0,0 +1,387 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// ArticleRepository maneja las operaciones de base de datos para la base de conocimiento
+type ArticleRepository struct {
+	db *sql.DB
+}
+
+// NewArticleRepository crea un nuevo repositorio de artículos
+func NewArticleRepository(db *sql.DB) *ArticleRepository {
+	return &ArticleRepository{db: db}
+}
+
+const articleColumns = `a.id, a.slug, a.locale, a.title, a.body, a.category_id, a.legacy_category, a.status, a.revision,
+	a.published_revision, a.author_id, a.editor_id, a.published_by, a.published_at, a.created_at, a.updated_at`
+
+// publishedArticleColumns sustituye el contenido de trabajo por el de la revisión publicada (r)
+const publishedArticleColumns = `a.id, a.slug, a.locale, r.title, r.body, r.category_id, a.legacy_category, a.status, a.revision,
+	a.published_revision, a.author_id, a.editor_id, a.published_by, a.published_at, a.created_at, a.updated_at`
+
+const articleRevisionColumns = `article_id, revision, title, body, category_id, editor_id, note, created_at`
+
+// scanArticle escanea una fila de kb_articles
+func scanArticle(row rowScanner) (models.Article, error) {
+	var article models.Article
+	var categoryID, legacyCategory, authorID, editorID, publishedBy sql.NullString
+	var publishedAt sql.NullTime
+
+	err := row.Scan(
+		&article.ID,
+		&article.Slug,
+		&article.Locale,
+		&article.Title,
+		&article.Body,
+		&categoryID,
+		&legacyCategory,
+		&article.Status,
+		&article.Revision,
+		&article.PublishedRevision,
+		&authorID,
+		&editorID,
+		&publishedBy,
+		&publishedAt,
+		&article.CreatedAt,
+		&article.UpdatedAt,
+	)
+	if err != nil {
+		return article, err
+	}
+
+	article.CategoryID = categoryID.String
+	article.LegacyCategory = legacyCategory.String
+	article.AuthorID = authorID.String
+	article.EditorID = editorID.String
+	article.PublishedBy = publishedBy.String
+	if publishedAt.Valid {
+		article.PublishedAt = &publishedAt.Time
+	}
+
+	return article, nil
+}
+
+// scanArticleRevision escanea una fila de kb_article_revisions
+func scanArticleRevision(row rowScanner) (models.ArticleRevision, error) {
+	var revision models.ArticleRevision
+	var categoryID, editorID, note sql.NullString
+
+	err := row.Scan(
+		&revision.ArticleID,
+		&revision.Revision,
+		&revision.Title,
+		&revision.Body,
+		&categoryID,
+		&editorID,
+		&note,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		return revision, err
+	}
+
+	revision.CategoryID = categoryID.String
+	revision.EditorID = editorID.String
+	revision.Note = note.String
+
+	return revision, nil
+}
+
+// queryArticles ejecuta una consulta de artículos
+func (r *ArticleRepository) queryArticles(query string, args ...interface{}) ([]models.Article, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar artículos: %v", err)
+	}
+	defer rows.Close()
+
+	articles := make([]models.Article, 0)
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear artículo: %v", err)
+		}
+		articles = append(articles, article)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar artículos: %v", err)
+	}
+
+	return articles, nil
+}
+
+// GetAll obtiene los artículos que cumplen el filtro
+func (r *ArticleRepository) GetAll(filter models.ArticleFilter) ([]models.Article, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("a.status = $%d", len(args)))
+	}
+	if filter.CategoryID != "" {
+		args = append(args, filter.CategoryID)
+		conditions = append(conditions, fmt.Sprintf("a.category_id = $%d", len(args)))
+	}
+
+	query := `SELECT ` + articleColumns + ` FROM kb_articles a`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY a.id ASC`
+
+	return r.queryArticles(query, args...)
+}
+
+// GetPublished obtiene los artículos publicados con el contenido de su revisión publicada
+func (r *ArticleRepository) GetPublished() ([]models.Article, error) {
+	return r.queryArticles(`
+		SELECT ` + publishedArticleColumns + `
+		FROM kb_articles a
+		JOIN kb_article_revisions r ON r.article_id = a.id AND r.revision = a.published_revision
+		WHERE a.published_revision > 0
+		ORDER BY a.id ASC
+	`)
+}
+
+// GetByID obtiene un artículo por su ID
+func (r *ArticleRepository) GetByID(id int) (*models.Article, error) {
+	article, err := scanArticle(r.db.QueryRow(`SELECT `+articleColumns+` FROM kb_articles a WHERE a.id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("artículo con ID %d no encontrado", id)
+		}
+		return nil, fmt.Errorf("error al consultar artículo: %v", err)
+	}
+	return &article, nil
+}
+
+// GetBySlug obtiene un artículo por su slug
+func (r *ArticleRepository) GetBySlug(slug string) (*models.Article, error) {
+	article, err := scanArticle(r.db.QueryRow(`SELECT `+articleColumns+` FROM kb_articles a WHERE a.slug = $1`, slug))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("artículo %s no encontrado", slug)
+		}
+		return nil, fmt.Errorf("error al consultar artículo: %v", err)
+	}
+	return &article, nil
+}
+
+// Create crea un artículo con su primera revisión
+func (r *ArticleRepository) Create(article models.Article, revision models.ArticleRevision) (*models.Article, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO kb_articles (
+			slug, locale, title, body, category_id, legacy_category, status, revision, published_revision,
+			author_id, editor_id, published_by, published_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id
+	`,
+		article.Slug,
+		article.Locale,
+		article.Title,
+		article.Body,
+		nullString(article.CategoryID),
+		nullString(article.LegacyCategory),
+		article.Status,
+		article.Revision,
+		article.PublishedRevision,
+		nullString(article.AuthorID),
+		nullString(article.EditorID),
+		nullString(article.PublishedBy),
+		article.PublishedAt,
+		article.CreatedAt,
+		article.UpdatedAt,
+	).Scan(&article.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error al crear artículo: %v", err)
+	}
+
+	revision.ArticleID = article.ID
+	if err := insertArticleRevision(tx, revision); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return &article, nil
+}
+
+// Update guarda un artículo y, si se indica, su nueva revisión. La actualización solo
+// se aplica si la revisión guardada es la que precede a la del artículo.
+func (r *ArticleRepository) Update(article models.Article, revision *models.ArticleRevision) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	expected := article.Revision
+	if revision != nil {
+		if revision.Revision != article.Revision {
+			return fmt.Errorf("el artículo %d fue modificado por otra edición", article.ID)
+		}
+		expected--
+	}
+
+	result, err := tx.Exec(`
+		UPDATE kb_articles
+		SET slug = $3, locale = $4, title = $5, body = $6, category_id = $7, legacy_category = $8,
+		    status = $9, revision = $10, published_revision = $11, editor_id = $12, published_by = $13,
+		    published_at = $14, updated_at = $15
+		WHERE id = $1 AND revision = $2
+	`,
+		article.ID,
+		expected,
+		article.Slug,
+		article.Locale,
+		article.Title,
+		article.Body,
+		nullString(article.CategoryID),
+		nullString(article.LegacyCategory),
+		article.Status,
+		article.Revision,
+		article.PublishedRevision,
+		nullString(article.EditorID),
+		nullString(article.PublishedBy),
+		article.PublishedAt,
+		article.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar artículo: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rowsAffected == 0 {
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM kb_articles WHERE id = $1)", article.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("error al verificar artículo: %v", err)
+		}
+		if !exists {
+			return fmt.Errorf("artículo con ID %d no encontrado", article.ID)
+		}
+		return fmt.Errorf("el artículo %d fue modificado por otra edición", article.ID)
+	}
+
+	if revision != nil {
+		revision.ArticleID = article.ID
+		if err := insertArticleRevision(tx, *revision); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return nil
+}
+
+// insertArticleRevision guarda una revisión dentro de una transacción
+func insertArticleRevision(tx *sql.Tx, revision models.ArticleRevision) error {
+	_, err := tx.Exec(`
+		INSERT INTO kb_article_revisions (article_id, revision, title, body, category_id, editor_id, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		revision.ArticleID,
+		revision.Revision,
+		revision.Title,
+		revision.Body,
+		nullString(revision.CategoryID),
+		nullString(revision.EditorID),
+		nullString(revision.Note),
+		revision.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al guardar revisión del artículo: %v", err)
+	}
+	return nil
+}
+
+// Delete elimina un artículo (sus revisiones y traducciones se eliminan en cascada)
+func (r *ArticleRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM kb_articles WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar artículo: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("artículo con ID %d no encontrado", id)
+	}
+
+	return nil
+}
+
+// GetRevisions obtiene el historial de un artículo, de la revisión más reciente a la más antigua
+func (r *ArticleRepository) GetRevisions(articleID int) ([]models.ArticleRevision, error) {
+	var exists bool
+	if err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM kb_articles WHERE id = $1)", articleID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("error al verificar artículo: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("artículo con ID %d no encontrado", articleID)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT `+articleRevisionColumns+`
+		FROM kb_article_revisions
+		WHERE article_id = $1
+		ORDER BY revision DESC
+	`, articleID)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar revisiones: %v", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]models.ArticleRevision, 0)
+	for rows.Next() {
+		revision, err := scanArticleRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear revisión: %v", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar revisiones: %v", err)
+	}
+
+	return revisions, nil
+}
+
+// GetRevision obtiene una revisión concreta de un artículo
+func (r *ArticleRepository) GetRevision(articleID, revision int) (*models.ArticleRevision, error) {
+	result, err := scanArticleRevision(r.db.QueryRow(`
+		SELECT `+articleRevisionColumns+`
+		FROM kb_article_revisions
+		WHERE article_id = $1 AND revision = $2
+	`, articleID, revision))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("revisión %d del artículo %d no encontrada", revision, articleID)
+		}
+		return nil, fmt.Errorf("error al consultar revisión: %v", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// ArticleTranslationRepository maneja las operaciones de base de datos para las
+// traducciones de los artículos
+type ArticleTranslationRepository struct {
+	db *sql.DB
+}
+
+// NewArticleTranslationRepository crea un nuevo repositorio de traducciones
+func NewArticleTranslationRepository(db *sql.DB) *ArticleTranslationRepository {
+	return &ArticleTranslationRepository{db: db}
+}
+
+const articleTranslationColumns = `article_id, locale, title, body, status, source_revision, published_title,
+	published_body, translator_id, published_by, published_at, created_at, updated_at`
+
+// scanArticleTranslation escanea una fila de kb_article_translations
+func scanArticleTranslation(row rowScanner) (models.ArticleTranslation, error) {
+	var translation models.ArticleTranslation
+	var publishedTitle, publishedBody, translatorID, publishedBy sql.NullString
+	var publishedAt sql.NullTime
+
+	err := row.Scan(
+		&translation.ArticleID,
+		&translation.Locale,
+		&translation.Title,
+		&translation.Body,
+		&translation.Status,
+		&translation.SourceRevision,
+		&publishedTitle,
+		&publishedBody,
+		&translatorID,
+		&publishedBy,
+		&publishedAt,
+		&translation.CreatedAt,
+		&translation.UpdatedAt,
+	)
+	if err != nil {
+		return translation, err
+	}
+
+	translation.PublishedTitle = publishedTitle.String
+	translation.PublishedBody = publishedBody.String
+	translation.TranslatorID = translatorID.String
+	translation.PublishedBy = publishedBy.String
+	if publishedAt.Valid {
+		translation.PublishedAt = &publishedAt.Time
+	}
+
+	return translation, nil
+}
+
+// queryTranslations ejecuta una consulta de traducciones
+func (r *ArticleTranslationRepository) queryTranslations(query string, args ...interface{}) ([]models.ArticleTranslation, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar traducciones: %v", err)
+	}
+	defer rows.Close()
+
+	translations := make([]models.ArticleTranslation, 0)
+	for rows.Next() {
+		translation, err := scanArticleTranslation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear traducción: %v", err)
+		}
+		translations = append(translations, translation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar traducciones: %v", err)
+	}
+
+	return translations, nil
+}
+
+// GetByArticle obtiene las traducciones de un artículo
+func (r *ArticleTranslationRepository) GetByArticle(articleID int) ([]models.ArticleTranslation, error) {
+	var exists bool
+	if err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM kb_articles WHERE id = $1)", articleID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("error al verificar artículo: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("artículo con ID %d no encontrado", articleID)
+	}
+
+	return r.queryTranslations(`
+		SELECT `+articleTranslationColumns+`
+		FROM kb_article_translations
+		WHERE article_id = $1
+		ORDER BY locale ASC
+	`, articleID)
+}
+
+// GetByLocale obtiene las traducciones de todos los artículos a un idioma
+func (r *ArticleTranslationRepository) GetByLocale(locale string) ([]models.ArticleTranslation, error) {
+	return r.queryTranslations(`
+		SELECT `+articleTranslationColumns+`
+		FROM kb_article_translations
+		WHERE locale = $1
+		ORDER BY article_id ASC
+	`, locale)
+}
+
+// GetByID obtiene la traducción de un artículo a un idioma
+func (r *ArticleTranslationRepository) GetByID(articleID int, locale string) (*models.ArticleTranslation, error) {
+	translation, err := scanArticleTranslation(r.db.QueryRow(`
+		SELECT `+articleTranslationColumns+`
+		FROM kb_article_translations
+		WHERE article_id = $1 AND locale = $2
+	`, articleID, locale))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("traducción %s del artículo %d no encontrada", locale, articleID)
+		}
+		return nil, fmt.Errorf("error al consultar traducción: %v", err)
+	}
+	return &translation, nil
+}
+
+// Save crea o reemplaza la traducción de un artículo a un idioma
+func (r *ArticleTranslationRepository) Save(translation models.ArticleTranslation) error {
+	_, err := r.db.Exec(`
+		INSERT INTO kb_article_translations (
+			article_id, locale, title, body, status, source_revision, published_title, published_body,
+			translator_id, published_by, published_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (article_id, locale) DO UPDATE SET
+			title = EXCLUDED.title,
+			body = EXCLUDED.body,
+			status = EXCLUDED.status,
+			source_revision = EXCLUDED.source_revision,
+			published_title = EXCLUDED.published_title,
+			published_body = EXCLUDED.published_body,
+			translator_id = EXCLUDED.translator_id,
+			published_by = EXCLUDED.published_by,
+			published_at = EXCLUDED.published_at,
+			updated_at = EXCLUDED.updated_at
+	`,
+		translation.ArticleID,
+		translation.Locale,
+		translation.Title,
+		translation.Body,
+		translation.Status,
+		translation.SourceRevision,
+		nullString(translation.PublishedTitle),
+		nullString(translation.PublishedBody),
+		nullString(translation.TranslatorID),
+		nullString(translation.PublishedBy),
+		translation.PublishedAt,
+		translation.CreatedAt,
+		translation.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al guardar traducción: %v", err)
+	}
+	return nil
+}
+
+// Delete elimina la traducción de un artículo a un idioma
+func (r *ArticleTranslationRepository) Delete(articleID int, locale string) error {
+	result, err := r.db.Exec("DELETE FROM kb_article_translations WHERE article_id = $1 AND locale = $2", articleID, locale)
+	if err != nil {
+		return fmt.Errorf("error al eliminar traducción: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("traducción %s del artículo %d no encontrada", locale, articleID)
+	}
+
+	return nil
+}
@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/lib/pq"
+)
+
+// AutomationRuleRepository maneja las operaciones de base de datos para las reglas de automatización
+type AutomationRuleRepository struct {
+	db *sql.DB
+}
+
+// NewAutomationRuleRepository crea un nuevo repositorio de reglas de automatización
+func NewAutomationRuleRepository(db *sql.DB) *AutomationRuleRepository {
+	return &AutomationRuleRepository{db: db}
+}
+
+const automationRuleColumns = `id, name, description, events, match, conditions, actions, position, active, stop_processing, created_at, updated_at`
+
+// scanAutomationRule escanea una fila de automation_rules
+func scanAutomationRule(row rowScanner) (models.AutomationRule, error) {
+	var rule models.AutomationRule
+	var description, match sql.NullString
+	var conditionsJSON, actionsJSON []byte
+
+	err := row.Scan(
+		&rule.ID,
+		&rule.Name,
+		&description,
+		pq.Array(&rule.Events),
+		&match,
+		&conditionsJSON,
+		&actionsJSON,
+		&rule.Position,
+		&rule.Active,
+		&rule.StopProcessing,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return rule, err
+	}
+
+	rule.Description = description.String
+	rule.Match = match.String
+	if len(conditionsJSON) > 0 {
+		if err := json.Unmarshal(conditionsJSON, &rule.Conditions); err != nil {
+			return rule, fmt.Errorf("error al analizar condiciones de la regla: %v", err)
+		}
+	}
+	if len(actionsJSON) > 0 {
+		if err := json.Unmarshal(actionsJSON, &rule.Actions); err != nil {
+			return rule, fmt.Errorf("error al analizar acciones de la regla: %v", err)
+		}
+	}
+
+	return rule, nil
+}
+
+// marshalAutomationRule serializa las condiciones y acciones de una regla para las columnas JSONB
+func marshalAutomationRule(rule models.AutomationRule) ([]byte, []byte, error) {
+	conditions := rule.Conditions
+	if conditions == nil {
+		conditions = []models.AutomationCondition{}
+	}
+
+	conditionsJSON, err := json.Marshal(conditions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al serializar condiciones de la regla: %v", err)
+	}
+	actionsJSON, err := json.Marshal(rule.Actions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al serializar acciones de la regla: %v", err)
+	}
+	return conditionsJSON, actionsJSON, nil
+}
+
+// GetAll obtiene todas las reglas en orden de evaluación
+func (r *AutomationRuleRepository) GetAll() ([]models.AutomationRule, error) {
+	rows, err := r.db.Query(`SELECT ` + automationRuleColumns + ` FROM automation_rules ORDER BY position ASC, created_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar reglas de automatización: %v", err)
+	}
+	defer rows.Close()
+
+	rules := make([]models.AutomationRule, 0)
+	for rows.Next() {
+		rule, err := scanAutomationRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear regla de automatización: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar reglas de automatización: %v", err)
+	}
+
+	return rules, nil
+}
+
+// GetByID obtiene una regla por su ID
+func (r *AutomationRuleRepository) GetByID(id string) (*models.AutomationRule, error) {
+	rule, err := scanAutomationRule(r.db.QueryRow(`SELECT `+automationRuleColumns+` FROM automation_rules WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("regla con ID %s no encontrada", id)
+		}
+		return nil, fmt.Errorf("error al consultar regla de automatización: %v", err)
+	}
+	return &rule, nil
+}
+
+// Create crea una nueva regla
+func (r *AutomationRuleRepository) Create(rule models.AutomationRule) (*models.AutomationRule, error) {
+	if rule.ID == "" {
+		rule.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = now
+	}
+	rule.UpdatedAt = now
+
+	conditionsJSON, actionsJSON, err := marshalAutomationRule(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO automation_rules (id, name, description, events, match, conditions, actions, position, active, stop_processing, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`,
+		rule.ID,
+		rule.Name,
+		nullString(rule.Description),
+		pq.Array(rule.Events),
+		nullString(rule.Match),
+		conditionsJSON,
+		actionsJSON,
+		rule.Position,
+		rule.Active,
+		rule.StopProcessing,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error al crear regla de automatización: %v", err)
+	}
+
+	return &rule, nil
+}
+
+// Update actualiza una regla existente
+func (r *AutomationRuleRepository) Update(rule models.AutomationRule) error {
+	rule.UpdatedAt = time.Now()
+
+	conditionsJSON, actionsJSON, err := marshalAutomationRule(rule)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(`
+		UPDATE automation_rules
+		SET name = $2, description = $3, events = $4, match = $5, conditions = $6, actions = $7,
+			position = $8, active = $9, stop_processing = $10, updated_at = $11
+		WHERE id = $1
+	`,
+		rule.ID,
+		rule.Name,
+		nullString(rule.Description),
+		pq.Array(rule.Events),
+		nullString(rule.Match),
+		conditionsJSON,
+		actionsJSON,
+		rule.Position,
+		rule.Active,
+		rule.StopProcessing,
+		rule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar regla de automatización: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("regla con ID %s no encontrada", rule.ID)
+	}
+
+	return nil
+}
+
+// Delete elimina una regla
+func (r *AutomationRuleRepository) Delete(id string) error {
+	result, err := r.db.Exec("DELETE FROM automation_rules WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar regla de automatización: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("regla con ID %s no encontrada", id)
+	}
+
+	return nil
+}
+
+// GetRuns obtiene los registros de ejecución de reglas programadas
+func (r *AutomationRuleRepository) GetRuns() ([]models.AutomationRuleRun, error) {
+	rows, err := r.db.Query(`SELECT rule_id, ticket_id, fired_at FROM automation_rule_runs`)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar ejecuciones de reglas: %v", err)
+	}
+	defer rows.Close()
+
+	runs := make([]models.AutomationRuleRun, 0)
+	for rows.Next() {
+		var run models.AutomationRuleRun
+		if err := rows.Scan(&run.RuleID, &run.TicketID, &run.FiredAt); err != nil {
+			return nil, fmt.Errorf("error al escanear ejecución de regla: %v", err)
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar ejecuciones de reglas: %v", err)
+	}
+
+	return runs, nil
+}
+
+// ClaimRun registra la ejecución de una regla sobre un ticket. La clave primaria
+// garantiza que solo una réplica la registre; devuelve false si ya existía.
+func (r *AutomationRuleRepository) ClaimRun(ruleID, ticketID string) (bool, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO automation_rule_runs (rule_id, ticket_id, fired_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (rule_id, ticket_id) DO NOTHING
+	`, ruleID, ticketID)
+	if err != nil {
+		return false, fmt.Errorf("error al registrar ejecución de regla: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// DeleteRuns elimina registros de ejecución
+func (r *AutomationRuleRepository) DeleteRuns(runs []models.AutomationRuleRun) error {
+	if len(runs) == 0 {
+		return nil
+	}
+
+	ruleIDs := make([]string, len(runs))
+	ticketIDs := make([]string, len(runs))
+	for i, run := range runs {
+		ruleIDs[i] = run.RuleID
+		ticketIDs[i] = run.TicketID
+	}
+
+	_, err := r.db.Exec(`
+		DELETE FROM automation_rule_runs
+		WHERE (rule_id, ticket_id) IN (SELECT * FROM UNNEST($1::text[], $2::text[]))
+	`, pq.Array(ruleIDs), pq.Array(ticketIDs))
+	if err != nil {
+		return fmt.Errorf("error al eliminar ejecuciones de reglas: %v", err)
+	}
+
+	return nil
+}
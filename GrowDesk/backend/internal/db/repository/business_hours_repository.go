@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// BusinessHoursRepository maneja las operaciones de base de datos para los horarios de atención
+type BusinessHoursRepository struct {
+	db *sql.DB
+}
+
+// NewBusinessHoursRepository crea un nuevo repositorio de horarios de atención
+func NewBusinessHoursRepository(db *sql.DB) *BusinessHoursRepository {
+	return &BusinessHoursRepository{db: db}
+}
+
+// rowScanner agrupa *sql.Row y *sql.Rows para reutilizar el escaneo
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanBusinessHours escanea una fila de business_hours
+func scanBusinessHours(row rowScanner) (models.BusinessHours, error) {
+	var hours models.BusinessHours
+	var widgetID, department, offlineMessage, expectedResponse sql.NullString
+	var scheduleJSON, holidaysJSON []byte
+
+	err := row.Scan(
+		&hours.ID,
+		&hours.Name,
+		&widgetID,
+		&department,
+		&hours.TimeZone,
+		&scheduleJSON,
+		&holidaysJSON,
+		&offlineMessage,
+		&expectedResponse,
+		&hours.CreatedAt,
+		&hours.UpdatedAt,
+	)
+	if err != nil {
+		return hours, err
+	}
+
+	// Asignar valores nulos
+	hours.WidgetID = widgetID.String
+	hours.Department = department.String
+	hours.OfflineMessage = offlineMessage.String
+	hours.ExpectedResponseTime = expectedResponse.String
+
+	if len(scheduleJSON) > 0 {
+		if err := json.Unmarshal(scheduleJSON, &hours.Schedule); err != nil {
+			return hours, fmt.Errorf("error al analizar horario: %v", err)
+		}
+	}
+	if len(holidaysJSON) > 0 {
+		if err := json.Unmarshal(holidaysJSON, &hours.Holidays); err != nil {
+			return hours, fmt.Errorf("error al analizar feriados: %v", err)
+		}
+	}
+
+	return hours, nil
+}
+
+// GetAll obtiene todos los horarios de atención
+func (r *BusinessHoursRepository) GetAll() ([]models.BusinessHours, error) {
+	query := `
+		SELECT id, name, widget_id, department, time_zone, schedule, holidays,
+		       offline_message, expected_response_time, created_at, updated_at
+		FROM business_hours
+		ORDER BY name ASC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar horarios de atención: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]models.BusinessHours, 0)
+	for rows.Next() {
+		hours, err := scanBusinessHours(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear horario de atención: %v", err)
+		}
+		result = append(result, hours)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar horarios de atención: %v", err)
+	}
+
+	return result, nil
+}
+
+// GetByID obtiene un horario de atención por su ID
+func (r *BusinessHoursRepository) GetByID(id string) (*models.BusinessHours, error) {
+	query := `
+		SELECT id, name, widget_id, department, time_zone, schedule, holidays,
+		       offline_message, expected_response_time, created_at, updated_at
+		FROM business_hours
+		WHERE id = $1
+	`
+
+	hours, err := scanBusinessHours(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("horario de atención con ID %s no encontrado", id)
+		}
+		return nil, fmt.Errorf("error al consultar horario de atención: %v", err)
+	}
+
+	return &hours, nil
+}
+
+// Create crea un nuevo horario de atención
+func (r *BusinessHoursRepository) Create(hours models.BusinessHours) (*models.BusinessHours, error) {
+	if hours.ID == "" {
+		hours.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	if hours.CreatedAt.IsZero() {
+		hours.CreatedAt = now
+	}
+	hours.UpdatedAt = now
+
+	scheduleJSON, holidaysJSON, err := marshalBusinessHours(hours)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO business_hours (
+			id, name, widget_id, department, time_zone, schedule, holidays,
+			offline_message, expected_response_time, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err = r.db.Exec(
+		query,
+		hours.ID,
+		hours.Name,
+		nullString(hours.WidgetID),
+		nullString(hours.Department),
+		hours.TimeZone,
+		scheduleJSON,
+		holidaysJSON,
+		nullString(hours.OfflineMessage),
+		nullString(hours.ExpectedResponseTime),
+		hours.CreatedAt,
+		hours.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error al crear horario de atención: %v", err)
+	}
+
+	return &hours, nil
+}
+
+// Update actualiza un horario de atención existente
+func (r *BusinessHoursRepository) Update(hours models.BusinessHours) error {
+	hours.UpdatedAt = time.Now()
+
+	scheduleJSON, holidaysJSON, err := marshalBusinessHours(hours)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE business_hours
+		SET name = $2, widget_id = $3, department = $4, time_zone = $5, schedule = $6,
+		    holidays = $7, offline_message = $8, expected_response_time = $9, updated_at = $10
+		WHERE id = $1
+	`
+
+	result, err := r.db.Exec(
+		query,
+		hours.ID,
+		hours.Name,
+		nullString(hours.WidgetID),
+		nullString(hours.Department),
+		hours.TimeZone,
+		scheduleJSON,
+		holidaysJSON,
+		nullString(hours.OfflineMessage),
+		nullString(hours.ExpectedResponseTime),
+		hours.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar horario de atención: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("horario de atención con ID %s no encontrado", hours.ID)
+	}
+
+	return nil
+}
+
+// Delete elimina un horario de atención
+func (r *BusinessHoursRepository) Delete(id string) error {
+	result, err := r.db.Exec("DELETE FROM business_hours WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar horario de atención: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("horario de atención con ID %s no encontrado", id)
+	}
+
+	return nil
+}
+
+// marshalBusinessHours serializa las columnas JSONB de un horario de atención
+func marshalBusinessHours(hours models.BusinessHours) ([]byte, []byte, error) {
+	if hours.Schedule == nil {
+		hours.Schedule = []models.DaySchedule{}
+	}
+	if hours.Holidays == nil {
+		hours.Holidays = []string{}
+	}
+
+	scheduleJSON, err := json.Marshal(hours.Schedule)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al serializar horario: %v", err)
+	}
+
+	holidaysJSON, err := json.Marshal(hours.Holidays)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al serializar feriados: %v", err)
+	}
+
+	return scheduleJSON, holidaysJSON, nil
+}
@@ -18,13 +18,60 @@ func NewCategoryRepository(db *sql.DB) *CategoryRepository {
 	return &CategoryRepository{db: db}
 }
 
+// categoryColumns son las columnas leídas por scanCategory
+const categoryColumns = `
+	id, name, description, color, icon, parent_id, position, default_department,
+	default_priority, assignee_group, sla_policy, active, created_at, updated_at
+`
+
+// scanCategory escanea una fila con las columnas de categoryColumns
+func scanCategory(row rowScanner) (models.Category, error) {
+	var category models.Category
+	var description, color, icon, parentID sql.NullString
+	var department, priority, assigneeGroup, slaPolicy sql.NullString
+	var createdAt, updatedAt time.Time
+
+	err := row.Scan(
+		&category.ID,
+		&category.Name,
+		&description,
+		&color,
+		&icon,
+		&parentID,
+		&category.Position,
+		&department,
+		&priority,
+		&assigneeGroup,
+		&slaPolicy,
+		&category.Active,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return category, err
+	}
+
+	// Asignar valores nulos
+	category.Description = description.String
+	category.Color = color.String
+	category.Icon = icon.String
+	category.ParentID = parentID.String
+	category.Defaults = models.CategoryDefaults{
+		Department:    department.String,
+		Priority:      priority.String,
+		AssigneeGroup: assigneeGroup.String,
+		SLAPolicy:     slaPolicy.String,
+	}
+
+	category.CreatedAt = createdAt
+	category.UpdatedAt = updatedAt
+
+	return category, nil
+}
+
 // GetAll obtiene todas las categorías
 func (r *CategoryRepository) GetAll() ([]models.Category, error) {
-	query := `
-		SELECT id, name, description, color, icon, active, created_at, updated_at
-		FROM categories
-		ORDER BY name ASC
-	`
+	query := `SELECT ` + categoryColumns + ` FROM categories ORDER BY position ASC, name ASC`
 
 	rows, err := r.db.Query(query)
 	if err != nil {
@@ -34,38 +81,10 @@ func (r *CategoryRepository) GetAll() ([]models.Category, error) {
 
 	categories := make([]models.Category, 0)
 	for rows.Next() {
-		var category models.Category
-		var description, color, icon sql.NullString
-		var createdAt, updatedAt time.Time
-
-		err := rows.Scan(
-			&category.ID,
-			&category.Name,
-			&description,
-			&color,
-			&icon,
-			&category.Active,
-			&createdAt,
-			&updatedAt,
-		)
+		category, err := scanCategory(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error al escanear categoría: %v", err)
 		}
-
-		// Asignar valores nulos
-		if description.Valid {
-			category.Description = description.String
-		}
-		if color.Valid {
-			category.Color = color.String
-		}
-		if icon.Valid {
-			category.Icon = icon.String
-		}
-
-		category.CreatedAt = createdAt
-		category.UpdatedAt = updatedAt
-
 		categories = append(categories, category)
 	}
 
@@ -78,26 +97,9 @@ func (r *CategoryRepository) GetAll() ([]models.Category, error) {
 
 // GetByID obtiene una categoría por su ID
 func (r *CategoryRepository) GetByID(id string) (*models.Category, error) {
-	query := `
-		SELECT id, name, description, color, icon, active, created_at, updated_at
-		FROM categories
-		WHERE id = $1
-	`
-
-	var category models.Category
-	var description, color, icon sql.NullString
-	var createdAt, updatedAt time.Time
+	query := `SELECT ` + categoryColumns + ` FROM categories WHERE id = $1`
 
-	err := r.db.QueryRow(query, id).Scan(
-		&category.ID,
-		&category.Name,
-		&description,
-		&color,
-		&icon,
-		&category.Active,
-		&createdAt,
-		&updatedAt,
-	)
+	category, err := scanCategory(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("categoría con ID %s no encontrada", id)
@@ -105,20 +107,6 @@ func (r *CategoryRepository) GetByID(id string) (*models.Category, error) {
 		return nil, fmt.Errorf("error al consultar categoría: %v", err)
 	}
 
-	// Asignar valores nulos
-	if description.Valid {
-		category.Description = description.String
-	}
-	if color.Valid {
-		category.Color = color.String
-	}
-	if icon.Valid {
-		category.Icon = icon.String
-	}
-
-	category.CreatedAt = createdAt
-	category.UpdatedAt = updatedAt
-
 	return &category, nil
 }
 
@@ -140,9 +128,10 @@ func (r *CategoryRepository) Create(category models.Category) (*models.Category,
 
 	query := `
 		INSERT INTO categories (
-			id, name, description, color, icon, active, created_at, updated_at
+			id, name, description, color, icon, parent_id, position, default_department,
+			default_priority, assignee_group, sla_policy, active, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 		)
 		RETURNING id
 	`
@@ -154,6 +143,12 @@ func (r *CategoryRepository) Create(category models.Category) (*models.Category,
 		nullString(category.Description),
 		nullString(category.Color),
 		nullString(category.Icon),
+		nullString(category.ParentID),
+		category.Position,
+		nullString(category.Defaults.Department),
+		nullString(category.Defaults.Priority),
+		nullString(category.Defaults.AssigneeGroup),
+		nullString(category.Defaults.SLAPolicy),
 		category.Active,
 		category.CreatedAt,
 		category.UpdatedAt,
@@ -174,7 +169,9 @@ func (r *CategoryRepository) Update(category models.Category) error {
 	query := `
 		UPDATE categories
 		SET name = $2, description = $3, color = $4, icon = $5,
-		    active = $6, updated_at = $7
+		    parent_id = $6, position = $7, default_department = $8,
+		    default_priority = $9, assignee_group = $10, sla_policy = $11,
+		    active = $12, updated_at = $13
 		WHERE id = $1
 	`
 
@@ -185,6 +182,12 @@ func (r *CategoryRepository) Update(category models.Category) error {
 		nullString(category.Description),
 		nullString(category.Color),
 		nullString(category.Icon),
+		nullString(category.ParentID),
+		category.Position,
+		nullString(category.Defaults.Department),
+		nullString(category.Defaults.Priority),
+		nullString(category.Defaults.AssigneeGroup),
+		nullString(category.Defaults.SLAPolicy),
 		category.Active,
 		category.UpdatedAt,
 	)
@@ -205,11 +208,79 @@ func (r *CategoryRepository) Update(category models.Category) error {
 	return nil
 }
 
-// Delete elimina una categoría por su ID
+// Delete elimina una categoría por su ID; sus subcategorías pasan a su categoría padre
 func (r *CategoryRepository) Delete(id string) error {
-	query := "DELETE FROM categories WHERE id = $1"
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := deleteCategory(tx, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return nil
+}
+
+// CountTickets devuelve cuántos tickets usan la categoría
+func (r *CategoryRepository) CountTickets(id string) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM tickets WHERE category_id = $1", id).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error al contar tickets de la categoría: %v", err)
+	}
+	return count, nil
+}
+
+// DeleteReassigning mueve los tickets de la categoría a target y la elimina en una
+// sola transacción. Devuelve el número de tickets reasignados.
+func (r *CategoryRepository) DeleteReassigning(id string, target models.Category) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE tickets SET category_id = $2, category = $3, updated_at = NOW()
+		WHERE category_id = $1
+	`, id, target.ID, target.Name)
+	if err != nil {
+		return 0, fmt.Errorf("error al reasignar tickets de la categoría: %v", err)
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+
+	if err := deleteCategory(tx, id); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return int(moved), nil
+}
+
+// deleteCategory sube las subcategorías un nivel y elimina la categoría
+func deleteCategory(q sqlRunner, id string) error {
+	_, err := q.Exec(`
+		UPDATE categories
+		SET parent_id = (SELECT parent_id FROM categories WHERE id = $1)
+		WHERE parent_id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("error al mover subcategorías: %v", err)
+	}
 
-	result, err := r.db.Exec(query, id)
+	result, err := q.Exec("DELETE FROM categories WHERE id = $1", id)
 	if err != nil {
 		return fmt.Errorf("error al eliminar categoría: %v", err)
 	}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// CustomFieldRepository maneja las operaciones de base de datos para las definiciones de campos personalizados
+type CustomFieldRepository struct {
+	db *sql.DB
+}
+
+// NewCustomFieldRepository crea un nuevo repositorio de campos personalizados
+func NewCustomFieldRepository(db *sql.DB) *CustomFieldRepository {
+	return &CustomFieldRepository{db: db}
+}
+
+const customFieldColumns = `id, key, label, type, category_id, required, options, description, position, active, created_at, updated_at`
+
+// scanCustomField escanea una fila de custom_field_definitions
+func scanCustomField(row rowScanner) (models.CustomFieldDefinition, error) {
+	var def models.CustomFieldDefinition
+	var categoryID, description sql.NullString
+	var optionsJSON []byte
+
+	err := row.Scan(
+		&def.ID,
+		&def.Key,
+		&def.Label,
+		&def.Type,
+		&categoryID,
+		&def.Required,
+		&optionsJSON,
+		&description,
+		&def.Position,
+		&def.Active,
+		&def.CreatedAt,
+		&def.UpdatedAt,
+	)
+	if err != nil {
+		return def, err
+	}
+
+	def.CategoryID = categoryID.String
+	def.Description = description.String
+	if len(optionsJSON) > 0 {
+		if err := json.Unmarshal(optionsJSON, &def.Options); err != nil {
+			return def, fmt.Errorf("error al analizar opciones del campo: %v", err)
+		}
+	}
+
+	return def, nil
+}
+
+// GetAll obtiene todas las definiciones ordenadas por posición
+func (r *CustomFieldRepository) GetAll() ([]models.CustomFieldDefinition, error) {
+	rows, err := r.db.Query(`SELECT ` + customFieldColumns + ` FROM custom_field_definitions ORDER BY position ASC, created_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar campos personalizados: %v", err)
+	}
+	defer rows.Close()
+
+	defs := make([]models.CustomFieldDefinition, 0)
+	for rows.Next() {
+		def, err := scanCustomField(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear campo personalizado: %v", err)
+		}
+		defs = append(defs, def)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar campos personalizados: %v", err)
+	}
+
+	return defs, nil
+}
+
+// GetByID obtiene una definición por su ID
+func (r *CustomFieldRepository) GetByID(id string) (*models.CustomFieldDefinition, error) {
+	def, err := scanCustomField(r.db.QueryRow(`SELECT `+customFieldColumns+` FROM custom_field_definitions WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("campo personalizado con ID %s no encontrado", id)
+		}
+		return nil, fmt.Errorf("error al consultar campo personalizado: %v", err)
+	}
+	return &def, nil
+}
+
+// Create crea una nueva definición
+func (r *CustomFieldRepository) Create(def models.CustomFieldDefinition) (*models.CustomFieldDefinition, error) {
+	if def.ID == "" {
+		def.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	if def.CreatedAt.IsZero() {
+		def.CreatedAt = now
+	}
+	def.UpdatedAt = now
+
+	optionsJSON, err := marshalOptions(def.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO custom_field_definitions (id, key, label, type, category_id, required, options, description, position, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`,
+		def.ID,
+		def.Key,
+		def.Label,
+		def.Type,
+		nullString(def.CategoryID),
+		def.Required,
+		optionsJSON,
+		nullString(def.Description),
+		def.Position,
+		def.Active,
+		def.CreatedAt,
+		def.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error al crear campo personalizado: %v", err)
+	}
+
+	return &def, nil
+}
+
+// Update actualiza una definición existente
+func (r *CustomFieldRepository) Update(def models.CustomFieldDefinition) error {
+	def.UpdatedAt = time.Now()
+
+	optionsJSON, err := marshalOptions(def.Options)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(`
+		UPDATE custom_field_definitions
+		SET key = $2, label = $3, type = $4, category_id = $5, required = $6, options = $7,
+			description = $8, position = $9, active = $10, updated_at = $11
+		WHERE id = $1
+	`,
+		def.ID,
+		def.Key,
+		def.Label,
+		def.Type,
+		nullString(def.CategoryID),
+		def.Required,
+		optionsJSON,
+		nullString(def.Description),
+		def.Position,
+		def.Active,
+		def.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar campo personalizado: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("campo personalizado con ID %s no encontrado", def.ID)
+	}
+
+	return nil
+}
+
+// Delete elimina una definición; los valores guardados en los tickets se conservan
+func (r *CustomFieldRepository) Delete(id string) error {
+	result, err := r.db.Exec("DELETE FROM custom_field_definitions WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar campo personalizado: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("campo personalizado con ID %s no encontrado", id)
+	}
+
+	return nil
+}
+
+// marshalOptions serializa las opciones de un campo para la columna JSONB
+func marshalOptions(options []string) ([]byte, error) {
+	if options == nil {
+		options = []string{}
+	}
+
+	data, err := json.Marshal(options)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar opciones del campo: %v", err)
+	}
+	return data, nil
+}
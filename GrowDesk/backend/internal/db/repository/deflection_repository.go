@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// DeflectionRepository maneja las operaciones de base de datos para las FAQs
+// sugeridas en el widget
+type DeflectionRepository struct {
+	db *sql.DB
+}
+
+// NewDeflectionRepository crea un nuevo repositorio de sugerencias de FAQs
+func NewDeflectionRepository(db *sql.DB) *DeflectionRepository {
+	return &DeflectionRepository{db: db}
+}
+
+const deflectionColumns = `session_id, article_id, widget_id, query, locale, shown_at, solved_at`
+
+// scanDeflection escanea una fila de faq_deflections
+func scanDeflection(row rowScanner) (models.FAQDeflection, error) {
+	var deflection models.FAQDeflection
+	var widgetID, query, locale sql.NullString
+	var solvedAt sql.NullTime
+
+	err := row.Scan(
+		&deflection.SessionID,
+		&deflection.ArticleID,
+		&widgetID,
+		&query,
+		&locale,
+		&deflection.ShownAt,
+		&solvedAt,
+	)
+	if err != nil {
+		return deflection, err
+	}
+
+	deflection.WidgetID = widgetID.String
+	deflection.Query = query.String
+	deflection.Locale = locale.String
+	if solvedAt.Valid {
+		deflection.SolvedAt = &solvedAt.Time
+	}
+
+	return deflection, nil
+}
+
+// Record registra las FAQs sugeridas en una sesión; las ya registradas se ignoran
+func (r *DeflectionRepository) Record(deflections []models.FAQDeflection) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, deflection := range deflections {
+		_, err := tx.Exec(`
+			INSERT INTO faq_deflections (session_id, article_id, widget_id, query, locale, shown_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (session_id, article_id) DO NOTHING
+		`,
+			deflection.SessionID,
+			deflection.ArticleID,
+			nullString(deflection.WidgetID),
+			nullString(deflection.Query),
+			nullString(deflection.Locale),
+			deflection.ShownAt,
+		)
+		if err != nil {
+			return fmt.Errorf("error al registrar sugerencia de FAQ: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return nil
+}
+
+// MarkSolved indica que una FAQ sugerida resolvió el problema del visitante
+func (r *DeflectionRepository) MarkSolved(sessionID string, articleID int, solvedAt time.Time) (*models.FAQDeflection, error) {
+	deflection, err := scanDeflection(r.db.QueryRow(`
+		UPDATE faq_deflections
+		SET solved_at = COALESCE(solved_at, $3)
+		WHERE session_id = $1 AND article_id = $2
+		RETURNING `+deflectionColumns,
+		sessionID, articleID, solvedAt,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("la FAQ %d no se sugirió en la sesión %s", articleID, sessionID)
+		}
+		return nil, fmt.Errorf("error al guardar FAQ resuelta: %v", err)
+	}
+	return &deflection, nil
+}
+
+// GetAll obtiene las sugerencias registradas en el período, las más recientes primero
+func (r *DeflectionRepository) GetAll(filter models.DeflectionFilter) ([]models.FAQDeflection, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		conditions = append(conditions, fmt.Sprintf("shown_at >= $%d", len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		conditions = append(conditions, fmt.Sprintf("shown_at < $%d", len(args)))
+	}
+
+	query := `SELECT ` + deflectionColumns + ` FROM faq_deflections`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY shown_at DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar sugerencias de FAQs: %v", err)
+	}
+	defer rows.Close()
+
+	deflections := make([]models.FAQDeflection, 0)
+	for rows.Next() {
+		deflection, err := scanDeflection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear sugerencia de FAQ: %v", err)
+		}
+		deflections = append(deflections, deflection)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar sugerencias de FAQs: %v", err)
+	}
+
+	return deflections, nil
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// FAQFeedbackRepository maneja las operaciones de base de datos para las visitas y
+// los votos de utilidad de las FAQs
+type FAQFeedbackRepository struct {
+	db *sql.DB
+}
+
+// NewFAQFeedbackRepository crea un nuevo repositorio de visitas y votos de FAQs
+func NewFAQFeedbackRepository(db *sql.DB) *FAQFeedbackRepository {
+	return &FAQFeedbackRepository{db: db}
+}
+
+const faqViewColumns = `session_id, article_id, widget_id, locale, view_count, first_viewed_at, last_viewed_at, ticket_id`
+
+const faqVoteColumns = `session_id, article_id, widget_id, helpful, comment, created_at, updated_at`
+
+// scanFAQView escanea una fila de faq_views
+func scanFAQView(row rowScanner) (models.FAQView, error) {
+	var view models.FAQView
+	var widgetID, locale, ticketID sql.NullString
+
+	err := row.Scan(
+		&view.SessionID,
+		&view.ArticleID,
+		&widgetID,
+		&locale,
+		&view.Count,
+		&view.FirstViewedAt,
+		&view.LastViewedAt,
+		&ticketID,
+	)
+	if err != nil {
+		return view, err
+	}
+
+	view.WidgetID = widgetID.String
+	view.Locale = locale.String
+	view.TicketID = ticketID.String
+
+	return view, nil
+}
+
+// scanFAQVote escanea una fila de faq_votes
+func scanFAQVote(row rowScanner) (models.FAQVote, error) {
+	var vote models.FAQVote
+	var widgetID, comment sql.NullString
+
+	err := row.Scan(
+		&vote.SessionID,
+		&vote.ArticleID,
+		&widgetID,
+		&vote.Helpful,
+		&comment,
+		&vote.CreatedAt,
+		&vote.UpdatedAt,
+	)
+	if err != nil {
+		return vote, err
+	}
+
+	vote.WidgetID = widgetID.String
+	vote.Comment = comment.String
+
+	return vote, nil
+}
+
+// activityConditions construye el filtro de fechas sobre la columna indicada
+func activityConditions(column string, filter models.FAQActivityFilter) (string, []interface{}) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		conditions = append(conditions, fmt.Sprintf("%s >= $%d", column, len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		conditions = append(conditions, fmt.Sprintf("%s < $%d", column, len(args)))
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// RecordView suma una visita de la sesión a la FAQ
+func (r *FAQFeedbackRepository) RecordView(view models.FAQView) (*models.FAQView, error) {
+	recorded, err := scanFAQView(r.db.QueryRow(`
+		INSERT INTO faq_views (session_id, article_id, widget_id, locale, view_count, first_viewed_at, last_viewed_at)
+		VALUES ($1, $2, $3, $4, 1, $5, $5)
+		ON CONFLICT (session_id, article_id) DO UPDATE
+		SET view_count = faq_views.view_count + 1, last_viewed_at = EXCLUDED.last_viewed_at
+		RETURNING `+faqViewColumns,
+		view.SessionID,
+		view.ArticleID,
+		nullString(view.WidgetID),
+		nullString(view.Locale),
+		view.FirstViewedAt,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error al registrar visita de FAQ: %v", err)
+	}
+	return &recorded, nil
+}
+
+// LinkViewsToTicket asocia a un ticket las FAQs vistas en la sesión que aún no tenían ticket
+func (r *FAQFeedbackRepository) LinkViewsToTicket(sessionID, ticketID string) error {
+	_, err := r.db.Exec(`
+		UPDATE faq_views SET ticket_id = $2
+		WHERE session_id = $1 AND ticket_id IS NULL
+	`, sessionID, ticketID)
+	if err != nil {
+		return fmt.Errorf("error al asociar visitas de FAQs al ticket: %v", err)
+	}
+	return nil
+}
+
+// GetViews obtiene las visitas de las sesiones que empezaron en el período
+func (r *FAQFeedbackRepository) GetViews(filter models.FAQActivityFilter) ([]models.FAQView, error) {
+	where, args := activityConditions("first_viewed_at", filter)
+	rows, err := r.db.Query(`SELECT `+faqViewColumns+` FROM faq_views`+where+` ORDER BY first_viewed_at DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar visitas de FAQs: %v", err)
+	}
+	defer rows.Close()
+
+	views := make([]models.FAQView, 0)
+	for rows.Next() {
+		view, err := scanFAQView(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear visita de FAQ: %v", err)
+		}
+		views = append(views, view)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar visitas de FAQs: %v", err)
+	}
+
+	return views, nil
+}
+
+// SaveVote guarda el voto de la sesión para la FAQ, reemplazando el anterior
+func (r *FAQFeedbackRepository) SaveVote(vote models.FAQVote) (*models.FAQVote, error) {
+	saved, err := scanFAQVote(r.db.QueryRow(`
+		INSERT INTO faq_votes (session_id, article_id, widget_id, helpful, comment, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (session_id, article_id) DO UPDATE
+		SET widget_id = EXCLUDED.widget_id, helpful = EXCLUDED.helpful,
+			comment = EXCLUDED.comment, updated_at = EXCLUDED.updated_at
+		RETURNING `+faqVoteColumns,
+		vote.SessionID,
+		vote.ArticleID,
+		nullString(vote.WidgetID),
+		vote.Helpful,
+		nullString(vote.Comment),
+		vote.CreatedAt,
+		vote.UpdatedAt,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error al guardar voto de FAQ: %v", err)
+	}
+	return &saved, nil
+}
+
+// GetVotes obtiene los votos emitidos o cambiados en el período, los más recientes primero
+func (r *FAQFeedbackRepository) GetVotes(filter models.FAQActivityFilter) ([]models.FAQVote, error) {
+	where, args := activityConditions("updated_at", filter)
+	rows, err := r.db.Query(`SELECT `+faqVoteColumns+` FROM faq_votes`+where+` ORDER BY updated_at DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar votos de FAQs: %v", err)
+	}
+	defer rows.Close()
+
+	votes := make([]models.FAQVote, 0)
+	for rows.Next() {
+		vote, err := scanFAQVote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear voto de FAQ: %v", err)
+		}
+		votes = append(votes, vote)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar votos de FAQs: %v", err)
+	}
+
+	return votes, nil
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// InvitationRepository maneja las operaciones de base de datos para las invitaciones de usuarios
+type InvitationRepository struct {
+	db *sql.DB
+}
+
+// NewInvitationRepository crea un nuevo repositorio de invitaciones
+func NewInvitationRepository(db *sql.DB) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+// invitationColumns son las columnas leídas por scanInvitation
+const invitationColumns = `
+	id, email, first_name, last_name, role, department, token_hash, invited_by, send_count,
+	sent_at, expires_at, accepted_at, revoked_at, user_id, created_at, updated_at
+`
+
+// scanInvitation escanea una fila con las columnas de invitationColumns
+func scanInvitation(row rowScanner) (models.Invitation, error) {
+	var invitation models.Invitation
+	var firstName, lastName, department, invitedBy, userID sql.NullString
+	var acceptedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.Email,
+		&firstName,
+		&lastName,
+		&invitation.Role,
+		&department,
+		&invitation.TokenHash,
+		&invitedBy,
+		&invitation.SendCount,
+		&invitation.SentAt,
+		&invitation.ExpiresAt,
+		&acceptedAt,
+		&revokedAt,
+		&userID,
+		&invitation.CreatedAt,
+		&invitation.UpdatedAt,
+	)
+	if err != nil {
+		return invitation, err
+	}
+
+	// Asignar valores nulos
+	invitation.FirstName = firstName.String
+	invitation.LastName = lastName.String
+	invitation.Department = department.String
+	invitation.InvitedBy = invitedBy.String
+	invitation.UserID = userID.String
+	if acceptedAt.Valid {
+		invitation.AcceptedAt = &acceptedAt.Time
+	}
+	if revokedAt.Valid {
+		invitation.RevokedAt = &revokedAt.Time
+	}
+
+	return invitation, nil
+}
+
+// GetAll obtiene todas las invitaciones, de la más reciente a la más antigua
+func (r *InvitationRepository) GetAll() ([]models.Invitation, error) {
+	rows, err := r.db.Query(`SELECT ` + invitationColumns + ` FROM invitations ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar invitaciones: %v", err)
+	}
+	defer rows.Close()
+
+	invitations := make([]models.Invitation, 0)
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear invitación: %v", err)
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar invitaciones: %v", err)
+	}
+
+	return invitations, nil
+}
+
+// GetByID obtiene una invitación por su ID
+func (r *InvitationRepository) GetByID(id string) (*models.Invitation, error) {
+	invitation, err := scanInvitation(r.db.QueryRow(`SELECT `+invitationColumns+` FROM invitations WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invitación con ID %s no encontrada", id)
+		}
+		return nil, fmt.Errorf("error al consultar invitación: %v", err)
+	}
+	return &invitation, nil
+}
+
+// GetByTokenHash obtiene la invitación cuyo token tiene el hash indicado
+func (r *InvitationRepository) GetByTokenHash(hash string) (*models.Invitation, error) {
+	invitation, err := scanInvitation(r.db.QueryRow(`SELECT `+invitationColumns+` FROM invitations WHERE token_hash = $1`, hash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invitación no encontrada")
+		}
+		return nil, fmt.Errorf("error al consultar invitación: %v", err)
+	}
+	return &invitation, nil
+}
+
+// Create crea una invitación
+func (r *InvitationRepository) Create(invitation models.Invitation) error {
+	query := `
+		INSERT INTO invitations (
+			id, email, first_name, last_name, role, department, token_hash, invited_by,
+			send_count, sent_at, expires_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := r.db.Exec(
+		query,
+		invitation.ID,
+		invitation.Email,
+		nullString(invitation.FirstName),
+		nullString(invitation.LastName),
+		invitation.Role,
+		nullString(invitation.Department),
+		invitation.TokenHash,
+		nullString(invitation.InvitedBy),
+		invitation.SendCount,
+		invitation.SentAt,
+		invitation.ExpiresAt,
+		invitation.CreatedAt,
+		invitation.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al crear invitación: %v", err)
+	}
+
+	return nil
+}
+
+// Update actualiza el token, los envíos y la revocación de una invitación
+func (r *InvitationRepository) Update(invitation models.Invitation) error {
+	query := `
+		UPDATE invitations
+		SET token_hash = $2, send_count = $3, sent_at = $4, expires_at = $5,
+		    revoked_at = $6, updated_at = $7
+		WHERE id = $1
+	`
+
+	var revokedAt sql.NullTime
+	if invitation.RevokedAt != nil {
+		revokedAt = sql.NullTime{Time: *invitation.RevokedAt, Valid: true}
+	}
+
+	result, err := r.db.Exec(
+		query,
+		invitation.ID,
+		invitation.TokenHash,
+		invitation.SendCount,
+		invitation.SentAt,
+		invitation.ExpiresAt,
+		revokedAt,
+		invitation.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar invitación: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("invitación con ID %s no encontrada", invitation.ID)
+	}
+
+	return nil
+}
+
+// Accept crea el usuario del invitado y marca la invitación como aceptada en una
+// transacción. Falla si la invitación ya no está pendiente o si el email ya existe.
+func (r *InvitationRepository) Accept(id string, user models.User) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM users WHERE lower(email) = lower($1))`, user.Email).Scan(&exists); err != nil {
+		return fmt.Errorf("error al consultar usuario por email: %v", err)
+	}
+	if exists {
+		return fmt.Errorf("ya existe un usuario con el email %s", user.Email)
+	}
+
+	if _, err := insertUser(tx, user); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE invitations
+		SET accepted_at = $2, user_id = $3, updated_at = $2
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $2
+	`, id, now, user.ID)
+	if err != nil {
+		return fmt.Errorf("error al aceptar invitación: %v", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("la invitación ya no está pendiente")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return nil
+}
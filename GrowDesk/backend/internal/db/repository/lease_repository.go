@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// LeaseRepository maneja los turnos (leases) que coordinan tareas periódicas entre
+// varias réplicas del servidor
+type LeaseRepository struct {
+	db *sql.DB
+}
+
+// NewLeaseRepository crea un nuevo repositorio de turnos
+func NewLeaseRepository(db *sql.DB) *LeaseRepository {
+	return &LeaseRepository{db: db}
+}
+
+// Acquire obtiene o renueva el turno name para holder durante ttl. Devuelve false si
+// otra réplica tiene un turno vigente.
+func (r *LeaseRepository) Acquire(name, holder string, ttl time.Duration) (bool, error) {
+	var current string
+	err := r.db.QueryRow(`
+		INSERT INTO scheduler_leases (name, holder, expires_at)
+		VALUES ($1, $2, NOW() + $3 * INTERVAL '1 millisecond')
+		ON CONFLICT (name) DO UPDATE
+		SET holder = EXCLUDED.holder, expires_at = EXCLUDED.expires_at
+		WHERE scheduler_leases.holder = EXCLUDED.holder OR scheduler_leases.expires_at < NOW()
+		RETURNING holder
+	`, name, holder, ttl.Milliseconds()).Scan(&current)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error al obtener turno %s: %v", name, err)
+	}
+
+	return current == holder, nil
+}
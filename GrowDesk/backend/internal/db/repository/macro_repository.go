@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// MacroRepository maneja las operaciones de base de datos para las macros de agentes
+type MacroRepository struct {
+	db *sql.DB
+}
+
+// NewMacroRepository crea un nuevo repositorio de macros
+func NewMacroRepository(db *sql.DB) *MacroRepository {
+	return &MacroRepository{db: db}
+}
+
+const macroColumns = `id, name, content, is_internal, shared, owner_id, actions, created_at, updated_at`
+
+// scanMacro escanea una fila de macros
+func scanMacro(row rowScanner) (models.Macro, error) {
+	var macro models.Macro
+	var content, ownerID sql.NullString
+	var actionsJSON []byte
+
+	err := row.Scan(
+		&macro.ID,
+		&macro.Name,
+		&content,
+		&macro.IsInternal,
+		&macro.Shared,
+		&ownerID,
+		&actionsJSON,
+		&macro.CreatedAt,
+		&macro.UpdatedAt,
+	)
+	if err != nil {
+		return macro, err
+	}
+
+	macro.Content = content.String
+	macro.OwnerID = ownerID.String
+	if len(actionsJSON) > 0 {
+		if err := json.Unmarshal(actionsJSON, &macro.Actions); err != nil {
+			return macro, fmt.Errorf("error al analizar acciones de la macro: %v", err)
+		}
+	}
+
+	return macro, nil
+}
+
+// GetAll obtiene todas las macros ordenadas por nombre
+func (r *MacroRepository) GetAll() ([]models.Macro, error) {
+	rows, err := r.db.Query(`SELECT ` + macroColumns + ` FROM macros ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar macros: %v", err)
+	}
+	defer rows.Close()
+
+	macros := make([]models.Macro, 0)
+	for rows.Next() {
+		macro, err := scanMacro(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear macro: %v", err)
+		}
+		macros = append(macros, macro)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar macros: %v", err)
+	}
+
+	return macros, nil
+}
+
+// GetByID obtiene una macro por su ID
+func (r *MacroRepository) GetByID(id string) (*models.Macro, error) {
+	macro, err := scanMacro(r.db.QueryRow(`SELECT `+macroColumns+` FROM macros WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("macro con ID %s no encontrada", id)
+		}
+		return nil, fmt.Errorf("error al consultar macro: %v", err)
+	}
+	return &macro, nil
+}
+
+// Create crea una nueva macro
+func (r *MacroRepository) Create(macro models.Macro) (*models.Macro, error) {
+	if macro.ID == "" {
+		macro.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	if macro.CreatedAt.IsZero() {
+		macro.CreatedAt = now
+	}
+	macro.UpdatedAt = now
+
+	actionsJSON, err := json.Marshal(macro.Actions)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar acciones de la macro: %v", err)
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO macros (id, name, content, is_internal, shared, owner_id, actions, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`,
+		macro.ID,
+		macro.Name,
+		nullString(macro.Content),
+		macro.IsInternal,
+		macro.Shared,
+		nullString(macro.OwnerID),
+		actionsJSON,
+		macro.CreatedAt,
+		macro.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error al crear macro: %v", err)
+	}
+
+	return &macro, nil
+}
+
+// Update actualiza una macro existente
+func (r *MacroRepository) Update(macro models.Macro) error {
+	macro.UpdatedAt = time.Now()
+
+	actionsJSON, err := json.Marshal(macro.Actions)
+	if err != nil {
+		return fmt.Errorf("error al serializar acciones de la macro: %v", err)
+	}
+
+	result, err := r.db.Exec(`
+		UPDATE macros
+		SET name = $2, content = $3, is_internal = $4, shared = $5, owner_id = $6, actions = $7, updated_at = $8
+		WHERE id = $1
+	`,
+		macro.ID,
+		macro.Name,
+		nullString(macro.Content),
+		macro.IsInternal,
+		macro.Shared,
+		nullString(macro.OwnerID),
+		actionsJSON,
+		macro.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar macro: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("macro con ID %s no encontrada", macro.ID)
+	}
+
+	return nil
+}
+
+// Delete elimina una macro
+func (r *MacroRepository) Delete(id string) error {
+	result, err := r.db.Exec("DELETE FROM macros WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar macro: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("macro con ID %s no encontrada", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/lib/pq"
+)
+
+// NotificationRepository maneja las operaciones de base de datos para las notificaciones de usuarios
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository crea un nuevo repositorio de notificaciones
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// GetByUser obtiene las notificaciones de un usuario, las más recientes primero
+func (r *NotificationRepository) GetByUser(userID string, unreadOnly bool) ([]models.Notification, error) {
+	rows, err := r.db.Query(`
+		SELECT id, user_id, message, type, read, COALESCE(related_id, ''), COALESCE(related_type, ''), created_at
+		FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR NOT read)
+		ORDER BY created_at DESC
+	`, userID, unreadOnly)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar notificaciones: %v", err)
+	}
+	defer rows.Close()
+
+	notifications := make([]models.Notification, 0)
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Message, &n.Type, &n.Read, &n.RelatedID, &n.RelatedType, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error al escanear notificación: %v", err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar notificaciones: %v", err)
+	}
+
+	return notifications, nil
+}
+
+// Create guarda una notificación. Si el usuario no existe en la base de datos
+// (p. ej. en modo desarrollo) la notificación se descarta.
+func (r *NotificationRepository) Create(n models.Notification) error {
+	if n.ID == "" {
+		n.ID = uuid.New().String()
+	}
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO notifications (id, user_id, message, type, read, related_id, related_type, created_at)
+		SELECT $1, id, $3, $4, $5, $6, $7, $8 FROM users WHERE id = $2
+	`, n.ID, n.UserID, n.Message, n.Type, n.Read, nullString(n.RelatedID), nullString(n.RelatedType), n.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error al crear notificación: %v", err)
+	}
+
+	return nil
+}
+
+// MarkRead marca como leídas las notificaciones indicadas de un usuario, o todas si ids está vacío
+func (r *NotificationRepository) MarkRead(userID string, ids []string) error {
+	var err error
+	if len(ids) == 0 {
+		_, err = r.db.Exec("UPDATE notifications SET read = TRUE WHERE user_id = $1 AND NOT read", userID)
+	} else {
+		_, err = r.db.Exec("UPDATE notifications SET read = TRUE WHERE user_id = $1 AND id = ANY($2)", userID, pq.Array(ids))
+	}
+	if err != nil {
+		return fmt.Errorf("error al marcar notificaciones como leídas: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// PasswordResetRepository maneja las operaciones de base de datos para los
+// restablecimientos de contraseña
+type PasswordResetRepository struct {
+	db *sql.DB
+}
+
+// NewPasswordResetRepository crea un nuevo repositorio de restablecimientos de contraseña
+func NewPasswordResetRepository(db *sql.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+// Create guarda un restablecimiento y anula los pendientes del mismo usuario en una
+// transacción
+func (r *PasswordResetRepository) Create(reset models.PasswordReset) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE password_resets SET used_at = $2
+		WHERE user_id = $1 AND used_at IS NULL
+	`, reset.UserID, time.Now()); err != nil {
+		return fmt.Errorf("error al anular restablecimientos anteriores: %v", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO password_resets (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, reset.ID, reset.UserID, reset.TokenHash, reset.ExpiresAt, reset.CreatedAt); err != nil {
+		return fmt.Errorf("error al crear restablecimiento de contraseña: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return nil
+}
+
+// GetByTokenHash obtiene el restablecimiento cuyo token tiene el hash indicado
+func (r *PasswordResetRepository) GetByTokenHash(hash string) (*models.PasswordReset, error) {
+	var reset models.PasswordReset
+	var usedAt sql.NullTime
+
+	err := r.db.QueryRow(`
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM password_resets
+		WHERE token_hash = $1
+	`, hash).Scan(
+		&reset.ID,
+		&reset.UserID,
+		&reset.TokenHash,
+		&reset.ExpiresAt,
+		&usedAt,
+		&reset.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("restablecimiento de contraseña no encontrado")
+		}
+		return nil, fmt.Errorf("error al consultar restablecimiento de contraseña: %v", err)
+	}
+
+	if usedAt.Valid {
+		reset.UsedAt = &usedAt.Time
+	}
+
+	return &reset, nil
+}
+
+// Consume marca el restablecimiento como usado y cambia la contraseña del usuario en una
+// transacción. Falla si el token ya se usó o caducó.
+func (r *PasswordResetRepository) Consume(id string, passwordHash string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	var userID string
+	err = tx.QueryRow(`
+		UPDATE password_resets SET used_at = $2
+		WHERE id = $1 AND used_at IS NULL AND expires_at > $2
+		RETURNING user_id
+	`, id, time.Now()).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("el restablecimiento de contraseña ya no es válido")
+		}
+		return fmt.Errorf("error al consumir restablecimiento de contraseña: %v", err)
+	}
+
+	if err := updatePassword(tx, userID, passwordHash); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// PreChatFormRepository maneja las operaciones de base de datos para los formularios previos al chat
+type PreChatFormRepository struct {
+	db *sql.DB
+}
+
+// NewPreChatFormRepository crea un nuevo repositorio de formularios previos al chat
+func NewPreChatFormRepository(db *sql.DB) *PreChatFormRepository {
+	return &PreChatFormRepository{db: db}
+}
+
+const preChatFormColumns = `id, widget_id, title, description, fields, active, created_at, updated_at`
+
+// scanPreChatForm escanea una fila de prechat_forms
+func scanPreChatForm(row rowScanner) (models.PreChatForm, error) {
+	var form models.PreChatForm
+	var description sql.NullString
+	var fieldsJSON []byte
+
+	err := row.Scan(
+		&form.ID,
+		&form.WidgetID,
+		&form.Title,
+		&description,
+		&fieldsJSON,
+		&form.Active,
+		&form.CreatedAt,
+		&form.UpdatedAt,
+	)
+	if err != nil {
+		return form, err
+	}
+
+	form.Description = description.String
+	form.Fields = make([]models.PreChatField, 0)
+	if len(fieldsJSON) > 0 {
+		if err := json.Unmarshal(fieldsJSON, &form.Fields); err != nil {
+			return form, fmt.Errorf("error al analizar campos del formulario: %v", err)
+		}
+	}
+
+	return form, nil
+}
+
+// GetAll obtiene todos los formularios
+func (r *PreChatFormRepository) GetAll() ([]models.PreChatForm, error) {
+	rows, err := r.db.Query(`SELECT ` + preChatFormColumns + ` FROM prechat_forms ORDER BY widget_id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar formularios: %v", err)
+	}
+	defer rows.Close()
+
+	forms := make([]models.PreChatForm, 0)
+	for rows.Next() {
+		form, err := scanPreChatForm(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear formulario: %v", err)
+		}
+		forms = append(forms, form)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar formularios: %v", err)
+	}
+
+	return forms, nil
+}
+
+// GetByID obtiene un formulario por su ID
+func (r *PreChatFormRepository) GetByID(id string) (*models.PreChatForm, error) {
+	form, err := scanPreChatForm(r.db.QueryRow(`SELECT `+preChatFormColumns+` FROM prechat_forms WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("formulario con ID %s no encontrado", id)
+		}
+		return nil, fmt.Errorf("error al consultar formulario: %v", err)
+	}
+	return &form, nil
+}
+
+// GetByWidget obtiene el formulario asignado a un widget
+func (r *PreChatFormRepository) GetByWidget(widgetID string) (*models.PreChatForm, error) {
+	form, err := scanPreChatForm(r.db.QueryRow(`SELECT `+preChatFormColumns+` FROM prechat_forms WHERE widget_id = $1`, widgetID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("formulario para el widget %s no encontrado", widgetID)
+		}
+		return nil, fmt.Errorf("error al consultar formulario: %v", err)
+	}
+	return &form, nil
+}
+
+// Create crea un nuevo formulario
+func (r *PreChatFormRepository) Create(form models.PreChatForm) (*models.PreChatForm, error) {
+	if form.ID == "" {
+		form.ID = uuid.New().String()
+	}
+
+	now := time.Now()
+	if form.CreatedAt.IsZero() {
+		form.CreatedAt = now
+	}
+	form.UpdatedAt = now
+
+	fieldsJSON, err := marshalPreChatFields(form.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO prechat_forms (id, widget_id, title, description, fields, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		form.ID,
+		form.WidgetID,
+		form.Title,
+		nullString(form.Description),
+		fieldsJSON,
+		form.Active,
+		form.CreatedAt,
+		form.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error al crear formulario: %v", err)
+	}
+
+	return &form, nil
+}
+
+// Update actualiza un formulario existente
+func (r *PreChatFormRepository) Update(form models.PreChatForm) error {
+	form.UpdatedAt = time.Now()
+
+	fieldsJSON, err := marshalPreChatFields(form.Fields)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(`
+		UPDATE prechat_forms
+		SET widget_id = $2, title = $3, description = $4, fields = $5, active = $6, updated_at = $7
+		WHERE id = $1
+	`,
+		form.ID,
+		form.WidgetID,
+		form.Title,
+		nullString(form.Description),
+		fieldsJSON,
+		form.Active,
+		form.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar formulario: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("formulario con ID %s no encontrado", form.ID)
+	}
+
+	return nil
+}
+
+// Delete elimina un formulario
+func (r *PreChatFormRepository) Delete(id string) error {
+	result, err := r.db.Exec("DELETE FROM prechat_forms WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar formulario: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("formulario con ID %s no encontrado", id)
+	}
+
+	return nil
+}
+
+// marshalPreChatFields serializa los campos del formulario para la columna JSONB
+func marshalPreChatFields(fields []models.PreChatField) ([]byte, error) {
+	if fields == nil {
+		fields = []models.PreChatField{}
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar campos del formulario: %v", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// SurveyRepository maneja las operaciones de base de datos para las encuestas de satisfacción
+type SurveyRepository struct {
+	db *sql.DB
+}
+
+// NewSurveyRepository crea un nuevo repositorio de encuestas
+func NewSurveyRepository(db *sql.DB) *SurveyRepository {
+	return &SurveyRepository{db: db}
+}
+
+const surveyColumns = `id, token_hash, ticket_id, agent_id, category_id, category, rating, comment, created_at, expires_at, responded_at`
+
+// scanSurvey escanea una fila de satisfaction_surveys
+func scanSurvey(row rowScanner) (models.SatisfactionSurvey, error) {
+	var survey models.SatisfactionSurvey
+	var agentID, categoryID, category, comment sql.NullString
+	var rating sql.NullInt64
+	var respondedAt sql.NullTime
+
+	err := row.Scan(
+		&survey.ID,
+		&survey.TokenHash,
+		&survey.TicketID,
+		&agentID,
+		&categoryID,
+		&category,
+		&rating,
+		&comment,
+		&survey.CreatedAt,
+		&survey.ExpiresAt,
+		&respondedAt,
+	)
+	if err != nil {
+		return survey, err
+	}
+
+	survey.AgentID = agentID.String
+	survey.CategoryID = categoryID.String
+	survey.Category = category.String
+	survey.Comment = comment.String
+	if rating.Valid {
+		value := int(rating.Int64)
+		survey.Rating = &value
+	}
+	if respondedAt.Valid {
+		survey.RespondedAt = &respondedAt.Time
+	}
+
+	return survey, nil
+}
+
+// GetAll obtiene las encuestas que cumplen el filtro, las más recientes primero
+func (r *SurveyRepository) GetAll(filter models.SurveyFilter) ([]models.SatisfactionSurvey, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	if filter.TicketID != "" {
+		args = append(args, filter.TicketID)
+		conditions = append(conditions, fmt.Sprintf("ticket_id = $%d", len(args)))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	query := `SELECT ` + surveyColumns + ` FROM satisfaction_surveys`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar encuestas: %v", err)
+	}
+	defer rows.Close()
+
+	surveys := make([]models.SatisfactionSurvey, 0)
+	for rows.Next() {
+		survey, err := scanSurvey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear encuesta: %v", err)
+		}
+		surveys = append(surveys, survey)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar encuestas: %v", err)
+	}
+
+	return surveys, nil
+}
+
+// GetByTokenHash obtiene una encuesta por el hash de su token
+func (r *SurveyRepository) GetByTokenHash(tokenHash string) (*models.SatisfactionSurvey, error) {
+	survey, err := scanSurvey(r.db.QueryRow(`SELECT `+surveyColumns+` FROM satisfaction_surveys WHERE token_hash = $1`, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("encuesta no encontrada")
+		}
+		return nil, fmt.Errorf("error al consultar encuesta: %v", err)
+	}
+	return &survey, nil
+}
+
+// Create guarda una nueva encuesta
+func (r *SurveyRepository) Create(survey models.SatisfactionSurvey) error {
+	if survey.ID == "" {
+		survey.ID = uuid.New().String()
+	}
+	if survey.CreatedAt.IsZero() {
+		survey.CreatedAt = time.Now()
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO satisfaction_surveys (id, token_hash, ticket_id, agent_id, category_id, category, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		survey.ID,
+		survey.TokenHash,
+		survey.TicketID,
+		nullString(survey.AgentID),
+		nullString(survey.CategoryID),
+		nullString(survey.Category),
+		survey.CreatedAt,
+		survey.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al crear encuesta: %v", err)
+	}
+
+	return nil
+}
+
+// UpdateTokenHash reemplaza el token de una encuesta; el enlace anterior deja de servir
+func (r *SurveyRepository) UpdateTokenHash(id, tokenHash string) error {
+	result, err := r.db.Exec(`UPDATE satisfaction_surveys SET token_hash = $2 WHERE id = $1`, id, tokenHash)
+	if err != nil {
+		return fmt.Errorf("error al actualizar token de encuesta: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("encuesta no encontrada")
+	}
+
+	return nil
+}
+
+// Answer guarda la respuesta del cliente si la encuesta no fue respondida antes
+func (r *SurveyRepository) Answer(tokenHash string, rating int, comment string) (*models.SatisfactionSurvey, error) {
+	survey, err := scanSurvey(r.db.QueryRow(`
+		UPDATE satisfaction_surveys
+		SET rating = $2, comment = $3, responded_at = NOW()
+		WHERE token_hash = $1 AND rating IS NULL
+		RETURNING `+surveyColumns,
+		tokenHash, rating, nullString(comment),
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("la encuesta no existe o ya fue respondida")
+		}
+		return nil, fmt.Errorf("error al guardar respuesta de encuesta: %v", err)
+	}
+	return &survey, nil
+}
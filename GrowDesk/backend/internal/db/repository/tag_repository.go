@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/lib/pq"
+)
+
+// sqlRunner es implementado por *sql.DB y *sql.Tx
+type sqlRunner interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// likeEscaper escapa los comodines de LIKE para buscar por prefijo literal
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// TagRepository maneja las operaciones de base de datos para las etiquetas de tickets
+type TagRepository struct {
+	db *sql.DB
+}
+
+// NewTagRepository crea un nuevo repositorio de etiquetas
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// Search obtiene las etiquetas que empiezan por prefix, las más usadas primero
+func (r *TagRepository) Search(prefix string, limit int) ([]models.Tag, error) {
+	query := `
+		SELECT id, name, COALESCE(color, ''), usage_count, created_at, updated_at
+		FROM tags
+		WHERE name LIKE $1
+		ORDER BY usage_count DESC, name ASC
+	`
+	args := []interface{}{likeEscaper.Replace(prefix) + "%"}
+	if limit > 0 {
+		query += " LIMIT $2"
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar etiquetas: %v", err)
+	}
+	defer rows.Close()
+
+	result := make([]models.Tag, 0)
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Color, &tag.UsageCount, &tag.CreatedAt, &tag.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error al escanear etiqueta: %v", err)
+		}
+		result = append(result, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar etiquetas: %v", err)
+	}
+
+	return result, nil
+}
+
+// AddToTicket añade etiquetas a un ticket y devuelve sus etiquetas resultantes
+func (r *TagRepository) AddToTicket(ticketID string, names []string) ([]string, error) {
+	return r.changeTicketTags(ticketID, func(tx *sql.Tx) (bool, error) {
+		return attachTags(tx, ticketID, names)
+	})
+}
+
+// RemoveFromTicket quita etiquetas de un ticket y devuelve sus etiquetas resultantes
+func (r *TagRepository) RemoveFromTicket(ticketID string, names []string) ([]string, error) {
+	return r.changeTicketTags(ticketID, func(tx *sql.Tx) (bool, error) {
+		return detachTags(tx, ticketID, names)
+	})
+}
+
+// changeTicketTags ejecuta un cambio de etiquetas en una transacción que bloquea el ticket
+func (r *TagRepository) changeTicketTags(ticketID string, change func(tx *sql.Tx) (bool, error)) ([]string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	var id string
+	err = tx.QueryRow("SELECT id FROM tickets WHERE id = $1 FOR UPDATE", ticketID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("ticket con ID %s no encontrado", ticketID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar ticket: %v", err)
+	}
+
+	changed, err := change(tx)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		if _, err := tx.Exec("UPDATE tickets SET updated_at = $2 WHERE id = $1", ticketID, time.Now()); err != nil {
+			return nil, fmt.Errorf("error al actualizar ticket: %v", err)
+		}
+	}
+
+	names, err := loadTicketTags(tx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return names, nil
+}
+
+// Report resume los tickets de cada etiqueta por estado
+func (r *TagRepository) Report() ([]models.TagReport, error) {
+	rows, err := r.db.Query(`
+		SELECT tg.name, t.status, COUNT(*)
+		FROM ticket_tags tt
+		JOIN tags tg ON tg.id = tt.tag_id
+		JOIN tickets t ON t.id = tt.ticket_id
+		GROUP BY tg.name, t.status
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar reporte de etiquetas: %v", err)
+	}
+	defer rows.Close()
+
+	reports := make([]models.TagReport, 0)
+	index := make(map[string]int)
+	for rows.Next() {
+		var name, status string
+		var count int
+		if err := rows.Scan(&name, &status, &count); err != nil {
+			return nil, fmt.Errorf("error al escanear reporte de etiquetas: %v", err)
+		}
+
+		i, ok := index[name]
+		if !ok {
+			reports = append(reports, models.TagReport{Tag: name, ByStatus: make(map[string]int)})
+			i = len(reports) - 1
+			index[name] = i
+		}
+		reports[i].Total += count
+		reports[i].ByStatus[status] += count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar reporte de etiquetas: %v", err)
+	}
+
+	return sortTagReports(reports), nil
+}
+
+// sortTagReports ordena el reporte por número de tickets y luego por nombre
+func sortTagReports(reports []models.TagReport) []models.TagReport {
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Total != reports[j].Total {
+			return reports[i].Total > reports[j].Total
+		}
+		return reports[i].Tag < reports[j].Tag
+	})
+	return reports
+}
+
+// attachTags enlaza etiquetas a un ticket creando las que no existen y actualizando su uso
+func attachTags(q sqlRunner, ticketID string, names []string) (bool, error) {
+	changed := false
+	for _, name := range names {
+		var tagID string
+		err := q.QueryRow(`
+			INSERT INTO tags (id, name, usage_count, created_at, updated_at)
+			VALUES ($1, $2, 0, NOW(), NOW())
+			ON CONFLICT (name) DO UPDATE SET updated_at = NOW()
+			RETURNING id
+		`, uuid.New().String(), name).Scan(&tagID)
+		if err != nil {
+			return false, fmt.Errorf("error al crear etiqueta %s: %v", name, err)
+		}
+
+		result, err := q.Exec(`
+			INSERT INTO ticket_tags (ticket_id, tag_id, created_at)
+			VALUES ($1, $2, NOW())
+			ON CONFLICT DO NOTHING
+		`, ticketID, tagID)
+		if err != nil {
+			return false, fmt.Errorf("error al etiquetar ticket: %v", err)
+		}
+
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected > 0 {
+			if _, err := q.Exec("UPDATE tags SET usage_count = usage_count + 1 WHERE id = $1", tagID); err != nil {
+				return false, fmt.Errorf("error al actualizar uso de etiqueta: %v", err)
+			}
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// detachTags desenlaza etiquetas de un ticket; con names nil quita todas
+func detachTags(q sqlRunner, ticketID string, names []string) (bool, error) {
+	query := `
+		WITH removed AS (
+			DELETE FROM ticket_tags tt
+			USING tags tg
+			WHERE tt.tag_id = tg.id AND tt.ticket_id = $1 AND ($2::text[] IS NULL OR tg.name = ANY($2))
+			RETURNING tt.tag_id
+		)
+		UPDATE tags SET usage_count = GREATEST(usage_count - 1, 0)
+		WHERE id IN (SELECT tag_id FROM removed)
+	`
+
+	var filter interface{}
+	if names != nil {
+		filter = pq.Array(names)
+	}
+
+	result, err := q.Exec(query, ticketID, filter)
+	if err != nil {
+		return false, fmt.Errorf("error al quitar etiquetas del ticket: %v", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// loadTicketTags obtiene los nombres de las etiquetas de un ticket
+func loadTicketTags(q sqlRunner, ticketID string) ([]string, error) {
+	rows, err := q.Query(`
+		SELECT tg.name
+		FROM ticket_tags tt
+		JOIN tags tg ON tg.id = tt.tag_id
+		WHERE tt.ticket_id = $1
+		ORDER BY tt.created_at ASC, tg.name ASC
+	`, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar etiquetas del ticket: %v", err)
+	}
+	defer rows.Close()
+
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("error al escanear etiqueta: %v", err)
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
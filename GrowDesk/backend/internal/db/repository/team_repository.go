@@ -0,0 +1,400 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// TeamRepository maneja las operaciones de base de datos para los equipos de agentes
+// y sus miembros
+type TeamRepository struct {
+	db *sql.DB
+}
+
+// NewTeamRepository crea un nuevo repositorio de equipos
+func NewTeamRepository(db *sql.DB) *TeamRepository {
+	return &TeamRepository{db: db}
+}
+
+// teamColumns son las columnas leídas por scanTeam
+const teamColumns = `
+	id, name, description, business_hours_id, queue_default_priority, queue_order,
+	queue_include_resolved, active, created_at, updated_at
+`
+
+// teamDepartment compara la columna de departamento indicada con el equipo $1. Los
+// departamentos anteriores a los equipos pueden guardar el nombre en lugar del ID.
+func teamDepartment(column string) string {
+	return fmt.Sprintf("(%[1]s = $1 OR lower(%[1]s) = (SELECT lower(name) FROM teams WHERE id = $1))", column)
+}
+
+// scanTeam escanea una fila con las columnas de teamColumns
+func scanTeam(row rowScanner) (models.Team, error) {
+	var team models.Team
+	var description, businessHoursID, defaultPriority, order sql.NullString
+
+	err := row.Scan(
+		&team.ID,
+		&team.Name,
+		&description,
+		&businessHoursID,
+		&defaultPriority,
+		&order,
+		&team.Queue.IncludeResolved,
+		&team.Active,
+		&team.CreatedAt,
+		&team.UpdatedAt,
+	)
+	if err != nil {
+		return team, err
+	}
+
+	// Asignar valores nulos
+	team.Description = description.String
+	team.BusinessHoursID = businessHoursID.String
+	team.Queue.DefaultPriority = defaultPriority.String
+	team.Queue.Order = order.String
+	team.Members = make([]models.TeamMember, 0)
+
+	return team, nil
+}
+
+// GetAll obtiene todos los equipos con sus miembros
+func (r *TeamRepository) GetAll() ([]models.Team, error) {
+	rows, err := r.db.Query(`SELECT ` + teamColumns + ` FROM teams ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar equipos: %v", err)
+	}
+	defer rows.Close()
+
+	teams := make([]models.Team, 0)
+	for rows.Next() {
+		team, err := scanTeam(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear equipo: %v", err)
+		}
+		teams = append(teams, team)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar equipos: %v", err)
+	}
+
+	members, err := r.getMembers("")
+	if err != nil {
+		return nil, err
+	}
+	for i := range teams {
+		if list, ok := members[teams[i].ID]; ok {
+			teams[i].Members = list
+		}
+	}
+
+	return teams, nil
+}
+
+// GetByID obtiene un equipo por su ID con sus miembros
+func (r *TeamRepository) GetByID(id string) (*models.Team, error) {
+	team, err := scanTeam(r.db.QueryRow(`SELECT `+teamColumns+` FROM teams WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("equipo con ID %s no encontrado", id)
+		}
+		return nil, fmt.Errorf("error al consultar equipo: %v", err)
+	}
+
+	members, err := r.getMembers(id)
+	if err != nil {
+		return nil, err
+	}
+	if list, ok := members[id]; ok {
+		team.Members = list
+	}
+
+	return &team, nil
+}
+
+// getMembers obtiene los miembros agrupados por equipo; teamID vacío incluye todos
+func (r *TeamRepository) getMembers(teamID string) (map[string][]models.TeamMember, error) {
+	query := `SELECT team_id, user_id, role, joined_at FROM team_members`
+	args := make([]interface{}, 0)
+	if teamID != "" {
+		query += ` WHERE team_id = $1`
+		args = append(args, teamID)
+	}
+	query += ` ORDER BY joined_at ASC, user_id ASC`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar miembros de equipos: %v", err)
+	}
+	defer rows.Close()
+
+	members := make(map[string][]models.TeamMember)
+	for rows.Next() {
+		var id string
+		var member models.TeamMember
+		if err := rows.Scan(&id, &member.UserID, &member.Role, &member.JoinedAt); err != nil {
+			return nil, fmt.Errorf("error al escanear miembro de equipo: %v", err)
+		}
+		members[id] = append(members[id], member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar miembros de equipos: %v", err)
+	}
+
+	return members, nil
+}
+
+// Create crea un equipo con sus miembros iniciales
+func (r *TeamRepository) Create(team models.Team) error {
+	now := time.Now()
+	if team.CreatedAt.IsZero() {
+		team.CreatedAt = now
+	}
+	if team.UpdatedAt.IsZero() {
+		team.UpdatedAt = now
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO teams (
+			id, name, description, business_hours_id, queue_default_priority, queue_order,
+			queue_include_resolved, active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`,
+		team.ID,
+		team.Name,
+		nullString(team.Description),
+		nullString(team.BusinessHoursID),
+		nullString(team.Queue.DefaultPriority),
+		nullString(team.Queue.Order),
+		team.Queue.IncludeResolved,
+		team.Active,
+		team.CreatedAt,
+		team.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error al crear equipo: %v", err)
+	}
+
+	for _, member := range team.Members {
+		if member.JoinedAt.IsZero() {
+			member.JoinedAt = now
+		}
+		if err := saveTeamMember(tx, team.ID, member); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return nil
+}
+
+// Update actualiza los datos y la configuración de un equipo, sin cambiar sus miembros
+func (r *TeamRepository) Update(team models.Team) error {
+	result, err := r.db.Exec(`
+		UPDATE teams
+		SET name = $2, description = $3, business_hours_id = $4, queue_default_priority = $5,
+			queue_order = $6, queue_include_resolved = $7, active = $8, updated_at = $9
+		WHERE id = $1
+	`,
+		team.ID,
+		team.Name,
+		nullString(team.Description),
+		nullString(team.BusinessHoursID),
+		nullString(team.Queue.DefaultPriority),
+		nullString(team.Queue.Order),
+		team.Queue.IncludeResolved,
+		team.Active,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("error al actualizar equipo: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("equipo con ID %s no encontrado", team.ID)
+	}
+
+	return nil
+}
+
+// Delete elimina un equipo; sus miembros se eliminan en cascada y las categorías que
+// lo usaban como departamento por defecto quedan sin él
+func (r *TeamRepository) Delete(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := deleteTeam(tx, id, ""); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return nil
+}
+
+// CountReferences devuelve cuántos tickets y usuarios pertenecen al equipo
+func (r *TeamRepository) CountReferences(id string) (int, int, error) {
+	var tickets, users int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM tickets WHERE `+teamDepartment("department"), id).Scan(&tickets)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error al contar tickets del equipo: %v", err)
+	}
+	err = r.db.QueryRow(`SELECT COUNT(*) FROM users WHERE `+teamDepartment("department"), id).Scan(&users)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error al contar usuarios del equipo: %v", err)
+	}
+	return tickets, users, nil
+}
+
+// DeleteReassigning mueve los tickets, los usuarios, los miembros y las categorías del
+// equipo a target y lo elimina en una sola transacción. Devuelve el número de tickets
+// reasignados.
+func (r *TeamRepository) DeleteReassigning(id string, target models.Team) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE tickets SET department = $2, updated_at = NOW()
+		WHERE `+teamDepartment("department"), id, target.ID)
+	if err != nil {
+		return 0, fmt.Errorf("error al reasignar tickets del equipo: %v", err)
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE users SET department = $2, updated_at = NOW()
+		WHERE `+teamDepartment("department"), id, target.ID)
+	if err != nil {
+		return 0, fmt.Errorf("error al reasignar usuarios del equipo: %v", err)
+	}
+
+	// Los miembros que no estaban en el equipo destino se suman como miembros
+	_, err = tx.Exec(`
+		INSERT INTO team_members (team_id, user_id, role, joined_at)
+		SELECT $2, user_id, 'member', NOW() FROM team_members WHERE team_id = $1
+		ON CONFLICT (team_id, user_id) DO NOTHING
+	`, id, target.ID)
+	if err != nil {
+		return 0, fmt.Errorf("error al mover miembros del equipo: %v", err)
+	}
+
+	if err := deleteTeam(tx, id, target.ID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return int(moved), nil
+}
+
+// AddMember agrega un usuario al equipo o cambia su rol si ya es miembro
+func (r *TeamRepository) AddMember(teamID string, member models.TeamMember) error {
+	if member.JoinedAt.IsZero() {
+		member.JoinedAt = time.Now()
+	}
+	if err := saveTeamMember(r.db, teamID, member); err != nil {
+		return err
+	}
+
+	_, err := r.db.Exec(`UPDATE teams SET updated_at = NOW() WHERE id = $1`, teamID)
+	if err != nil {
+		return fmt.Errorf("error al actualizar equipo: %v", err)
+	}
+	return nil
+}
+
+// RemoveMember quita a un usuario del equipo
+func (r *TeamRepository) RemoveMember(teamID, userID string) error {
+	result, err := r.db.Exec(`DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`, teamID, userID)
+	if err != nil {
+		return fmt.Errorf("error al quitar miembro del equipo: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("el usuario %s no es miembro del equipo %s", userID, teamID)
+	}
+
+	_, err = r.db.Exec(`UPDATE teams SET updated_at = NOW() WHERE id = $1`, teamID)
+	if err != nil {
+		return fmt.Errorf("error al actualizar equipo: %v", err)
+	}
+	return nil
+}
+
+// saveTeamMember inserta al miembro o actualiza su rol
+func saveTeamMember(q sqlRunner, teamID string, member models.TeamMember) error {
+	_, err := q.Exec(`
+		INSERT INTO team_members (team_id, user_id, role, joined_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (team_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`, teamID, member.UserID, member.Role, member.JoinedAt)
+	if err != nil {
+		return fmt.Errorf("error al guardar miembro del equipo: %v", err)
+	}
+	return nil
+}
+
+// deleteTeam cambia el departamento por defecto de las categorías que usaban el equipo
+// por replacement y elimina el equipo
+func deleteTeam(q sqlRunner, id, replacement string) error {
+	_, err := q.Exec(`
+		UPDATE categories SET default_department = $2
+		WHERE `+teamDepartment("default_department"), id, nullString(replacement))
+	if err != nil {
+		return fmt.Errorf("error al actualizar categorías del equipo: %v", err)
+	}
+
+	result, err := q.Exec("DELETE FROM teams WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar equipo: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("equipo con ID %s no encontrado", id)
+	}
+
+	return nil
+}
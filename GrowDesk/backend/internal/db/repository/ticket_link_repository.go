@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// TicketLinkRepository maneja las operaciones de base de datos para las relaciones entre tickets
+type TicketLinkRepository struct {
+	db *sql.DB
+}
+
+// NewTicketLinkRepository crea un nuevo repositorio de relaciones entre tickets
+func NewTicketLinkRepository(db *sql.DB) *TicketLinkRepository {
+	return &TicketLinkRepository{db: db}
+}
+
+// GetByTicket obtiene las relaciones en las que participa un ticket, en cualquier dirección
+func (r *TicketLinkRepository) GetByTicket(ticketID string) ([]models.TicketLink, error) {
+	rows, err := r.db.Query(`
+		SELECT id, ticket_id, linked_ticket_id, type, COALESCE(created_by, ''), created_at
+		FROM ticket_links
+		WHERE ticket_id = $1 OR linked_ticket_id = $1
+		ORDER BY created_at ASC
+	`, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar relaciones del ticket: %v", err)
+	}
+	defer rows.Close()
+
+	links := make([]models.TicketLink, 0)
+	for rows.Next() {
+		var link models.TicketLink
+		if err := rows.Scan(&link.ID, &link.TicketID, &link.LinkedTicketID, &link.Type, &link.CreatedBy, &link.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error al escanear relación: %v", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar relaciones: %v", err)
+	}
+
+	return links, nil
+}
+
+// Create guarda una relación entre dos tickets existentes
+func (r *TicketLinkRepository) Create(link models.TicketLink) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range []string{link.TicketID, link.LinkedTicketID} {
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM tickets WHERE id = $1)", id).Scan(&exists); err != nil {
+			return fmt.Errorf("error al comprobar ticket: %v", err)
+		}
+		if !exists {
+			return fmt.Errorf("Ticket no encontrado: %s", id)
+		}
+	}
+
+	var duplicate, hasParent bool
+	err = tx.QueryRow(`
+		SELECT
+			EXISTS(SELECT 1 FROM ticket_links WHERE ticket_id = $1 AND linked_ticket_id = $2 AND type = $3),
+			EXISTS(SELECT 1 FROM ticket_links WHERE ticket_id = $1 AND type = $4)
+	`, link.TicketID, link.LinkedTicketID, link.Type, models.TicketLinkChildOf).Scan(&duplicate, &hasParent)
+	if err != nil {
+		return fmt.Errorf("error al comprobar relaciones existentes: %v", err)
+	}
+	if duplicate {
+		return fmt.Errorf("la relación ya existe")
+	}
+	if link.Type == models.TicketLinkChildOf && hasParent {
+		return fmt.Errorf("el ticket %s ya tiene un ticket padre", link.TicketID)
+	}
+
+	if link.ID == "" {
+		link.ID = uuid.New().String()
+	}
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = time.Now()
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO ticket_links (id, ticket_id, linked_ticket_id, type, created_by, created_at)
+		VALUES ($1, $2, $3, $4, (SELECT id FROM users WHERE id = $5), $6)
+	`, link.ID, link.TicketID, link.LinkedTicketID, link.Type, link.CreatedBy, link.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error al crear relación: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// Delete elimina una relación entre tickets
+func (r *TicketLinkRepository) Delete(id string) error {
+	result, err := r.db.Exec("DELETE FROM ticket_links WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("error al eliminar relación: %v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al verificar eliminación: %v", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("relación no encontrada: %s", id)
+	}
+
+	return nil
+}
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/lib/pq"
 )
 
 // TicketRepository maneja las operaciones de base de datos relacionadas con tickets
@@ -23,110 +25,22 @@ func NewTicketRepository(db *sql.DB) *TicketRepository {
 	}
 }
 
-// GetAll obtiene todos los tickets de la base de datos
-func (r *TicketRepository) GetAll() ([]models.Ticket, error) {
-	query := `
-		SELECT t.id, t.title, t.subject, t.description, t.status, t.priority, 
-		       t.category, t.category_id, t.assigned_to, t.created_by, t.user_id,
-		       t.source, t.widget_id, t.department, t.metadata,
-		       t.created_at, t.updated_at
-		FROM tickets t
-		ORDER BY t.created_at DESC
-	`
-
-	rows, err := r.DB.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("error al consultar tickets: %v", err)
-	}
-	defer rows.Close()
-
-	tickets := make([]models.Ticket, 0)
-	for rows.Next() {
-		var ticket models.Ticket
-		var categoryID, assignedTo, createdBy, userID, metadataJSON sql.NullString
-		var createdAt, updatedAt time.Time
-
-		err := rows.Scan(
-			&ticket.ID,
-			&ticket.Title,
-			&ticket.Subject,
-			&ticket.Description,
-			&ticket.Status,
-			&ticket.Priority,
-			&ticket.Category,
-			&categoryID,
-			&assignedTo,
-			&createdBy,
-			&userID,
-			&ticket.Source,
-			&ticket.WidgetID,
-			&ticket.Department,
-			&metadataJSON,
-			&createdAt,
-			&updatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("error al escanear ticket: %v", err)
-		}
-
-		// Asignar valores nulos
-		if categoryID.Valid {
-			ticket.CategoryID = categoryID.String
-		}
-		if assignedTo.Valid {
-			ticket.AssignedTo = assignedTo.String
-		}
-		if createdBy.Valid {
-			ticket.CreatedBy = createdBy.String
-		}
-		if userID.Valid {
-			ticket.UserID = userID.String
-		}
-
-		// Parsear metadata JSON si existe
-		if metadataJSON.Valid && metadataJSON.String != "" {
-			var metadata models.Metadata
-			if err := json.Unmarshal([]byte(metadataJSON.String), &metadata); err == nil {
-				ticket.Metadata = &metadata
-			}
-		}
-
-		ticket.CreatedAt = createdAt
-		ticket.UpdatedAt = updatedAt
-
-		// Cargar mensajes del ticket
-		messages, err := r.getMessagesForTicket(ticket.ID)
-		if err != nil {
-			return nil, fmt.Errorf("error al obtener mensajes para ticket %s: %v", ticket.ID, err)
-		}
-		ticket.Messages = messages
-
-		tickets = append(tickets, ticket)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error al iterar tickets: %v", err)
-	}
-
-	return tickets, nil
-}
-
-// GetByID obtiene un ticket por su ID
-func (r *TicketRepository) GetByID(id string) (*models.Ticket, error) {
-	query := `
-		SELECT t.id, t.title, t.subject, t.description, t.status, t.priority, 
-		       t.category, t.category_id, t.assigned_to, t.created_by, t.user_id,
-		       t.source, t.widget_id, t.department, t.metadata,
-		       t.created_at, t.updated_at
-		FROM tickets t
-		WHERE t.id = $1
-	`
+// ticketColumns son las columnas leídas por scanTicket
+const ticketColumns = `
+	t.id, t.title, t.subject, t.description, t.status, t.priority,
+	t.category, t.category_id, t.assigned_to, t.created_by, t.user_id,
+	t.source, t.widget_id, t.department, t.metadata, t.custom_fields,
+	t.merged_into, t.assignee_group, t.sla_policy, t.created_at, t.updated_at
+`
 
+// scanTicket escanea una fila con las columnas de ticketColumns
+func scanTicket(row rowScanner) (models.Ticket, error) {
 	var ticket models.Ticket
-	var categoryID, assignedTo, createdBy, userID, metadataJSON sql.NullString
+	var categoryID, assignedTo, createdBy, userID, metadataJSON, customFieldsJSON, mergedInto sql.NullString
+	var assigneeGroup, slaPolicy sql.NullString
 	var createdAt, updatedAt time.Time
 
-	err := r.DB.QueryRow(query, id).Scan(
+	err := row.Scan(
 		&ticket.ID,
 		&ticket.Title,
 		&ticket.Subject,
@@ -142,14 +56,15 @@ func (r *TicketRepository) GetByID(id string) (*models.Ticket, error) {
 		&ticket.WidgetID,
 		&ticket.Department,
 		&metadataJSON,
+		&customFieldsJSON,
+		&mergedInto,
+		&assigneeGroup,
+		&slaPolicy,
 		&createdAt,
 		&updatedAt,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("ticket con ID %s no encontrado", id)
-		}
-		return nil, fmt.Errorf("error al consultar ticket: %v", err)
+		return ticket, err
 	}
 
 	// Asignar valores nulos
@@ -165,6 +80,9 @@ func (r *TicketRepository) GetByID(id string) (*models.Ticket, error) {
 	if userID.Valid {
 		ticket.UserID = userID.String
 	}
+	ticket.MergedInto = mergedInto.String
+	ticket.AssigneeGroup = assigneeGroup.String
+	ticket.SLAPolicy = slaPolicy.String
 
 	// Parsear metadata JSON si existe
 	if metadataJSON.Valid && metadataJSON.String != "" {
@@ -174,9 +92,195 @@ func (r *TicketRepository) GetByID(id string) (*models.Ticket, error) {
 		}
 	}
 
+	// Parsear campos personalizados si existen
+	if customFieldsJSON.Valid && customFieldsJSON.String != "" {
+		if err := json.Unmarshal([]byte(customFieldsJSON.String), &ticket.CustomFields); err != nil {
+			return ticket, fmt.Errorf("error al analizar campos personalizados: %v", err)
+		}
+	}
+
 	ticket.CreatedAt = createdAt
 	ticket.UpdatedAt = updatedAt
 
+	return ticket, nil
+}
+
+// marshalCustomFields serializa los campos personalizados para la columna JSONB
+func marshalCustomFields(fields map[string]interface{}) (sql.NullString, error) {
+	if len(fields) == 0 {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("error al serializar campos personalizados: %v", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// GetAll obtiene todos los tickets de la base de datos
+func (r *TicketRepository) GetAll() ([]models.Ticket, error) {
+	return r.Query(models.TicketFilter{})
+}
+
+// ticketSortColumns traduce los campos de orden estándar a expresiones SQL
+var ticketSortColumns = map[string]string{
+	"createdAt": "t.created_at",
+	"updatedAt": "t.updated_at",
+	"status":    "t.status",
+	"title":     "LOWER(t.title)",
+	"priority":  "CASE LOWER(t.priority) WHEN 'low' THEN 1 WHEN 'medium' THEN 2 WHEN 'high' THEN 3 WHEN 'urgent' THEN 4 END",
+}
+
+// customFieldNumber devuelve la expresión numérica de un campo personalizado (NULL si no es número)
+func customFieldNumber(keyArg string) string {
+	return fmt.Sprintf("(CASE WHEN jsonb_typeof(t.custom_fields->%[1]s) = 'number' THEN (t.custom_fields->>%[1]s)::numeric END)", keyArg)
+}
+
+// Query obtiene los tickets que cumplen el filtro. Los filtros de igualdad sobre campos
+// personalizados usan @> para aprovechar el índice GIN de custom_fields.
+func (r *TicketRepository) Query(filter models.TicketFilter) ([]models.Ticket, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "t.status = "+addArg(filter.Status))
+	}
+	if filter.Priority != "" {
+		conditions = append(conditions, "LOWER(t.priority) = LOWER("+addArg(filter.Priority)+")")
+	}
+	if filter.CategoryID != "" {
+		conditions = append(conditions, "t.category_id = "+addArg(filter.CategoryID))
+	}
+	if filter.AssignedTo != "" {
+		conditions = append(conditions, "t.assigned_to = "+addArg(filter.AssignedTo))
+	}
+	if filter.Department != "" {
+		conditions = append(conditions, "t.department = "+addArg(filter.Department))
+	}
+	if filter.Source != "" {
+		conditions = append(conditions, "t.source = "+addArg(filter.Source))
+	}
+
+	if filter.WatchedBy != "" {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM ticket_watchers tw
+			WHERE tw.ticket_id = t.id AND tw.user_id = `+addArg(filter.WatchedBy)+`)`)
+	}
+
+	for _, name := range filter.Tags {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM ticket_tags tt JOIN tags tg ON tg.id = tt.tag_id
+			WHERE tt.ticket_id = t.id AND tg.name = `+addArg(name)+`)`)
+	}
+
+	for _, cf := range filter.CustomFields {
+		switch cf.Op {
+		case models.CustomFieldFilterMin, models.CustomFieldFilterMax:
+			operator := ">="
+			if cf.Op == models.CustomFieldFilterMax {
+				operator = "<="
+			}
+			keyArg := addArg(cf.Key) + "::text"
+			if cf.Type == models.CustomFieldNumber {
+				conditions = append(conditions, customFieldNumber(keyArg)+" "+operator+" "+addArg(cf.Value))
+			} else {
+				conditions = append(conditions, "t.custom_fields->>"+keyArg+" "+operator+" "+addArg(cf.Value))
+			}
+		default:
+			value := cf.Value
+			if cf.Type == models.CustomFieldMultiEnum {
+				value = []interface{}{cf.Value}
+			}
+			doc, err := json.Marshal(map[string]interface{}{cf.Key: value})
+			if err != nil {
+				return nil, fmt.Errorf("error al serializar filtro de campo personalizado: %v", err)
+			}
+			conditions = append(conditions, "t.custom_fields @> "+addArg(string(doc))+"::jsonb")
+		}
+	}
+
+	query := `
+		SELECT ` + ticketColumns + `
+		FROM tickets t
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+	switch {
+	case filter.SortField != nil && filter.SortField.Type == models.CustomFieldNumber:
+		query += " ORDER BY " + customFieldNumber(addArg(filter.SortField.Key)+"::text") + " " + direction + " NULLS LAST, t.created_at DESC"
+	case filter.SortField != nil:
+		query += " ORDER BY t.custom_fields->>" + addArg(filter.SortField.Key) + "::text " + direction + " NULLS LAST, t.created_at DESC"
+	case ticketSortColumns[filter.SortBy] != "":
+		query += " ORDER BY " + ticketSortColumns[filter.SortBy] + " " + direction + " NULLS LAST, t.created_at DESC"
+	default:
+		query += " ORDER BY t.created_at DESC"
+	}
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar tickets: %v", err)
+	}
+	defer rows.Close()
+
+	tickets := make([]models.Ticket, 0)
+	for rows.Next() {
+		ticket, err := scanTicket(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error al escanear ticket: %v", err)
+		}
+		tickets = append(tickets, ticket)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar tickets: %v", err)
+	}
+	rows.Close()
+
+	// Cargar mensajes y etiquetas de cada ticket
+	for i := range tickets {
+		messages, err := r.getMessagesForTicket(tickets[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("error al obtener mensajes para ticket %s: %v", tickets[i].ID, err)
+		}
+		tickets[i].Messages = messages
+
+		tags, err := loadTicketTags(r.DB, tickets[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("error al obtener etiquetas para ticket %s: %v", tickets[i].ID, err)
+		}
+		tickets[i].Tags = tags
+	}
+
+	return tickets, nil
+}
+
+// GetByID obtiene un ticket por su ID
+func (r *TicketRepository) GetByID(id string) (*models.Ticket, error) {
+	query := `
+		SELECT ` + ticketColumns + `
+		FROM tickets t
+		WHERE t.id = $1
+	`
+
+	ticket, err := scanTicket(r.DB.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("ticket con ID %s no encontrado", id)
+		}
+		return nil, fmt.Errorf("error al consultar ticket: %v", err)
+	}
+
 	// Cargar mensajes del ticket
 	messages, err := r.getMessagesForTicket(ticket.ID)
 	if err != nil {
@@ -184,6 +288,13 @@ func (r *TicketRepository) GetByID(id string) (*models.Ticket, error) {
 	}
 	ticket.Messages = messages
 
+	// Cargar etiquetas del ticket
+	tags, err := loadTicketTags(r.DB, ticket.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener etiquetas para ticket %s: %v", ticket.ID, err)
+	}
+	ticket.Tags = tags
+
 	return &ticket, nil
 }
 
@@ -201,6 +312,21 @@ func (r *TicketRepository) Create(ticket models.Ticket) (*models.Ticket, error)
 		ticket.ID = fmt.Sprintf("TICKET-%s", time.Now().Format("20060102-150405"))
 	}
 
+	if err := insertTicket(tx, &ticket); err != nil {
+		return nil, err
+	}
+
+	// Confirmar transacción
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	log.Printf("Ticket creado con ID: %s", ticket.ID)
+	return &ticket, nil
+}
+
+// insertTicket inserta un ticket con sus mensajes y etiquetas iniciales
+func insertTicket(q sqlRunner, ticket *models.Ticket) error {
 	// Establecer timestamps si no están definidos
 	now := time.Now()
 	if ticket.CreatedAt.IsZero() {
@@ -215,24 +341,29 @@ func (r *TicketRepository) Create(ticket models.Ticket) (*models.Ticket, error)
 	if ticket.Metadata != nil {
 		data, err := json.Marshal(ticket.Metadata)
 		if err != nil {
-			return nil, fmt.Errorf("error al serializar metadata: %v", err)
+			return fmt.Errorf("error al serializar metadata: %v", err)
 		}
 		metadataJSON = sql.NullString{String: string(data), Valid: true}
 	}
 
+	customFieldsJSON, err := marshalCustomFields(ticket.CustomFields)
+	if err != nil {
+		return err
+	}
+
 	// Insertar ticket
 	query := `
 		INSERT INTO tickets (
 			id, title, subject, description, status, priority, category, category_id,
 			assigned_to, created_by, user_id, source, widget_id, department, metadata,
-			created_at, updated_at
+			custom_fields, assignee_group, sla_policy, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
 		)
 		RETURNING id
 	`
 
-	err = tx.QueryRow(
+	err = q.QueryRow(
 		query,
 		ticket.ID,
 		ticket.Title,
@@ -249,12 +380,15 @@ func (r *TicketRepository) Create(ticket models.Ticket) (*models.Ticket, error)
 		ticket.WidgetID,
 		ticket.Department,
 		metadataJSON,
+		customFieldsJSON,
+		nullString(ticket.AssigneeGroup),
+		nullString(ticket.SLAPolicy),
 		ticket.CreatedAt,
 		ticket.UpdatedAt,
 	).Scan(&ticket.ID)
 
 	if err != nil {
-		return nil, fmt.Errorf("error al crear ticket: %v", err)
+		return fmt.Errorf("error al crear ticket: %v", err)
 	}
 
 	// Insertar mensajes si existen
@@ -264,6 +398,9 @@ func (r *TicketRepository) Create(ticket models.Ticket) (*models.Ticket, error)
 			message.ID = uuid.New().String()
 			ticket.Messages[i].ID = message.ID
 		}
+		message.NormalizeVisibility()
+		ticket.Messages[i].Visibility = message.Visibility
+		ticket.Messages[i].IsInternal = message.IsInternal
 
 		// Establecer timestamps si no están definidos
 		if message.CreatedAt.IsZero() {
@@ -278,20 +415,21 @@ func (r *TicketRepository) Create(ticket models.Ticket) (*models.Ticket, error)
 		// Insertar mensaje
 		messageQuery := `
 			INSERT INTO messages (
-				id, ticket_id, content, is_client, is_internal, user_id,
+				id, ticket_id, content, is_client, is_internal, visibility, user_id,
 				user_name, user_email, timestamp, created_at
 			) VALUES (
-				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 			)
 		`
 
-		_, err = tx.Exec(
+		_, err = q.Exec(
 			messageQuery,
 			message.ID,
 			ticket.ID,
 			message.Content,
 			message.IsClient,
 			message.IsInternal,
+			message.Visibility,
 			nullString(message.UserID),
 			nullString(message.UserName),
 			nullString(message.UserEmail),
@@ -300,17 +438,16 @@ func (r *TicketRepository) Create(ticket models.Ticket) (*models.Ticket, error)
 		)
 
 		if err != nil {
-			return nil, fmt.Errorf("error al crear mensaje para ticket: %v", err)
+			return fmt.Errorf("error al crear mensaje para ticket: %v", err)
 		}
 	}
 
-	// Confirmar transacción
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("error al confirmar transacción: %v", err)
+	// Enlazar etiquetas iniciales (p. ej. las del formulario previo al chat)
+	if _, err := attachTags(q, ticket.ID, ticket.Tags); err != nil {
+		return err
 	}
 
-	log.Printf("Ticket creado con ID: %s", ticket.ID)
-	return &ticket, nil
+	return nil
 }
 
 // Update actualiza un ticket existente
@@ -335,13 +472,19 @@ func (r *TicketRepository) Update(ticket models.Ticket) error {
 		metadataJSON = sql.NullString{String: string(data), Valid: true}
 	}
 
+	customFieldsJSON, err := marshalCustomFields(ticket.CustomFields)
+	if err != nil {
+		return err
+	}
+
 	// Actualizar ticket
 	query := `
 		UPDATE tickets
 		SET title = $2, subject = $3, description = $4, status = $5,
 		    priority = $6, category = $7, category_id = $8, assigned_to = $9,
 		    created_by = $10, user_id = $11, source = $12, widget_id = $13,
-		    department = $14, metadata = $15, updated_at = $16
+		    department = $14, metadata = $15, custom_fields = $16, updated_at = $17,
+		    assignee_group = $18, sla_policy = $19
 		WHERE id = $1
 	`
 
@@ -362,7 +505,10 @@ func (r *TicketRepository) Update(ticket models.Ticket) error {
 		ticket.WidgetID,
 		ticket.Department,
 		metadataJSON,
+		customFieldsJSON,
 		ticket.UpdatedAt,
+		nullString(ticket.AssigneeGroup),
+		nullString(ticket.SLAPolicy),
 	)
 
 	if err != nil {
@@ -395,20 +541,39 @@ func (r *TicketRepository) Delete(id string) error {
 	}
 	defer tx.Rollback()
 
+	if err := deleteTicket(tx, id); err != nil {
+		return err
+	}
+
+	// Confirmar transacción
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return nil
+}
+
+// deleteTicket elimina un ticket con sus mensajes, etiquetas y metadata
+func deleteTicket(q sqlRunner, id string) error {
 	// Eliminar mensajes asociados
-	_, err = tx.Exec("DELETE FROM messages WHERE ticket_id = $1", id)
+	_, err := q.Exec("DELETE FROM messages WHERE ticket_id = $1", id)
 	if err != nil {
 		return fmt.Errorf("error al eliminar mensajes del ticket: %v", err)
 	}
 
+	// Quitar etiquetas para mantener los contadores de uso
+	if _, err := detachTags(q, id, nil); err != nil {
+		return err
+	}
+
 	// Eliminar metadata del ticket
-	_, err = tx.Exec("DELETE FROM ticket_metadata WHERE ticket_id = $1", id)
+	_, err = q.Exec("DELETE FROM ticket_metadata WHERE ticket_id = $1", id)
 	if err != nil {
 		return fmt.Errorf("error al eliminar metadata del ticket: %v", err)
 	}
 
 	// Eliminar ticket
-	result, err := tx.Exec("DELETE FROM tickets WHERE id = $1", id)
+	result, err := q.Exec("DELETE FROM tickets WHERE id = $1", id)
 	if err != nil {
 		return fmt.Errorf("error al eliminar ticket: %v", err)
 	}
@@ -422,14 +587,319 @@ func (r *TicketRepository) Delete(id string) error {
 		return fmt.Errorf("ticket con ID %s no encontrado", id)
 	}
 
-	// Confirmar transacción
+	return nil
+}
+
+// BulkUpdate aplica los mismos cambios a varios tickets y registra una actividad por ticket
+// afectado. En modo transaccional todo se ejecuta en una única transacción que se revierte
+// ante el primer error, sin marcar como exitoso ningún ticket; en caso contrario cada ticket
+// usa su propia transacción.
+func (r *TicketRepository) BulkUpdate(ticketIDs []string, changes models.BulkTicketChanges, activity models.Activity, transactional bool) ([]models.BulkTicketResult, error) {
+	results := make([]models.BulkTicketResult, len(ticketIDs))
+	for i, id := range ticketIDs {
+		results[i].TicketID = id
+	}
+
+	if !transactional {
+		for i, id := range ticketIDs {
+			if err := r.bulkUpdateOne(id, changes, activity); err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+			results[i].Success = true
+		}
+		return results, nil
+	}
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	for i, id := range ticketIDs {
+		if err := applyBulkChanges(tx, id, changes, activity); err != nil {
+			// Tras un error PostgreSQL aborta la transacción: no se procesan más tickets
+			results[i].Error = err.Error()
+			return results, nil
+		}
+		results[i].Success = true
+	}
+
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("error al confirmar transacción: %v", err)
+		return nil, fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return results, nil
+}
+
+// bulkUpdateOne aplica los cambios de una operación masiva a un ticket en su propia transacción
+func (r *TicketRepository) bulkUpdateOne(id string, changes models.BulkTicketChanges, activity models.Activity) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %v", err)
 	}
+	defer tx.Rollback()
 
+	if err := applyBulkChanges(tx, id, changes, activity); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %v", err)
+	}
 	return nil
 }
 
+// applyBulkChanges aplica los cambios de una operación masiva a un ticket y registra la actividad
+func applyBulkChanges(q sqlRunner, id string, changes models.BulkTicketChanges, activity models.Activity) error {
+	if changes.Delete {
+		if err := deleteTicket(q, id); err != nil {
+			return err
+		}
+		return insertActivity(q, activity, id)
+	}
+
+	var assignedTo sql.NullString
+	if changes.AssignedTo != nil {
+		assignedTo = nullString(*changes.AssignedTo)
+	}
+
+	// Los parámetros vacíos conservan el valor actual de la columna
+	result, err := q.Exec(`
+		UPDATE tickets
+		SET status = COALESCE(NULLIF($2, ''), status),
+		    priority = COALESCE(NULLIF($3, ''), priority),
+		    assigned_to = CASE WHEN $4 THEN $5 ELSE assigned_to END,
+		    category_id = COALESCE(NULLIF($6, ''), category_id),
+		    category = CASE WHEN $6 <> '' THEN $7 ELSE category END,
+		    updated_at = NOW()
+		WHERE id = $1
+	`, id, changes.Status, changes.Priority, changes.AssignedTo != nil, assignedTo, changes.CategoryID, changes.Category)
+	if err != nil {
+		return fmt.Errorf("error al actualizar ticket: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("ticket con ID %s no encontrado", id)
+	}
+
+	if len(changes.AddTags) > 0 {
+		if _, err := attachTags(q, id, changes.AddTags); err != nil {
+			return err
+		}
+	}
+	if len(changes.RemoveTags) > 0 {
+		if _, err := detachTags(q, id, changes.RemoveTags); err != nil {
+			return err
+		}
+	}
+
+	return insertActivity(q, activity, id)
+}
+
+// Merge mueve los mensajes (con sus adjuntos) y etiquetas de los tickets origen al destino.
+// Los orígenes quedan cerrados y apuntando al destino para que sus IDs sigan resolviendo.
+func (r *TicketRepository) Merge(targetID string, sourceIDs []string, activity models.Activity) (*models.Ticket, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Quitar orígenes repetidos conservando el orden recibido
+	seen := map[string]bool{targetID: true}
+	unique := make([]string, 0, len(sourceIDs))
+	for _, id := range sourceIDs {
+		if id == targetID {
+			return nil, fmt.Errorf("un ticket no se puede fusionar consigo mismo")
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	sourceIDs = unique
+
+	// Bloquear destino y orígenes en orden de ID para que dos fusiones concurrentes
+	// no se bloqueen mutuamente
+	rows, err := tx.Query(
+		"SELECT id, merged_into FROM tickets WHERE id = ANY($1) ORDER BY id FOR UPDATE",
+		pq.Array(append([]string{targetID}, sourceIDs...)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar ticket: %v", err)
+	}
+	locked := make(map[string]bool, len(seen))
+	for rows.Next() {
+		var id string
+		var mergedInto sql.NullString
+		if err := rows.Scan(&id, &mergedInto); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error al consultar ticket: %v", err)
+		}
+		if mergedInto.Valid {
+			rows.Close()
+			return nil, fmt.Errorf("el ticket %s ya fue fusionado en %s", id, mergedInto.String)
+		}
+		locked[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("error al consultar ticket: %v", err)
+	}
+	rows.Close()
+	for _, id := range append([]string{targetID}, sourceIDs...) {
+		if !locked[id] {
+			return nil, fmt.Errorf("ticket con ID %s no encontrado", id)
+		}
+	}
+
+	for _, id := range sourceIDs {
+		// Los adjuntos siguen a sus mensajes por message_id
+		if _, err := tx.Exec("UPDATE messages SET ticket_id = $1 WHERE ticket_id = $2", targetID, id); err != nil {
+			return nil, fmt.Errorf("error al mover mensajes del ticket %s: %v", id, err)
+		}
+
+		names, err := loadTicketTags(tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := detachTags(tx, id, nil); err != nil {
+			return nil, err
+		}
+		if _, err := attachTags(tx, targetID, names); err != nil {
+			return nil, err
+		}
+
+		// Quienes seguían el ticket origen pasan a seguir el destino
+		_, err = tx.Exec(`
+			INSERT INTO ticket_watchers (ticket_id, user_id, added_by, created_at)
+			SELECT $1, user_id, added_by, created_at FROM ticket_watchers WHERE ticket_id = $2
+			ON CONFLICT (ticket_id, user_id) DO NOTHING
+		`, targetID, id)
+		if err != nil {
+			return nil, fmt.Errorf("error al mover seguidores del ticket %s: %v", id, err)
+		}
+		if _, err := tx.Exec("DELETE FROM ticket_watchers WHERE ticket_id = $1", id); err != nil {
+			return nil, fmt.Errorf("error al mover seguidores del ticket %s: %v", id, err)
+		}
+
+		_, err = tx.Exec(`
+			UPDATE tickets SET status = 'closed', merged_into = $2, updated_at = NOW() WHERE id = $1
+		`, id, targetID)
+		if err != nil {
+			return nil, fmt.Errorf("error al cerrar ticket fusionado: %v", err)
+		}
+
+		sourceActivity := activity
+		sourceActivity.Description = fmt.Sprintf("Ticket fusionado en %s", targetID)
+		if err := insertActivity(tx, sourceActivity, id); err != nil {
+			return nil, err
+		}
+
+		targetActivity := activity
+		targetActivity.Description = fmt.Sprintf("Ticket %s fusionado en este ticket", id)
+		if err := insertActivity(tx, targetActivity, targetID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Los tickets fusionados antes en un origen pasan a apuntar directamente al destino
+	_, err = tx.Exec("UPDATE tickets SET merged_into = $1 WHERE merged_into = ANY($2)", targetID, pq.Array(sourceIDs))
+	if err != nil {
+		return nil, fmt.Errorf("error al actualizar redirecciones: %v", err)
+	}
+
+	if _, err := tx.Exec("UPDATE tickets SET updated_at = NOW() WHERE id = $1", targetID); err != nil {
+		return nil, fmt.Errorf("error al actualizar ticket: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return r.GetByID(targetID)
+}
+
+// Split mueve los mensajes indicados de un ticket a un ticket nuevo creado a partir de ticket
+func (r *TicketRepository) Split(sourceID string, messageIDs []string, ticket models.Ticket, activity models.Activity) (*models.Ticket, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error al iniciar transacción: %v", err)
+	}
+	defer tx.Rollback()
+
+	var mergedInto sql.NullString
+	err = tx.QueryRow("SELECT merged_into FROM tickets WHERE id = $1 FOR UPDATE", sourceID).Scan(&mergedInto)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("ticket con ID %s no encontrado", sourceID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar ticket: %v", err)
+	}
+	if mergedInto.Valid {
+		return nil, fmt.Errorf("el ticket %s fue fusionado en %s", sourceID, mergedInto.String)
+	}
+
+	var selected, total int
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FILTER (WHERE id = ANY($2)), COUNT(*)
+		FROM messages WHERE ticket_id = $1
+	`, sourceID, pq.Array(messageIDs)).Scan(&selected, &total)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar mensajes del ticket: %v", err)
+	}
+	if selected == 0 {
+		return nil, fmt.Errorf("debe indicar al menos un mensaje")
+	}
+	if selected != len(messageIDs) {
+		return nil, fmt.Errorf("algunos mensajes no pertenecen al ticket %s", sourceID)
+	}
+	if selected == total {
+		return nil, fmt.Errorf("el ticket original debe conservar al menos un mensaje")
+	}
+
+	if ticket.ID == "" {
+		ticket.ID = uuid.New().String()
+	}
+	ticket.Messages = nil
+	ticket.Tags = nil
+	if err := insertTicket(tx, &ticket); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec("UPDATE messages SET ticket_id = $1 WHERE ticket_id = $2 AND id = ANY($3)", ticket.ID, sourceID, pq.Array(messageIDs))
+	if err != nil {
+		return nil, fmt.Errorf("error al mover mensajes: %v", err)
+	}
+	if _, err := tx.Exec("UPDATE tickets SET updated_at = NOW() WHERE id = $1", sourceID); err != nil {
+		return nil, fmt.Errorf("error al actualizar ticket: %v", err)
+	}
+
+	sourceActivity := activity
+	sourceActivity.Description = fmt.Sprintf("%d mensajes movidos al ticket %s", selected, ticket.ID)
+	if err := insertActivity(tx, sourceActivity, sourceID); err != nil {
+		return nil, err
+	}
+
+	targetActivity := activity
+	targetActivity.Description = fmt.Sprintf("Ticket creado a partir de %d mensajes de %s", selected, sourceID)
+	if err := insertActivity(tx, targetActivity, ticket.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error al confirmar transacción: %v", err)
+	}
+
+	return r.GetByID(ticket.ID)
+}
+
 // AddMessage añade un nuevo mensaje a un ticket existente
 func (r *TicketRepository) AddMessage(ticketID string, message models.Message) (*models.Message, error) {
 	// Iniciar transacción
@@ -454,6 +924,7 @@ func (r *TicketRepository) AddMessage(ticketID string, message models.Message) (
 	if message.ID == "" {
 		message.ID = uuid.New().String()
 	}
+	message.NormalizeVisibility()
 
 	// Establecer timestamps si no están definidos
 	now := time.Now()
@@ -467,10 +938,10 @@ func (r *TicketRepository) AddMessage(ticketID string, message models.Message) (
 	// Insertar mensaje
 	query := `
 		INSERT INTO messages (
-			id, ticket_id, content, is_client, is_internal, user_id,
+			id, ticket_id, content, is_client, is_internal, visibility, user_id,
 			user_name, user_email, timestamp, created_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 		)
 		RETURNING id
 	`
@@ -482,6 +953,7 @@ func (r *TicketRepository) AddMessage(ticketID string, message models.Message) (
 		message.Content,
 		message.IsClient,
 		message.IsInternal,
+		message.Visibility,
 		nullString(message.UserID),
 		nullString(message.UserName),
 		nullString(message.UserEmail),
@@ -508,13 +980,36 @@ func (r *TicketRepository) AddMessage(ticketID string, message models.Message) (
 	return &message, nil
 }
 
+// GetMessages obtiene los mensajes de un ticket visibles para el destinatario.
+// Los clientes solo reciben las respuestas públicas.
+func (r *TicketRepository) GetMessages(ticketID, audience string) ([]models.Message, error) {
+	var exists bool
+	err := r.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM tickets WHERE id = $1)", ticketID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("error al verificar ticket: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("ticket con ID %s no encontrado", ticketID)
+	}
+
+	if audience == models.MessageAudienceAgent {
+		return r.getMessagesForTicket(ticketID)
+	}
+	return r.queryMessages(ticketID, "AND visibility = '"+models.MessageVisibilityPublic+"'")
+}
+
 // getMessagesForTicket obtiene todos los mensajes para un ticket
 func (r *TicketRepository) getMessagesForTicket(ticketID string) ([]models.Message, error) {
+	return r.queryMessages(ticketID, "")
+}
+
+// queryMessages obtiene los mensajes de un ticket que cumplen la condición adicional
+func (r *TicketRepository) queryMessages(ticketID, condition string) ([]models.Message, error) {
 	query := `
-		SELECT id, content, is_client, is_internal, user_id, user_name, user_email,
+		SELECT id, content, is_client, is_internal, visibility, user_id, user_name, user_email,
 		       timestamp, created_at
 		FROM messages
-		WHERE ticket_id = $1
+		WHERE ticket_id = $1 ` + condition + `
 		ORDER BY timestamp ASC
 	`
 
@@ -535,6 +1030,7 @@ func (r *TicketRepository) getMessagesForTicket(ticketID string) ([]models.Messa
 			&message.Content,
 			&message.IsClient,
 			&message.IsInternal,
+			&message.Visibility,
 			&userID,
 			&userName,
 			&userEmail,
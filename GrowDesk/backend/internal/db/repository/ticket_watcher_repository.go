@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/lib/pq"
+)
+
+// TicketWatcherRepository maneja las operaciones de base de datos para los seguidores de tickets
+type TicketWatcherRepository struct {
+	db *sql.DB
+}
+
+// NewTicketWatcherRepository crea un nuevo repositorio de seguidores de tickets
+func NewTicketWatcherRepository(db *sql.DB) *TicketWatcherRepository {
+	return &TicketWatcherRepository{db: db}
+}
+
+// GetByTicket obtiene los seguidores de un ticket en el orden en que se añadieron
+func (r *TicketWatcherRepository) GetByTicket(ticketID string) ([]models.TicketWatcher, error) {
+	rows, err := r.db.Query(`
+		SELECT ticket_id, user_id, COALESCE(added_by, ''), created_at
+		FROM ticket_watchers
+		WHERE ticket_id = $1
+		ORDER BY created_at ASC
+	`, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar seguidores del ticket: %v", err)
+	}
+	defer rows.Close()
+
+	watchers := make([]models.TicketWatcher, 0)
+	for rows.Next() {
+		var watcher models.TicketWatcher
+		if err := rows.Scan(&watcher.TicketID, &watcher.UserID, &watcher.AddedBy, &watcher.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error al escanear seguidor: %v", err)
+		}
+		watchers = append(watchers, watcher)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar seguidores: %v", err)
+	}
+
+	return watchers, nil
+}
+
+// Add añade seguidores a un ticket y devuelve los que no lo seguían ya.
+// Los usuarios inexistentes se ignoran.
+func (r *TicketWatcherRepository) Add(ticketID string, userIDs []string, addedBy string) ([]string, error) {
+	var exists bool
+	if err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM tickets WHERE id = $1)", ticketID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("error al comprobar ticket: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("Ticket no encontrado: %s", ticketID)
+	}
+
+	rows, err := r.db.Query(`
+		INSERT INTO ticket_watchers (ticket_id, user_id, added_by, created_at)
+		SELECT $1, u.id, (SELECT id FROM users WHERE id = $3), NOW()
+		FROM users u
+		WHERE u.id = ANY($2)
+		ON CONFLICT (ticket_id, user_id) DO NOTHING
+		RETURNING user_id
+	`, ticketID, pq.Array(userIDs), addedBy)
+	if err != nil {
+		return nil, fmt.Errorf("error al añadir seguidores: %v", err)
+	}
+	defer rows.Close()
+
+	added := make([]string, 0)
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("error al escanear seguidor: %v", err)
+		}
+		added = append(added, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error al iterar seguidores: %v", err)
+	}
+
+	return added, nil
+}
+
+// Remove quita a un usuario de los seguidores de un ticket
+func (r *TicketWatcherRepository) Remove(ticketID, userID string) error {
+	result, err := r.db.Exec("DELETE FROM ticket_watchers WHERE ticket_id = $1 AND user_id = $2", ticketID, userID)
+	if err != nil {
+		return fmt.Errorf("error al quitar seguidor: %v", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al verificar eliminación: %v", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("el usuario %s no sigue el ticket %s", userID, ticketID)
+	}
+
+	return nil
+}
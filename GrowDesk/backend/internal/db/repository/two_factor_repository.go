@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// TwoFactorRepository maneja las operaciones de base de datos para la autenticación en
+// dos pasos de los usuarios
+type TwoFactorRepository struct {
+	db *sql.DB
+}
+
+// NewTwoFactorRepository crea un nuevo repositorio de autenticación en dos pasos
+func NewTwoFactorRepository(db *sql.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// GetByUserID obtiene la configuración del usuario; sin configuración guardada devuelve
+// una desactivada
+func (r *TwoFactorRepository) GetByUserID(userID string) (*models.TwoFactor, error) {
+	var exists bool
+	if err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("error al consultar usuario: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("usuario con ID %s no encontrado", userID)
+	}
+
+	twoFactor := models.TwoFactor{UserID: userID}
+	var codesJSON []byte
+	var enabledAt sql.NullTime
+
+	err := r.db.QueryRow(`
+		SELECT secret, enabled, recovery_codes, last_used_step, enabled_at, updated_at
+		FROM user_two_factor
+		WHERE user_id = $1
+	`, userID).Scan(
+		&twoFactor.Secret,
+		&twoFactor.Enabled,
+		&codesJSON,
+		&twoFactor.LastUsedStep,
+		&enabledAt,
+		&twoFactor.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &twoFactor, nil
+		}
+		return nil, fmt.Errorf("error al consultar autenticación en dos pasos: %v", err)
+	}
+
+	// Asignar valores nulos
+	if enabledAt.Valid {
+		twoFactor.EnabledAt = &enabledAt.Time
+	}
+	if len(codesJSON) > 0 {
+		if err := json.Unmarshal(codesJSON, &twoFactor.RecoveryCodes); err != nil {
+			return nil, fmt.Errorf("error al analizar códigos de recuperación: %v", err)
+		}
+	}
+
+	return &twoFactor, nil
+}
+
+// Save crea o reemplaza la configuración de un usuario
+func (r *TwoFactorRepository) Save(twoFactor models.TwoFactor) error {
+	codes := twoFactor.RecoveryCodes
+	if codes == nil {
+		codes = make([]string, 0)
+	}
+	codesJSON, err := json.Marshal(codes)
+	if err != nil {
+		return fmt.Errorf("error al serializar códigos de recuperación: %v", err)
+	}
+
+	var enabledAt sql.NullTime
+	if twoFactor.EnabledAt != nil {
+		enabledAt = sql.NullTime{Time: *twoFactor.EnabledAt, Valid: true}
+	}
+
+	query := `
+		INSERT INTO user_two_factor (user_id, secret, enabled, recovery_codes, last_used_step, enabled_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			enabled = EXCLUDED.enabled,
+			recovery_codes = EXCLUDED.recovery_codes,
+			last_used_step = EXCLUDED.last_used_step,
+			enabled_at = EXCLUDED.enabled_at,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err = r.db.Exec(
+		query,
+		twoFactor.UserID,
+		twoFactor.Secret,
+		twoFactor.Enabled,
+		codesJSON,
+		twoFactor.LastUsedStep,
+		enabledAt,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("error al guardar autenticación en dos pasos: %v", err)
+	}
+
+	return nil
+}
+
+// Delete elimina la configuración de un usuario si existe
+func (r *TwoFactorRepository) Delete(userID string) error {
+	if _, err := r.db.Exec(`DELETE FROM user_two_factor WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("error al eliminar autenticación en dos pasos: %v", err)
+	}
+	return nil
+}
+
+// ConsumeStep registra el intervalo TOTP de un código aceptado. Falla si ese intervalo o
+// uno posterior ya se usó.
+func (r *TwoFactorRepository) ConsumeStep(userID string, step int64) error {
+	result, err := r.db.Exec(`
+		UPDATE user_two_factor SET last_used_step = $2, updated_at = $3
+		WHERE user_id = $1 AND last_used_step < $2
+	`, userID, step, time.Now())
+	if err != nil {
+		return fmt.Errorf("error al registrar código: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("el código ya se usó")
+	}
+
+	return nil
+}
+
+// ConsumeRecoveryCode elimina el código de recuperación con ese hash. Falla si no existe.
+func (r *TwoFactorRepository) ConsumeRecoveryCode(userID, codeHash string) error {
+	result, err := r.db.Exec(`
+		UPDATE user_two_factor SET recovery_codes = recovery_codes - $2::text, updated_at = $3
+		WHERE user_id = $1 AND recovery_codes ? $2
+	`, userID, codeHash, time.Now())
+	if err != nil {
+		return fmt.Errorf("error al usar código de recuperación: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al obtener filas afectadas: %v", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("código de recuperación no encontrado")
+	}
+
+	return nil
+}
@@ -113,7 +113,7 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 		SELECT id, first_name, last_name, email, role, department, active,
 		       position, phone, language, created_at, updated_at
 		FROM users
-		WHERE email = $1
+		WHERE lower(email) = lower($1)
 	`
 
 	var user models.User
@@ -148,6 +148,11 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 
 // Create crea un nuevo usuario
 func (r *UserRepository) Create(user models.User) (*models.User, error) {
+	return insertUser(r.db, user)
+}
+
+// insertUser inserta un usuario con la conexión o transacción indicada
+func insertUser(q sqlRunner, user models.User) (*models.User, error) {
 	query := `
 		INSERT INTO users (id, first_name, last_name, email, password, role, department,
 		                  active, position, phone, language, created_at, updated_at)
@@ -163,7 +168,7 @@ func (r *UserRepository) Create(user models.User) (*models.User, error) {
 		user.UpdatedAt = now
 	}
 
-	err := r.db.QueryRow(
+	err := q.QueryRow(
 		query,
 		user.ID,
 		user.FirstName,
@@ -251,17 +256,23 @@ func (r *UserRepository) Delete(id string) error {
 	return nil
 }
 
-// UpdatePassword actualiza la contraseña de un usuario
+// UpdatePassword actualiza la contraseña de un usuario y cierra sus sesiones abiertas
 func (r *UserRepository) UpdatePassword(id string, password string) error {
+	return updatePassword(r.db, id, password)
+}
+
+// updatePassword cambia la contraseña y aumenta la versión de sesión del usuario con la
+// conexión o transacción indicada
+func updatePassword(q sqlRunner, id string, password string) error {
 	query := `
 		UPDATE users
-		SET password = $2, updated_at = $3
+		SET password = $2, session_version = session_version + 1, updated_at = $3
 		WHERE id = $1
 	`
 
 	now := time.Now()
 
-	result, err := r.db.Exec(query, id, password, now)
+	result, err := q.Exec(query, id, password, now)
 	if err != nil {
 		return fmt.Errorf("error al actualizar contraseña: %v", err)
 	}
@@ -277,3 +288,29 @@ func (r *UserRepository) UpdatePassword(id string, password string) error {
 
 	return nil
 }
+
+// GetPasswordHash obtiene el hash de la contraseña de un usuario
+func (r *UserRepository) GetPasswordHash(id string) (string, error) {
+	var password string
+	err := r.db.QueryRow(`SELECT password FROM users WHERE id = $1`, id).Scan(&password)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("usuario con ID %s no encontrado", id)
+		}
+		return "", fmt.Errorf("error al consultar contraseña: %v", err)
+	}
+	return password, nil
+}
+
+// GetSessionVersion obtiene la versión de sesión vigente de un usuario
+func (r *UserRepository) GetSessionVersion(id string) (int, error) {
+	var version int
+	err := r.db.QueryRow(`SELECT session_version FROM users WHERE id = $1`, id).Scan(&version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("%w: %s", models.ErrUserNotFound, id)
+		}
+		return 0, fmt.Errorf("error al consultar versión de sesión: %v", err)
+	}
+	return version, nil
+}
@@ -50,6 +50,7 @@ CREATE TABLE IF NOT EXISTS tickets (
     widget_id TEXT,
     department TEXT,
     metadata JSONB,
+    custom_fields JSONB,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
@@ -89,7 +90,7 @@ CREATE TABLE IF NOT EXISTS messages (
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
--- Tabla de FAQs
+-- Tabla de FAQs (anterior a la base de conocimiento; sus filas se migran a kb_articles)
 CREATE TABLE IF NOT EXISTS faqs (
     id SERIAL PRIMARY KEY,
     question TEXT NOT NULL,
@@ -129,11 +130,32 @@ CREATE TABLE IF NOT EXISTS widget_tickets (
     client_email TEXT NOT NULL,
     widget_id TEXT REFERENCES widget_settings(widget_id),
     department TEXT,
+    metadata JSONB,
     synced BOOLEAN DEFAULT FALSE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
+-- Columnas agregadas a tablas existentes
+ALTER TABLE widget_tickets ADD COLUMN IF NOT EXISTS metadata JSONB;
+ALTER TABLE widget_tickets ADD COLUMN IF NOT EXISTS custom_fields JSONB;
+ALTER TABLE widget_tickets ADD COLUMN IF NOT EXISTS merged_into TEXT;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS custom_fields JSONB;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS merged_into TEXT REFERENCES tickets(id) ON DELETE SET NULL;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'public';
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id TEXT REFERENCES categories(id) ON DELETE SET NULL;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS default_department TEXT;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS default_priority TEXT;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS assignee_group TEXT;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS sla_policy TEXT;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS assignee_group TEXT;
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS sla_policy TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS session_version INTEGER NOT NULL DEFAULT 0;
+
+-- Las notas internas anteriores a la columna de visibilidad solo tienen is_internal
+UPDATE messages SET visibility = 'internal' WHERE is_internal AND visibility = 'public';
+
 -- Tabla de mensajes de widget
 CREATE TABLE IF NOT EXISTS widget_messages (
     id TEXT PRIMARY KEY,
@@ -206,6 +228,331 @@ CREATE TABLE IF NOT EXISTS attachments (
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
+-- Tabla de horarios de atención
+CREATE TABLE IF NOT EXISTS business_hours (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    widget_id TEXT,
+    department TEXT,
+    time_zone TEXT NOT NULL DEFAULT 'UTC',
+    schedule JSONB NOT NULL DEFAULT '[]',
+    holidays JSONB NOT NULL DEFAULT '[]',
+    offline_message TEXT,
+    expected_response_time TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Tabla de formularios previos al chat (uno por widget; widget_id vacío = formulario por defecto)
+CREATE TABLE IF NOT EXISTS prechat_forms (
+    id TEXT PRIMARY KEY,
+    widget_id TEXT NOT NULL DEFAULT '' UNIQUE,
+    title TEXT NOT NULL,
+    description TEXT,
+    fields JSONB NOT NULL DEFAULT '[]',
+    active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Tabla de definiciones de campos personalizados de tickets (category_id NULL = todas las categorías)
+CREATE TABLE IF NOT EXISTS custom_field_definitions (
+    id TEXT PRIMARY KEY,
+    key TEXT NOT NULL UNIQUE,
+    label TEXT NOT NULL,
+    type TEXT NOT NULL,
+    category_id TEXT REFERENCES categories(id) ON DELETE CASCADE,
+    required BOOLEAN DEFAULT FALSE,
+    options JSONB NOT NULL DEFAULT '[]',
+    description TEXT,
+    position INTEGER DEFAULT 0,
+    active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Tabla de etiquetas de tickets
+CREATE TABLE IF NOT EXISTS tags (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    color TEXT,
+    usage_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Relación entre tickets y etiquetas
+CREATE TABLE IF NOT EXISTS ticket_tags (
+    ticket_id TEXT REFERENCES tickets(id) ON DELETE CASCADE,
+    tag_id TEXT REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (ticket_id, tag_id)
+);
+
+-- Relaciones entre tickets (se guardan en una sola dirección: duplicate_of, related_to, child_of, blocks)
+CREATE TABLE IF NOT EXISTS ticket_links (
+    id TEXT PRIMARY KEY,
+    ticket_id TEXT NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    linked_ticket_id TEXT NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (ticket_id, linked_ticket_id, type),
+    CHECK (ticket_id <> linked_ticket_id)
+);
+
+-- Usuarios que siguen un ticket y reciben notificaciones de sus cambios
+CREATE TABLE IF NOT EXISTS ticket_watchers (
+    ticket_id TEXT REFERENCES tickets(id) ON DELETE CASCADE,
+    user_id TEXT REFERENCES users(id) ON DELETE CASCADE,
+    added_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (ticket_id, user_id)
+);
+
+-- Tabla de macros de agentes (respuestas predefinidas con acciones sobre el ticket)
+CREATE TABLE IF NOT EXISTS macros (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    content TEXT,
+    is_internal BOOLEAN DEFAULT FALSE,
+    shared BOOLEAN DEFAULT FALSE,
+    owner_id TEXT,
+    actions JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Tabla de reglas de automatización (se evalúan por posición ascendente)
+CREATE TABLE IF NOT EXISTS automation_rules (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT,
+    events TEXT[] NOT NULL DEFAULT '{}',
+    match VARCHAR(10),
+    conditions JSONB NOT NULL DEFAULT '[]'::jsonb,
+    actions JSONB NOT NULL DEFAULT '{}'::jsonb,
+    position INTEGER DEFAULT 0,
+    active BOOLEAN DEFAULT TRUE,
+    stop_processing BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Ejecuciones de reglas programadas: una regla se aplica una sola vez por ticket
+-- mientras el ticket siga cumpliendo sus condiciones
+CREATE TABLE IF NOT EXISTS automation_rule_runs (
+    rule_id TEXT NOT NULL REFERENCES automation_rules(id) ON DELETE CASCADE,
+    ticket_id TEXT NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    fired_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (rule_id, ticket_id)
+);
+
+-- Turnos para que solo una réplica ejecute cada tarea periódica
+CREATE TABLE IF NOT EXISTS scheduler_leases (
+    name TEXT PRIMARY KEY,
+    holder TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Encuestas de satisfacción (CSAT) enviadas al resolver tickets
+CREATE TABLE IF NOT EXISTS satisfaction_surveys (
+    id TEXT PRIMARY KEY,
+    token_hash TEXT NOT NULL UNIQUE,
+    ticket_id TEXT NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    agent_id TEXT,
+    category_id TEXT,
+    category TEXT,
+    rating SMALLINT CHECK (rating BETWEEN 1 AND 5),
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    responded_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Las encuestas anteriores guardaban el token en claro; se reemplaza por su hash SHA-256
+ALTER TABLE satisfaction_surveys ADD COLUMN IF NOT EXISTS token_hash TEXT;
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'satisfaction_surveys' AND column_name = 'token') THEN
+        UPDATE satisfaction_surveys SET token_hash = encode(sha256(convert_to(token, 'UTF8')), 'hex')
+        WHERE token_hash IS NULL;
+        ALTER TABLE satisfaction_surveys DROP COLUMN token;
+    END IF;
+END $$;
+
+-- Artículos de la base de conocimiento (sustituyen a las FAQs). title, body y category_id
+-- son la versión de trabajo; el widget muestra la revisión published_revision (0 = no publicado)
+CREATE TABLE IF NOT EXISTS kb_articles (
+    id SERIAL PRIMARY KEY,
+    slug TEXT NOT NULL UNIQUE,
+    locale TEXT NOT NULL DEFAULT 'es',
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    category_id TEXT REFERENCES categories(id) ON DELETE SET NULL,
+    legacy_category TEXT,
+    status TEXT NOT NULL DEFAULT 'draft',
+    revision INTEGER NOT NULL DEFAULT 1,
+    published_revision INTEGER NOT NULL DEFAULT 0,
+    author_id TEXT,
+    editor_id TEXT,
+    published_by TEXT,
+    published_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Historial de revisiones de los artículos (nunca se reescribe; restaurar crea una revisión nueva)
+CREATE TABLE IF NOT EXISTS kb_article_revisions (
+    article_id INTEGER REFERENCES kb_articles(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    category_id TEXT,
+    editor_id TEXT,
+    note TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (article_id, revision)
+);
+
+-- Traducciones de los artículos, con estado de publicación propio
+CREATE TABLE IF NOT EXISTS kb_article_translations (
+    article_id INTEGER REFERENCES kb_articles(id) ON DELETE CASCADE,
+    locale TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'draft',
+    source_revision INTEGER NOT NULL DEFAULT 0,
+    published_title TEXT,
+    published_body TEXT,
+    translator_id TEXT,
+    published_by TEXT,
+    published_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (article_id, locale)
+);
+
+-- FAQs de la tabla faqs ya convertidas en artículos; las filas de faqs se conservan
+CREATE TABLE IF NOT EXISTS kb_legacy_faqs (
+    faq_id INTEGER PRIMARY KEY,
+    article_id INTEGER REFERENCES kb_articles(id) ON DELETE SET NULL,
+    migrated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- FAQs sugeridas en el widget antes de crear un ticket (una fila por sesión y FAQ)
+CREATE TABLE IF NOT EXISTS faq_deflections (
+    session_id TEXT NOT NULL,
+    article_id INTEGER REFERENCES kb_articles(id) ON DELETE CASCADE,
+    widget_id TEXT,
+    query TEXT,
+    locale TEXT,
+    shown_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    solved_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (session_id, article_id)
+);
+
+-- Visitas a FAQs por sesión del widget; ticket_id indica un ticket creado después de verla
+CREATE TABLE IF NOT EXISTS faq_views (
+    session_id TEXT NOT NULL,
+    article_id INTEGER REFERENCES kb_articles(id) ON DELETE CASCADE,
+    widget_id TEXT,
+    locale TEXT,
+    view_count INTEGER NOT NULL DEFAULT 1,
+    first_viewed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_viewed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ticket_id TEXT,
+    PRIMARY KEY (session_id, article_id)
+);
+
+-- Votos "¿Te resultó útil?" de las FAQs, uno por sesión y FAQ
+CREATE TABLE IF NOT EXISTS faq_votes (
+    session_id TEXT NOT NULL,
+    article_id INTEGER REFERENCES kb_articles(id) ON DELETE CASCADE,
+    widget_id TEXT,
+    helpful BOOLEAN NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (session_id, article_id)
+);
+
+-- Equipos de agentes; su ID es el valor de department en usuarios y tickets
+CREATE TABLE IF NOT EXISTS teams (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT,
+    business_hours_id TEXT REFERENCES business_hours(id) ON DELETE SET NULL,
+    queue_default_priority TEXT,
+    queue_order TEXT,
+    queue_include_resolved BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Miembros y responsables de los equipos
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id TEXT REFERENCES teams(id) ON DELETE CASCADE,
+    user_id TEXT REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('lead', 'member')),
+    joined_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (team_id, user_id)
+);
+
+-- Perfiles de agentes: estado fijado a mano (NULL se deriva de la presencia),
+-- capacidad (0 sin límite) y habilidades con su nivel
+CREATE TABLE IF NOT EXISTS agent_profiles (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT CHECK (status IN ('online', 'away', 'meeting', 'offline')),
+    max_chats INTEGER NOT NULL DEFAULT 0,
+    max_tickets INTEGER NOT NULL DEFAULT 0,
+    skills JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Invitaciones de usuarios; del token de un solo uso solo se guarda el hash
+CREATE TABLE IF NOT EXISTS invitations (
+    id TEXT PRIMARY KEY,
+    email TEXT NOT NULL,
+    first_name TEXT,
+    last_name TEXT,
+    role TEXT NOT NULL CHECK (role IN ('admin', 'assistant', 'employee')),
+    department TEXT,
+    token_hash TEXT UNIQUE NOT NULL,
+    invited_by TEXT,
+    send_count INTEGER NOT NULL DEFAULT 1,
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    user_id TEXT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Tabla de restablecimientos de contraseña (solo se guarda el hash del token)
+CREATE TABLE IF NOT EXISTS password_resets (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT UNIQUE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Autenticación en dos pasos (TOTP); los códigos de recuperación se guardan como hashes
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    recovery_codes JSONB NOT NULL DEFAULT '[]',
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    enabled_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
 -- Índices
 CREATE INDEX IF NOT EXISTS idx_tickets_status ON tickets(status);
 CREATE INDEX IF NOT EXISTS idx_tickets_user_id ON tickets(user_id);
@@ -215,7 +562,33 @@ CREATE INDEX IF NOT EXISTS idx_messages_ticket_id ON messages(ticket_id);
 CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
 CREATE INDEX IF NOT EXISTS idx_faqs_is_published ON faqs(is_published);
 CREATE INDEX IF NOT EXISTS idx_widget_tickets_widget_id ON widget_tickets(widget_id);
+CREATE INDEX IF NOT EXISTS idx_widget_tickets_ticket_id ON widget_tickets(ticket_id);
 CREATE INDEX IF NOT EXISTS idx_widget_messages_widget_ticket_id ON widget_messages(widget_ticket_id);
 CREATE INDEX IF NOT EXISTS idx_activities_user_id ON activities(user_id);
 CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id);
-CREATE INDEX IF NOT EXISTS idx_notifications_read ON notifications(read); 
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_notifications_read ON notifications(read); 
+CREATE INDEX IF NOT EXISTS idx_business_hours_widget_department ON business_hours(widget_id, department);
+CREATE INDEX IF NOT EXISTS idx_tickets_custom_fields ON tickets USING GIN (custom_fields jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_custom_field_definitions_category_id ON custom_field_definitions(category_id);
+CREATE INDEX IF NOT EXISTS idx_ticket_tags_tag_id ON ticket_tags(tag_id);
+CREATE INDEX IF NOT EXISTS idx_tags_name_pattern ON tags(name text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_activities_target_id ON activities(target_id);
+CREATE INDEX IF NOT EXISTS idx_ticket_links_linked_ticket_id ON ticket_links(linked_ticket_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ticket_links_single_parent ON ticket_links(ticket_id) WHERE type = 'child_of';
+CREATE INDEX IF NOT EXISTS idx_macros_owner_id ON macros(owner_id);
+CREATE INDEX IF NOT EXISTS idx_satisfaction_surveys_ticket_id ON satisfaction_surveys(ticket_id);
+CREATE INDEX IF NOT EXISTS idx_satisfaction_surveys_created_at ON satisfaction_surveys(created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_satisfaction_surveys_token_hash ON satisfaction_surveys(token_hash);
+CREATE INDEX IF NOT EXISTS idx_ticket_watchers_user_id ON ticket_watchers(user_id);
+CREATE INDEX IF NOT EXISTS idx_kb_articles_status ON kb_articles(status);
+CREATE INDEX IF NOT EXISTS idx_kb_articles_category_id ON kb_articles(category_id);
+CREATE INDEX IF NOT EXISTS idx_kb_article_translations_locale ON kb_article_translations(locale);
+CREATE INDEX IF NOT EXISTS idx_faq_deflections_shown_at ON faq_deflections(shown_at);
+CREATE INDEX IF NOT EXISTS idx_faq_views_first_viewed_at ON faq_views(first_viewed_at);
+CREATE INDEX IF NOT EXISTS idx_faq_views_session_id ON faq_views(session_id);
+CREATE INDEX IF NOT EXISTS idx_faq_votes_updated_at ON faq_votes(updated_at);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
+CREATE INDEX IF NOT EXISTS idx_team_members_user_id ON team_members(user_id);
+CREATE INDEX IF NOT EXISTS idx_tickets_department ON tickets(department);
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON invitations(lower(email));
+CREATE INDEX IF NOT EXISTS idx_password_resets_user_id ON password_resets(user_id);
@@ -0,0 +1,158 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// Tipos de eventos de ticket enviados al widget
+const (
+	TicketMessageAdded  = "message_added"
+	TicketStatusChanged = "status_changed"
+	TicketMerged        = "ticket_merged"
+	SurveyRequested     = "survey_requested"
+)
+
+// TokenHeader es el encabezado con el token compartido del canal interno
+const TokenHeader = "X-Internal-Token"
+
+// Publisher envía eventos de tickets a la API del widget a través de un canal
+// HTTP interno autenticado con un token compartido. Un Publisher nil no envía nada.
+type Publisher struct {
+	url    string
+	token  string
+	client *http.Client
+	queue  chan models.TicketEvent
+}
+
+// NewPublisher crea un publicador de eventos. Si url está vacío devuelve nil
+// y los eventos se descartan.
+func NewPublisher(url, token string) *Publisher {
+	if url == "" {
+		return nil
+	}
+
+	p := &Publisher{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+		queue:  make(chan models.TicketEvent, 256),
+	}
+
+	// Un único worker mantiene el orden de los eventos
+	go p.run()
+
+	return p
+}
+
+// Publish encola un evento para su envío sin bloquear la solicitud actual
+func (p *Publisher) Publish(event models.TicketEvent) {
+	if p == nil {
+		return
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	select {
+	case p.queue <- event:
+	default:
+		log.Printf("Cola de eventos llena, descartando evento %s del ticket %s", event.Type, event.TicketID)
+	}
+}
+
+// PublishMessage publica un mensaje de agente. Las notas internas, los eventos del
+// sistema y los mensajes del propio cliente nunca se envían al widget.
+func (p *Publisher) PublishMessage(ticketID string, message models.Message) {
+	if !message.VisibleTo(models.MessageAudienceCustomer) || message.IsClient {
+		return
+	}
+
+	p.Publish(models.TicketEvent{
+		Type:     TicketMessageAdded,
+		TicketID: ticketID,
+		Message:  &message,
+	})
+}
+
+// PublishStatus publica un cambio de estado de ticket
+func (p *Publisher) PublishStatus(ticketID, status string) {
+	p.Publish(models.TicketEvent{
+		Type:     TicketStatusChanged,
+		TicketID: ticketID,
+		Status:   status,
+	})
+}
+
+// PublishMerged publica que un ticket fue fusionado en otro, para que el widget
+// continúe la conversación en el ticket destino
+func (p *Publisher) PublishMerged(ticketID, targetID string) {
+	p.Publish(models.TicketEvent{
+		Type:       TicketMerged,
+		TicketID:   ticketID,
+		MergedInto: targetID,
+	})
+}
+
+// PublishSurvey publica una encuesta de satisfacción para que el widget pida la valoración
+func (p *Publisher) PublishSurvey(ticketID, token string) {
+	p.Publish(models.TicketEvent{
+		Type:        SurveyRequested,
+		TicketID:    ticketID,
+		SurveyToken: token,
+	})
+}
+
+// run procesa la cola de eventos
+func (p *Publisher) run() {
+	for event := range p.queue {
+		if err := p.send(event); err != nil {
+			log.Printf("Error al enviar evento %s del ticket %s: %v", event.Type, event.TicketID, err)
+		}
+	}
+}
+
+// send envía un evento con reintentos
+func (p *Publisher) send(event models.TicketEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error al serializar evento: %v", err)
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= 3; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("error al crear solicitud: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(TokenHeader, p.token)
+
+		resp, err := p.client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			switch {
+			case resp.StatusCode >= 200 && resp.StatusCode < 300:
+				return nil
+			case resp.StatusCode == http.StatusNotFound:
+				// El ticket no pertenece al widget; no tiene sentido reintentar
+				return nil
+			case resp.StatusCode < 500:
+				return fmt.Errorf("respuesta no exitosa: %d", resp.StatusCode)
+			}
+			err = fmt.Errorf("respuesta no exitosa: %d", resp.StatusCode)
+		}
+
+		lastErr = err
+		time.Sleep(time.Duration(300*attempt) * time.Millisecond)
+	}
+
+	return fmt.Errorf("fallo después de 3 intentos: %v", lastErr)
+}
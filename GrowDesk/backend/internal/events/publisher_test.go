@@ -0,0 +1,57 @@
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+func TestPublishMessageOnlySendsPublicAgentReplies(t *testing.T) {
+	received := make(chan models.TicketEvent, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(TokenHeader) != "secreto" {
+			t.Errorf("token interno %q, se esperaba secreto", r.Header.Get(TokenHeader))
+		}
+		var event models.TicketEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("evento inválido: %v", err)
+		}
+		received <- event
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	publisher := NewPublisher(server.URL, "secreto")
+
+	// Ninguno de estos mensajes debe llegar al widget
+	hidden := []models.Message{
+		{ID: "internal", Content: "Nota interna", Visibility: models.MessageVisibilityInternal},
+		{ID: "system", Content: "Ticket asignado", Visibility: models.MessageVisibilitySystem},
+		{ID: "legacy-note", Content: "Nota antigua", IsInternal: true},
+		{ID: "client", Content: "Mensaje del cliente", IsClient: true, Visibility: models.MessageVisibilityPublic},
+	}
+	for _, message := range hidden {
+		publisher.PublishMessage("TICKET-1", message)
+	}
+	publisher.PublishMessage("TICKET-1", models.Message{ID: "public", Content: "Respuesta", Visibility: models.MessageVisibilityPublic})
+
+	// Un único worker mantiene el orden: el primer evento debe ser la respuesta pública
+	select {
+	case event := <-received:
+		if event.Type != TicketMessageAdded || event.Message == nil || event.Message.ID != "public" {
+			t.Fatalf("se recibió %+v, se esperaba solo la respuesta pública", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no se recibió la respuesta pública")
+	}
+
+	select {
+	case event := <-received:
+		t.Errorf("se recibió un evento inesperado: %+v", event)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
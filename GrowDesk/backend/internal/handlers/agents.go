@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/agents"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/presence"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// Intervalos de la conexión de presencia de agentes
+const (
+	agentSocketPingInterval = 30 * time.Second
+	agentSocketReadTimeout  = 75 * time.Second
+)
+
+// agentSocketUpgrader actualiza las conexiones de presencia de agentes a WebSocket
+var agentSocketUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		return true // Permitir todas las solicitudes en desarrollo
+	},
+}
+
+// AgentHandler contiene manejadores para los perfiles de agentes: estado, capacidad y
+// habilidades
+type AgentHandler struct {
+	Store    data.DataStore
+	Agents   *agents.Service
+	Presence *presence.Tracker
+
+	mu      sync.Mutex
+	sockets map[string]int // Conexiones de presencia abiertas por agente
+}
+
+// agentProfileRequest son los cambios de un perfil; los campos omitidos no cambian
+type agentProfileRequest struct {
+	Status     *string              `json:"status"`
+	MaxChats   *int                 `json:"maxChats"`
+	MaxTickets *int                 `json:"maxTickets"`
+	Skills     *[]models.AgentSkill `json:"skills"`
+}
+
+// agentIDFromPath obtiene el ID del agente de /api/agents/:id; "me" es el usuario autenticado
+func agentIDFromPath(r *http.Request) string {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) < 3 || segments[2] == "" {
+		return ""
+	}
+	if segments[2] == "me" {
+		userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+		return userID
+	}
+	return segments[2]
+}
+
+// GetAgents devuelve los agentes con su estado, capacidad, carga y habilidades. Admite
+// los filtros department, status, skill, minLevel y available=true.
+func (h *AgentHandler) GetAgents(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	query := r.URL.Query()
+	filter := agents.Filter{
+		Department: query.Get("department"),
+		Status:     query.Get("status"),
+		Skill:      query.Get("skill"),
+		Available:  query.Get("available") == "true",
+	}
+	if filter.Status != "" && !agents.ValidStatus(filter.Status) {
+		http.Error(w, "Estado inválido: "+filter.Status, http.StatusBadRequest)
+		return
+	}
+	if value := query.Get("minLevel"); value != "" {
+		level, err := strconv.Atoi(value)
+		if err != nil || level < 0 {
+			http.Error(w, "Nivel mínimo inválido", http.StatusBadRequest)
+			return
+		}
+		filter.MinLevel = level
+	}
+
+	list, err := h.Agents.List()
+	if err != nil {
+		http.Error(w, "Error al obtener agentes", http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]models.Agent, 0, len(list))
+	for _, agent := range list {
+		if filter.Matches(agent) {
+			result = append(result, agent)
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, result)
+}
+
+// GetAgent obtiene un agente por ID
+func (h *AgentHandler) GetAgent(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	agentID := agentIDFromPath(r)
+	if agentID == "" {
+		http.Error(w, "URL de agente inválida", http.StatusBadRequest)
+		return
+	}
+
+	agent, err := h.Agents.Get(agentID)
+	if err != nil {
+		http.Error(w, "Agente no encontrado", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, agent)
+}
+
+// UpdateAgentProfile cambia el estado, la capacidad o las habilidades de un agente. Cada
+// agente puede cambiar su propio estado; la capacidad, las habilidades y el estado de
+// otros agentes solo los cambia un administrador. Un estado vacío vuelve al estado
+// derivado de la presencia.
+func (h *AgentHandler) UpdateAgentProfile(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes PUT
+	if r.Method != http.MethodPut {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	agentID := agentIDFromPath(r)
+	if agentID == "" {
+		http.Error(w, "URL de agente inválida", http.StatusBadRequest)
+		return
+	}
+
+	var req agentProfileRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer datos del agente", http.StatusBadRequest)
+		return
+	}
+
+	// Verificar permisos: el propio agente solo puede cambiar su estado
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	if role != "admin" {
+		if agentID != userID || req.MaxChats != nil || req.MaxTickets != nil || req.Skills != nil {
+			http.Error(w, "No autorizado", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	user, err := h.Store.GetUser(agentID)
+	if err != nil || user.Role == "customer" {
+		http.Error(w, "Agente no encontrado", http.StatusNotFound)
+		return
+	}
+
+	profile, err := h.Store.GetAgentProfile(agentID)
+	if err != nil {
+		http.Error(w, "Agente no encontrado", http.StatusNotFound)
+		return
+	}
+
+	if req.Status != nil {
+		profile.Status = strings.TrimSpace(*req.Status)
+	}
+	if req.MaxChats != nil {
+		profile.MaxChats = *req.MaxChats
+	}
+	if req.MaxTickets != nil {
+		profile.MaxTickets = *req.MaxTickets
+	}
+	if req.Skills != nil {
+		profile.Skills = *req.Skills
+	}
+	if err := agents.Validate(profile); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.SaveAgentProfile(*profile); err != nil {
+		http.Error(w, "Error al guardar perfil del agente", http.StatusInternalServerError)
+		return
+	}
+
+	agent, err := h.Agents.Get(agentID)
+	if err != nil {
+		http.Error(w, "Error al obtener agente", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, agent)
+}
+
+// PresenceSocket mantiene al agente autenticado en línea mientras la conexión WebSocket
+// esté abierta. Los navegadores no pueden enviar encabezados en un WebSocket, por lo que
+// el token se acepta también en ?token= (ver main). Al cerrarse la última conexión del
+// agente, este pasa a desconectado.
+func (h *AgentHandler) PresenceSocket(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	if userID == "" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := agentSocketUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Error al actualizar conexión de presencia a WebSocket: %v", err)
+		return
+	}
+
+	agent := agentPresence(h.Store, r, userID)
+	h.Presence.Heartbeat(agent)
+	h.socketOpened(userID)
+
+	// Cerrar la conexión y desconectar al agente cuando finalice
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		conn.Close()
+		if h.socketClosed(userID) {
+			h.Presence.SetOffline(userID)
+		}
+	}()
+
+	// Cada pong del cliente renueva la presencia
+	conn.SetReadDeadline(time.Now().Add(agentSocketReadTimeout))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(agentSocketReadTimeout))
+		h.Presence.Heartbeat(agent)
+		return nil
+	})
+
+	// Mantener la conexión viva con pings
+	go func() {
+		ticker := time.NewTicker(agentSocketPingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(10*time.Second)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	// Cualquier mensaje del cliente también renueva la presencia
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break // Salir si hay error (agente desconectado)
+		}
+		conn.SetReadDeadline(time.Now().Add(agentSocketReadTimeout))
+		h.Presence.Heartbeat(agent)
+	}
+}
+
+// socketOpened cuenta una conexión de presencia abierta del agente
+func (h *AgentHandler) socketOpened(userID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.sockets == nil {
+		h.sockets = make(map[string]int)
+	}
+	h.sockets[userID]++
+}
+
+// socketClosed descuenta una conexión e indica si era la última del agente
+func (h *AgentHandler) socketClosed(userID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.sockets[userID]--
+	if h.sockets[userID] > 0 {
+		return false
+	}
+	delete(h.sockets, userID)
+	return true
+}
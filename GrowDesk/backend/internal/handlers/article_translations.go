@@ -0,0 +1,416 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/kb"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// requestLocales devuelve la cadena de idiomas de una petición pública: ?lang= tiene
+// prioridad sobre la cabecera Accept-Language
+func requestLocales(r *http.Request) []string {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		return kb.FallbackChain(strings.Split(lang, ","))
+	}
+	return kb.FallbackChain(kb.ParseAcceptLanguage(r.Header.Get("Accept-Language")))
+}
+
+// translationsByArticle devuelve las traducciones a los idiomas indicados, indexadas
+// por artículo y por idioma
+func translationsByArticle(store data.DataStore, locales []string) (map[int]map[string]models.ArticleTranslation, error) {
+	byArticle := make(map[int]map[string]models.ArticleTranslation)
+	for _, locale := range locales {
+		translations, err := store.GetTranslationsByLocale(locale)
+		if err != nil {
+			return nil, err
+		}
+		for _, translation := range translations {
+			if byArticle[translation.ArticleID] == nil {
+				byArticle[translation.ArticleID] = make(map[string]models.ArticleTranslation)
+			}
+			byArticle[translation.ArticleID][locale] = translation
+		}
+	}
+	return byArticle, nil
+}
+
+// localizeArticles aplica a cada artículo la primera traducción publicada de la cadena
+// de idiomas
+func localizeArticles(store data.DataStore, articles []models.Article, chain []string) error {
+	if len(chain) == 0 {
+		return nil
+	}
+
+	byArticle, err := translationsByArticle(store, chain)
+	if err != nil {
+		return err
+	}
+
+	for i := range articles {
+		kb.Localize(&articles[i], byArticle[articles[i].ID], chain)
+	}
+	return nil
+}
+
+// translationLocale obtiene el idioma de la URL /api/kb/articles/:id/translations/:locale
+func translationLocale(r *http.Request) (string, error) {
+	segments := articleSegments(r)
+	if len(segments) < 3 || segments[2] == "" {
+		return "", fmt.Errorf("Idioma no indicado")
+	}
+	locale, ok := kb.NormalizeLocale(segments[2])
+	if !ok || !kb.IsSupported(locale) {
+		return "", fmt.Errorf("Idioma no admitido: %s", segments[2])
+	}
+	return locale, nil
+}
+
+// GetLocales devuelve el idioma por defecto y los idiomas admitidos
+func (h *ArticleHandler) GetLocales(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"default":   kb.DefaultLocale,
+		"supported": kb.SupportedLocales,
+	})
+}
+
+// GetArticleTranslations devuelve las traducciones de un artículo
+// Formato de URL: /api/kb/articles/:id/translations
+func (h *ArticleHandler) GetArticleTranslations(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	article, err := h.articleFromPath(r)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	translations, err := h.Store.GetArticleTranslations(article.ID)
+	if err != nil {
+		http.Error(w, "Error al obtener traducciones", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, translations)
+}
+
+// GetArticleTranslation devuelve la traducción de un artículo a un idioma
+// Formato de URL: /api/kb/articles/:id/translations/:locale
+func (h *ArticleHandler) GetArticleTranslation(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	locale, err := translationLocale(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	article, err := h.articleFromPath(r)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	translation, err := h.Store.GetArticleTranslation(article.ID, locale)
+	if err != nil {
+		http.Error(w, "Traducción no encontrada", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, translation)
+}
+
+// SaveArticleTranslation crea o edita la traducción de un artículo. Si estaba
+// publicada, el widget sigue mostrando la versión publicada hasta que se vuelva a publicar.
+// Formato de URL: /api/kb/articles/:id/translations/:locale
+func (h *ArticleHandler) SaveArticleTranslation(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes PUT
+	if r.Method != http.MethodPut {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	if !canEditArticles(role) {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	locale, err := translationLocale(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req models.ArticleTranslationRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer datos de la traducción", http.StatusBadRequest)
+		return
+	}
+	if err := kb.ValidateTranslation(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	article, err := h.articleFromPath(r)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+	if locale == article.Locale {
+		http.Error(w, "El artículo ya está escrito en "+locale, http.StatusBadRequest)
+		return
+	}
+
+	existing, _ := h.Store.GetArticleTranslation(article.ID, locale)
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	translation := kb.Translate(existing, *article, locale, req, userID, time.Now())
+
+	if err := h.Store.SaveArticleTranslation(translation); err != nil {
+		http.Error(w, "Error al guardar traducción: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if existing == nil {
+		status = http.StatusCreated
+	}
+	utils.WriteJSON(w, status, translation)
+}
+
+// DeleteArticleTranslation elimina la traducción de un artículo a un idioma
+// Formato de URL: /api/kb/articles/:id/translations/:locale
+func (h *ArticleHandler) DeleteArticleTranslation(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes DELETE
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	if !canPublishArticles(role) {
+		http.Error(w, "Prohibido: Permisos insuficientes", http.StatusForbidden)
+		return
+	}
+
+	locale, err := translationLocale(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	article, err := h.articleFromPath(r)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	if err := h.Store.DeleteArticleTranslation(article.ID, locale); err != nil {
+		http.Error(w, "Traducción no encontrada", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ChangeTranslationStatus mueve una traducción en el flujo editorial. Publicar requiere
+// rol de administrador o asistente.
+// Formato de URL: /api/kb/articles/:id/translations/:locale/status
+func (h *ArticleHandler) ChangeTranslationStatus(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	if !canEditArticles(role) {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	locale, err := translationLocale(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req models.ArticleStatusRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer el estado", http.StatusBadRequest)
+		return
+	}
+	if !kb.ValidStatus(req.Status) {
+		http.Error(w, "Estado inválido: "+req.Status, http.StatusBadRequest)
+		return
+	}
+	if req.Status == models.ArticleStatusPublished && !canPublishArticles(role) {
+		http.Error(w, "Prohibido: Permisos insuficientes para publicar", http.StatusForbidden)
+		return
+	}
+
+	article, err := h.articleFromPath(r)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+	translation, err := h.Store.GetArticleTranslation(article.ID, locale)
+	if err != nil {
+		http.Error(w, "Traducción no encontrada", http.StatusNotFound)
+		return
+	}
+	if !kb.CanTransition(translation.Status, req.Status) {
+		http.Error(w, fmt.Sprintf("No se puede pasar de %s a %s", translation.Status, req.Status), http.StatusConflict)
+		return
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	now := time.Now()
+	if req.Status == models.ArticleStatusPublished {
+		kb.PublishTranslation(translation, userID, now)
+	} else {
+		translation.Status = req.Status
+		translation.UpdatedAt = now
+	}
+
+	if err := h.Store.SaveArticleTranslation(*translation); err != nil {
+		http.Error(w, "Error al guardar traducción: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, translation)
+}
+
+// UnpublishTranslation retira una traducción del widget; el widget vuelve a mostrar el
+// siguiente idioma de la cadena
+// Formato de URL: /api/kb/articles/:id/translations/:locale/unpublish
+func (h *ArticleHandler) UnpublishTranslation(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	if !canPublishArticles(role) {
+		http.Error(w, "Prohibido: Permisos insuficientes", http.StatusForbidden)
+		return
+	}
+
+	locale, err := translationLocale(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	article, err := h.articleFromPath(r)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+	translation, err := h.Store.GetArticleTranslation(article.ID, locale)
+	if err != nil {
+		http.Error(w, "Traducción no encontrada", http.StatusNotFound)
+		return
+	}
+	if translation.PublishedAt == nil {
+		http.Error(w, "La traducción no está publicada", http.StatusConflict)
+		return
+	}
+
+	kb.UnpublishTranslation(translation, time.Now())
+	if err := h.Store.SaveArticleTranslation(*translation); err != nil {
+		http.Error(w, "Error al guardar traducción: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, translation)
+}
+
+// GetMissingTranslations lista las traducciones pendientes: inexistentes, sin publicar
+// o desactualizadas respecto a la revisión publicada del artículo.
+// Parámetros: ?locale= (por defecto, todos los idiomas admitidos) y ?status= del artículo
+func (h *ArticleHandler) GetMissingTranslations(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	if !canEditArticles(role) {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	locales := kb.SupportedLocales
+	if value := r.URL.Query().Get("locale"); value != "" {
+		locale, ok := kb.NormalizeLocale(value)
+		if !ok || !kb.IsSupported(locale) {
+			http.Error(w, "Idioma no admitido: "+value, http.StatusBadRequest)
+			return
+		}
+		locales = []string{locale}
+	}
+
+	filter := models.ArticleFilter{Status: r.URL.Query().Get("status")}
+	if filter.Status != "" && !kb.ValidStatus(filter.Status) {
+		http.Error(w, "Estado inválido: "+filter.Status, http.StatusBadRequest)
+		return
+	}
+
+	articles, err := h.Store.GetArticles(filter)
+	if err != nil {
+		http.Error(w, "Error al obtener artículos", http.StatusInternalServerError)
+		return
+	}
+
+	byArticle, err := translationsByArticle(h.Store, locales)
+	if err != nil {
+		http.Error(w, "Error al obtener traducciones", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, kb.TranslationGaps(articles, byArticle, locales))
+}
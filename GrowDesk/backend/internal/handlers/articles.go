@@ -0,0 +1,609 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/kb"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// ArticleHandler contiene manejadores para la base de conocimiento
+type ArticleHandler struct {
+	Store data.DataStore
+}
+
+// canEditArticles indica si el rol puede redactar artículos (cualquier agente)
+func canEditArticles(role string) bool {
+	return role != "" && role != "customer"
+}
+
+// canPublishArticles indica si el rol puede publicar y retirar artículos
+func canPublishArticles(role string) bool {
+	return role == "admin" || role == "assistant"
+}
+
+// articleSegments devuelve los segmentos de la URL que siguen a /api/kb/articles/
+// (o /widget/kb/articles/)
+func articleSegments(r *http.Request) []string {
+	path := strings.Trim(r.URL.Path, "/")
+	if _, rest, found := strings.Cut(path, "kb/articles/"); found {
+		return strings.Split(rest, "/")
+	}
+	return nil
+}
+
+// articleFromPath obtiene el artículo de la URL, indicado por ID o por slug
+func (h *ArticleHandler) articleFromPath(r *http.Request) (*models.Article, error) {
+	segments := articleSegments(r)
+	if len(segments) == 0 || segments[0] == "" {
+		return nil, fmt.Errorf("artículo no indicado")
+	}
+	if id, err := strconv.Atoi(segments[0]); err == nil {
+		return h.Store.GetArticle(id)
+	}
+	return h.Store.GetArticleBySlug(segments[0])
+}
+
+// uniqueArticleSlug genera un slug libre a partir del título
+func uniqueArticleSlug(store data.DataStore, title string) string {
+	return kb.UniqueSlug(kb.Slugify(title), func(slug string) bool {
+		_, err := store.GetArticleBySlug(slug)
+		return err == nil
+	})
+}
+
+// validateArticleRequest comprueba el contenido de un artículo y su categoría
+func (h *ArticleHandler) validateArticleRequest(req *models.ArticleRequest) error {
+	if err := kb.Validate(req); err != nil {
+		return err
+	}
+	if req.CategoryID != "" {
+		if _, err := h.Store.GetCategory(req.CategoryID); err != nil {
+			return fmt.Errorf("Categoría no encontrada: %s", req.CategoryID)
+		}
+	}
+	return nil
+}
+
+// GetArticles devuelve los artículos en su versión de trabajo.
+// Parámetros: ?status=draft|review|published&categoryId=
+func (h *ArticleHandler) GetArticles(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	filter := models.ArticleFilter{
+		Status:     r.URL.Query().Get("status"),
+		CategoryID: r.URL.Query().Get("categoryId"),
+	}
+	if filter.Status != "" && !kb.ValidStatus(filter.Status) {
+		http.Error(w, "Estado inválido: "+filter.Status, http.StatusBadRequest)
+		return
+	}
+
+	articles, err := h.Store.GetArticles(filter)
+	if err != nil {
+		http.Error(w, "Error al obtener artículos", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, articles)
+}
+
+// GetArticle devuelve un artículo por ID o slug.
+// Formato de URL: /api/kb/articles/:id
+func (h *ArticleHandler) GetArticle(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	article, err := h.articleFromPath(r)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, article)
+}
+
+// CreateArticle crea un artículo en borrador con su primera revisión
+func (h *ArticleHandler) CreateArticle(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	if !canEditArticles(role) {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.ArticleRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer datos del artículo", http.StatusBadRequest)
+		return
+	}
+	if err := h.validateArticleRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Slug == "" {
+		req.Slug = uniqueArticleSlug(h.Store, req.Title)
+	} else if _, err := h.Store.GetArticleBySlug(req.Slug); err == nil {
+		http.Error(w, "El slug ya está en uso: "+req.Slug, http.StatusConflict)
+		return
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	article, revision := kb.New(req, userID, time.Now())
+
+	created, err := h.Store.CreateArticle(article, revision)
+	if err != nil {
+		http.Error(w, "Error al crear artículo: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, created)
+}
+
+// UpdateArticle guarda una nueva revisión de un artículo. Si el artículo estaba
+// publicado vuelve a borrador y el widget sigue mostrando la revisión publicada.
+// Formato de URL: /api/kb/articles/:id
+func (h *ArticleHandler) UpdateArticle(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes PUT
+	if r.Method != http.MethodPut {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	if !canEditArticles(role) {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.ArticleRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer datos del artículo", http.StatusBadRequest)
+		return
+	}
+	if err := h.validateArticleRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	article, err := h.articleFromPath(r)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+	if req.Revision != 0 && req.Revision != article.Revision {
+		http.Error(w, fmt.Sprintf("El artículo fue modificado por otra edición (revisión actual: %d)", article.Revision), http.StatusConflict)
+		return
+	}
+	if req.Slug != "" && req.Slug != article.Slug {
+		if _, err := h.Store.GetArticleBySlug(req.Slug); err == nil {
+			http.Error(w, "El slug ya está en uso: "+req.Slug, http.StatusConflict)
+			return
+		}
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	revision := kb.Revise(article, req, userID, time.Now())
+
+	if err := h.Store.UpdateArticle(*article, &revision); err != nil {
+		http.Error(w, "Error al guardar el artículo: "+err.Error(), http.StatusConflict)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, article)
+}
+
+// DeleteArticle elimina un artículo y su historial
+// Formato de URL: /api/kb/articles/:id
+func (h *ArticleHandler) DeleteArticle(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes DELETE
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	article, err := h.articleFromPath(r)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	if err := h.Store.DeleteArticle(article.ID); err != nil {
+		http.Error(w, "Error al eliminar artículo", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ChangeArticleStatus mueve un artículo en el flujo editorial (borrador → revisión →
+// publicado). Publicar requiere rol de administrador o asistente.
+// Formato de URL: /api/kb/articles/:id/status
+func (h *ArticleHandler) ChangeArticleStatus(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	if !canEditArticles(role) {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.ArticleStatusRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer el estado", http.StatusBadRequest)
+		return
+	}
+	if !kb.ValidStatus(req.Status) {
+		http.Error(w, "Estado inválido: "+req.Status, http.StatusBadRequest)
+		return
+	}
+	if req.Status == models.ArticleStatusPublished && !canPublishArticles(role) {
+		http.Error(w, "Prohibido: Permisos insuficientes para publicar", http.StatusForbidden)
+		return
+	}
+
+	article, err := h.articleFromPath(r)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+	if !kb.CanTransition(article.Status, req.Status) {
+		http.Error(w, fmt.Sprintf("No se puede pasar de %s a %s", article.Status, req.Status), http.StatusConflict)
+		return
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	now := time.Now()
+	if req.Status == models.ArticleStatusPublished {
+		kb.Publish(article, userID, now)
+	} else {
+		article.Status = req.Status
+		article.UpdatedAt = now
+	}
+
+	if err := h.Store.UpdateArticle(*article, nil); err != nil {
+		http.Error(w, "Error al guardar el artículo: "+err.Error(), http.StatusConflict)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, article)
+}
+
+// UnpublishArticle retira un artículo del widget
+// Formato de URL: /api/kb/articles/:id/unpublish
+func (h *ArticleHandler) UnpublishArticle(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	if !canPublishArticles(role) {
+		http.Error(w, "Prohibido: Permisos insuficientes", http.StatusForbidden)
+		return
+	}
+
+	article, err := h.articleFromPath(r)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+	if article.PublishedRevision == 0 {
+		http.Error(w, "El artículo no está publicado", http.StatusConflict)
+		return
+	}
+
+	kb.Unpublish(article, time.Now())
+	if err := h.Store.UpdateArticle(*article, nil); err != nil {
+		http.Error(w, "Error al guardar el artículo: "+err.Error(), http.StatusConflict)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, article)
+}
+
+// GetArticleRevisions devuelve el historial de revisiones de un artículo
+// Formato de URL: /api/kb/articles/:id/revisions
+func (h *ArticleHandler) GetArticleRevisions(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	article, err := h.articleFromPath(r)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	revisions, err := h.Store.GetArticleRevisions(article.ID)
+	if err != nil {
+		http.Error(w, "Error al obtener revisiones", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, revisions)
+}
+
+// GetArticleRevision devuelve una revisión concreta de un artículo
+// Formato de URL: /api/kb/articles/:id/revisions/:revision
+func (h *ArticleHandler) GetArticleRevision(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	segments := articleSegments(r)
+	if len(segments) != 3 {
+		http.Error(w, "URL de revisión inválida", http.StatusBadRequest)
+		return
+	}
+	number, err := strconv.Atoi(segments[2])
+	if err != nil {
+		http.Error(w, "Número de revisión inválido", http.StatusBadRequest)
+		return
+	}
+
+	article, err := h.articleFromPath(r)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	revision, err := h.Store.GetArticleRevision(article.ID, number)
+	if err != nil {
+		http.Error(w, "Revisión no encontrada", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, revision)
+}
+
+// GetArticleDiff compara dos revisiones de un artículo.
+// Parámetros: ?from=N&to=M (por defecto, la revisión actual frente a la anterior)
+// Formato de URL: /api/kb/articles/:id/diff
+func (h *ArticleHandler) GetArticleDiff(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	article, err := h.articleFromPath(r)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	to := article.Revision
+	if value := query.Get("to"); value != "" {
+		if to, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Revisión final inválida", http.StatusBadRequest)
+			return
+		}
+	}
+	from := to - 1
+	if value := query.Get("from"); value != "" {
+		if from, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Revisión inicial inválida", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// La revisión 0 representa el artículo vacío (diff de la primera revisión)
+	fromRevision := models.ArticleRevision{ArticleID: article.ID}
+	if from != 0 {
+		revision, err := h.Store.GetArticleRevision(article.ID, from)
+		if err != nil {
+			http.Error(w, "Revisión no encontrada: "+strconv.Itoa(from), http.StatusNotFound)
+			return
+		}
+		fromRevision = *revision
+	}
+	toRevision, err := h.Store.GetArticleRevision(article.ID, to)
+	if err != nil {
+		http.Error(w, "Revisión no encontrada: "+strconv.Itoa(to), http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, kb.CompareRevisions(fromRevision, *toRevision))
+}
+
+// RollbackArticle restaura el contenido de una revisión anterior como revisión nueva.
+// El artículo queda en borrador hasta que se vuelva a publicar.
+// Formato de URL: /api/kb/articles/:id/rollback
+func (h *ArticleHandler) RollbackArticle(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	if !canEditArticles(role) {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.ArticleRollbackRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer la revisión a restaurar", http.StatusBadRequest)
+		return
+	}
+
+	article, err := h.articleFromPath(r)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+	if req.Revision == article.Revision {
+		http.Error(w, "La revisión indicada ya es la actual", http.StatusBadRequest)
+		return
+	}
+
+	target, err := h.Store.GetArticleRevision(article.ID, req.Revision)
+	if err != nil {
+		http.Error(w, "Revisión no encontrada", http.StatusNotFound)
+		return
+	}
+	if target.CategoryID != "" {
+		if _, err := h.Store.GetCategory(target.CategoryID); err != nil {
+			// La categoría de la revisión ya no existe; se conserva la actual
+			target.CategoryID = article.CategoryID
+		}
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	revision := kb.Rollback(article, *target, userID, strings.TrimSpace(req.Note), time.Now())
+
+	if err := h.Store.UpdateArticle(*article, &revision); err != nil {
+		http.Error(w, "Error al guardar el artículo: "+err.Error(), http.StatusConflict)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, article)
+}
+
+// GetPublishedArticles devuelve los artículos publicados para el widget (?categoryId=),
+// traducidos al idioma preferido cuando hay una traducción publicada
+func (h *ArticleHandler) GetPublishedArticles(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	articles, err := h.Store.GetPublishedArticles()
+	if err != nil {
+		http.Error(w, "Error al obtener artículos", http.StatusInternalServerError)
+		return
+	}
+
+	if categoryID := r.URL.Query().Get("categoryId"); categoryID != "" {
+		filtered := make([]models.Article, 0, len(articles))
+		for _, article := range articles {
+			if article.CategoryID == categoryID {
+				filtered = append(filtered, article)
+			}
+		}
+		articles = filtered
+	}
+
+	// Mostrar cada artículo en el idioma preferido (?lang= o Accept-Language)
+	w.Header().Set("Vary", "Accept-Language")
+	if err := localizeArticles(h.Store, articles, requestLocales(r)); err != nil {
+		http.Error(w, "Error al obtener traducciones", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, articles)
+}
+
+// GetPublishedArticle devuelve la versión publicada de un artículo por slug o ID
+// Formato de URL: /widget/kb/articles/:slug
+func (h *ArticleHandler) GetPublishedArticle(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	article, err := h.articleFromPath(r)
+	if err != nil || article.PublishedRevision == 0 {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	revision, err := h.Store.GetArticleRevision(article.ID, article.PublishedRevision)
+	if err != nil {
+		http.Error(w, "Artículo no encontrado", http.StatusNotFound)
+		return
+	}
+	article.Title = revision.Title
+	article.Body = revision.Body
+	article.CategoryID = revision.CategoryID
+
+	// Mostrar el artículo en el idioma preferido (?lang= o Accept-Language)
+	articles := []models.Article{*article}
+	w.Header().Set("Vary", "Accept-Language")
+	if err := localizeArticles(h.Store, articles, requestLocales(r)); err != nil {
+		http.Error(w, "Error al obtener traducciones", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, articles[0])
+}
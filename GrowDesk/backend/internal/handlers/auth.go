@@ -3,19 +3,29 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/passwords"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/twofactor"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
 )
 
 // AuthHandler contiene manejadores para autenticación
 type AuthHandler struct {
-	Store data.DataStore
+	Store     data.DataStore
+	Passwords *passwords.Service
+	TwoFactor *twofactor.Service
+	// MockLogin mantiene el inicio de sesión de prueba para los emails sin cuenta
+	MockLogin bool
 }
 
-// Login maneja solicitudes de inicio de sesión de usuarios
+// Login maneja solicitudes de inicio de sesión de usuarios. Si el usuario tiene el
+// segundo factor activado, o su rol lo exige, responde con un desafío en lugar de una
+// sesión; el desafío se completa en /api/auth/2fa/verify o, si aún no lo configuró, en
+// /api/auth/2fa/setup y /api/auth/2fa/enable.
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	// Solo maneja solicitudes POST
 	if r.Method != http.MethodPost {
@@ -36,8 +46,25 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// En una implementación produccion, validaríamos las credenciales contra la base de datos
-	// Por ahora, usaremos un token fijo para cualquier inicio de sesión válido
+	// Buscar al usuario y comprobar la contraseña
+	user, err := h.Store.GetUserByEmail(strings.TrimSpace(loginReq.Email))
+	if err == nil {
+		stored, err := h.Store.GetUserPasswordHash(user.ID)
+		if err != nil || !utils.CheckPassword(stored, loginReq.Password) {
+			http.Error(w, "Email o contraseña incorrectos", http.StatusUnauthorized)
+			return
+		}
+
+		startSession(w, h.Store, h.TwoFactor, http.StatusOK, *user)
+		return
+	}
+
+	if !h.MockLogin {
+		http.Error(w, "Email o contraseña incorrectos", http.StatusUnauthorized)
+		return
+	}
+
+	// En desarrollo, los emails sin cuenta reciben un token fijo de prueba
 
 	// Generar token
 	token := utils.GenerateMockToken()
@@ -137,3 +164,51 @@ func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(user)
 }
+
+// startSession inicia la sesión de un usuario ya autenticado por contraseña. Si tiene el
+// segundo factor activado, o su rol lo exige, responde con un desafío en lugar de una
+// sesión.
+func startSession(w http.ResponseWriter, store data.DataStore, twoFactorService *twofactor.Service, status int, user models.User) {
+	version, err := store.GetUserSessionVersion(user.ID)
+	if err != nil {
+		http.Error(w, "Error al iniciar sesión", http.StatusInternalServerError)
+		return
+	}
+
+	// Pedir el segundo factor si está activado o si el rol lo exige
+	if twoFactorService != nil {
+		twoFactor, err := store.GetTwoFactor(user.ID)
+		if err != nil {
+			http.Error(w, "Error al iniciar sesión", http.StatusInternalServerError)
+			return
+		}
+		if twoFactor.Enabled || twoFactorService.Required(user.Role) {
+			challenge, expiresAt, err := utils.GenerateChallengeToken(user.ID, user.Email, user.Role, version, twofactor.ChallengeTTL)
+			if err != nil {
+				http.Error(w, "Error al generar token", http.StatusInternalServerError)
+				return
+			}
+			utils.WriteJSON(w, status, models.LoginChallenge{
+				TwoFactorRequired: true,
+				SetupRequired:     !twoFactor.Enabled,
+				ChallengeToken:    challenge,
+				ExpiresAt:         expiresAt,
+			})
+			return
+		}
+	}
+
+	writeSession(w, status, user, version)
+}
+
+// writeSession responde con un token de sesión nuevo y los datos del usuario
+func writeSession(w http.ResponseWriter, status int, user models.User, sessionVersion int) {
+	token, err := utils.GenerateSessionToken(user.ID, user.Email, user.Role, sessionVersion)
+	if err != nil {
+		http.Error(w, "Error al generar token", http.StatusInternalServerError)
+		return
+	}
+
+	user.Password = ""
+	utils.WriteJSON(w, status, models.AuthResponse{Token: token, User: user})
+}
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/agents"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/automation"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/customfields"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// AutomationHandler contiene manejadores para las reglas de automatización.
+// Todas las operaciones requieren rol de administrador.
+type AutomationHandler struct {
+	Store data.DataStore
+}
+
+// validateAutomationRule comprueba y normaliza una regla antes de guardarla
+func (h *AutomationHandler) validateAutomationRule(rule *models.AutomationRule) error {
+	if err := automation.ValidateRule(rule); err != nil {
+		return err
+	}
+
+	actions := &rule.Actions
+	hasActions := false
+
+	// La asignación automática elige al agente al aplicar la regla
+	ticketActions := actions.TicketActions
+	if ticketActions.AssignedTo != nil && *ticketActions.AssignedTo == agents.AutoAssign {
+		ticketActions.AssignedTo = nil
+		hasActions = true
+	}
+
+	changes := macroChanges(ticketActions)
+	changes.CategoryID = actions.CategoryID
+	if hasMacroActions(ticketActions) || changes.CategoryID != "" {
+		validated, err := validateBulkChanges(h.Store, changes)
+		if err != nil {
+			return err
+		}
+		actions.AddTags = validated.AddTags
+		actions.RemoveTags = validated.RemoveTags
+		hasActions = true
+	}
+
+	actions.Department = strings.TrimSpace(actions.Department)
+	if actions.Department != "" {
+		teamID, err := resolveTeam(h.Store, actions.Department)
+		if err != nil {
+			return err
+		}
+		actions.Department = teamID
+		hasActions = true
+	}
+
+	if len(actions.CustomFields) > 0 {
+		defs, err := h.Store.GetCustomFieldDefinitions()
+		if err != nil {
+			return fmt.Errorf("Error al obtener campos personalizados")
+		}
+		// Los campos deben existir; los que dependen de la categoría se validan al aplicar la regla
+		for key := range actions.CustomFields {
+			def, found := customfields.Find(defs, key)
+			if !found {
+				return fmt.Errorf("Campo personalizado desconocido: %s", key)
+			}
+			if _, err := customfields.Normalize(*def, actions.CustomFields[key]); err != nil {
+				return err
+			}
+		}
+		hasActions = true
+	}
+
+	if actions.MacroID != "" {
+		macro, err := h.Store.GetMacro(actions.MacroID)
+		if err != nil || !macro.Shared {
+			return fmt.Errorf("Macro compartida no encontrada: %s", actions.MacroID)
+		}
+		hasActions = true
+	}
+
+	for _, userID := range actions.Notify {
+		if userID == automation.NotifyAssignee {
+			continue
+		}
+		if _, err := h.Store.GetUser(userID); err != nil {
+			return fmt.Errorf("Usuario a notificar no encontrado: %s", userID)
+		}
+	}
+	if len(actions.Notify) > 0 {
+		hasActions = true
+	}
+
+	actions.WebhookURL = strings.TrimSpace(actions.WebhookURL)
+	if actions.WebhookURL != "" {
+		if err := automation.ValidateWebhookURL(actions.WebhookURL); err != nil {
+			return err
+		}
+		hasActions = true
+	}
+
+	if !hasActions {
+		return fmt.Errorf("La regla debe tener al menos una acción")
+	}
+	return nil
+}
+
+// GetAllAutomationRules devuelve las reglas en orden de evaluación
+func (h *AutomationHandler) GetAllAutomationRules(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	rules, err := h.Store.GetAutomationRules()
+	if err != nil {
+		http.Error(w, "Error al obtener reglas de automatización", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, rules)
+}
+
+// GetAutomationRule obtiene una regla por ID
+func (h *AutomationHandler) GetAutomationRule(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	// Obtener ID de la URL (/api/automation/rules/:id)
+	segments := strings.Split(r.URL.Path, "/")
+	if len(segments) < 5 {
+		http.Error(w, "URL de regla inválida", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.Store.GetAutomationRule(segments[4])
+	if err != nil {
+		http.Error(w, "Regla no encontrada", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, rule)
+}
+
+// CreateAutomationRule crea una nueva regla
+func (h *AutomationHandler) CreateAutomationRule(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	// Las reglas nuevas están activas salvo que se indique lo contrario
+	rule := models.AutomationRule{Active: true}
+	if err := utils.DecodeJSON(r, &rule); err != nil {
+		http.Error(w, "Error al leer datos de la regla", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validateAutomationRule(&rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Establecer ID y marcas de tiempo
+	rule.ID = uuid.New().String()
+	now := time.Now()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	if err := h.Store.CreateAutomationRule(rule); err != nil {
+		http.Error(w, "Error al crear regla de automatización", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, rule)
+}
+
+// UpdateAutomationRule reemplaza una regla existente
+func (h *AutomationHandler) UpdateAutomationRule(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes PUT
+	if r.Method != http.MethodPut {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	segments := strings.Split(r.URL.Path, "/")
+	if len(segments) < 5 {
+		http.Error(w, "URL de regla inválida", http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.Store.GetAutomationRule(segments[4])
+	if err != nil {
+		http.Error(w, "Regla no encontrada", http.StatusNotFound)
+		return
+	}
+
+	rule := *existing
+	if err := utils.DecodeJSON(r, &rule); err != nil {
+		http.Error(w, "Error al leer datos de la regla", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validateAutomationRule(&rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rule.ID = existing.ID
+	rule.CreatedAt = existing.CreatedAt
+	rule.UpdatedAt = time.Now()
+
+	if err := h.Store.UpdateAutomationRule(rule); err != nil {
+		http.Error(w, "Error al actualizar regla de automatización", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, rule)
+}
+
+// DeleteAutomationRule elimina una regla
+func (h *AutomationHandler) DeleteAutomationRule(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes DELETE
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	segments := strings.Split(r.URL.Path, "/")
+	if len(segments) < 5 {
+		http.Error(w, "URL de regla inválida", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.DeleteAutomationRule(segments[4]); err != nil {
+		http.Error(w, "Regla no encontrada", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
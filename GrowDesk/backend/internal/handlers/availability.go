@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/availability"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/presence"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// AvailabilityHandler contiene manejadores para horarios de atención, presencia de agentes
+// y estado público del widget
+type AvailabilityHandler struct {
+	Store        data.DataStore
+	Presence     *presence.Tracker
+	Availability *availability.Service
+}
+
+// GetWidgetStatus devuelve la disponibilidad del chat en vivo para un widget (público)
+func (h *AvailabilityHandler) GetWidgetStatus(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	widgetID := r.URL.Query().Get("widgetId")
+	if widgetID == "" {
+		widgetID = r.Header.Get("X-Widget-ID")
+	}
+	department := r.URL.Query().Get("department")
+
+	status := h.Availability.Status(widgetID, department, time.Now())
+
+	utils.WriteJSON(w, http.StatusOK, status)
+}
+
+// GetAllBusinessHours devuelve todos los horarios de atención
+func (h *AvailabilityHandler) GetAllBusinessHours(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	hours, err := h.Store.GetBusinessHours()
+	if err != nil {
+		http.Error(w, "Error al obtener horarios de atención", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, hours)
+}
+
+// GetBusinessHours obtiene un horario de atención por ID
+func (h *AvailabilityHandler) GetBusinessHours(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Obtener ID de la URL (/api/business-hours/:id)
+	segments := strings.Split(r.URL.Path, "/")
+	if len(segments) < 4 {
+		http.Error(w, "URL de horario inválida", http.StatusBadRequest)
+		return
+	}
+
+	hours, err := h.Store.GetBusinessHoursByID(segments[3])
+	if err != nil {
+		http.Error(w, "Horario de atención no encontrado", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, hours)
+}
+
+// CreateBusinessHours crea un nuevo horario de atención
+func (h *AvailabilityHandler) CreateBusinessHours(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	var hours models.BusinessHours
+	if err := utils.DecodeJSON(r, &hours); err != nil {
+		http.Error(w, "Error al leer datos del horario", http.StatusBadRequest)
+		return
+	}
+
+	if hours.Name == "" {
+		http.Error(w, "El nombre del horario es requerido", http.StatusBadRequest)
+		return
+	}
+	if hours.TimeZone == "" {
+		hours.TimeZone = "UTC"
+	}
+	if err := availability.Validate(hours); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Establecer ID y marcas de tiempo
+	hours.ID = uuid.New().String()
+	now := time.Now()
+	hours.CreatedAt = now
+	hours.UpdatedAt = now
+
+	if err := h.Store.CreateBusinessHours(hours); err != nil {
+		http.Error(w, "Error al crear horario de atención", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, hours)
+}
+
+// UpdateBusinessHours reemplaza un horario de atención existente
+func (h *AvailabilityHandler) UpdateBusinessHours(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes PUT
+	if r.Method != http.MethodPut {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	segments := strings.Split(r.URL.Path, "/")
+	if len(segments) < 4 {
+		http.Error(w, "URL de horario inválida", http.StatusBadRequest)
+		return
+	}
+	hoursID := segments[3]
+
+	existing, err := h.Store.GetBusinessHoursByID(hoursID)
+	if err != nil {
+		http.Error(w, "Horario de atención no encontrado", http.StatusNotFound)
+		return
+	}
+
+	var hours models.BusinessHours
+	if err := utils.DecodeJSON(r, &hours); err != nil {
+		http.Error(w, "Error al leer datos del horario", http.StatusBadRequest)
+		return
+	}
+
+	hours.ID = existing.ID
+	hours.CreatedAt = existing.CreatedAt
+	hours.UpdatedAt = time.Now()
+	if hours.Name == "" {
+		hours.Name = existing.Name
+	}
+	if hours.TimeZone == "" {
+		hours.TimeZone = existing.TimeZone
+	}
+	if err := availability.Validate(hours); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.UpdateBusinessHours(hours); err != nil {
+		http.Error(w, "Error al actualizar horario de atención", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, hours)
+}
+
+// DeleteBusinessHours elimina un horario de atención
+func (h *AvailabilityHandler) DeleteBusinessHours(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes DELETE
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	segments := strings.Split(r.URL.Path, "/")
+	if len(segments) < 4 {
+		http.Error(w, "URL de horario inválida", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.DeleteBusinessHours(segments[3]); err != nil {
+		http.Error(w, "Horario de atención no encontrado", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// GetOnlineAgents devuelve los agentes conectados, opcionalmente filtrados por departamento
+func (h *AvailabilityHandler) GetOnlineAgents(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	agents := h.Presence.Online(r.URL.Query().Get("department"))
+
+	utils.WriteJSON(w, http.StatusOK, agents)
+}
+
+// Heartbeat registra la presencia del agente autenticado. El panel de agentes debe
+// llamarlo periódicamente; con {"status": "offline"} el agente se desconecta de inmediato.
+func (h *AvailabilityHandler) Heartbeat(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	if userID == "" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	// El cuerpo es opcional
+	var req struct {
+		Status string `json:"status"`
+	}
+	if r.ContentLength > 0 {
+		if err := utils.DecodeJSON(r, &req); err != nil {
+			http.Error(w, "Error al leer datos de presencia", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if req.Status == "offline" {
+		h.Presence.SetOffline(userID)
+		utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"success": true, "status": "offline"})
+		return
+	}
+
+	h.Presence.Heartbeat(agentPresence(h.Store, r, userID))
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"success": true, "status": "online"})
+}
+
+// agentPresence arma la presencia del agente autenticado con los datos de su usuario
+func agentPresence(store data.DataStore, r *http.Request, userID string) models.AgentPresence {
+	agent := models.AgentPresence{UserID: userID}
+	if email, ok := r.Context().Value(middleware.EmailKey).(string); ok {
+		agent.Email = email
+	}
+	if user, err := store.GetUser(userID); err == nil {
+		agent.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		agent.Email = user.Email
+		agent.Department = user.Department
+	}
+	return agent
+}
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/categories"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
@@ -17,7 +19,26 @@ type CategoryHandler struct {
 	Store data.DataStore
 }
 
-// GetAllCategories devuelve todas las categorías
+// validateCategory comprueba la posición en el árbol y los valores por defecto de una
+// categoría. all son las categorías existentes.
+func validateCategory(all []models.Category, category models.Category) error {
+	if strings.TrimSpace(category.Name) == "" {
+		return fmt.Errorf("El nombre de la categoría es requerido")
+	}
+	if category.Position < 0 {
+		return fmt.Errorf("La posición no puede ser negativa")
+	}
+	if err := categories.ValidateParent(all, category.ID, category.ParentID); err != nil {
+		return err
+	}
+	if category.Defaults.Priority != "" && !bulkTicketPriorities[category.Defaults.Priority] {
+		return fmt.Errorf("Prioridad por defecto inválida: %s", category.Defaults.Priority)
+	}
+	return nil
+}
+
+// GetAllCategories devuelve todas las categorías en el orden del árbol (cada categoría
+// seguida de sus subcategorías). Con ?tree=true devuelve el árbol anidado.
 func (h *CategoryHandler) GetAllCategories(w http.ResponseWriter, r *http.Request) {
 	// Esta función solo maneja solicitudes GET
 	if r.Method != http.MethodGet {
@@ -29,14 +50,18 @@ func (h *CategoryHandler) GetAllCategories(w http.ResponseWriter, r *http.Reques
 	utils.SetCORS(w)
 
 	// Obtener categorías del almacén
-	categories, err := h.Store.GetCategories()
+	all, err := h.Store.GetCategories()
 	if err != nil {
 		http.Error(w, "Error al obtener categorías", http.StatusInternalServerError)
 		return
 	}
 
 	// Devolver categorías como JSON
-	utils.WriteJSON(w, http.StatusOK, categories)
+	if r.URL.Query().Get("tree") == "true" {
+		utils.WriteJSON(w, http.StatusOK, categories.Tree(all))
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, categories.Flatten(all))
 }
 
 // GetCategory obtiene una categoría por ID
@@ -96,9 +121,19 @@ func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Validar campos requeridos
-	if category.Name == "" {
-		http.Error(w, "El nombre de la categoría es requerido", http.StatusBadRequest)
+	// Validar campos requeridos, la categoría padre y los valores por defecto
+	all, err := h.Store.GetCategories()
+	if err != nil {
+		http.Error(w, "Error al obtener categorías", http.StatusInternalServerError)
+		return
+	}
+	category.ID = ""
+	if err := validateCategory(all, category); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if category.Defaults.Department, err = resolveTeam(h.Store, category.Defaults.Department); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -162,8 +197,14 @@ func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Decodificar cuerpo de la solicitud
-	var updates models.Category
+	// Decodificar cuerpo de la solicitud; los campos omitidos no cambian
+	var updates struct {
+		models.Category
+		ParentID *string                  `json:"parentId"`
+		Position *int                     `json:"position"`
+		Defaults *models.CategoryDefaults `json:"defaults"`
+		Active   *bool                    `json:"active"`
+	}
 	if err := utils.DecodeJSON(r, &updates); err != nil {
 		http.Error(w, "Error al leer datos de actualización", http.StatusBadRequest)
 		return
@@ -183,8 +224,38 @@ func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 		existingCategory.Icon = updates.Icon
 	}
 
+	if updates.ParentID != nil {
+		existingCategory.ParentID = *updates.ParentID
+	}
+	if updates.Position != nil {
+		existingCategory.Position = *updates.Position
+	}
+	if updates.Defaults != nil {
+		existingCategory.Defaults = *updates.Defaults
+	}
+
 	// Actualizar campo active explícitamente si se proporciona
-	existingCategory.Active = updates.Active
+	if updates.Active != nil {
+		existingCategory.Active = *updates.Active
+	}
+
+	// Validar la nueva posición en el árbol y los valores por defecto
+	all, err := h.Store.GetCategories()
+	if err != nil {
+		http.Error(w, "Error al obtener categorías", http.StatusInternalServerError)
+		return
+	}
+	if err := validateCategory(all, *existingCategory); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if updates.Defaults != nil {
+		existingCategory.Defaults.Department, err = resolveTeam(h.Store, existingCategory.Defaults.Department)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
 
 	// Actualizar marca de tiempo
 	existingCategory.UpdatedAt = time.Now()
@@ -199,7 +270,9 @@ func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 	utils.WriteJSON(w, http.StatusOK, existingCategory)
 }
 
-// DeleteCategory elimina una categoría existente
+// DeleteCategory elimina una categoría existente. Si hay tickets que la usan se debe
+// indicar ?reassignTo=ID para moverlos a otra categoría, o ?archive=true para
+// archivarla en lugar de eliminarla. Las subcategorías pasan a la categoría padre.
 func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 	// Esta función solo maneja solicitudes DELETE
 	if r.Method != http.MethodDelete {
@@ -227,6 +300,62 @@ func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request)
 
 	categoryID := segments[3]
 
+	category, err := h.Store.GetCategory(categoryID)
+	if err != nil {
+		http.Error(w, "Categoría no encontrada", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+
+	// Archivar: la categoría deja de ofrecerse pero conserva sus tickets
+	if query.Get("archive") == "true" {
+		category.Active = false
+		category.UpdatedAt = time.Now()
+		if err := h.Store.UpdateCategory(*category); err != nil {
+			http.Error(w, "Error al archivar categoría", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"success": true, "archived": true})
+		return
+	}
+
+	// Reasignar los tickets a otra categoría antes de eliminarla
+	if targetID := query.Get("reassignTo"); targetID != "" {
+		if targetID == categoryID {
+			http.Error(w, "La categoría de destino debe ser distinta", http.StatusBadRequest)
+			return
+		}
+		target, err := h.Store.GetCategory(targetID)
+		if err != nil {
+			http.Error(w, "Categoría de destino no encontrada", http.StatusBadRequest)
+			return
+		}
+		if !target.Active {
+			http.Error(w, "La categoría de destino está archivada", http.StatusBadRequest)
+			return
+		}
+
+		moved, err := h.Store.DeleteCategoryReassigning(categoryID, *target)
+		if err != nil {
+			http.Error(w, "Error al eliminar categoría", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"success": true, "reassignedTickets": moved})
+		return
+	}
+
+	// Sin reasignación solo se eliminan las categorías sin tickets
+	count, err := h.Store.CountCategoryTickets(categoryID)
+	if err != nil {
+		http.Error(w, "Error al contar tickets de la categoría", http.StatusInternalServerError)
+		return
+	}
+	if count > 0 {
+		http.Error(w, fmt.Sprintf("La categoría tiene %d tickets: reasígnelos con ?reassignTo= o archívela con ?archive=true", count), http.StatusConflict)
+		return
+	}
+
 	// Eliminar la categoría
 	if err := h.Store.DeleteCategory(categoryID); err != nil {
 		http.Error(w, "Error al eliminar categoría", http.StatusInternalServerError)
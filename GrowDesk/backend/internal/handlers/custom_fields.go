@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/customfields"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// CustomFieldHandler contiene manejadores para las definiciones de campos personalizados
+type CustomFieldHandler struct {
+	Store data.DataStore
+}
+
+// GetAllCustomFields devuelve las definiciones de campos personalizados. Con ?categoryId=
+// devuelve solo los campos activos que aplican a esa categoría.
+func (h *CustomFieldHandler) GetAllCustomFields(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	defs, err := h.Store.GetCustomFieldDefinitions()
+	if err != nil {
+		http.Error(w, "Error al obtener campos personalizados", http.StatusInternalServerError)
+		return
+	}
+
+	if categoryID, ok := r.URL.Query()["categoryId"]; ok {
+		defs = customfields.Applicable(defs, categoryID[0])
+	}
+
+	utils.WriteJSON(w, http.StatusOK, defs)
+}
+
+// GetCustomField obtiene una definición de campo personalizado por ID
+func (h *CustomFieldHandler) GetCustomField(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Obtener ID de la URL (/api/custom-fields/:id)
+	segments := strings.Split(r.URL.Path, "/")
+	if len(segments) < 4 {
+		http.Error(w, "URL de campo personalizado inválida", http.StatusBadRequest)
+		return
+	}
+
+	def, err := h.Store.GetCustomFieldDefinition(segments[3])
+	if err != nil {
+		http.Error(w, "Campo personalizado no encontrado", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, def)
+}
+
+// CreateCustomField crea una nueva definición de campo personalizado
+func (h *CustomFieldHandler) CreateCustomField(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	// Los campos nuevos están activos salvo que se indique lo contrario
+	def := models.CustomFieldDefinition{Active: true}
+	if err := utils.DecodeJSON(r, &def); err != nil {
+		http.Error(w, "Error al leer datos del campo personalizado", http.StatusBadRequest)
+		return
+	}
+
+	if err := customfields.ValidateDefinition(def); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if def.CategoryID != "" {
+		if _, err := h.Store.GetCategory(def.CategoryID); err != nil {
+			http.Error(w, "Categoría no encontrada", http.StatusBadRequest)
+			return
+		}
+	}
+
+	existing, err := h.Store.GetCustomFieldDefinitions()
+	if err != nil {
+		http.Error(w, "Error al obtener campos personalizados", http.StatusInternalServerError)
+		return
+	}
+	if _, found := customfields.Find(existing, def.Key); found {
+		http.Error(w, "Ya existe un campo personalizado con esa clave", http.StatusConflict)
+		return
+	}
+
+	// Establecer ID y marcas de tiempo
+	def.ID = uuid.New().String()
+	now := time.Now()
+	def.CreatedAt = now
+	def.UpdatedAt = now
+
+	if err := h.Store.CreateCustomFieldDefinition(def); err != nil {
+		http.Error(w, "Error al crear campo personalizado", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, def)
+}
+
+// UpdateCustomField reemplaza una definición existente. La clave y el tipo no pueden
+// cambiar porque los tickets ya guardan valores con ellos.
+func (h *CustomFieldHandler) UpdateCustomField(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes PUT
+	if r.Method != http.MethodPut {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	segments := strings.Split(r.URL.Path, "/")
+	if len(segments) < 4 {
+		http.Error(w, "URL de campo personalizado inválida", http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.Store.GetCustomFieldDefinition(segments[3])
+	if err != nil {
+		http.Error(w, "Campo personalizado no encontrado", http.StatusNotFound)
+		return
+	}
+
+	def := *existing
+	if err := utils.DecodeJSON(r, &def); err != nil {
+		http.Error(w, "Error al leer datos del campo personalizado", http.StatusBadRequest)
+		return
+	}
+
+	if def.Key != existing.Key || def.Type != existing.Type {
+		http.Error(w, "No se puede cambiar la clave ni el tipo de un campo existente", http.StatusBadRequest)
+		return
+	}
+	if err := customfields.ValidateDefinition(def); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if def.CategoryID != "" && def.CategoryID != existing.CategoryID {
+		if _, err := h.Store.GetCategory(def.CategoryID); err != nil {
+			http.Error(w, "Categoría no encontrada", http.StatusBadRequest)
+			return
+		}
+	}
+
+	def.ID = existing.ID
+	def.CreatedAt = existing.CreatedAt
+	def.UpdatedAt = time.Now()
+
+	if err := h.Store.UpdateCustomFieldDefinition(def); err != nil {
+		http.Error(w, "Error al actualizar campo personalizado", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, def)
+}
+
+// DeleteCustomField elimina una definición de campo personalizado
+func (h *CustomFieldHandler) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes DELETE
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	segments := strings.Split(r.URL.Path, "/")
+	if len(segments) < 4 {
+		http.Error(w, "URL de campo personalizado inválida", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.DeleteCustomFieldDefinition(segments[3]); err != nil {
+		http.Error(w, "Campo personalizado no encontrado", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
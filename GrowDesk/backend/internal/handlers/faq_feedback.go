@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/kb"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// publishedFAQ obtiene una FAQ publicada por ID
+func (h *FAQHandler) publishedFAQ(id int) (*models.Article, bool) {
+	article, err := h.Store.GetArticle(id)
+	if err != nil || article.PublishedRevision == 0 {
+		return nil, false
+	}
+	return article, true
+}
+
+// RecordFAQView registra que un visitante del widget abrió una FAQ. Si no se envía la
+// sesión se genera una nueva, que el widget debe reenviar en las siguientes visitas,
+// en los votos y al crear el ticket.
+func (h *FAQHandler) RecordFAQView(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	var req models.FAQViewRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer datos", http.StatusBadRequest)
+		return
+	}
+	req.SessionID = strings.TrimSpace(req.SessionID)
+	if req.SessionID == "" || len(req.SessionID) > maxSessionIDLength {
+		req.SessionID = uuid.New().String()
+	}
+	if req.FAQID <= 0 {
+		http.Error(w, "La FAQ es requerida", http.StatusBadRequest)
+		return
+	}
+
+	article, ok := h.publishedFAQ(req.FAQID)
+	if !ok {
+		http.Error(w, "FAQ no encontrada", http.StatusNotFound)
+		return
+	}
+
+	// Idioma en que se mostró la FAQ
+	chain := requestLocales(r)
+	translations := make(map[string]models.ArticleTranslation)
+	for _, locale := range chain {
+		if translation, err := h.Store.GetArticleTranslation(article.ID, locale); err == nil {
+			translations[locale] = *translation
+		}
+	}
+	kb.Localize(article, translations, chain)
+
+	view, err := h.Store.RecordFAQView(models.FAQView{
+		SessionID:     req.SessionID,
+		ArticleID:     article.ID,
+		WidgetID:      r.Header.Get("X-Widget-ID"),
+		Locale:        article.Locale,
+		FirstViewedAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Error al registrar visita de FAQ: %v", err)
+		http.Error(w, "Error al registrar la visita", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, view)
+}
+
+// VoteFAQ registra la respuesta del visitante a "¿Te resultó útil?", con un comentario
+// opcional. Cada sesión tiene un voto por FAQ y puede cambiarlo, pero el número de
+// votos por sesión está limitado.
+func (h *FAQHandler) VoteFAQ(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	var req models.FAQVoteRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer datos", http.StatusBadRequest)
+		return
+	}
+	if err := kb.ValidateVote(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.SessionID) > maxSessionIDLength {
+		http.Error(w, "Sesión inválida", http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := h.publishedFAQ(req.FAQID); !ok {
+		http.Error(w, "FAQ no encontrada", http.StatusNotFound)
+		return
+	}
+
+	now := time.Now()
+	if !h.VoteLimiter.Allow(req.SessionID, now) {
+		http.Error(w, "Demasiados votos; inténtelo más tarde", http.StatusTooManyRequests)
+		return
+	}
+
+	vote, err := h.Store.SaveFAQVote(models.FAQVote{
+		SessionID: req.SessionID,
+		ArticleID: req.FAQID,
+		WidgetID:  r.Header.Get("X-Widget-ID"),
+		Helpful:   *req.Helpful,
+		Comment:   req.Comment,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		log.Printf("Error al guardar voto de FAQ: %v", err)
+		http.Error(w, "Error al guardar el voto", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, vote)
+}
+
+// GetFAQAnalytics devuelve, por FAQ, sus visitas, sus votos de utilidad y los tickets
+// creados después de verla, y marca las FAQs publicadas sin actualizar en N meses.
+// Parámetros: ?from=YYYY-MM-DD&to=YYYY-MM-DD&staleMonths=N&sort=views|helpfulness|tickets
+func (h *FAQHandler) GetFAQAnalytics(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	from, to := query.Get("from"), query.Get("to")
+
+	// Las fechas son días completos; "to" se incluye
+	var filter models.FAQActivityFilter
+	if from != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			http.Error(w, "Fecha inicial inválida (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		filter.From = parsed
+	}
+	if to != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			http.Error(w, "Fecha final inválida (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		filter.To = parsed.AddDate(0, 0, 1)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		http.Error(w, "La fecha inicial debe ser anterior a la final", http.StatusBadRequest)
+		return
+	}
+
+	staleMonths := h.StaleMonths
+	if staleMonths <= 0 {
+		staleMonths = kb.DefaultStaleMonths
+	}
+	if value := query.Get("staleMonths"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			http.Error(w, "Número de meses inválido", http.StatusBadRequest)
+			return
+		}
+		staleMonths = parsed
+	}
+
+	sortBy := query.Get("sort")
+	if sortBy == "" {
+		sortBy = kb.SortByViews
+	}
+	if !kb.ValidSort(sortBy) {
+		http.Error(w, "Orden inválido (use views, helpfulness o tickets)", http.StatusBadRequest)
+		return
+	}
+
+	articles, err := h.Store.GetArticles(models.ArticleFilter{})
+	if err != nil {
+		http.Error(w, "Error al obtener FAQs", http.StatusInternalServerError)
+		return
+	}
+
+	views, err := h.Store.GetFAQViews(filter)
+	if err != nil {
+		http.Error(w, "Error al obtener visitas de FAQs", http.StatusInternalServerError)
+		return
+	}
+
+	votes, err := h.Store.GetFAQVotes(filter)
+	if err != nil {
+		http.Error(w, "Error al obtener votos de FAQs", http.StatusInternalServerError)
+		return
+	}
+
+	report := kb.Analytics(articles, views, votes, kb.StaleBefore(staleMonths, time.Now()), sortBy)
+	report.From = from
+	report.To = to
+	report.StaleMonths = staleMonths
+
+	utils.WriteJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/kb"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// maxSessionIDLength limita el ID de sesión que envía el widget
+const maxSessionIDLength = 100
+
+// SuggestFAQs sugiere FAQs publicadas a partir del borrador del visitante, antes de
+// que cree un ticket. Cada FAQ sugerida se registra una vez por sesión para el
+// informe de deflexión.
+// Parámetros: ?q=borrador&lang=&limit=&sessionId=
+func (h *FAQHandler) SuggestFAQs(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	query := r.URL.Query()
+	response := models.FAQSuggestResponse{
+		SessionID:   strings.TrimSpace(query.Get("sessionId")),
+		Suggestions: make([]models.FAQSuggestion, 0),
+	}
+	if response.SessionID == "" || len(response.SessionID) > maxSessionIDLength {
+		response.SessionID = uuid.New().String()
+	}
+
+	limit := kb.DefaultSuggestions
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			http.Error(w, "Límite inválido", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+		if limit > kb.MaxSuggestions {
+			limit = kb.MaxSuggestions
+		}
+	}
+
+	// Los borradores muy cortos no dan sugerencias útiles
+	draft := []rune(strings.TrimSpace(query.Get("q")))
+	if len(draft) < kb.MinQueryLength {
+		utils.WriteJSON(w, http.StatusOK, response)
+		return
+	}
+	if len(draft) > kb.MaxQueryLength {
+		draft = draft[:kb.MaxQueryLength]
+	}
+
+	articles, err := h.Store.GetPublishedArticles()
+	if err != nil {
+		http.Error(w, "Error al obtener FAQs", http.StatusInternalServerError)
+		return
+	}
+
+	// Buscar en el idioma en que se mostrarán las FAQs
+	w.Header().Set("Vary", "Accept-Language")
+	if err := localizeArticles(h.Store, articles, requestLocales(r)); err != nil {
+		http.Error(w, "Error al obtener FAQs", http.StatusInternalServerError)
+		return
+	}
+
+	ranked := kb.Rank(articles, string(draft), limit)
+	if len(ranked) == 0 {
+		utils.WriteJSON(w, http.StatusOK, response)
+		return
+	}
+
+	now := time.Now()
+	widgetID := r.Header.Get("X-Widget-ID")
+	deflections := make([]models.FAQDeflection, 0, len(ranked))
+	for _, result := range ranked {
+		response.Suggestions = append(response.Suggestions, models.FAQSuggestion{
+			FAQ:   h.toFAQ(result.Article),
+			Slug:  result.Article.Slug,
+			Score: result.Score,
+		})
+		deflections = append(deflections, models.FAQDeflection{
+			SessionID: response.SessionID,
+			ArticleID: result.Article.ID,
+			WidgetID:  widgetID,
+			Query:     string(draft),
+			Locale:    result.Article.Locale,
+			ShownAt:   now,
+		})
+	}
+
+	// Un fallo al registrar no debe impedir mostrar las sugerencias
+	if err := h.Store.RecordFAQSuggestions(deflections); err != nil {
+		log.Printf("Error al registrar FAQs sugeridas: %v", err)
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// MarkFAQSolved registra que una FAQ sugerida resolvió el problema del visitante
+// ("esto resolvió mi problema")
+func (h *FAQHandler) MarkFAQSolved(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	var req models.FAQSolvedRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer datos", http.StatusBadRequest)
+		return
+	}
+	req.SessionID = strings.TrimSpace(req.SessionID)
+	if req.SessionID == "" || req.FAQID <= 0 {
+		http.Error(w, "La sesión y la FAQ son requeridas", http.StatusBadRequest)
+		return
+	}
+
+	deflection, err := h.Store.MarkFAQSolved(req.SessionID, req.FAQID, time.Now())
+	if err != nil {
+		http.Error(w, "La FAQ no se sugirió en esta sesión", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, deflection)
+}
+
+// GetDeflectionReport devuelve, por FAQ, en cuántas sesiones del widget se sugirió y
+// en cuántas resolvió el problema del visitante sin crear un ticket.
+// Parámetros: ?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *FAQHandler) GetDeflectionReport(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	from, to := query.Get("from"), query.Get("to")
+
+	// Las fechas son días completos; "to" se incluye
+	var filter models.DeflectionFilter
+	if from != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			http.Error(w, "Fecha inicial inválida (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		filter.From = parsed
+	}
+	if to != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			http.Error(w, "Fecha final inválida (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		filter.To = parsed.AddDate(0, 0, 1)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		http.Error(w, "La fecha inicial debe ser anterior a la final", http.StatusBadRequest)
+		return
+	}
+
+	deflections, err := h.Store.GetFAQDeflections(filter)
+	if err != nil {
+		http.Error(w, "Error al obtener sugerencias de FAQs", http.StatusInternalServerError)
+		return
+	}
+
+	articles, err := h.Store.GetArticles(models.ArticleFilter{})
+	if err != nil {
+		http.Error(w, "Error al obtener FAQs", http.StatusInternalServerError)
+		return
+	}
+	titles := make(map[int]string, len(articles))
+	for _, article := range articles {
+		titles[article.ID] = article.Title
+	}
+
+	report := kb.DeflectionReport(deflections, titles)
+	report.From = from
+	report.To = to
+
+	utils.WriteJSON(w, http.StatusOK, report)
+}
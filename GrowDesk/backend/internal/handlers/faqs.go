@@ -8,14 +8,53 @@ import (
 	"time"
 
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/kb"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
 )
 
-// FAQHandler contiene manejadores para FAQs
+// FAQHandler contiene manejadores para FAQs. Las FAQs son una vista de compatibilidad
+// sobre los artículos de la base de conocimiento (pregunta = título, respuesta = cuerpo).
 type FAQHandler struct {
-	Store data.DataStore
+	Store       data.DataStore
+	VoteLimiter *kb.VoteLimiter // Votos de utilidad por sesión del widget
+	StaleMonths int             // Meses sin cambios tras los que una FAQ se considera desactualizada
+}
+
+// categoryNames devuelve el nombre de cada categoría indexado por ID
+func (h *FAQHandler) categoryNames() map[string]string {
+	names := make(map[string]string)
+	categories, err := h.Store.GetCategories()
+	if err != nil {
+		return names
+	}
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+	return names
+}
+
+// toFAQs convierte artículos en FAQs
+func (h *FAQHandler) toFAQs(articles []models.Article) []models.FAQ {
+	names := h.categoryNames()
+	faqs := make([]models.FAQ, 0, len(articles))
+	for _, article := range articles {
+		faqs = append(faqs, kb.ToFAQ(article, names[article.CategoryID]))
+	}
+	return faqs
+}
+
+// toFAQ convierte un artículo en FAQ
+func (h *FAQHandler) toFAQ(article models.Article) models.FAQ {
+	return h.toFAQs([]models.Article{article})[0]
+}
+
+// matchCategory enlaza la categoría de texto de una FAQ con una categoría existente.
+// Devuelve el ID de la categoría o, si no existe, el texto original.
+func (h *FAQHandler) matchCategory(category string) (string, string) {
+	categories, _ := h.Store.GetCategories()
+	return kb.MatchCategory(category, categories)
 }
 
 // GetAllFAQs devuelve todas las FAQs
@@ -32,28 +71,30 @@ func (h *FAQHandler) GetAllFAQs(w http.ResponseWriter, r *http.Request) {
 	// Determinar si se debe filtrar por estado de publicación
 	published := r.URL.Query().Get("published")
 
-	var faqs []models.FAQ
-	var err error
+	articles, err := h.Store.GetArticles(models.ArticleFilter{})
+	if err != nil {
+		http.Error(w, "Error al obtener FAQs", http.StatusInternalServerError)
+		return
+	}
 
+	faqs := h.toFAQs(articles)
 	if published != "" {
 		// Convertir string a bool
 		isPublished := published == "true"
-		faqs, err = h.Store.GetFAQsByStatus(isPublished)
-	} else {
-		// Obtener todas las FAQs
-		faqs, err = h.Store.GetFAQs()
-	}
-
-	if err != nil {
-		http.Error(w, "Error al obtener FAQs", http.StatusInternalServerError)
-		return
+		filtered := make([]models.FAQ, 0, len(faqs))
+		for _, faq := range faqs {
+			if faq.IsPublished == isPublished {
+				filtered = append(filtered, faq)
+			}
+		}
+		faqs = filtered
 	}
 
 	// Devolver FAQs como JSON
 	utils.WriteJSON(w, http.StatusOK, faqs)
 }
 
-// GetPublishedFAQs devuelve solo las FAQs publicadas
+// GetPublishedFAQs devuelve solo las FAQs publicadas, en el idioma preferido del cliente
 func (h *FAQHandler) GetPublishedFAQs(w http.ResponseWriter, r *http.Request) {
 	// Esta función solo maneja solicitudes GET
 	if r.Method != http.MethodGet {
@@ -64,13 +105,22 @@ func (h *FAQHandler) GetPublishedFAQs(w http.ResponseWriter, r *http.Request) {
 	// Establecer CORS
 	utils.SetCORS(w)
 
-	// Obtener FAQs publicadas
-	faqs, err := h.Store.GetFAQsByStatus(true)
+	// Obtener FAQs publicadas (contenido de la revisión publicada de cada artículo)
+	articles, err := h.Store.GetPublishedArticles()
 	if err != nil {
 		http.Error(w, "Error al obtener FAQs", http.StatusInternalServerError)
 		return
 	}
 
+	// Traducir al idioma preferido (?lang= o Accept-Language); las FAQs sin traducción
+	// publicada se devuelven en su idioma original
+	w.Header().Set("Vary", "Accept-Language")
+	if err := localizeArticles(h.Store, articles, requestLocales(r)); err != nil {
+		http.Error(w, "Error al obtener FAQs", http.StatusInternalServerError)
+		return
+	}
+	faqs := h.toFAQs(articles)
+
 	// Devolver FAQs como JSON
 	utils.WriteJSON(w, http.StatusOK, faqs)
 }
@@ -100,7 +150,7 @@ func (h *FAQHandler) GetFAQ(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Obtener FAQ por ID
-	faq, err := h.Store.GetFAQ(id)
+	article, err := h.Store.GetArticle(id)
 	if err != nil {
 		http.Error(w, "FAQ no encontrada", http.StatusNotFound)
 		return
@@ -108,7 +158,7 @@ func (h *FAQHandler) GetFAQ(w http.ResponseWriter, r *http.Request) {
 
 	// Devolver la FAQ
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(faq)
+	json.NewEncoder(w).Encode(h.toFAQ(*article))
 }
 
 // CreateFAQ crea una nueva FAQ
@@ -142,19 +192,32 @@ func (h *FAQHandler) CreateFAQ(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Establecer marcas de tiempo
+	// Crear el artículo equivalente
+	categoryID, legacyCategory := h.matchCategory(faq.Category)
+	req := models.ArticleRequest{Title: faq.Question, Body: faq.Answer, CategoryID: categoryID}
+	if err := kb.Validate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Slug = uniqueArticleSlug(h.Store, req.Title)
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
 	now := time.Now()
-	faq.CreatedAt = now
-	faq.UpdatedAt = now
+	article, revision := kb.New(req, userID, now)
+	article.LegacyCategory = legacyCategory
+	if faq.IsPublished {
+		kb.Publish(&article, userID, now)
+	}
 
 	// Guardar en el almacén
-	if err := h.Store.CreateFAQ(faq); err != nil {
+	created, err := h.Store.CreateArticle(article, revision)
+	if err != nil {
 		http.Error(w, "Error al crear FAQ", http.StatusInternalServerError)
 		return
 	}
 
 	// Devolver FAQ creada
-	utils.WriteJSON(w, http.StatusCreated, faq)
+	utils.WriteJSON(w, http.StatusCreated, h.toFAQ(*created))
 }
 
 // UpdateFAQ actualiza una FAQ existente
@@ -208,30 +271,46 @@ func (h *FAQHandler) UpdateFAQ(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Obtener la FAQ existente
-	faq, err := h.Store.GetFAQ(id)
+	article, err := h.Store.GetArticle(id)
 	if err != nil {
 		http.Error(w, "FAQ no encontrada", http.StatusNotFound)
 		return
 	}
 
-	// Actualizar campos
-	faq.Question = updateReq.Question
-	faq.Answer = updateReq.Answer
-	faq.Category = updateReq.Category
+	categoryID, legacyCategory := h.matchCategory(updateReq.Category)
+	req := models.ArticleRequest{Title: updateReq.Question, Body: updateReq.Answer, CategoryID: categoryID}
+	if err := kb.Validate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Registrar una nueva revisión. La API de FAQs no tiene flujo editorial, así que
+	// la edición se publica si la FAQ ya lo estaba (o si se pide explícitamente).
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	now := time.Now()
+	wasPublished := article.PublishedRevision > 0
+	revision := kb.Revise(article, req, userID, now)
+	article.LegacyCategory = legacyCategory
+
+	publish := wasPublished
 	if updateReq.IsPublished != nil {
-		faq.IsPublished = *updateReq.IsPublished
+		publish = *updateReq.IsPublished
+	}
+	if publish {
+		kb.Publish(article, userID, now)
+	} else if wasPublished {
+		kb.Unpublish(article, now)
 	}
-	faq.UpdatedAt = time.Now()
 
 	// Guardar cambios
-	if err := h.Store.UpdateFAQ(*faq); err != nil {
+	if err := h.Store.UpdateArticle(*article, &revision); err != nil {
 		http.Error(w, "Error al actualizar FAQ", http.StatusInternalServerError)
 		return
 	}
 
 	// Devolver la FAQ actualizada
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(faq)
+	json.NewEncoder(w).Encode(h.toFAQ(*article))
 }
 
 // DeleteFAQ elimina una FAQ existente
@@ -266,7 +345,7 @@ func (h *FAQHandler) DeleteFAQ(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Eliminar la FAQ
-	if err := h.Store.DeleteFAQ(id); err != nil {
+	if err := h.Store.DeleteArticle(id); err != nil {
 		http.Error(w, "Error al eliminar FAQ", http.StatusInternalServerError)
 		return
 	}
@@ -306,20 +385,26 @@ func (h *FAQHandler) TogglePublishFAQ(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Llamar al método para alternar estado de publicación
-	if err := h.Store.ToggleFAQPublish(id); err != nil {
-		http.Error(w, "Error al cambiar estado de publicación", http.StatusInternalServerError)
+	article, err := h.Store.GetArticle(id)
+	if err != nil {
+		http.Error(w, "FAQ no encontrada", http.StatusNotFound)
 		return
 	}
 
-	// Obtener la FAQ actualizada
-	faq, err := h.Store.GetFAQ(id)
-	if err != nil {
-		http.Error(w, "Error al obtener FAQ actualizada", http.StatusInternalServerError)
+	// Alternar estado de publicación (se publica la revisión actual)
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	if article.PublishedRevision > 0 {
+		kb.Unpublish(article, time.Now())
+	} else {
+		kb.Publish(article, userID, time.Now())
+	}
+
+	if err := h.Store.UpdateArticle(*article, nil); err != nil {
+		http.Error(w, "Error al cambiar estado de publicación", http.StatusInternalServerError)
 		return
 	}
 
 	// Devolver la FAQ actualizada
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(faq)
+	json.NewEncoder(w).Encode(h.toFAQ(*article))
 }
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/invitations"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/twofactor"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// InvitationHandler contiene manejadores para invitar usuarios y para que los invitados
+// creen su cuenta
+type InvitationHandler struct {
+	Store       data.DataStore
+	Invitations *invitations.Service
+	// TwoFactor decide si la cuenta nueva debe completar el segundo factor antes de
+	// recibir una sesión
+	TwoFactor *twofactor.Service
+}
+
+// writeInvitationError responde 409 a los conflictos con usuarios o invitaciones
+// existentes y 400 al resto de errores
+func writeInvitationError(w http.ResponseWriter, err error) {
+	var conflict *invitations.ConflictError
+	if errors.As(err, &conflict) {
+		http.Error(w, conflict.Message, http.StatusConflict)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// invitationIDFromPath obtiene el ID de /api/invitations/:id[/resend]
+func invitationIDFromPath(r *http.Request) string {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) < 3 {
+		return ""
+	}
+	return segments[2]
+}
+
+// GetInvitations devuelve las invitaciones, opcionalmente filtradas por ?status=
+func (h *InvitationHandler) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.InvitationStatusPending, models.InvitationStatusAccepted,
+		models.InvitationStatusRevoked, models.InvitationStatusExpired:
+	default:
+		http.Error(w, "Estado inválido: "+status, http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.Invitations.List(status)
+	if err != nil {
+		http.Error(w, "Error al obtener invitaciones", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, list)
+}
+
+// CreateInvitation invita a una persona por email y rol y le envía el enlace para crear
+// su cuenta. Responde 409 si el email ya tiene usuario o una invitación pendiente.
+func (h *InvitationHandler) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.InvitationRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer datos de la invitación", http.StatusBadRequest)
+		return
+	}
+
+	// El departamento debe ser un equipo activo
+	department, err := resolveTeam(h.Store, req.Department)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Department = department
+
+	invitedBy, _ := r.Context().Value(middleware.UserIDKey).(string)
+	result, err := h.Invitations.Invite(req, invitedBy)
+	if err != nil {
+		writeInvitationError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, result)
+}
+
+// ResendInvitation genera un enlace nuevo para la invitación y lo vuelve a enviar
+func (h *InvitationHandler) ResendInvitation(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	invitationID := invitationIDFromPath(r)
+	if _, err := h.Store.GetInvitation(invitationID); err != nil {
+		http.Error(w, "Invitación no encontrada", http.StatusNotFound)
+		return
+	}
+
+	result, err := h.Invitations.Resend(invitationID)
+	if err != nil {
+		writeInvitationError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, result)
+}
+
+// RevokeInvitation anula una invitación; su enlace deja de funcionar
+func (h *InvitationHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes DELETE
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	invitationID := invitationIDFromPath(r)
+	if _, err := h.Store.GetInvitation(invitationID); err != nil {
+		http.Error(w, "Invitación no encontrada", http.StatusNotFound)
+		return
+	}
+
+	invitation, err := h.Invitations.Revoke(invitationID)
+	if err != nil {
+		writeInvitationError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, invitation)
+}
+
+// GetInvitationByToken devuelve la invitación de un enlace para mostrar el formulario de
+// alta (público)
+func (h *InvitationHandler) GetInvitationByToken(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	invitation, err := h.Invitations.Lookup(r.URL.Query().Get("token"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"email":      invitation.Email,
+		"firstName":  invitation.FirstName,
+		"lastName":   invitation.LastName,
+		"role":       invitation.Role,
+		"department": invitation.Department,
+		"expiresAt":  invitation.ExpiresAt,
+	})
+}
+
+// AcceptInvitation crea la cuenta del invitado con la contraseña que elige e inicia su
+// sesión como Login (público): si el rol exige el segundo factor, responde con un
+// desafío para configurarlo. El enlace no se puede volver a usar.
+func (h *InvitationHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	var req models.AcceptInvitationRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer datos de la cuenta", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Invitations.Accept(req)
+	if err != nil {
+		writeInvitationError(w, err)
+		return
+	}
+
+	startSession(w, h.Store, h.TwoFactor, http.StatusCreated, *user)
+}
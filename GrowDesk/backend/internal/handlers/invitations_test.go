@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/invitations"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/twofactor"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// acceptTestInvitation crea una invitación pendiente para el rol y la acepta con un
+// manejador que exige el segundo factor a los administradores
+func acceptTestInvitation(t *testing.T, role string) (*httptest.ResponseRecorder, data.DataStore) {
+	t.Helper()
+
+	// Los archivos vacíos de usuarios y FAQs evitan que se creen los datos por defecto
+	dir := t.TempDir()
+	for _, name := range []string{"users.json", "faqs.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("[]"), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	store := data.NewStore(dir)
+
+	now := time.Now()
+	err := store.CreateInvitation(models.Invitation{
+		ID:        "inv-" + role,
+		Email:     role + "@example.com",
+		FirstName: "Nueva",
+		LastName:  "Cuenta",
+		Role:      role,
+		TokenHash: invitations.HashToken("token-" + role),
+		SendCount: 1,
+		SentAt:    now,
+		ExpiresAt: now.Add(time.Hour),
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		t.Fatalf("CreateInvitation: %v", err)
+	}
+
+	h := &InvitationHandler{
+		Store:       store,
+		Invitations: invitations.NewService(store, nil, "", 0),
+		TwoFactor:   twofactor.NewService(store, "GrowDesk", "admin"),
+	}
+	body := `{"token":"token-` + role + `","password":"Contrasena-Segura-2024!"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/invitations/accept", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.AcceptInvitation(rec, req)
+	return rec, store
+}
+
+func TestAcceptInvitationRequiresTwoFactorSetupForEnforcedRoles(t *testing.T) {
+	rec, _ := acceptTestInvitation(t, "admin")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("código %d, se esperaba %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	var challenge models.LoginChallenge
+	if err := json.Unmarshal(rec.Body.Bytes(), &challenge); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !challenge.TwoFactorRequired || !challenge.SetupRequired {
+		t.Errorf("se esperaba un desafío de configuración del segundo factor: %+v", challenge)
+	}
+	if _, err := utils.ValidateToken(challenge.ChallengeToken); err == nil {
+		t.Error("el token de desafío no debe servir como sesión")
+	}
+	if _, err := utils.ValidateChallengeToken(challenge.ChallengeToken); err != nil {
+		t.Errorf("ValidateChallengeToken: %v", err)
+	}
+}
+
+func TestAcceptInvitationStartsSessionWithCurrentVersion(t *testing.T) {
+	rec, store := acceptTestInvitation(t, "employee")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("código %d, se esperaba %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	var resp models.AuthResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	claims, err := utils.ValidateToken(resp.Token)
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	version, err := store.GetUserSessionVersion(resp.User.ID)
+	if err != nil {
+		t.Fatalf("GetUserSessionVersion: %v", err)
+	}
+	if claims.SessionVersion != version {
+		t.Errorf("versión de sesión %d, se esperaba %d", claims.SessionVersion, version)
+	}
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/macros"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// MacroHandler contiene manejadores para las macros de agentes
+type MacroHandler struct {
+	Store data.DataStore
+}
+
+// macroVisible indica si un usuario puede ver y aplicar una macro
+func macroVisible(macro models.Macro, userID string) bool {
+	return macro.Shared || macro.OwnerID == userID
+}
+
+// macroEditable indica si un usuario puede modificar una macro. Las macros compartidas
+// solo las gestionan los administradores; las personales, su propietario.
+func macroEditable(macro models.Macro, userID, role string) bool {
+	if role == "admin" {
+		return true
+	}
+	return !macro.Shared && macro.OwnerID == userID
+}
+
+// macroChanges convierte las acciones de una macro en cambios de ticket
+func macroChanges(actions models.TicketActions) models.BulkTicketChanges {
+	return models.BulkTicketChanges{
+		Status:     actions.Status,
+		Priority:   actions.Priority,
+		AssignedTo: actions.AssignedTo,
+		AddTags:    actions.AddTags,
+		RemoveTags: actions.RemoveTags,
+	}
+}
+
+// hasMacroActions indica si la macro cambia algo en el ticket
+func hasMacroActions(actions models.TicketActions) bool {
+	return actions.Status != "" || actions.Priority != "" || actions.AssignedTo != nil ||
+		len(actions.AddTags) > 0 || len(actions.RemoveTags) > 0
+}
+
+// validateMacro comprueba y normaliza una macro antes de guardarla
+func (h *MacroHandler) validateMacro(macro *models.Macro) error {
+	macro.Name = strings.TrimSpace(macro.Name)
+	if macro.Name == "" {
+		return fmt.Errorf("El nombre de la macro es requerido")
+	}
+
+	if strings.TrimSpace(macro.Content) == "" && !hasMacroActions(macro.Actions) {
+		return fmt.Errorf("La macro debe tener un texto o al menos una acción")
+	}
+	if err := macros.Validate(macro.Content); err != nil {
+		return err
+	}
+
+	if hasMacroActions(macro.Actions) {
+		changes, err := validateBulkChanges(h.Store, macroChanges(macro.Actions))
+		if err != nil {
+			return err
+		}
+		macro.Actions.AddTags = changes.AddTags
+		macro.Actions.RemoveTags = changes.RemoveTags
+	}
+
+	return nil
+}
+
+// GetAllMacros devuelve las macros compartidas y las personales del usuario.
+// Con ?q= filtra por nombre.
+func (h *MacroHandler) GetAllMacros(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	all, err := h.Store.GetMacros()
+	if err != nil {
+		http.Error(w, "Error al obtener macros", http.StatusInternalServerError)
+		return
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	query := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("q")))
+
+	result := make([]models.Macro, 0, len(all))
+	for _, macro := range all {
+		if !macroVisible(macro, userID) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(macro.Name), query) {
+			continue
+		}
+		result = append(result, macro)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name)
+	})
+
+	utils.WriteJSON(w, http.StatusOK, result)
+}
+
+// GetMacro obtiene una macro por ID
+func (h *MacroHandler) GetMacro(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Obtener ID de la URL (/api/macros/:id)
+	segments := strings.Split(r.URL.Path, "/")
+	if len(segments) < 4 {
+		http.Error(w, "URL de macro inválida", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	macro, err := h.Store.GetMacro(segments[3])
+	if err != nil || !macroVisible(*macro, userID) {
+		http.Error(w, "Macro no encontrada", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, macro)
+}
+
+// CreateMacro crea una macro personal o, para administradores, compartida
+func (h *MacroHandler) CreateMacro(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	var macro models.Macro
+	if err := utils.DecodeJSON(r, &macro); err != nil {
+		http.Error(w, "Error al leer datos de la macro", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	macro.OwnerID = userID
+	if !macroEditable(macro, userID, role) {
+		http.Error(w, "Solo los administradores pueden crear macros compartidas", http.StatusForbidden)
+		return
+	}
+
+	if err := h.validateMacro(&macro); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Establecer ID y marcas de tiempo
+	macro.ID = uuid.New().String()
+	now := time.Now()
+	macro.CreatedAt = now
+	macro.UpdatedAt = now
+
+	if err := h.Store.CreateMacro(macro); err != nil {
+		http.Error(w, "Error al crear macro", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, macro)
+}
+
+// UpdateMacro reemplaza una macro existente
+func (h *MacroHandler) UpdateMacro(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes PUT
+	if r.Method != http.MethodPut {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	segments := strings.Split(r.URL.Path, "/")
+	if len(segments) < 4 {
+		http.Error(w, "URL de macro inválida", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+
+	existing, err := h.Store.GetMacro(segments[3])
+	if err != nil || (!macroVisible(*existing, userID) && role != "admin") {
+		http.Error(w, "Macro no encontrada", http.StatusNotFound)
+		return
+	}
+	if !macroEditable(*existing, userID, role) {
+		http.Error(w, "No autorizado para modificar esta macro", http.StatusForbidden)
+		return
+	}
+
+	macro := *existing
+	if err := utils.DecodeJSON(r, &macro); err != nil {
+		http.Error(w, "Error al leer datos de la macro", http.StatusBadRequest)
+		return
+	}
+
+	// Compartir una macro personal también requiere rol de administrador
+	macro.OwnerID = existing.OwnerID
+	if !macroEditable(macro, userID, role) {
+		http.Error(w, "Solo los administradores pueden compartir macros", http.StatusForbidden)
+		return
+	}
+
+	if err := h.validateMacro(&macro); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	macro.ID = existing.ID
+	macro.CreatedAt = existing.CreatedAt
+	macro.UpdatedAt = time.Now()
+
+	if err := h.Store.UpdateMacro(macro); err != nil {
+		http.Error(w, "Error al actualizar macro", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, macro)
+}
+
+// DeleteMacro elimina una macro
+func (h *MacroHandler) DeleteMacro(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes DELETE
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	segments := strings.Split(r.URL.Path, "/")
+	if len(segments) < 4 {
+		http.Error(w, "URL de macro inválida", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+
+	macro, err := h.Store.GetMacro(segments[3])
+	if err != nil || (!macroVisible(*macro, userID) && role != "admin") {
+		http.Error(w, "Macro no encontrada", http.StatusNotFound)
+		return
+	}
+	if !macroEditable(*macro, userID, role) {
+		http.Error(w, "No autorizado para eliminar esta macro", http.StatusForbidden)
+		return
+	}
+
+	if err := h.Store.DeleteMacro(macro.ID); err != nil {
+		http.Error(w, "Error al eliminar macro", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
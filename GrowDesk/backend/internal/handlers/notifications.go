@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// NotificationHandler contiene manejadores para las notificaciones del usuario actual
+type NotificationHandler struct {
+	Store data.DataStore
+}
+
+// GetNotifications devuelve las notificaciones del usuario, las más recientes primero.
+// Con ?unread=true devuelve solo las no leídas.
+func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	notifications, err := h.Store.GetNotifications(userID, r.URL.Query().Get("unread") == "true")
+	if err != nil {
+		http.Error(w, "Error al obtener notificaciones", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, notifications)
+}
+
+// MarkNotificationsRead marca como leídas las notificaciones indicadas, o todas si no se indica ninguna
+func (h *NotificationHandler) MarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+	if r.ContentLength != 0 {
+		if err := utils.DecodeJSON(r, &req); err != nil {
+			http.Error(w, "Error al leer la solicitud", http.StatusBadRequest)
+			return
+		}
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	if err := h.Store.MarkNotificationsRead(userID, req.IDs); err != nil {
+		http.Error(w, "Error al marcar notificaciones", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
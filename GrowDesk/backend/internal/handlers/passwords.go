@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/passwords"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// ForgotPassword envía un enlace para restablecer la contraseña (público). Siempre
+// responde 202 para no revelar qué emails tienen cuenta.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	var req models.ForgotPasswordRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "El cuerpo de la solicitud es inválido", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Passwords.Forgot(req.Email); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusAccepted, map[string]string{
+		"message": "Si el email tiene una cuenta, recibirá un enlace para restablecer la contraseña",
+	})
+}
+
+// ResetPassword establece una contraseña nueva con el token del enlace y cierra todas
+// las sesiones del usuario (público)
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	var req models.ResetPasswordRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "El cuerpo de la solicitud es inválido", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Passwords.Reset(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// ChangePassword cambia la contraseña del usuario autenticado. Exige la contraseña
+// actual, cierra las demás sesiones y devuelve un token nuevo para la sesión actual.
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "El cuerpo de la solicitud es inválido", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.Store.GetUser(userID); err != nil {
+		http.Error(w, "Usuario no encontrado", http.StatusNotFound)
+		return
+	}
+
+	if err := h.Passwords.Change(userID, req); err != nil {
+		if errors.Is(err, passwords.ErrWrongPassword) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Emitir un token con la versión de sesión nueva
+	user, err := h.Store.GetUser(userID)
+	if err != nil {
+		http.Error(w, "Usuario no encontrado", http.StatusNotFound)
+		return
+	}
+	version, err := h.Store.GetUserSessionVersion(userID)
+	if err != nil {
+		http.Error(w, "Error al generar token", http.StatusInternalServerError)
+		return
+	}
+	writeSession(w, http.StatusOK, *user, version)
+}
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// customFieldPrefix es el prefijo de MapTo para guardar el valor como campo personalizado
+const customFieldPrefix = "customField:"
+
+// preChatFieldKeyPattern restringe las claves de campo a identificadores simples
+var preChatFieldKeyPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,49}$`)
+
+// PreChatFormHandler contiene manejadores para los formularios previos al chat
+type PreChatFormHandler struct {
+	Store data.DataStore
+}
+
+// defaultPreChatForm es el formulario usado cuando no hay ninguno configurado
+func defaultPreChatForm() models.PreChatForm {
+	return models.PreChatForm{
+		ID:     "default",
+		Title:  "Iniciar conversación",
+		Active: true,
+		Fields: []models.PreChatField{
+			{Key: "name", Label: "Nombre", Type: models.PreChatFieldText, Required: true, MaxLength: 100, MapTo: "name"},
+			{Key: "email", Label: "Email", Type: models.PreChatFieldEmail, Required: true, MaxLength: 200, MapTo: "email"},
+			{Key: "message", Label: "Mensaje", Type: models.PreChatFieldTextarea, Required: true, MaxLength: 5000, MapTo: "message"},
+		},
+	}
+}
+
+// validatePreChatForm comprueba que la definición de un formulario sea coherente
+func validatePreChatForm(form models.PreChatForm) error {
+	if strings.TrimSpace(form.Title) == "" {
+		return fmt.Errorf("el título del formulario es requerido")
+	}
+	if len(form.Fields) == 0 {
+		return fmt.Errorf("el formulario debe tener al menos un campo")
+	}
+
+	keys := make(map[string]bool)
+	targets := make(map[string]bool)
+	for i, field := range form.Fields {
+		if !preChatFieldKeyPattern.MatchString(field.Key) {
+			return fmt.Errorf("campo %d: la clave %q no es válida", i+1, field.Key)
+		}
+		if keys[field.Key] {
+			return fmt.Errorf("campo %d: la clave %q está duplicada", i+1, field.Key)
+		}
+		keys[field.Key] = true
+
+		if strings.TrimSpace(field.Label) == "" {
+			return fmt.Errorf("campo %s: la etiqueta es requerida", field.Key)
+		}
+
+		switch field.Type {
+		case models.PreChatFieldText, models.PreChatFieldEmail, models.PreChatFieldTextarea, models.PreChatFieldCheckbox:
+		case models.PreChatFieldSelect:
+			if len(field.Options) == 0 {
+				return fmt.Errorf("campo %s: los campos de selección requieren opciones", field.Key)
+			}
+		default:
+			return fmt.Errorf("campo %s: tipo %q no soportado", field.Key, field.Type)
+		}
+
+		if field.MinLength < 0 || field.MaxLength < 0 || (field.MaxLength > 0 && field.MinLength > field.MaxLength) {
+			return fmt.Errorf("campo %s: longitudes mínima y máxima inválidas", field.Key)
+		}
+		if field.Pattern != "" {
+			if _, err := regexp.Compile(field.Pattern); err != nil {
+				return fmt.Errorf("campo %s: expresión regular inválida", field.Key)
+			}
+		}
+
+		switch {
+		case field.MapTo == "":
+		case field.MapTo == "name", field.MapTo == "email", field.MapTo == "subject", field.MapTo == "message",
+			field.MapTo == "priority", field.MapTo == "department", field.MapTo == "category":
+			if targets[field.MapTo] {
+				return fmt.Errorf("campo %s: ya hay otro campo asignado a %s", field.Key, field.MapTo)
+			}
+			targets[field.MapTo] = true
+		case field.MapTo == "tags":
+			// Varios campos pueden aportar etiquetas al ticket
+		case strings.HasPrefix(field.MapTo, customFieldPrefix):
+			if !preChatFieldKeyPattern.MatchString(strings.TrimPrefix(field.MapTo, customFieldPrefix)) {
+				return fmt.Errorf("campo %s: campo personalizado inválido", field.Key)
+			}
+		default:
+			return fmt.Errorf("campo %s: destino %q no soportado", field.Key, field.MapTo)
+		}
+	}
+
+	// El ticket necesita siempre un nombre y un email de contacto
+	if !targets["name"] || !targets["email"] {
+		return fmt.Errorf("el formulario debe incluir campos asignados a name y email")
+	}
+
+	return nil
+}
+
+// GetWidgetForm devuelve el formulario activo para un widget (público). Si el widget no
+// tiene uno propio se usa el formulario por defecto configurado o, en su defecto, el integrado.
+func (h *PreChatFormHandler) GetWidgetForm(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	widgetID := r.URL.Query().Get("widgetId")
+	if widgetID == "" {
+		widgetID = r.Header.Get("X-Widget-ID")
+	}
+
+	if widgetID != "" {
+		if form, err := h.Store.GetPreChatFormByWidget(widgetID); err == nil && form.Active {
+			utils.WriteJSON(w, http.StatusOK, form)
+			return
+		}
+	}
+
+	if form, err := h.Store.GetPreChatFormByWidget(""); err == nil && form.Active {
+		utils.WriteJSON(w, http.StatusOK, form)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, defaultPreChatForm())
+}
+
+// GetAllForms devuelve todos los formularios previos al chat
+func (h *PreChatFormHandler) GetAllForms(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	forms, err := h.Store.GetPreChatForms()
+	if err != nil {
+		http.Error(w, "Error al obtener formularios", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, forms)
+}
+
+// GetForm obtiene un formulario previo al chat por ID
+func (h *PreChatFormHandler) GetForm(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Obtener ID de la URL (/api/prechat-forms/:id)
+	segments := strings.Split(r.URL.Path, "/")
+	if len(segments) < 4 {
+		http.Error(w, "URL de formulario inválida", http.StatusBadRequest)
+		return
+	}
+
+	form, err := h.Store.GetPreChatForm(segments[3])
+	if err != nil {
+		http.Error(w, "Formulario no encontrado", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, form)
+}
+
+// CreateForm crea un nuevo formulario previo al chat
+func (h *PreChatFormHandler) CreateForm(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	var form models.PreChatForm
+	if err := utils.DecodeJSON(r, &form); err != nil {
+		http.Error(w, "Error al leer datos del formulario", http.StatusBadRequest)
+		return
+	}
+
+	if err := validatePreChatForm(form); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.Store.GetPreChatFormByWidget(form.WidgetID); err == nil {
+		http.Error(w, "Ya existe un formulario para este widget", http.StatusConflict)
+		return
+	}
+
+	// Establecer ID y marcas de tiempo
+	form.ID = uuid.New().String()
+	now := time.Now()
+	form.CreatedAt = now
+	form.UpdatedAt = now
+
+	if err := h.Store.CreatePreChatForm(form); err != nil {
+		http.Error(w, "Error al crear formulario", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, form)
+}
+
+// UpdateForm reemplaza un formulario previo al chat existente
+func (h *PreChatFormHandler) UpdateForm(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes PUT
+	if r.Method != http.MethodPut {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	segments := strings.Split(r.URL.Path, "/")
+	if len(segments) < 4 {
+		http.Error(w, "URL de formulario inválida", http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.Store.GetPreChatForm(segments[3])
+	if err != nil {
+		http.Error(w, "Formulario no encontrado", http.StatusNotFound)
+		return
+	}
+
+	var form models.PreChatForm
+	if err := utils.DecodeJSON(r, &form); err != nil {
+		http.Error(w, "Error al leer datos del formulario", http.StatusBadRequest)
+		return
+	}
+
+	form.ID = existing.ID
+	form.CreatedAt = existing.CreatedAt
+	form.UpdatedAt = time.Now()
+
+	if err := validatePreChatForm(form); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if other, err := h.Store.GetPreChatFormByWidget(form.WidgetID); err == nil && other.ID != form.ID {
+		http.Error(w, "Ya existe un formulario para este widget", http.StatusConflict)
+		return
+	}
+
+	if err := h.Store.UpdatePreChatForm(form); err != nil {
+		http.Error(w, "Error al actualizar formulario", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, form)
+}
+
+// DeleteForm elimina un formulario previo al chat
+func (h *PreChatFormHandler) DeleteForm(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes DELETE
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	segments := strings.Split(r.URL.Path, "/")
+	if len(segments) < 4 {
+		http.Error(w, "URL de formulario inválida", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.DeletePreChatForm(segments[3]); err != nil {
+		http.Error(w, "Formulario no encontrado", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
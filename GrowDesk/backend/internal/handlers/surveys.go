@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/csat"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// SurveyHandler contiene manejadores para las encuestas de satisfacción
+type SurveyHandler struct {
+	Store data.DataStore
+}
+
+// surveyFromPath obtiene la encuesta del token de la URL (/widget/csat/:token).
+// Devuelve también el código HTTP a usar si hay error.
+func (h *SurveyHandler) surveyFromPath(r *http.Request) (*models.SatisfactionSurvey, int, string) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) != 3 || segments[2] == "" {
+		return nil, http.StatusBadRequest, "URL de encuesta inválida"
+	}
+
+	survey, err := h.Store.GetSurveyByTokenHash(csat.HashToken(segments[2]))
+	if err != nil {
+		return nil, http.StatusNotFound, "Encuesta no encontrada"
+	}
+	return survey, http.StatusOK, ""
+}
+
+// GetPublicSurvey devuelve los datos públicos de una encuesta para mostrarla al cliente
+func (h *SurveyHandler) GetPublicSurvey(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	survey, status, message := h.surveyFromPath(r)
+	if survey == nil {
+		http.Error(w, message, status)
+		return
+	}
+
+	response := models.PublicSurvey{
+		TicketID: survey.TicketID,
+		Answered: survey.Rating != nil,
+		Expired:  survey.Rating == nil && csat.Expired(*survey, time.Now()),
+		Rating:   survey.Rating,
+	}
+	if ticket, err := h.Store.GetTicket(survey.TicketID); err == nil {
+		response.TicketTitle = ticket.Title
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// SubmitSurvey registra la valoración (1-5) y el comentario del cliente
+func (h *SurveyHandler) SubmitSurvey(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	var req models.SurveyResponseRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer la respuesta de la encuesta", http.StatusBadRequest)
+		return
+	}
+	if err := csat.ValidateResponse(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	survey, status, message := h.surveyFromPath(r)
+	if survey == nil {
+		http.Error(w, message, status)
+		return
+	}
+	if survey.Rating != nil {
+		http.Error(w, "La encuesta ya fue respondida", http.StatusConflict)
+		return
+	}
+	if csat.Expired(*survey, time.Now()) {
+		http.Error(w, "La encuesta ha caducado", http.StatusGone)
+		return
+	}
+
+	// Otra solicitud pudo responderla entre la consulta y la actualización
+	if _, err := h.Store.AnswerSurvey(survey.TokenHash, req.Rating, req.Comment); err != nil {
+		http.Error(w, "La encuesta ya fue respondida", http.StatusConflict)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// GetCSATReport resume las encuestas por agente, categoría o período.
+// Parámetros: ?from=YYYY-MM-DD&to=YYYY-MM-DD&groupBy=agent|category|day|week|month
+func (h *SurveyHandler) GetCSATReport(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	report := models.CSATReport{
+		From:    query.Get("from"),
+		To:      query.Get("to"),
+		GroupBy: query.Get("groupBy"),
+	}
+	if report.GroupBy == "" {
+		report.GroupBy = csat.GroupByAgent
+	}
+	if !csat.ValidGroupBy(report.GroupBy) {
+		http.Error(w, "Agrupación inválida: "+report.GroupBy, http.StatusBadRequest)
+		return
+	}
+
+	// Las fechas son días completos; "to" se incluye
+	var filter models.SurveyFilter
+	if report.From != "" {
+		from, err := time.ParseInLocation("2006-01-02", report.From, time.Local)
+		if err != nil {
+			http.Error(w, "Fecha inicial inválida (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		filter.From = from
+	}
+	if report.To != "" {
+		to, err := time.ParseInLocation("2006-01-02", report.To, time.Local)
+		if err != nil {
+			http.Error(w, "Fecha final inválida (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		http.Error(w, "La fecha inicial debe ser anterior a la final", http.StatusBadRequest)
+		return
+	}
+
+	surveys, err := h.Store.GetSurveys(filter)
+	if err != nil {
+		http.Error(w, "Error al obtener encuestas", http.StatusInternalServerError)
+		return
+	}
+
+	agentNames := make(map[string]string)
+	if report.GroupBy == csat.GroupByAgent {
+		users, err := h.Store.GetUsers()
+		if err != nil {
+			http.Error(w, "Error al obtener usuarios", http.StatusInternalServerError)
+			return
+		}
+		for _, user := range users {
+			agentNames[user.ID] = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		}
+	}
+
+	report.Overall, report.Groups = csat.Report(surveys, report.GroupBy, agentNames, time.Local)
+	utils.WriteJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/tags"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// Límites del autocompletado de etiquetas
+const (
+	defaultTagSuggestions = 10
+	maxTagSuggestions     = 100
+	maxBulkTagTickets     = 500
+)
+
+// TagHandler contiene manejadores para las etiquetas de tickets
+type TagHandler struct {
+	Store data.DataStore
+}
+
+// ticketTagsResult es el resultado de etiquetar un ticket en una operación masiva
+type ticketTagsResult struct {
+	TicketID string   `json:"ticketId"`
+	Tags     []string `json:"tags,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// SearchTags devuelve etiquetas para autocompletar (?q=prefijo&limit=10)
+func (h *TagHandler) SearchTags(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	limit := defaultTagSuggestions
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			http.Error(w, "Límite inválido", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	if limit > maxTagSuggestions {
+		limit = maxTagSuggestions
+	}
+
+	result, err := h.Store.GetTags(tags.Normalize(r.URL.Query().Get("q")), limit)
+	if err != nil {
+		http.Error(w, "Error al obtener etiquetas", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, result)
+}
+
+// GetTagReport devuelve el número de tickets por etiqueta y estado
+func (h *TagHandler) GetTagReport(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	report, err := h.Store.GetTagReport()
+	if err != nil {
+		http.Error(w, "Error al obtener reporte de etiquetas", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, report)
+}
+
+// UpdateTicketTags añade (POST) o quita (DELETE) etiquetas de un ticket.
+// Formato de URL: /api/tickets/:id/tags
+func (h *TagHandler) UpdateTicketTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 5 {
+		http.Error(w, "ID de ticket inválido", http.StatusBadRequest)
+		return
+	}
+	ticketID := parts[len(parts)-2]
+
+	var req models.TicketTagsRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer etiquetas", http.StatusBadRequest)
+		return
+	}
+
+	// "tags" equivale a "add" en POST y a "remove" en DELETE
+	add, remove := req.Add, req.Remove
+	if r.Method == http.MethodPost {
+		add = append(add, req.Tags...)
+	} else {
+		remove = append(remove, req.Tags...)
+	}
+	add, remove = tags.NormalizeAll(add), tags.NormalizeAll(remove)
+	if len(add) == 0 && len(remove) == 0 {
+		http.Error(w, "Debe indicar al menos una etiqueta", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.applyTags(ticketID, add, remove)
+	if err != nil {
+		http.Error(w, "Ticket no encontrado", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, ticketTagsResult{TicketID: ticketID, Tags: result})
+}
+
+// BulkTagTickets añade y quita etiquetas de varios tickets a la vez
+func (h *TagHandler) BulkTagTickets(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	var req models.TicketTagsRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer etiquetas", http.StatusBadRequest)
+		return
+	}
+
+	add := tags.NormalizeAll(append(req.Add, req.Tags...))
+	remove := tags.NormalizeAll(req.Remove)
+	if len(req.TicketIDs) == 0 || (len(add) == 0 && len(remove) == 0) {
+		http.Error(w, "Debe indicar tickets y al menos una etiqueta", http.StatusBadRequest)
+		return
+	}
+	if len(req.TicketIDs) > maxBulkTagTickets {
+		http.Error(w, "Demasiados tickets en una sola operación", http.StatusBadRequest)
+		return
+	}
+
+	results := make([]ticketTagsResult, 0, len(req.TicketIDs))
+	failed := 0
+	for _, ticketID := range req.TicketIDs {
+		result, err := h.applyTags(ticketID, add, remove)
+		if err != nil {
+			failed++
+			results = append(results, ticketTagsResult{TicketID: ticketID, Error: err.Error()})
+			continue
+		}
+		results = append(results, ticketTagsResult{TicketID: ticketID, Tags: result})
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"updated": len(results) - failed,
+		"failed":  failed,
+		"results": results,
+	})
+}
+
+// applyTags añade y luego quita etiquetas de un ticket
+func (h *TagHandler) applyTags(ticketID string, add, remove []string) ([]string, error) {
+	var result []string
+	var err error
+	if len(add) > 0 {
+		if result, err = h.Store.AddTicketTags(ticketID, add); err != nil {
+			return nil, err
+		}
+	}
+	if len(remove) > 0 {
+		if result, err = h.Store.RemoveTicketTags(ticketID, remove); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
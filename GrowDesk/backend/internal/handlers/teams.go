@@ -0,0 +1,492 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/teams"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// TeamHandler contiene manejadores para los equipos de agentes
+type TeamHandler struct {
+	Store data.DataStore
+}
+
+// validateTeam comprueba el nombre, el horario de atención y la configuración de la
+// cola de un equipo
+func (h *TeamHandler) validateTeam(team models.Team) error {
+	if strings.TrimSpace(team.Name) == "" {
+		return fmt.Errorf("El nombre del equipo es requerido")
+	}
+	if team.BusinessHoursID != "" {
+		if _, err := h.Store.GetBusinessHoursByID(team.BusinessHoursID); err != nil {
+			return fmt.Errorf("Horario de atención no encontrado: %s", team.BusinessHoursID)
+		}
+	}
+	if team.Queue.DefaultPriority != "" && !bulkTicketPriorities[team.Queue.DefaultPriority] {
+		return fmt.Errorf("Prioridad por defecto inválida: %s", team.Queue.DefaultPriority)
+	}
+	if !teams.ValidOrder(team.Queue.Order) {
+		return fmt.Errorf("Orden de la cola inválido (use oldest, newest o priority)")
+	}
+	return nil
+}
+
+// validateMember comprueba el rol del miembro y que el usuario exista
+func (h *TeamHandler) validateMember(member *models.TeamMember) error {
+	if member.Role == "" {
+		member.Role = models.TeamRoleMember
+	}
+	if !teams.ValidRole(member.Role) {
+		return fmt.Errorf("Rol de miembro inválido (use lead o member)")
+	}
+	if _, err := h.Store.GetUser(member.UserID); err != nil {
+		return fmt.Errorf("Usuario no encontrado: %s", member.UserID)
+	}
+	return nil
+}
+
+// resolveTeam devuelve el ID del equipo activo que corresponde al departamento; un
+// departamento vacío se devuelve vacío
+func resolveTeam(store data.DataStore, department string) (string, error) {
+	department = strings.TrimSpace(department)
+	if department == "" {
+		return "", nil
+	}
+	allTeams, err := store.GetTeams()
+	if err != nil {
+		return "", fmt.Errorf("Error al obtener equipos")
+	}
+	return teams.Resolve(allTeams, department)
+}
+
+// teamIDFromPath devuelve el ID del equipo de una URL /api/teams/:id/...
+func teamIDFromPath(path string) string {
+	segments := strings.Split(path, "/")
+	if len(segments) < 4 {
+		return ""
+	}
+	return segments[3]
+}
+
+// GetTeams devuelve todos los equipos con sus miembros
+func (h *TeamHandler) GetTeams(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	list, err := h.Store.GetTeams()
+	if err != nil {
+		http.Error(w, "Error al obtener equipos", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, list)
+}
+
+// GetTeam devuelve un equipo por ID
+func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	team, err := h.Store.GetTeam(teamIDFromPath(r.URL.Path))
+	if err != nil {
+		http.Error(w, "Equipo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, team)
+}
+
+// CreateTeam crea un equipo. Si no se indica ID se genera a partir del nombre; el ID
+// es el valor que se guarda como departamento en usuarios y tickets.
+func (h *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	var team models.Team
+	if err := utils.DecodeJSON(r, &team); err != nil {
+		http.Error(w, "Error al leer datos del equipo", http.StatusBadRequest)
+		return
+	}
+
+	team.Name = strings.TrimSpace(team.Name)
+	if team.ID == "" {
+		team.ID = team.Name
+	}
+	team.ID = teams.Key(team.ID)
+	if err := h.validateTeam(team); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	seen := make(map[string]bool)
+	for i := range team.Members {
+		if seen[team.Members[i].UserID] {
+			http.Error(w, fmt.Sprintf("Miembro duplicado: %s", team.Members[i].UserID), http.StatusBadRequest)
+			return
+		}
+		seen[team.Members[i].UserID] = true
+		if err := h.validateMember(&team.Members[i]); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if _, err := h.Store.GetTeam(team.ID); err == nil {
+		http.Error(w, fmt.Sprintf("Ya existe un equipo con ID %s", team.ID), http.StatusConflict)
+		return
+	}
+
+	now := time.Now()
+	for i := range team.Members {
+		team.Members[i].JoinedAt = now
+	}
+	if team.Members == nil {
+		team.Members = make([]models.TeamMember, 0)
+	}
+	team.Active = true
+	team.CreatedAt = now
+	team.UpdatedAt = now
+
+	if err := h.Store.CreateTeam(team); err != nil {
+		http.Error(w, "Error al crear equipo", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, team)
+}
+
+// UpdateTeam actualiza un equipo. Los responsables del equipo pueden cambiar la
+// descripción, el horario de atención y la configuración de la cola; el nombre y el
+// estado solo los cambia un administrador.
+func (h *TeamHandler) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes PUT
+	if r.Method != http.MethodPut {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	team, err := h.Store.GetTeam(teamIDFromPath(r.URL.Path))
+	if err != nil {
+		http.Error(w, "Equipo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	// Verificar permisos: administrador o responsable del equipo
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	isAdmin := role == "admin"
+	if !isAdmin && !teams.IsLead(*team, userID) {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	// Decodificar cuerpo de la solicitud; los campos omitidos no cambian
+	var updates struct {
+		Name            *string                   `json:"name"`
+		Description     *string                   `json:"description"`
+		BusinessHoursID *string                   `json:"businessHoursId"`
+		Queue           *models.TeamQueueSettings `json:"queue"`
+		Active          *bool                     `json:"active"`
+	}
+	if err := utils.DecodeJSON(r, &updates); err != nil {
+		http.Error(w, "Error al leer datos de actualización", http.StatusBadRequest)
+		return
+	}
+
+	if !isAdmin && (updates.Name != nil || updates.Active != nil) {
+		http.Error(w, "Solo un administrador puede cambiar el nombre o el estado del equipo", http.StatusUnauthorized)
+		return
+	}
+
+	if updates.Name != nil {
+		team.Name = strings.TrimSpace(*updates.Name)
+	}
+	if updates.Description != nil {
+		team.Description = *updates.Description
+	}
+	if updates.BusinessHoursID != nil {
+		team.BusinessHoursID = *updates.BusinessHoursID
+	}
+	if updates.Queue != nil {
+		team.Queue = *updates.Queue
+	}
+	if updates.Active != nil {
+		team.Active = *updates.Active
+	}
+
+	if err := h.validateTeam(*team); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	team.UpdatedAt = time.Now()
+	if err := h.Store.UpdateTeam(*team); err != nil {
+		http.Error(w, "Error al actualizar equipo", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, team)
+}
+
+// DeleteTeam elimina un equipo. Si tiene tickets o usuarios se debe indicar
+// ?reassignTo=ID para moverlos a otro equipo, o ?archive=true para archivarlo en
+// lugar de eliminarlo.
+func (h *TeamHandler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes DELETE
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	team, err := h.Store.GetTeam(teamIDFromPath(r.URL.Path))
+	if err != nil {
+		http.Error(w, "Equipo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+
+	// Archivar: el equipo deja de aceptar tickets nuevos pero conserva los existentes
+	if query.Get("archive") == "true" {
+		team.Active = false
+		team.UpdatedAt = time.Now()
+		if err := h.Store.UpdateTeam(*team); err != nil {
+			http.Error(w, "Error al archivar equipo", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"success": true, "archived": true})
+		return
+	}
+
+	// Reasignar tickets, usuarios y miembros a otro equipo antes de eliminarlo
+	if targetID := query.Get("reassignTo"); targetID != "" {
+		target, err := h.Store.GetTeam(teams.Key(targetID))
+		if err != nil {
+			http.Error(w, "Equipo de destino no encontrado", http.StatusBadRequest)
+			return
+		}
+		if target.ID == team.ID {
+			http.Error(w, "El equipo de destino debe ser distinto", http.StatusBadRequest)
+			return
+		}
+		if !target.Active {
+			http.Error(w, "El equipo de destino está archivado", http.StatusBadRequest)
+			return
+		}
+
+		moved, err := h.Store.DeleteTeamReassigning(team.ID, *target)
+		if err != nil {
+			http.Error(w, "Error al eliminar equipo", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"success": true, "reassignedTickets": moved})
+		return
+	}
+
+	// Sin reasignación solo se eliminan los equipos sin tickets ni usuarios
+	tickets, users, err := h.Store.CountTeamReferences(team.ID)
+	if err != nil {
+		http.Error(w, "Error al contar tickets y usuarios del equipo", http.StatusInternalServerError)
+		return
+	}
+	if tickets > 0 || users > 0 {
+		http.Error(w, fmt.Sprintf("El equipo tiene %d tickets y %d usuarios: reasígnelos con ?reassignTo= o archívelo con ?archive=true", tickets, users), http.StatusConflict)
+		return
+	}
+
+	if err := h.Store.DeleteTeam(team.ID); err != nil {
+		http.Error(w, "Error al eliminar equipo", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// AddTeamMember agrega un usuario al equipo o cambia su rol.
+// Formato de URL: /api/teams/:id/members
+func (h *TeamHandler) AddTeamMember(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	team, err := h.Store.GetTeam(teamIDFromPath(r.URL.Path))
+	if err != nil {
+		http.Error(w, "Equipo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	var member models.TeamMember
+	if err := utils.DecodeJSON(r, &member); err != nil {
+		http.Error(w, "Error al leer datos del miembro", http.StatusBadRequest)
+		return
+	}
+	if member.UserID == "" {
+		http.Error(w, "El usuario es requerido", http.StatusBadRequest)
+		return
+	}
+	if err := h.validateMember(&member); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.AddTeamMember(team.ID, member); err != nil {
+		http.Error(w, "Error al agregar miembro al equipo", http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := h.Store.GetTeam(team.ID)
+	if err != nil {
+		http.Error(w, "Error al obtener equipo", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, updated)
+}
+
+// RemoveTeamMember quita a un usuario del equipo.
+// Formato de URL: /api/teams/:id/members/:userId
+func (h *TeamHandler) RemoveTeamMember(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes DELETE
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 6 {
+		http.Error(w, "URL de miembro inválida", http.StatusBadRequest)
+		return
+	}
+
+	team, err := h.Store.GetTeam(parts[3])
+	if err != nil {
+		http.Error(w, "Equipo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	if err := h.Store.RemoveTeamMember(team.ID, parts[5]); err != nil {
+		http.Error(w, "Miembro no encontrado", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// GetTeamQueue devuelve la cola de tickets del equipo en el orden configurado. Solo la
+// ven los administradores y los miembros del equipo. Con ?unassigned=true se limita a
+// los tickets sin asignar.
+// Formato de URL: /api/teams/:id/queue
+func (h *TeamHandler) GetTeamQueue(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	team, err := h.Store.GetTeam(teamIDFromPath(r.URL.Path))
+	if err != nil {
+		http.Error(w, "Equipo no encontrado", http.StatusNotFound)
+		return
+	}
+
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	if role != "admin" && teams.MemberRole(*team, userID) == "" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	tickets, err := h.Store.QueryTickets(teams.QueueFilter(team.Queue))
+	if err != nil {
+		http.Error(w, "Error al obtener tickets", http.StatusInternalServerError)
+		return
+	}
+
+	queue := teams.Queue(*team, tickets)
+	if r.URL.Query().Get("unassigned") == "true" {
+		unassigned := make([]models.Ticket, 0, queue.Unassigned)
+		for _, ticket := range queue.Tickets {
+			if ticket.AssignedTo == "" {
+				unassigned = append(unassigned, ticket)
+			}
+		}
+		queue.Tickets = unassigned
+	}
+
+	// La cola es una vista para agentes: no incluye los mensajes de los tickets
+	for i := range queue.Tickets {
+		queue.Tickets[i].Messages = nil
+	}
+
+	utils.WriteJSON(w, http.StatusOK, queue)
+}
@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/tags"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/teams"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// maxBulkTickets limita el número de tickets de una operación masiva
+const maxBulkTickets = 1000
+
+// bulkRolledBackError es el error de los tickets no aplicados en una operación transaccional revertida
+const bulkRolledBackError = "Operación revertida: otro ticket de la operación falló"
+
+// Estados y prioridades que acepta una operación masiva
+var (
+	bulkTicketStatuses   = map[string]bool{"open": true, "assigned": true, "in_progress": true, "pending": true, "resolved": true, "closed": true}
+	bulkTicketPriorities = map[string]bool{"low": true, "medium": true, "high": true, "urgent": true}
+)
+
+// BulkTickets aplica cambios de estado, prioridad, asignación, categoría, etiquetas o
+// eliminación a una lista de tickets o a los que cumplan un filtro
+func (h *TicketHandler) BulkTickets(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	var req models.BulkTicketRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer la operación masiva", http.StatusBadRequest)
+		return
+	}
+
+	changes, err := validateBulkChanges(h.Store, req.Changes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Eliminar tickets en bloque requiere rol de administrador
+	if changes.Delete {
+		role, ok := r.Context().Value(middleware.RoleKey).(string)
+		if !ok || role != "admin" {
+			http.Error(w, "No autorizado", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	ticketIDs, status, err := h.resolveBulkTickets(req)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if len(ticketIDs) > maxBulkTickets {
+		http.Error(w, fmt.Sprintf("La operación afecta a %d tickets; el máximo es %d", len(ticketIDs), maxBulkTickets), http.StatusBadRequest)
+		return
+	}
+
+	// Reasignar tickets requiere ser administrador o responsable de sus equipos
+	if changes.AssignedTo != nil {
+		denied, err := h.deniedAssignments(r, ticketIDs, *changes.AssignedTo)
+		if err != nil {
+			http.Error(w, "Error al obtener equipos", http.StatusInternalServerError)
+			return
+		}
+		if denied > 0 {
+			http.Error(w, fmt.Sprintf("No autorizado para reasignar %d de los tickets: pertenecen a equipos que no gestiona", denied), http.StatusUnauthorized)
+			return
+		}
+	}
+
+	// Vista previa: solo se comprueba qué tickets existen
+	if req.DryRun {
+		response := models.BulkTicketResponse{DryRun: true, Results: make([]models.BulkTicketResult, 0, len(ticketIDs))}
+		for _, id := range ticketIDs {
+			result := models.BulkTicketResult{TicketID: id, Success: true}
+			if _, err := h.Store.GetTicket(id); err != nil {
+				result = models.BulkTicketResult{TicketID: id, Error: "Ticket no encontrado"}
+				response.Failed++
+			} else {
+				response.Matched++
+			}
+			response.Results = append(response.Results, result)
+		}
+		utils.WriteJSON(w, http.StatusOK, response)
+		return
+	}
+
+	// Recordar el estado previo de los tickets para notificar cambios al widget
+	// y enviar las encuestas de satisfacción
+	previousStatus := make(map[string]string)
+	widgetTickets := make(map[string]bool)
+	if changes.Status != "" {
+		for _, id := range ticketIDs {
+			if ticket, err := h.Store.GetTicket(id); err == nil {
+				previousStatus[id] = ticket.Status
+				widgetTickets[id] = ticket.Source == "widget"
+			}
+		}
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	results, err := h.Store.BulkUpdateTickets(ticketIDs, changes, bulkActivity(userID, changes), req.Transactional)
+	if err != nil {
+		http.Error(w, "Error al aplicar la operación masiva", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.BulkTicketResponse{Matched: len(ticketIDs), Results: results}
+	for _, result := range results {
+		if !result.Success {
+			response.Failed++
+		}
+	}
+	if req.Transactional && response.Failed > 0 {
+		response.RolledBack = true
+		response.Failed = len(results)
+		for i := range results {
+			results[i].Success = false
+			if results[i].Error == "" {
+				results[i].Error = bulkRolledBackError
+			}
+		}
+	}
+	response.Updated = len(results) - response.Failed
+
+	// Notificar a los visitantes del widget los cambios de estado aplicados
+	for _, result := range results {
+		previous, ok := previousStatus[result.TicketID]
+		if !ok || !result.Success {
+			continue
+		}
+		if widgetTickets[result.TicketID] && previous != changes.Status {
+			h.Events.PublishStatus(result.TicketID, changes.Status)
+		}
+		h.Surveys.StatusChanged(result.TicketID, previous, changes.Status)
+	}
+
+	// Notificar a los seguidores de los tickets actualizados
+	if !changes.Delete {
+		activity := bulkActivity(userID, changes)
+		for _, result := range results {
+			if result.Success {
+				h.Watchers.Notify(result.TicketID, userID, fmt.Sprintf("Ticket %s: %s", result.TicketID, activity.Description))
+			}
+		}
+
+		// Aplicar reglas de automatización a cada ticket actualizado
+		for _, result := range results {
+			if result.Success {
+				h.Automation.Run(models.AutomationEventTicketUpdated, result.TicketID, nil)
+			}
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetTicketActivity devuelve el registro de actividad de un ticket.
+// Formato de URL: /api/tickets/:id/activity
+func (h *TicketHandler) GetTicketActivity(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 5 {
+		http.Error(w, "ID de ticket inválido", http.StatusBadRequest)
+		return
+	}
+
+	activities, err := h.Store.GetActivities(parts[len(parts)-2])
+	if err != nil {
+		http.Error(w, "Error al obtener actividad del ticket", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, activities)
+}
+
+// validateBulkChanges comprueba y normaliza los cambios de una operación masiva
+func validateBulkChanges(store data.DataStore, changes models.BulkTicketChanges) (models.BulkTicketChanges, error) {
+	changes.AddTags = tags.NormalizeAll(changes.AddTags)
+	changes.RemoveTags = tags.NormalizeAll(changes.RemoveTags)
+	changes.Category = ""
+
+	hasUpdate := changes.Status != "" || changes.Priority != "" || changes.AssignedTo != nil ||
+		changes.CategoryID != "" || len(changes.AddTags) > 0 || len(changes.RemoveTags) > 0
+	if changes.Delete {
+		if hasUpdate {
+			return changes, fmt.Errorf("La eliminación no se puede combinar con otros cambios")
+		}
+		return changes, nil
+	}
+	if !hasUpdate {
+		return changes, fmt.Errorf("Debe indicar al menos un cambio")
+	}
+
+	if changes.Status != "" && !bulkTicketStatuses[changes.Status] {
+		return changes, fmt.Errorf("Estado inválido: %s", changes.Status)
+	}
+	if changes.Priority != "" && !bulkTicketPriorities[changes.Priority] {
+		return changes, fmt.Errorf("Prioridad inválida: %s", changes.Priority)
+	}
+	if changes.AssignedTo != nil && *changes.AssignedTo != "" {
+		if _, err := store.GetUser(*changes.AssignedTo); err != nil {
+			return changes, fmt.Errorf("Agente no encontrado: %s", *changes.AssignedTo)
+		}
+	}
+	if changes.CategoryID != "" {
+		category, err := store.GetCategory(changes.CategoryID)
+		if err != nil {
+			return changes, fmt.Errorf("Categoría no encontrada: %s", changes.CategoryID)
+		}
+		changes.Category = category.Name
+	}
+
+	return changes, nil
+}
+
+// resolveBulkTickets obtiene los IDs de la operación, de la lista explícita o del filtro.
+// Devuelve también el código HTTP a usar si hay error.
+func (h *TicketHandler) resolveBulkTickets(req models.BulkTicketRequest) ([]string, int, error) {
+	if (len(req.TicketIDs) > 0) == (req.Filter != "") {
+		return nil, http.StatusBadRequest, fmt.Errorf("Debe indicar ticketIds o filter, pero no ambos")
+	}
+
+	if len(req.TicketIDs) > 0 {
+		return uniqueTicketIDs(req.TicketIDs), http.StatusOK, nil
+	}
+
+	query, err := url.ParseQuery(strings.TrimPrefix(req.Filter, "?"))
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("Filtro inválido")
+	}
+
+	// Un filtro sin criterios afectaría a todos los tickets
+	hasCriteria := false
+	for param, values := range query {
+		if param != "sort" && param != "order" && strings.Join(values, "") != "" {
+			hasCriteria = true
+			break
+		}
+	}
+	if !hasCriteria {
+		return nil, http.StatusBadRequest, fmt.Errorf("El filtro debe incluir al menos un criterio")
+	}
+
+	defs, err := h.Store.GetCustomFieldDefinitions()
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("Error al obtener campos personalizados")
+	}
+	filter, err := parseTicketFilter(query, defs)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	tickets, err := h.Store.QueryTickets(filter)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("Error al obtener tickets")
+	}
+
+	ids := make([]string, 0, len(tickets))
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.ID)
+	}
+	return uniqueTicketIDs(ids), http.StatusOK, nil
+}
+
+// uniqueTicketIDs quita IDs vacíos y repetidos conservando el orden
+func uniqueTicketIDs(ticketIDs []string) []string {
+	ids := make([]string, 0, len(ticketIDs))
+	seen := make(map[string]bool)
+	for _, id := range ticketIDs {
+		if id = strings.TrimSpace(id); id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// bulkActivity construye la entrada de actividad que se registra en cada ticket afectado
+func bulkActivity(userID string, changes models.BulkTicketChanges) models.Activity {
+	if changes.Delete {
+		return models.Activity{
+			UserID:      userID,
+			Type:        models.ActivityTicketBulkDelete,
+			Description: "Ticket eliminado en una operación masiva",
+		}
+	}
+
+	descriptions := make([]string, 0)
+	metadata := make(map[string]any)
+	if changes.Status != "" {
+		descriptions = append(descriptions, "estado: "+changes.Status)
+		metadata["status"] = changes.Status
+	}
+	if changes.Priority != "" {
+		descriptions = append(descriptions, "prioridad: "+changes.Priority)
+		metadata["priority"] = changes.Priority
+	}
+	if changes.AssignedTo != nil {
+		if *changes.AssignedTo == "" {
+			descriptions = append(descriptions, "sin asignar")
+		} else {
+			descriptions = append(descriptions, "asignado a: "+*changes.AssignedTo)
+		}
+		metadata["assignedTo"] = *changes.AssignedTo
+	}
+	if changes.CategoryID != "" {
+		descriptions = append(descriptions, "categoría: "+changes.Category)
+		metadata["categoryId"] = changes.CategoryID
+	}
+	if len(changes.AddTags) > 0 {
+		descriptions = append(descriptions, "etiquetas añadidas: "+strings.Join(changes.AddTags, ", "))
+		metadata["addTags"] = changes.AddTags
+	}
+	if len(changes.RemoveTags) > 0 {
+		descriptions = append(descriptions, "etiquetas quitadas: "+strings.Join(changes.RemoveTags, ", "))
+		metadata["removeTags"] = changes.RemoveTags
+	}
+
+	return models.Activity{
+		UserID:      userID,
+		Type:        models.ActivityTicketBulkUpdate,
+		Description: "Actualización masiva (" + strings.Join(descriptions, "; ") + ")",
+		Metadata:    metadata,
+	}
+}
+
+// deniedAssignments cuenta los tickets que quien hace la solicitud no puede asignar a
+// assignee. Los tickets que no existen se informan en los resultados de la operación.
+func (h *TicketHandler) deniedAssignments(r *http.Request, ticketIDs []string, assignee string) (int, error) {
+	allTeams, err := h.Store.GetTeams()
+	if err != nil {
+		return 0, err
+	}
+
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	denied := 0
+	for _, id := range ticketIDs {
+		ticket, err := h.Store.GetTicket(id)
+		if err != nil {
+			continue
+		}
+		if !teams.CanAssign(allTeams, role, userID, *ticket, assignee) {
+			denied++
+		}
+	}
+	return denied, nil
+}
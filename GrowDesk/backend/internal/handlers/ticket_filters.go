@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/customfields"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/tags"
+)
+
+// customFieldParamPrefix identifica los parámetros de filtro y orden por campo personalizado
+const customFieldParamPrefix = "cf."
+
+// standardTicketSorts son los campos estándar por los que se puede ordenar el listado
+var standardTicketSorts = map[string]bool{
+	"createdAt": true,
+	"updatedAt": true,
+	"priority":  true,
+	"status":    true,
+	"title":     true,
+}
+
+// parseTicketFilter construye el filtro del listado de tickets a partir de la consulta:
+//
+//	?status=open&priority=high&categoryId=...&assignedTo=...&department=...&source=...
+//	?tags=facturación,urgente   el ticket debe tener todas las etiquetas
+//	?watchedBy=<userId>         el usuario sigue el ticket
+//	?cf.<clave>=valor           igualdad (en multi_enum: contiene el valor)
+//	?cf.<clave>.min=valor       mayor o igual (number y date)
+//	?cf.<clave>.max=valor       menor o igual (number y date)
+//	?sort=priority|cf.<clave>&order=asc|desc
+func parseTicketFilter(query url.Values, defs []models.CustomFieldDefinition) (models.TicketFilter, error) {
+	filter := models.TicketFilter{
+		Status:     query.Get("status"),
+		Priority:   query.Get("priority"),
+		CategoryID: query.Get("categoryId"),
+		AssignedTo: query.Get("assignedTo"),
+		Department: query.Get("department"),
+		Source:     query.Get("source"),
+		WatchedBy:  query.Get("watchedBy"),
+	}
+	if value := query.Get("tags"); value != "" {
+		filter.Tags = tags.Split(value)
+	}
+
+	for param, values := range query {
+		if !strings.HasPrefix(param, customFieldParamPrefix) || len(values) == 0 {
+			continue
+		}
+
+		key := strings.TrimPrefix(param, customFieldParamPrefix)
+		op := models.CustomFieldFilterEq
+		if strings.HasSuffix(key, ".min") {
+			key, op = strings.TrimSuffix(key, ".min"), models.CustomFieldFilterMin
+		} else if strings.HasSuffix(key, ".max") {
+			key, op = strings.TrimSuffix(key, ".max"), models.CustomFieldFilterMax
+		}
+
+		def, ok := customfields.Find(defs, key)
+		if !ok {
+			return filter, fmt.Errorf("campo personalizado desconocido: %s", key)
+		}
+		if op != models.CustomFieldFilterEq && def.Type != models.CustomFieldNumber && def.Type != models.CustomFieldDate {
+			return filter, fmt.Errorf("el campo %s solo admite filtros de igualdad", def.Label)
+		}
+
+		// En multi_enum se filtra por una sola opción
+		fieldType := def.Type
+		normalizeAs := *def
+		if fieldType == models.CustomFieldMultiEnum {
+			normalizeAs.Type = models.CustomFieldEnum
+		}
+
+		value, err := customfields.Normalize(normalizeAs, values[0])
+		if err != nil {
+			return filter, err
+		}
+
+		filter.CustomFields = append(filter.CustomFields, models.CustomFieldFilter{
+			Key:   key,
+			Type:  fieldType,
+			Op:    op,
+			Value: value,
+		})
+	}
+
+	if sortBy := query.Get("sort"); sortBy != "" {
+		if strings.HasPrefix(sortBy, customFieldParamPrefix) {
+			def, ok := customfields.Find(defs, strings.TrimPrefix(sortBy, customFieldParamPrefix))
+			if !ok {
+				return filter, fmt.Errorf("campo personalizado desconocido: %s", sortBy)
+			}
+			filter.SortField = def
+		} else if !standardTicketSorts[sortBy] {
+			return filter, fmt.Errorf("no se puede ordenar por %s", sortBy)
+		}
+		filter.SortBy = sortBy
+		filter.SortDesc = strings.EqualFold(query.Get("order"), "desc")
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// maxTicketHierarchyDepth limita la profundidad de la jerarquía de tickets padre e hijo
+const maxTicketHierarchyDepth = 10
+
+// ticketLinkInverse asocia cada tipo de relación con el tipo visto desde el otro ticket
+var ticketLinkInverse = map[string]string{
+	models.TicketLinkDuplicateOf:  models.TicketLinkDuplicatedBy,
+	models.TicketLinkDuplicatedBy: models.TicketLinkDuplicateOf,
+	models.TicketLinkRelatedTo:    models.TicketLinkRelatedTo,
+	models.TicketLinkChildOf:      models.TicketLinkParentOf,
+	models.TicketLinkParentOf:     models.TicketLinkChildOf,
+	models.TicketLinkBlocks:       models.TicketLinkBlockedBy,
+	models.TicketLinkBlockedBy:    models.TicketLinkBlocks,
+}
+
+// storedTicketLinkTypes son los tipos con los que se guardan las relaciones
+var storedTicketLinkTypes = map[string]bool{
+	models.TicketLinkDuplicateOf: true,
+	models.TicketLinkRelatedTo:   true,
+	models.TicketLinkChildOf:     true,
+	models.TicketLinkBlocks:      true,
+}
+
+// orientTicketLink devuelve la relación vista desde ticketID
+func orientTicketLink(link models.TicketLink, ticketID string) models.TicketLink {
+	if link.TicketID == ticketID {
+		return link
+	}
+	link.TicketID, link.LinkedTicketID = link.LinkedTicketID, link.TicketID
+	link.Type = ticketLinkInverse[link.Type]
+	return link
+}
+
+// ticketLinks obtiene las relaciones de un ticket vistas desde él, con el título y estado
+// del ticket enlazado. Se omiten las relaciones con tickets que ya no existen.
+func (h *TicketHandler) ticketLinks(ticketID string) ([]models.TicketLink, error) {
+	stored, err := h.Store.GetTicketLinks(ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]models.TicketLink, 0, len(stored))
+	for _, link := range stored {
+		link = orientTicketLink(link, ticketID)
+		linked, err := h.Store.GetTicket(link.LinkedTicketID)
+		if err != nil {
+			continue
+		}
+		link.LinkedTicketTitle = linked.Title
+		link.LinkedTicketStatus = linked.Status
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+// parentTicketID devuelve el ticket padre de un ticket, o "" si no tiene
+func (h *TicketHandler) parentTicketID(ticketID string) (string, error) {
+	links, err := h.Store.GetTicketLinks(ticketID)
+	if err != nil {
+		return "", err
+	}
+	for _, link := range links {
+		if link.Type == models.TicketLinkChildOf && link.TicketID == ticketID {
+			return link.LinkedTicketID, nil
+		}
+	}
+	return "", nil
+}
+
+// GetTicketLinks devuelve las relaciones de un ticket.
+// Formato de URL: /api/tickets/:id/links
+func (h *TicketHandler) GetTicketLinks(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 5 {
+		http.Error(w, "ID de ticket inválido", http.StatusBadRequest)
+		return
+	}
+
+	ticket, err := h.resolveTicket(parts[len(parts)-2])
+	if err != nil {
+		http.Error(w, "Ticket no encontrado", http.StatusNotFound)
+		return
+	}
+
+	links, err := h.ticketLinks(ticket.ID)
+	if err != nil {
+		http.Error(w, "Error al obtener relaciones del ticket", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, links)
+}
+
+// CreateTicketLink enlaza el ticket de la URL con otro ticket.
+// Formato de URL: /api/tickets/:id/links
+func (h *TicketHandler) CreateTicketLink(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 5 {
+		http.Error(w, "ID de ticket inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req models.TicketLinkRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer la relación", http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := ticketLinkInverse[req.Type]; !ok {
+		http.Error(w, fmt.Sprintf("Tipo de relación inválido: %s", req.Type), http.StatusBadRequest)
+		return
+	}
+
+	// Los tickets fusionados se enlazan a través de su ticket destino
+	ticket, err := h.resolveTicket(parts[len(parts)-2])
+	if err != nil {
+		http.Error(w, "Ticket no encontrado", http.StatusNotFound)
+		return
+	}
+	linked, err := h.resolveTicket(strings.TrimSpace(req.LinkedTicketID))
+	if err != nil {
+		http.Error(w, "Ticket enlazado no encontrado", http.StatusNotFound)
+		return
+	}
+	if ticket.ID == linked.ID {
+		http.Error(w, "Un ticket no se puede enlazar consigo mismo", http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.ticketLinks(ticket.ID)
+	if err != nil {
+		http.Error(w, "Error al obtener relaciones del ticket", http.StatusInternalServerError)
+		return
+	}
+	for _, link := range existing {
+		if link.LinkedTicketID == linked.ID && link.Type == req.Type {
+			http.Error(w, "La relación ya existe", http.StatusConflict)
+			return
+		}
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	link := models.TicketLink{
+		TicketID:       ticket.ID,
+		LinkedTicketID: linked.ID,
+		Type:           req.Type,
+		CreatedBy:      userID,
+		CreatedAt:      time.Now(),
+	}
+	if !storedTicketLinkTypes[link.Type] {
+		link = orientTicketLink(link, linked.ID)
+	}
+
+	// Un ticket no puede ser hijo de uno de sus descendientes
+	if link.Type == models.TicketLinkChildOf {
+		if status, err := h.checkParentCycle(link.TicketID, link.LinkedTicketID); err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+	}
+
+	if err := h.Store.CreateTicketLink(link); err != nil {
+		http.Error(w, "Error al crear relación: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	links, err := h.ticketLinks(ticket.ID)
+	if err != nil {
+		http.Error(w, "Error al obtener relaciones del ticket", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, links)
+}
+
+// checkParentCycle comprueba que parentID no sea descendiente de childID.
+// Devuelve también el código HTTP a usar si hay error.
+func (h *TicketHandler) checkParentCycle(childID, parentID string) (int, error) {
+	current := parentID
+	for hops := 0; current != ""; hops++ {
+		if current == childID {
+			return http.StatusBadRequest, fmt.Errorf("La relación crearía un ciclo entre tickets padre e hijo")
+		}
+		if hops == maxTicketHierarchyDepth {
+			return http.StatusBadRequest, fmt.Errorf("La jerarquía de tickets es demasiado profunda")
+		}
+
+		parent, err := h.parentTicketID(current)
+		if err != nil {
+			return http.StatusInternalServerError, fmt.Errorf("Error al obtener relaciones del ticket")
+		}
+		current = parent
+	}
+	return http.StatusOK, nil
+}
+
+// DeleteTicketLink elimina una relación del ticket de la URL.
+// Formato de URL: /api/tickets/:id/links/:linkId
+func (h *TicketHandler) DeleteTicketLink(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes DELETE
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 6 {
+		http.Error(w, "ID de relación inválido", http.StatusBadRequest)
+		return
+	}
+	linkID := parts[len(parts)-1]
+
+	ticket, err := h.resolveTicket(parts[len(parts)-3])
+	if err != nil {
+		http.Error(w, "Ticket no encontrado", http.StatusNotFound)
+		return
+	}
+
+	links, err := h.Store.GetTicketLinks(ticket.ID)
+	if err != nil {
+		http.Error(w, "Error al obtener relaciones del ticket", http.StatusInternalServerError)
+		return
+	}
+
+	found := false
+	for _, link := range links {
+		if link.ID == linkID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		http.Error(w, "Relación no encontrada", http.StatusNotFound)
+		return
+	}
+
+	if err := h.Store.DeleteTicketLink(linkID); err != nil {
+		http.Error(w, "Error al eliminar relación", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResolveIncident resuelve un ticket padre (problema/incidente) y, si se pide, todos sus
+// tickets hijos abiertos, enviando a cada uno la misma respuesta.
+// Formato de URL: /api/tickets/:id/resolve
+func (h *TicketHandler) ResolveIncident(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 5 {
+		http.Error(w, "ID de ticket inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req models.ResolveIncidentRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer la solicitud", http.StatusBadRequest)
+		return
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "resolved"
+	}
+	if status != "resolved" && status != "closed" {
+		http.Error(w, fmt.Sprintf("Estado inválido: %s", status), http.StatusBadRequest)
+		return
+	}
+
+	parent, err := h.resolveTicket(parts[len(parts)-2])
+	if err != nil {
+		http.Error(w, "Ticket no encontrado", http.StatusNotFound)
+		return
+	}
+
+	tickets := []models.Ticket{*parent}
+	if req.ResolveChildren {
+		links, err := h.ticketLinks(parent.ID)
+		if err != nil {
+			http.Error(w, "Error al obtener relaciones del ticket", http.StatusInternalServerError)
+			return
+		}
+		for _, link := range links {
+			if link.Type != models.TicketLinkParentOf {
+				continue
+			}
+			child, err := h.Store.GetTicket(link.LinkedTicketID)
+			if err != nil || child.MergedInto != "" || child.Status == "resolved" || child.Status == "closed" {
+				continue
+			}
+			tickets = append(tickets, *child)
+		}
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	content := strings.TrimSpace(req.Message)
+
+	// Enviar la respuesta a cada ticket; los que fallen no cambian de estado
+	ticketIDs := make([]string, 0, len(tickets))
+	failed := make([]models.BulkTicketResult, 0)
+	for _, ticket := range tickets {
+		if content != "" {
+			now := time.Now()
+			message := models.Message{
+				ID:        utils.GenerateMessageID(),
+				Content:   content,
+				IsClient:  false,
+				Timestamp: now,
+				CreatedAt: now,
+				UserID:    userID,
+			}
+			if _, err := h.addTicketMessage(ticket.ID, message); err != nil {
+				failed = append(failed, models.BulkTicketResult{TicketID: ticket.ID, Error: "Error al enviar la respuesta: " + err.Error()})
+				continue
+			}
+		}
+		ticketIDs = append(ticketIDs, ticket.ID)
+	}
+
+	activity := models.Activity{
+		UserID:      userID,
+		Type:        models.ActivityTicketIncidentResolved,
+		Description: fmt.Sprintf("Estado %s aplicado al resolver el incidente %s", status, parent.ID),
+		Metadata:    map[string]any{"parentId": parent.ID, "status": status},
+	}
+	results, err := h.Store.BulkUpdateTickets(ticketIDs, models.BulkTicketChanges{Status: status}, activity, false)
+	if err != nil {
+		http.Error(w, "Error al resolver el incidente", http.StatusInternalServerError)
+		return
+	}
+	results = append(results, failed...)
+
+	response := models.BulkTicketResponse{Matched: len(tickets), Results: results}
+	for _, result := range results {
+		if result.Success {
+			response.Updated++
+		} else {
+			response.Failed++
+		}
+	}
+
+	// Notificar a los visitantes del widget los cambios de estado aplicados
+	updated := make(map[string]bool, len(results))
+	for _, result := range results {
+		updated[result.TicketID] = result.Success
+	}
+	for _, ticket := range tickets {
+		if ticket.Source == "widget" && updated[ticket.ID] && ticket.Status != status {
+			h.Events.PublishStatus(ticket.ID, status)
+		}
+		if updated[ticket.ID] {
+			h.Surveys.StatusChanged(ticket.ID, ticket.Status, status)
+			changed := ticket
+			changed.Status = status
+			h.Watchers.TicketUpdated(ticket, changed, userID)
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
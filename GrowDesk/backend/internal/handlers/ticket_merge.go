@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// maxMergeRedirects limita los saltos al seguir redirecciones de tickets fusionados
+const maxMergeRedirects = 10
+
+// resolveTicket obtiene un ticket siguiendo las redirecciones de fusión, de modo que
+// los IDs de tickets fusionados sigan resolviendo al ticket destino
+func (h *TicketHandler) resolveTicket(ticketID string) (*models.Ticket, error) {
+	ticket, err := h.Store.GetTicket(ticketID)
+	for hops := 0; err == nil && ticket.MergedInto != ""; hops++ {
+		if hops == maxMergeRedirects {
+			return nil, fmt.Errorf("demasiadas redirecciones desde el ticket %s", ticketID)
+		}
+		ticket, err = h.Store.GetTicket(ticket.MergedInto)
+	}
+	return ticket, err
+}
+
+// MergeTickets fusiona los tickets origen en el ticket de la URL.
+// Formato de URL: /api/tickets/:id/merge
+func (h *TicketHandler) MergeTickets(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 5 {
+		http.Error(w, "ID de ticket inválido", http.StatusBadRequest)
+		return
+	}
+	targetID := parts[len(parts)-2]
+
+	var req models.MergeTicketsRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer tickets a fusionar", http.StatusBadRequest)
+		return
+	}
+
+	sourceIDs := uniqueTicketIDs(req.SourceIDs)
+	if len(sourceIDs) == 0 {
+		http.Error(w, "Debe indicar al menos un ticket a fusionar", http.StatusBadRequest)
+		return
+	}
+	if len(sourceIDs) > maxBulkTickets {
+		http.Error(w, "Demasiados tickets en una sola operación", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.Store.GetTicket(targetID); err != nil {
+		http.Error(w, "Ticket no encontrado", http.StatusNotFound)
+		return
+	}
+
+	// Los visitantes del widget de los tickets origen deben pasar al ticket destino
+	widgetSources := make([]string, 0)
+	for _, id := range sourceIDs {
+		source, err := h.Store.GetTicket(id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Ticket no encontrado: %s", id), http.StatusNotFound)
+			return
+		}
+		if source.Source == "widget" {
+			widgetSources = append(widgetSources, id)
+		}
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	activity := models.Activity{
+		UserID:   userID,
+		Type:     models.ActivityTicketMerged,
+		Metadata: map[string]any{"targetId": targetID, "sourceIds": sourceIDs},
+	}
+
+	ticket, err := h.Store.MergeTickets(targetID, sourceIDs, activity)
+	if err != nil {
+		http.Error(w, "Error al fusionar tickets: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, id := range widgetSources {
+		h.Events.PublishMerged(id, targetID)
+	}
+
+	// Los seguidores de los orígenes ya siguen el destino
+	h.Watchers.Notify(targetID, userID, fmt.Sprintf("Tickets %s fusionados en el ticket %s - %s",
+		strings.Join(sourceIDs, ", "), targetID, ticket.Title))
+
+	utils.WriteJSON(w, http.StatusOK, ticket)
+}
+
+// SplitTicket mueve los mensajes indicados del ticket de la URL a un ticket nuevo.
+// Formato de URL: /api/tickets/:id/split
+func (h *TicketHandler) SplitTicket(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 5 {
+		http.Error(w, "ID de ticket inválido", http.StatusBadRequest)
+		return
+	}
+	sourceID := parts[len(parts)-2]
+
+	var req models.SplitTicketRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "Error al leer mensajes a mover", http.StatusBadRequest)
+		return
+	}
+
+	messageIDs := uniqueTicketIDs(req.MessageIDs)
+	if len(messageIDs) == 0 {
+		http.Error(w, "Debe indicar al menos un mensaje", http.StatusBadRequest)
+		return
+	}
+
+	source, err := h.Store.GetTicket(sourceID)
+	if err != nil {
+		http.Error(w, "Ticket no encontrado", http.StatusNotFound)
+		return
+	}
+
+	// El ticket nuevo conserva el cliente, origen y clasificación del original
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = source.Title + " (separado)"
+	}
+	newTicket := models.Ticket{
+		Title:        title,
+		Subject:      title,
+		Status:       "open",
+		Priority:     source.Priority,
+		Category:     source.Category,
+		CategoryID:   source.CategoryID,
+		AssignedTo:   source.AssignedTo,
+		CreatedBy:    source.CreatedBy,
+		UserID:       source.UserID,
+		Description:  source.Description,
+		Customer:     source.Customer,
+		Source:       source.Source,
+		WidgetID:     source.WidgetID,
+		Department:   source.Department,
+		Metadata:     source.Metadata,
+		CustomFields: source.CustomFields,
+
+		AssigneeGroup: source.AssigneeGroup,
+		SLAPolicy:     source.SLAPolicy,
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	activity := models.Activity{
+		UserID:   userID,
+		Type:     models.ActivityTicketSplit,
+		Metadata: map[string]any{"sourceId": sourceID, "messageIds": messageIDs},
+	}
+
+	ticket, err := h.Store.SplitTicket(sourceID, messageIDs, newTicket, activity)
+	if err != nil {
+		http.Error(w, "Error al separar ticket: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, ticket)
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// GetTicketWatchers devuelve los seguidores de un ticket.
+// Formato de URL: /api/tickets/:id/watchers
+func (h *TicketHandler) GetTicketWatchers(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 5 {
+		http.Error(w, "ID de ticket inválido", http.StatusBadRequest)
+		return
+	}
+
+	ticket, err := h.resolveTicket(parts[len(parts)-2])
+	if err != nil {
+		http.Error(w, "Ticket no encontrado", http.StatusNotFound)
+		return
+	}
+
+	watchers, err := h.Store.GetTicketWatchers(ticket.ID)
+	if err != nil {
+		http.Error(w, "Error al obtener seguidores del ticket", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, watchers)
+}
+
+// AddTicketWatchers añade seguidores a un ticket; sin userIds, el usuario actual pasa a seguirlo.
+// Formato de URL: /api/tickets/:id/watchers
+func (h *TicketHandler) AddTicketWatchers(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 5 {
+		http.Error(w, "ID de ticket inválido", http.StatusBadRequest)
+		return
+	}
+
+	var req models.TicketWatchersRequest
+	if r.ContentLength != 0 {
+		if err := utils.DecodeJSON(r, &req); err != nil {
+			http.Error(w, "Error al leer seguidores", http.StatusBadRequest)
+			return
+		}
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	userIDs := uniqueTicketIDs(req.UserIDs)
+	if len(userIDs) == 0 {
+		if userID == "" {
+			http.Error(w, "Debe indicar al menos un usuario", http.StatusBadRequest)
+			return
+		}
+		userIDs = []string{userID}
+	}
+
+	// Solo los agentes activos pueden seguir tickets
+	for _, id := range userIDs {
+		user, err := h.Store.GetUser(id)
+		if err != nil || !user.Active || user.Role == "customer" {
+			http.Error(w, fmt.Sprintf("Agente no encontrado: %s", id), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ticket, err := h.resolveTicket(parts[len(parts)-2])
+	if err != nil {
+		http.Error(w, "Ticket no encontrado", http.StatusNotFound)
+		return
+	}
+
+	if _, err := h.Store.AddTicketWatchers(ticket.ID, userIDs, userID); err != nil {
+		http.Error(w, "Error al añadir seguidores: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	watchers, err := h.Store.GetTicketWatchers(ticket.ID)
+	if err != nil {
+		http.Error(w, "Error al obtener seguidores del ticket", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, watchers)
+}
+
+// RemoveTicketWatcher quita un seguidor de un ticket. Cada usuario puede dejar de seguir
+// un ticket ("me" o su propio ID); quitar a otro usuario requiere rol de administrador.
+// Formato de URL: /api/tickets/:id/watchers/:userId
+func (h *TicketHandler) RemoveTicketWatcher(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes DELETE
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 6 {
+		http.Error(w, "URL de seguidor inválida", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	watcherID := parts[len(parts)-1]
+	if watcherID == "me" {
+		watcherID = userID
+	}
+	if watcherID != userID {
+		role, ok := r.Context().Value(middleware.RoleKey).(string)
+		if !ok || role != "admin" {
+			http.Error(w, "No autorizado", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	ticket, err := h.resolveTicket(parts[len(parts)-3])
+	if err != nil {
+		http.Error(w, "Ticket no encontrado", http.StatusNotFound)
+		return
+	}
+
+	if err := h.Store.RemoveTicketWatcher(ticket.ID, watcherID); err != nil {
+		http.Error(w, "Seguidor no encontrado", http.StatusNotFound)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// GetWatchedTickets devuelve los tickets que sigue el usuario actual. Admite los mismos
+// filtros y orden que el listado de tickets.
+// Formato de URL: /api/tickets/watching
+func (h *TicketHandler) GetWatchedTickets(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	if userID == "" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	defs, err := h.Store.GetCustomFieldDefinitions()
+	if err != nil {
+		http.Error(w, "Error al obtener campos personalizados", http.StatusInternalServerError)
+		return
+	}
+	filter, err := parseTicketFilter(r.URL.Query(), defs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.WatchedBy = userID
+
+	tickets, err := h.Store.QueryTickets(filter)
+	if err != nil {
+		http.Error(w, "Error al obtener tickets", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, tickets)
+}
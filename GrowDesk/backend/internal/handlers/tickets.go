@@ -10,15 +10,29 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/automation"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/availability"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/categories"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/csat"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/customfields"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/events"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/tags"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/teams"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/watchers"
 )
 
 // TicketHandler contiene manejadores para operaciones de tickets
 type TicketHandler struct {
-	Store data.DataStore
+	Store        data.DataStore
+	Events       *events.Publisher     // Envía respuestas y cambios de estado al widget (opcional)
+	Availability *availability.Service // Determina si hay chat en vivo para tickets del widget
+	Automation   *automation.Engine    // Evalúa las reglas de automatización (opcional)
+	Surveys      *csat.Service         // Envía encuestas de satisfacción al resolver (opcional)
+	Watchers     *watchers.Service     // Notifica a los seguidores y procesa las menciones (opcional)
 }
 
 // GetAllTickets maneja la obtención de todos los tickets
@@ -32,13 +46,32 @@ func (h *TicketHandler) GetAllTickets(w http.ResponseWriter, r *http.Request) {
 	// Establecer CORS
 	utils.SetCORS(w)
 
+	// Construir filtros y orden a partir de la consulta
+	defs, err := h.Store.GetCustomFieldDefinitions()
+	if err != nil {
+		http.Error(w, "Error al obtener campos personalizados", http.StatusInternalServerError)
+		return
+	}
+	filter, err := parseTicketFilter(r.URL.Query(), defs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Obtener tickets del almacén
-	tickets, err := h.Store.GetTickets()
+	tickets, err := h.Store.QueryTickets(filter)
 	if err != nil {
 		http.Error(w, "Error al obtener tickets", http.StatusInternalServerError)
 		return
 	}
 
+	// Los clientes no ven notas internas ni eventos del sistema
+	if audience := messageAudience(r); audience != models.MessageAudienceAgent {
+		for i := range tickets {
+			tickets[i].Messages = models.FilterMessages(tickets[i].Messages, audience)
+		}
+	}
+
 	// Devolver tickets como JSON
 	utils.WriteJSON(w, http.StatusOK, tickets)
 }
@@ -61,13 +94,26 @@ func (h *TicketHandler) GetTicket(w http.ResponseWriter, r *http.Request) {
 
 	ticketID := parts[len(parts)-1]
 
-	// Obtener el ticket
-	ticket, err := h.Store.GetTicket(ticketID)
+	// Obtener el ticket (los tickets fusionados resuelven a su destino)
+	ticket, err := h.resolveTicket(ticketID)
 	if err != nil {
 		http.Error(w, "Ticket no encontrado", http.StatusNotFound)
 		return
 	}
 
+	// Incluir las relaciones con otros tickets y los seguidores
+	ticket.Links, err = h.ticketLinks(ticket.ID)
+	if err != nil {
+		http.Error(w, "Error al obtener relaciones del ticket", http.StatusInternalServerError)
+		return
+	}
+	ticket.Watchers, err = h.Store.GetTicketWatchers(ticket.ID)
+	if err != nil {
+		http.Error(w, "Error al obtener seguidores del ticket", http.StatusInternalServerError)
+		return
+	}
+	ticket.Messages = models.FilterMessages(ticket.Messages, messageAudience(r))
+
 	// Devolver el ticket
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(ticket)
@@ -104,6 +150,34 @@ func (h *TicketHandler) CreateTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// La categoría debe existir y no estar archivada
+	allCategories, err := h.Store.GetCategories()
+	if err != nil {
+		http.Error(w, "Error al obtener categorías", http.StatusInternalServerError)
+		return
+	}
+	category, err := h.Store.GetCategory(ticketReq.CategoryID)
+	if err != nil {
+		http.Error(w, "Categoría no encontrada", http.StatusBadRequest)
+		return
+	}
+	if !category.Active {
+		http.Error(w, "La categoría está archivada", http.StatusBadRequest)
+		return
+	}
+
+	// Validar campos personalizados según la categoría
+	defs, err := h.Store.GetCustomFieldDefinitions()
+	if err != nil {
+		http.Error(w, "Error al obtener campos personalizados", http.StatusInternalServerError)
+		return
+	}
+	customFields, err := customfields.Validate(defs, ticketReq.CategoryID, ticketReq.CustomFields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Crear mensaje inicial
 	initialMessage := models.Message{
 		ID:        uuid.New().String(),
@@ -128,14 +202,41 @@ func (h *TicketHandler) CreateTicket(w http.ResponseWriter, r *http.Request) {
 		UpdatedAt:   time.Now(),
 		Messages:    []models.Message{initialMessage},
 		Metadata:    ticketReq.Metadata,
+
+		CustomFields: customFields,
 	}
 
+	// Departamento, prioridad, grupo y SLA por defecto de la categoría
+	categories.Apply(&newTicket, categories.Defaults(allCategories, category.ID))
+
+	// El departamento debe ser un equipo activo; su cola aporta la prioridad por defecto
+	allTeams, err := h.Store.GetTeams()
+	if err != nil {
+		http.Error(w, "Error al obtener equipos", http.StatusInternalServerError)
+		return
+	}
+	if newTicket.Department != "" {
+		teamID, err := teams.Resolve(allTeams, newTicket.Department)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		newTicket.Department = teamID
+	}
+	teams.Apply(allTeams, &newTicket)
+
 	// Agregar ticket al almacén
 	if err := h.Store.CreateTicket(newTicket); err != nil {
 		http.Error(w, "Error al crear ticket", http.StatusInternalServerError)
 		return
 	}
 
+	// Aplicar reglas de automatización; pueden cambiar el ticket recién creado
+	h.Automation.Run(models.AutomationEventTicketCreated, newTicket.ID, &initialMessage)
+	if created, err := h.Store.GetTicket(newTicket.ID); err == nil {
+		newTicket = *created
+	}
+
 	// Devolver ticket creado
 	utils.WriteJSON(w, http.StatusCreated, newTicket)
 }
@@ -161,8 +262,8 @@ func (h *TicketHandler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 
 	ticketID := segments[3]
 
-	// Obtener el ticket existente
-	ticket, err := h.Store.GetTicket(ticketID)
+	// Obtener el ticket existente (los tickets fusionados resuelven a su destino)
+	ticket, err := h.resolveTicket(ticketID)
 	if err != nil {
 		http.Error(w, "Ticket no encontrado", http.StatusNotFound)
 		return
@@ -175,6 +276,52 @@ func (h *TicketHandler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Validar campos personalizados antes de modificar el ticket
+	updatedCustomFields := ticket.CustomFields
+	if updates.CustomFields != nil {
+		defs, err := h.Store.GetCustomFieldDefinitions()
+		if err != nil {
+			http.Error(w, "Error al obtener campos personalizados", http.StatusInternalServerError)
+			return
+		}
+		customFields, err := customfields.Merge(defs, ticket.CategoryID, ticket.CustomFields, updates.CustomFields)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		updatedCustomFields = customFields
+	}
+
+	// El departamento debe ser un equipo activo
+	allTeams, err := h.Store.GetTeams()
+	if err != nil {
+		http.Error(w, "Error al obtener equipos", http.StatusInternalServerError)
+		return
+	}
+	if updates.Department != "" {
+		teamID, err := teams.Resolve(allTeams, updates.Department)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		updates.Department = teamID
+	}
+
+	// Asignar el ticket a otro agente o transferirlo a otro equipo requiere ser
+	// administrador o responsable del equipo del ticket
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	reassigning := updates.AssignedTo != "" && !teams.CanAssign(allTeams, role, userID, *ticket, updates.AssignedTo)
+	transferring := updates.Department != "" && updates.Department != teams.Key(ticket.Department) &&
+		!teams.CanManage(allTeams, role, userID, ticket.Department)
+	if reassigning || transferring {
+		http.Error(w, "No autorizado para gestionar los tickets de este equipo", http.StatusUnauthorized)
+		return
+	}
+
+	previous := *ticket
+	previousStatus := ticket.Status
+
 	// Actualizar los campos del ticket
 	if updates.Status != "" {
 		ticket.Status = updates.Status
@@ -194,6 +341,7 @@ func (h *TicketHandler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 	if updates.Subject != "" {
 		ticket.Subject = updates.Subject
 	}
+	ticket.CustomFields = updatedCustomFields
 
 	// Actualizar timestamp
 	ticket.UpdatedAt = time.Now()
@@ -204,11 +352,27 @@ func (h *TicketHandler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Notificar al visitante del widget si cambió el estado
+	if ticket.Source == "widget" && ticket.Status != previousStatus {
+		h.Events.PublishStatus(ticket.ID, ticket.Status)
+	}
+	h.Surveys.StatusChanged(ticket.ID, previousStatus, ticket.Status)
+
+	// Notificar a los seguidores del ticket
+	h.Watchers.TicketUpdated(previous, *ticket, userID)
+
+	// Aplicar reglas de automatización y devolver el ticket con sus cambios
+	h.Automation.Run(models.AutomationEventTicketUpdated, ticket.ID, nil)
+	if updated, err := h.Store.GetTicket(ticket.ID); err == nil {
+		ticket = updated
+	}
+
 	// Devolver ticket actualizado
 	utils.WriteJSON(w, http.StatusOK, ticket)
 }
 
-// GetTicketMessages devuelve mensajes para un ticket específico
+// GetTicketMessages devuelve mensajes para un ticket específico. Los clientes y el
+// widget solo reciben las respuestas públicas.
 func (h *TicketHandler) GetTicketMessages(w http.ResponseWriter, r *http.Request) {
 	// Solo maneja solicitudes GET
 	if r.Method != http.MethodGet {
@@ -227,16 +391,48 @@ func (h *TicketHandler) GetTicketMessages(w http.ResponseWriter, r *http.Request
 	// Obtener el ID desde la URL (asumiendo formato /tickets/ID/messages)
 	ticketID := parts[len(parts)-2]
 
-	// Obtener el ticket
-	ticket, err := h.Store.GetTicket(ticketID)
+	// Obtener el ticket (los tickets fusionados resuelven a su destino)
+	ticket, err := h.resolveTicket(ticketID)
 	if err != nil {
 		http.Error(w, "Ticket no encontrado", http.StatusNotFound)
 		return
 	}
 
+	messages, err := h.Store.GetTicketMessages(ticket.ID, messageAudience(r))
+	if err != nil {
+		http.Error(w, "Error al obtener mensajes del ticket", http.StatusInternalServerError)
+		return
+	}
+
 	// Devolver los mensajes
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(ticket.Messages)
+	json.NewEncoder(w).Encode(messages)
+}
+
+// messageAudience indica qué mensajes puede ver quien hace la solicitud. Las rutas
+// públicas del widget no tienen rol y se tratan como cliente.
+func messageAudience(r *http.Request) string {
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	if role == "" || role == models.MessageAudienceCustomer {
+		return models.MessageAudienceCustomer
+	}
+	return models.MessageAudienceAgent
+}
+
+// messageVisibility determina la visibilidad de un mensaje nuevo. Los agentes solo
+// pueden crear respuestas públicas o notas internas; los eventos del sistema los
+// genera el servidor.
+func messageVisibility(visibility string, isInternal bool) (string, error) {
+	switch visibility {
+	case "":
+		if isInternal {
+			return models.MessageVisibilityInternal, nil
+		}
+		return models.MessageVisibilityPublic, nil
+	case models.MessageVisibilityPublic, models.MessageVisibilityInternal:
+		return visibility, nil
+	}
+	return "", fmt.Errorf("Visibilidad de mensaje inválida: %s", visibility)
 }
 
 // AddTicketMessage agrega un mensaje a un ticket
@@ -270,26 +466,35 @@ func (h *TicketHandler) AddTicketMessage(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "El contenido del mensaje es requerido", http.StatusBadRequest)
 		return
 	}
+	visibility, err := messageVisibility(messageReq.Visibility, messageReq.IsInternal)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Crear nuevo mensaje
 	message := models.Message{
-		ID:        utils.GenerateMessageID(),
-		Content:   messageReq.Content,
-		IsClient:  messageReq.IsClient,
-		Timestamp: time.Now(),
-		CreatedAt: time.Now(),
-		UserName:  messageReq.UserName,
-		UserEmail: messageReq.UserEmail,
+		ID:         utils.GenerateMessageID(),
+		Content:    messageReq.Content,
+		IsClient:   messageReq.IsClient,
+		IsInternal: visibility != models.MessageVisibilityPublic,
+		Visibility: visibility,
+		Timestamp:  time.Now(),
+		CreatedAt:  time.Now(),
+		UserID:     messageReq.UserID,
+		UserName:   messageReq.UserName,
+		UserEmail:  messageReq.UserEmail,
 	}
 
 	// Agregar mensaje al ticket
-	if err := h.Store.AddTicketMessage(ticketID, message); err != nil {
+	ticketID, err = h.addTicketMessage(ticketID, message)
+	if err != nil {
 		http.Error(w, "Failed to add message: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Broadcast a los clientes WebSocket
-	h.Store.BroadcastMessage(ticketID, message)
+	// Aplicar reglas de automatización
+	h.Automation.Run(models.AutomationEventMessageAdded, ticketID, &message)
 
 	// Devolver respuesta de éxito
 	response := struct {
@@ -307,6 +512,104 @@ func (h *TicketHandler) AddTicketMessage(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(response)
 }
 
+// addTicketMessage agrega un mensaje a un ticket, lo difunde a los clientes WebSocket y
+// lo envía al visitante del widget. Los mensajes enviados a un ticket fusionado se
+// agregan al ticket destino, cuyo ID se devuelve.
+func (h *TicketHandler) addTicketMessage(ticketID string, message models.Message) (string, error) {
+	ticketID, err := h.storeTicketMessage(ticketID, message)
+	if err != nil {
+		return ticketID, err
+	}
+
+	// Enviar la respuesta del agente al visitante del widget
+	if ticket, err := h.Store.GetTicket(ticketID); err == nil && ticket.Source == "widget" {
+		h.Events.PublishMessage(ticketID, message)
+	}
+
+	return ticketID, nil
+}
+
+// storeTicketMessage agrega un mensaje a un ticket (o a su destino si fue fusionado) y lo
+// difunde a los clientes WebSocket, sin enviarlo al widget. Devuelve el ID del ticket.
+func (h *TicketHandler) storeTicketMessage(ticketID string, message models.Message) (string, error) {
+	if ticket, err := h.resolveTicket(ticketID); err == nil {
+		ticketID = ticket.ID
+	}
+
+	if err := h.Store.AddTicketMessage(ticketID, message); err != nil {
+		return ticketID, err
+	}
+
+	// Broadcast a los clientes WebSocket
+	h.Store.BroadcastMessage(ticketID, message)
+
+	// Notificar a los seguidores y a los agentes mencionados
+	h.Watchers.MessageAdded(ticketID, message)
+
+	return ticketID, nil
+}
+
+// AddWidgetTicketMessage agrega un mensaje recibido de la API del widget. El mensaje ya
+// está en la conversación del widget, por lo que no se le reenvía.
+// Formato de URL: /widget/tickets/:id/messages
+func (h *TicketHandler) AddWidgetTicketMessage(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 4 {
+		http.Error(w, "ID de ticket inválido", http.StatusBadRequest)
+		return
+	}
+	ticketID := parts[len(parts)-2]
+
+	ticket, err := h.resolveTicket(ticketID)
+	if err != nil || ticket.Source != "widget" {
+		http.Error(w, "Ticket no encontrado", http.StatusNotFound)
+		return
+	}
+
+	var messageReq models.NewMessageRequest
+	if err := utils.DecodeJSON(r, &messageReq); err != nil {
+		http.Error(w, "El cuerpo de la solicitud es inválido", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(messageReq.Content) == "" {
+		http.Error(w, "El contenido del mensaje es requerido", http.StatusBadRequest)
+		return
+	}
+
+	// Las notas internas no pueden llegar desde el widget
+	now := time.Now()
+	message := models.Message{
+		ID:        utils.GenerateMessageID(),
+		Content:   messageReq.Content,
+		IsClient:  messageReq.IsClient,
+		Timestamp: now,
+		CreatedAt: now,
+		UserID:    messageReq.UserID,
+		UserName:  messageReq.UserName,
+		UserEmail: messageReq.UserEmail,
+	}
+
+	ticketID, err = h.storeTicketMessage(ticket.ID, message)
+	if err != nil {
+		http.Error(w, "Error al agregar el mensaje: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Aplicar reglas de automatización
+	h.Automation.Run(models.AutomationEventMessageAdded, ticketID, &message)
+
+	utils.WriteJSON(w, http.StatusCreated, message)
+}
+
 // CreateWidgetTicket crea un nuevo ticket desde el widget
 func (h *TicketHandler) CreateWidgetTicket(w http.ResponseWriter, r *http.Request) {
 	// Solo maneja solicitudes POST
@@ -322,21 +625,25 @@ func (h *TicketHandler) CreateWidgetTicket(w http.ResponseWriter, r *http.Reques
 
 	// Estructura para recibir el formato específico del widget
 	var widgetRequest struct {
-		ID          string                 `json:"id"`
-		Title       string                 `json:"title"`
-		Subject     string                 `json:"subject"`
-		Description string                 `json:"description"`
-		Status      string                 `json:"status"`
-		Priority    string                 `json:"priority"`
-		Email       string                 `json:"email"`
-		Name        string                 `json:"name"`
-		ClientName  string                 `json:"clientName"`
-		ClientEmail string                 `json:"clientEmail"`
-		Department  string                 `json:"department"`
-		Source      string                 `json:"source"`
-		WidgetID    string                 `json:"widgetId"`
-		CreatedAt   string                 `json:"createdAt"`
-		Metadata    map[string]interface{} `json:"metadata"`
+		ID           string                 `json:"id"`
+		Title        string                 `json:"title"`
+		Subject      string                 `json:"subject"`
+		Description  string                 `json:"description"`
+		Status       string                 `json:"status"`
+		Priority     string                 `json:"priority"`
+		Email        string                 `json:"email"`
+		Name         string                 `json:"name"`
+		ClientName   string                 `json:"clientName"`
+		ClientEmail  string                 `json:"clientEmail"`
+		Department   string                 `json:"department"`
+		Category     string                 `json:"category"`
+		Source       string                 `json:"source"`
+		WidgetID     string                 `json:"widgetId"`
+		CreatedAt    string                 `json:"createdAt"`
+		Metadata     map[string]interface{} `json:"metadata"`
+		CustomFields map[string]interface{} `json:"customFields"`
+		Tags         []string               `json:"tags"`
+		FAQSessionID string                 `json:"faqSessionId"` // Sesión con la que el visitante vio FAQs
 	}
 
 	// Intentar decodificar primero con el formato del widget
@@ -353,6 +660,40 @@ func (h *TicketHandler) CreateWidgetTicket(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// La categoría elegida aporta departamento, prioridad, grupo y SLA por defecto
+	var categoryDefaults models.CategoryDefaults
+	if widgetRequest.Category != "" {
+		if allCategories, err := h.Store.GetCategories(); err == nil {
+			categoryDefaults = categories.Defaults(allCategories, widgetRequest.Category)
+		}
+	}
+	if widgetRequest.Department == "" {
+		widgetRequest.Department = categoryDefaults.Department
+	}
+	if widgetRequest.Priority == "" {
+		widgetRequest.Priority = categoryDefaults.Priority
+	}
+
+	// El departamento debe ser un equipo activo; si no, el ticket va al equipo por
+	// defecto. La cola del equipo aporta su prioridad por defecto.
+	if widgetRequest.Department == "" {
+		widgetRequest.Department = teams.DefaultTeamID
+	}
+	allTeams, err := h.Store.GetTeams()
+	if err != nil {
+		http.Error(w, "Error al obtener equipos", http.StatusInternalServerError)
+		return
+	}
+	teamID, err := teams.Resolve(allTeams, widgetRequest.Department)
+	if err != nil {
+		fmt.Printf("Departamento del widget no válido (%v), se usa %s\n", err, teams.DefaultTeamID)
+		teamID = teams.DefaultTeamID
+	}
+	widgetRequest.Department = teamID
+	if team := teams.Find(allTeams, teamID); team != nil && widgetRequest.Priority == "" {
+		widgetRequest.Priority = team.Queue.DefaultPriority
+	}
+
 	// Establecer valores por defecto si están vacíos
 	if widgetRequest.Status == "" {
 		widgetRequest.Status = "open"
@@ -362,10 +703,6 @@ func (h *TicketHandler) CreateWidgetTicket(w http.ResponseWriter, r *http.Reques
 		widgetRequest.Priority = "medium"
 	}
 
-	if widgetRequest.Department == "" {
-		widgetRequest.Department = "soporte"
-	}
-
 	if widgetRequest.Source == "" {
 		widgetRequest.Source = "widget"
 	}
@@ -430,6 +767,34 @@ func (h *TicketHandler) CreateWidgetTicket(w http.ResponseWriter, r *http.Reques
 		UserEmail: email,
 	}
 
+	messages := []models.Message{initialMessage}
+
+	// Determinar si hay chat en vivo; si no, responder automáticamente con el tiempo estimado
+	status := h.Availability.Status(widgetRequest.WidgetID, widgetRequest.Department, now)
+	var autoReply *models.Message
+	if !status.LiveChatAvailable {
+		autoReply = &models.Message{
+			ID:        utils.GenerateMessageID(),
+			Content:   availability.AutoReply(status),
+			IsClient:  false,
+			Timestamp: now,
+			CreatedAt: now,
+			UserName:  "GrowDesk",
+		}
+		messages = append(messages, *autoReply)
+	}
+
+	// La categoría elegida en el formulario puede ser un ID existente o un nombre libre
+	category := widgetRequest.Department
+	categoryID := ""
+	if widgetRequest.Category != "" {
+		category = widgetRequest.Category
+		if existingCategory, err := h.Store.GetCategory(widgetRequest.Category); err == nil {
+			category = existingCategory.Name
+			categoryID = existingCategory.ID
+		}
+	}
+
 	// Verificar si existe un usuario con este email para evitar violar la restricción foreign key
 	var userID string = ""
 	existingUser, userErr := h.Store.GetUserByEmail(email)
@@ -458,7 +823,8 @@ func (h *TicketHandler) CreateWidgetTicket(w http.ResponseWriter, r *http.Reques
 		UpdatedAt:   now,
 		Description: widgetRequest.Description,
 		Priority:    widgetRequest.Priority,
-		Category:    widgetRequest.Department,
+		Category:    category,
+		CategoryID:  categoryID,
 		Department:  widgetRequest.Department,
 		// No usar CreatedBy como referencia, usar UserID para la referencia de clave foránea
 		UserID:   userID,
@@ -468,14 +834,19 @@ func (h *TicketHandler) CreateWidgetTicket(w http.ResponseWriter, r *http.Reques
 			Name:  name,
 			Email: email,
 		},
-		Messages: []models.Message{initialMessage},
-		Metadata: ticketMetadata,
+		Messages:     messages,
+		Metadata:     ticketMetadata,
+		CustomFields: widgetRequest.CustomFields,
+		Tags:         tags.NormalizeAll(widgetRequest.Tags),
+
+		AssigneeGroup: categoryDefaults.AssigneeGroup,
+		SLAPolicy:     categoryDefaults.SLAPolicy,
 	}
 
 	fmt.Printf("Intentando guardar ticket en base de datos: %+v\n", ticket)
 
 	// Almacenar en la base de datos
-	err := h.Store.CreateTicket(ticket)
+	err = h.Store.CreateTicket(ticket)
 	if err != nil {
 		fmt.Printf("ERROR AL GUARDAR TICKET EN LA BASE DE DATOS: %v\n", err)
 		fmt.Printf("Detalles del ticket que no se pudo guardar: ID=%s, Title=%s\n", ticket.ID, ticket.Title)
@@ -495,6 +866,20 @@ func (h *TicketHandler) CreateWidgetTicket(w http.ResponseWriter, r *http.Reques
 	// Confirmar la creación y loguear para depuración
 	fmt.Printf("Ticket %s guardado correctamente en la base de datos\n", ticketID)
 
+	// Aplicar reglas de automatización (p. ej. enrutamiento por departamento o widget)
+	h.Automation.Run(models.AutomationEventTicketCreated, ticketID, &initialMessage)
+
+	// Las FAQs vistas en la sesión no evitaron el ticket
+	faqSessionID := widgetRequest.FAQSessionID
+	if faqSessionID == "" && widgetRequest.Metadata != nil {
+		faqSessionID = utils.GetStringFromMap(widgetRequest.Metadata, "faqSessionId")
+	}
+	if faqSessionID != "" {
+		if err := h.Store.LinkFAQViewsToTicket(faqSessionID, ticketID); err != nil {
+			fmt.Printf("Error al asociar las FAQs vistas al ticket %s: %v\n", ticketID, err)
+		}
+	}
+
 	// Verificar que el ticket se guardó correctamente
 	verifiedTicket, verifyErr := h.Store.GetTicket(ticketID)
 	if verifyErr != nil {
@@ -508,9 +893,16 @@ func (h *TicketHandler) CreateWidgetTicket(w http.ResponseWriter, r *http.Reques
 		"success":           true,
 		"ticketId":          ticketID,
 		"id":                ticketID,
-		"liveChatAvailable": true,
+		"liveChatAvailable": status.LiveChatAvailable,
+		"offlineMode":       status.OfflineMode,
 		"message":           "Ticket creado correctamente",
 	}
+	// La API del widget guarda la respuesta automática con el mismo ID
+	if autoReply != nil {
+		response["autoReply"] = autoReply.Content
+		response["autoReplyMessageId"] = autoReply.ID
+		response["expectedResponseTime"] = status.ExpectedResponseTime
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
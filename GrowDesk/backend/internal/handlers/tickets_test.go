@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// testTicketID es el ticket que crea newTicketTestHandler
+const testTicketID = "TICKET-TEST-1"
+
+// Mensajes del ticket de prueba según quién los lee
+var (
+	customerMessageIDs = []string{"public"}
+	agentMessageIDs    = []string{"public", "internal", "system", "legacy-note"}
+)
+
+// newTicketTestHandler crea un manejador con un almacén de archivos temporal y un ticket
+// con una respuesta pública, una nota interna, un evento del sistema y una nota anterior
+// al campo de visibilidad
+func newTicketTestHandler(t *testing.T) *TicketHandler {
+	t.Helper()
+
+	// Los archivos vacíos de usuarios y FAQs evitan que se creen los datos por defecto
+	dir := t.TempDir()
+	for _, name := range []string{"users.json", "faqs.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("[]"), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	store := data.NewStore(dir)
+	now := time.Now()
+	err := store.CreateTicket(models.Ticket{
+		ID:       testTicketID,
+		Title:    "Consulta",
+		Status:   "open",
+		Source:   "widget",
+		Customer: models.Customer{Name: "Cliente", Email: "cliente@example.com"},
+		Messages: []models.Message{
+			{ID: "public", Content: "Hola, ¿en qué podemos ayudarle?", Visibility: models.MessageVisibilityPublic, Timestamp: now},
+			{ID: "internal", Content: "Nota interna", Visibility: models.MessageVisibilityInternal, Timestamp: now},
+			{ID: "system", Content: "Ticket asignado", Visibility: models.MessageVisibilitySystem, Timestamp: now},
+			{ID: "legacy-note", Content: "Nota antigua", IsInternal: true, Timestamp: now},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTicket: %v", err)
+	}
+
+	return &TicketHandler{Store: store}
+}
+
+// requestAs crea una solicitud con el rol indicado en el contexto; sin rol simula las
+// rutas públicas del widget
+func requestAs(method, path, role string) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	if role != "" {
+		ctx := context.WithValue(req.Context(), middleware.RoleKey, role)
+		ctx = context.WithValue(ctx, middleware.UserIDKey, role+"-1")
+		req = req.WithContext(ctx)
+	}
+	return req
+}
+
+// messageIDs devuelve los IDs de los mensajes en orden
+func messageIDs(messages []models.Message) []string {
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+	return ids
+}
+
+func TestGetTicketMessagesVisibility(t *testing.T) {
+	h := newTicketTestHandler(t)
+
+	tests := []struct {
+		name string
+		path string
+		role string
+		want []string
+	}{
+		{"widget", "/widget/tickets/" + testTicketID + "/messages", "", customerMessageIDs},
+		{"cliente", "/api/tickets/" + testTicketID + "/messages", "customer", customerMessageIDs},
+		{"agente", "/api/tickets/" + testTicketID + "/messages", "agent", agentMessageIDs},
+		{"administrador", "/api/tickets/" + testTicketID + "/messages", "admin", agentMessageIDs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.GetTicketMessages(rec, requestAs(http.MethodGet, tt.path, tt.role))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("código %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var messages []models.Message
+			if err := json.Unmarshal(rec.Body.Bytes(), &messages); err != nil {
+				t.Fatalf("respuesta inválida: %v", err)
+			}
+			if got := messageIDs(messages); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mensajes %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTicketVisibility(t *testing.T) {
+	h := newTicketTestHandler(t)
+
+	tests := []struct {
+		role string
+		want []string
+	}{
+		{"customer", customerMessageIDs},
+		{"agent", agentMessageIDs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.GetTicket(rec, requestAs(http.MethodGet, "/api/tickets/"+testTicketID, tt.role))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("código %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var ticket models.Ticket
+			if err := json.Unmarshal(rec.Body.Bytes(), &ticket); err != nil {
+				t.Fatalf("respuesta inválida: %v", err)
+			}
+			if got := messageIDs(ticket.Messages); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mensajes %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAllTicketsVisibility(t *testing.T) {
+	h := newTicketTestHandler(t)
+
+	tests := []struct {
+		role string
+		want []string
+	}{
+		{"customer", customerMessageIDs},
+		{"agent", agentMessageIDs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.GetAllTickets(rec, requestAs(http.MethodGet, "/api/tickets", tt.role))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("código %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var tickets []models.Ticket
+			if err := json.Unmarshal(rec.Body.Bytes(), &tickets); err != nil {
+				t.Fatalf("respuesta inválida: %v", err)
+			}
+			if len(tickets) != 1 {
+				t.Fatalf("se recibieron %d tickets, se esperaba 1", len(tickets))
+			}
+			if got := messageIDs(tickets[0].Messages); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mensajes %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageAudience(t *testing.T) {
+	tests := []struct {
+		role string
+		want string
+	}{
+		{"", models.MessageAudienceCustomer},
+		{"customer", models.MessageAudienceCustomer},
+		{"agent", models.MessageAudienceAgent},
+		{"assistant", models.MessageAudienceAgent},
+		{"admin", models.MessageAudienceAgent},
+	}
+
+	for _, tt := range tests {
+		if got := messageAudience(requestAs(http.MethodGet, "/api/tickets", tt.role)); got != tt.want {
+			t.Errorf("messageAudience(rol %q) = %q, se esperaba %q", tt.role, got, tt.want)
+		}
+	}
+}
+
+// failingTeamsStore simula un almacén que no puede leer los equipos
+type failingTeamsStore struct {
+	data.DataStore
+}
+
+func (failingTeamsStore) GetTeams() ([]models.Team, error) {
+	return nil, errors.New("base de datos no disponible")
+}
+
+func TestCreateTicketEnforcesTeams(t *testing.T) {
+	h := newTicketTestHandler(t)
+	err := h.Store.CreateCategory(models.Category{
+		ID:       "cat-tecnologia",
+		Name:     "Tecnología",
+		Active:   true,
+		Defaults: models.CategoryDefaults{Department: "tecnologia"},
+	})
+	if err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+	if err := h.Store.CreateTeam(models.Team{ID: "tecnologia", Name: "Tecnología", Active: true}); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+
+	createTicket := func(h *TicketHandler) *httptest.ResponseRecorder {
+		body := `{"title": "Sin acceso", "description": "No puedo entrar", "categoryId": "cat-tecnologia"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/tickets", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "agent-1"))
+		rec := httptest.NewRecorder()
+		h.CreateTicket(rec, req)
+		return rec
+	}
+
+	t.Run("equipo activo", func(t *testing.T) {
+		rec := createTicket(h)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("código %d: %s", rec.Code, rec.Body.String())
+		}
+		var ticket models.Ticket
+		if err := json.Unmarshal(rec.Body.Bytes(), &ticket); err != nil {
+			t.Fatalf("respuesta inválida: %v", err)
+		}
+		if ticket.Department != "tecnologia" {
+			t.Errorf("departamento %q, se esperaba tecnologia", ticket.Department)
+		}
+	})
+
+	t.Run("error al leer los equipos", func(t *testing.T) {
+		rec := createTicket(&TicketHandler{Store: failingTeamsStore{h.Store}})
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("código %d, se esperaba %d", rec.Code, http.StatusInternalServerError)
+		}
+	})
+
+	t.Run("equipo archivado", func(t *testing.T) {
+		team, err := h.Store.GetTeam("tecnologia")
+		if err != nil {
+			t.Fatalf("GetTeam: %v", err)
+		}
+		team.Active = false
+		if err := h.Store.UpdateTeam(*team); err != nil {
+			t.Fatalf("UpdateTeam: %v", err)
+		}
+
+		rec := createTicket(h)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("código %d, se esperaba %d", rec.Code, http.StatusBadRequest)
+		}
+	})
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/middleware"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/twofactor"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// writeTwoFactorError responde a los errores del segundo factor con el estado adecuado
+func writeTwoFactorError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, twofactor.ErrInvalidCode), errors.Is(err, twofactor.ErrWrongPassword),
+		errors.Is(err, twofactor.ErrRequired):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, twofactor.ErrTooManyAttempts):
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	case errors.Is(err, twofactor.ErrAlreadyEnabled), errors.Is(err, twofactor.ErrNotEnabled),
+		errors.Is(err, twofactor.ErrNotSetUp):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Error en la autenticación en dos pasos", http.StatusInternalServerError)
+	}
+}
+
+// twoFactorUser obtiene el usuario autenticado (por sesión o por desafío)
+func (h *AuthHandler) twoFactorUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	user, err := h.Store.GetUser(userID)
+	if err != nil {
+		http.Error(w, "Usuario no encontrado", http.StatusNotFound)
+		return nil, false
+	}
+	return user, true
+}
+
+// GetTwoFactorStatus devuelve el estado del segundo factor del usuario autenticado
+func (h *AuthHandler) GetTwoFactorStatus(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	user, ok := h.twoFactorUser(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.TwoFactor.Status(*user)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, status)
+}
+
+// SetupTwoFactor genera el secreto y la URI para el código QR. Acepta una sesión o el
+// desafío de un inicio de sesión que exige configurar el segundo factor.
+func (h *AuthHandler) SetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	user, ok := h.twoFactorUser(w, r)
+	if !ok {
+		return
+	}
+
+	setup, err := h.TwoFactor.Setup(*user)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, setup)
+}
+
+// EnableTwoFactor activa el segundo factor con un código de la aplicación y devuelve los
+// códigos de recuperación. Con un desafío de inicio de sesión devuelve además la sesión.
+func (h *AuthHandler) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	user, ok := h.twoFactorUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "El cuerpo de la solicitud es inválido", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.TwoFactor.Enable(user.ID, req.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{"recoveryCodes": codes}
+
+	// Completar el inicio de sesión que quedó pendiente de configurar el segundo factor
+	if challenge, _ := r.Context().Value(middleware.ChallengeKey).(bool); challenge {
+		version, err := h.Store.GetUserSessionVersion(user.ID)
+		if err != nil {
+			http.Error(w, "Error al generar token", http.StatusInternalServerError)
+			return
+		}
+		token, err := utils.GenerateSessionToken(user.ID, user.Email, user.Role, version)
+		if err != nil {
+			http.Error(w, "Error al generar token", http.StatusInternalServerError)
+			return
+		}
+		user.Password = ""
+		response["token"] = token
+		response["user"] = user
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// VerifyTwoFactor completa el inicio de sesión con el desafío y un código de la
+// aplicación o de recuperación (público)
+func (h *AuthHandler) VerifyTwoFactor(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	var req models.TwoFactorVerifyRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "El cuerpo de la solicitud es inválido", http.StatusBadRequest)
+		return
+	}
+
+	// El desafío debe ser vigente y posterior al último cambio de contraseña
+	claims, err := utils.ValidateChallengeToken(strings.TrimSpace(req.ChallengeToken))
+	if err != nil {
+		http.Error(w, "El desafío no es válido o caducó; inicie sesión de nuevo", http.StatusUnauthorized)
+		return
+	}
+	version, err := h.Store.GetUserSessionVersion(claims.UserID)
+	if err != nil || version != claims.SessionVersion {
+		http.Error(w, "El desafío no es válido o caducó; inicie sesión de nuevo", http.StatusUnauthorized)
+		return
+	}
+	user, err := h.Store.GetUser(claims.UserID)
+	if err != nil {
+		http.Error(w, "Usuario no encontrado", http.StatusNotFound)
+		return
+	}
+
+	err = h.TwoFactor.Verify(user.ID, models.TwoFactorCodeRequest{Code: req.Code, RecoveryCode: req.RecoveryCode})
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	writeSession(w, http.StatusOK, *user, version)
+}
+
+// DisableTwoFactor desactiva el segundo factor del usuario autenticado. Exige la
+// contraseña y un código; no se permite si el rol lo hace obligatorio.
+func (h *AuthHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	user, ok := h.twoFactorUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "El cuerpo de la solicitud es inválido", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.TwoFactor.Disable(*user, req); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// RegenerateRecoveryCodes reemplaza los códigos de recuperación del usuario autenticado
+// tras verificar un código
+func (h *AuthHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	user, ok := h.twoFactorUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := utils.DecodeJSON(r, &req); err != nil {
+		http.Error(w, "El cuerpo de la solicitud es inválido", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.TwoFactor.RegenerateRecoveryCodes(user.ID, req)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"recoveryCodes": codes})
+}
+
+// ResetUserTwoFactor quita el segundo factor de otro usuario, por ejemplo si perdió su
+// dispositivo (DELETE /api/users/:id/2fa)
+func (h *AuthHandler) ResetUserTwoFactor(w http.ResponseWriter, r *http.Request) {
+	// Solo maneja solicitudes DELETE
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Establecer CORS
+	utils.SetCORS(w)
+
+	// Verificar permisos de administrador
+	role, ok := r.Context().Value(middleware.RoleKey).(string)
+	if !ok || role != "admin" {
+		http.Error(w, "No autorizado", http.StatusUnauthorized)
+		return
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) < 3 {
+		http.Error(w, "URL de usuario inválida", http.StatusBadRequest)
+		return
+	}
+	userID := segments[2]
+
+	if _, err := h.Store.GetUser(userID); err != nil {
+		http.Error(w, "Usuario no encontrado", http.StatusNotFound)
+		return
+	}
+
+	if err := h.TwoFactor.Reset(userID); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
@@ -0,0 +1,355 @@
+// Package invitations gestiona las invitaciones para crear cuentas: el administrador
+// invita por email y rol, el sistema envía un enlace con un token de un solo uso que
+// caduca y el invitado crea su cuenta con su propia contraseña.
+package invitations
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/mailer"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// DefaultTTL es la vigencia por defecto del enlace de una invitación
+const DefaultTTL = 7 * 24 * time.Hour
+
+// TokenPlaceholder se reemplaza por el token en la URL de aceptación
+const TokenPlaceholder = "{token}"
+
+// Roles que se pueden asignar por invitación; los clientes se registran desde el widget
+var invitableRoles = map[string]bool{
+	"admin":     true,
+	"assistant": true,
+	"employee":  true,
+}
+
+// ConflictError indica que la operación choca con un usuario o una invitación existente
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// Service crea, reenvía, revoca y acepta invitaciones
+type Service struct {
+	Store     data.DataStore
+	Mailer    *mailer.Mailer
+	AcceptURL string // URL de aceptación para el correo, con {token}
+	TTL       time.Duration
+}
+
+// NewService crea un servicio de invitaciones
+func NewService(store data.DataStore, m *mailer.Mailer, acceptURL string, ttl time.Duration) *Service {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Service{Store: store, Mailer: m, AcceptURL: acceptURL, TTL: ttl}
+}
+
+// Result es una invitación recién enviada. Link solo se completa cuando el correo no se
+// pudo enviar, para que el administrador comparta el enlace por otro medio.
+type Result struct {
+	Invitation models.Invitation `json:"invitation"`
+	EmailSent  bool              `json:"emailSent"`
+	Link       string            `json:"link,omitempty"`
+}
+
+// Status calcula el estado de la invitación en el instante now
+func Status(invitation models.Invitation, now time.Time) string {
+	switch {
+	case invitation.RevokedAt != nil:
+		return models.InvitationStatusRevoked
+	case invitation.AcceptedAt != nil:
+		return models.InvitationStatusAccepted
+	case now.After(invitation.ExpiresAt):
+		return models.InvitationStatusExpired
+	}
+	return models.InvitationStatusPending
+}
+
+// Public prepara la invitación para una respuesta: calcula el estado y quita el hash del token
+func Public(invitation models.Invitation, now time.Time) models.Invitation {
+	invitation.Status = Status(invitation, now)
+	invitation.TokenHash = ""
+	return invitation
+}
+
+// NormalizeEmail valida el email y lo devuelve en minúsculas
+func NormalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", fmt.Errorf("Email inválido: %s", email)
+	}
+	return email, nil
+}
+
+// HashToken devuelve el hash con el que se guarda el token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// List devuelve las invitaciones con su estado; status vacío las incluye todas
+func (s *Service) List(status string) ([]models.Invitation, error) {
+	list, err := s.Store.GetInvitations()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]models.Invitation, 0, len(list))
+	for _, invitation := range list {
+		invitation = Public(invitation, now)
+		if status == "" || invitation.Status == status {
+			result = append(result, invitation)
+		}
+	}
+	return result, nil
+}
+
+// Invite crea una invitación y envía el enlace. El departamento ya debe estar validado.
+func (s *Service) Invite(req models.InvitationRequest, invitedBy string) (*Result, error) {
+	email, err := NormalizeEmail(req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if !invitableRoles[req.Role] {
+		return nil, fmt.Errorf("Rol inválido: %s", req.Role)
+	}
+	if _, err := s.Store.GetUserByEmail(email); err == nil {
+		return nil, &ConflictError{Message: fmt.Sprintf("Ya existe un usuario con el email %s", email)}
+	}
+	if pending, err := s.pendingFor(email); err != nil {
+		return nil, err
+	} else if pending != nil {
+		return nil, &ConflictError{Message: fmt.Sprintf("Ya hay una invitación pendiente para %s; puede reenviarla", email)}
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, fmt.Errorf("error al generar token: %v", err)
+	}
+
+	now := time.Now()
+	invitation := models.Invitation{
+		ID:         uuid.New().String(),
+		Email:      email,
+		FirstName:  strings.TrimSpace(req.FirstName),
+		LastName:   strings.TrimSpace(req.LastName),
+		Role:       req.Role,
+		Department: req.Department,
+		TokenHash:  HashToken(token),
+		InvitedBy:  invitedBy,
+		SendCount:  1,
+		SentAt:     now,
+		ExpiresAt:  now.Add(s.TTL),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.Store.CreateInvitation(invitation); err != nil {
+		return nil, err
+	}
+
+	return s.deliver(invitation, token), nil
+}
+
+// Resend genera un token nuevo, renueva la vigencia y vuelve a enviar el enlace. El
+// enlace anterior deja de funcionar. Se pueden reenviar invitaciones pendientes o caducadas.
+func (s *Service) Resend(id string) (*Result, error) {
+	invitation, err := s.Store.GetInvitation(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	switch Status(*invitation, now) {
+	case models.InvitationStatusAccepted:
+		return nil, &ConflictError{Message: "La invitación ya fue aceptada"}
+	case models.InvitationStatusRevoked:
+		return nil, &ConflictError{Message: "La invitación fue revocada"}
+	}
+	if _, err := s.Store.GetUserByEmail(invitation.Email); err == nil {
+		return nil, &ConflictError{Message: fmt.Sprintf("Ya existe un usuario con el email %s", invitation.Email)}
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, fmt.Errorf("error al generar token: %v", err)
+	}
+	invitation.TokenHash = HashToken(token)
+	invitation.SendCount++
+	invitation.SentAt = now
+	invitation.ExpiresAt = now.Add(s.TTL)
+	invitation.UpdatedAt = now
+	if err := s.Store.UpdateInvitation(*invitation); err != nil {
+		return nil, err
+	}
+
+	return s.deliver(*invitation, token), nil
+}
+
+// Revoke anula una invitación pendiente o caducada
+func (s *Service) Revoke(id string) (*models.Invitation, error) {
+	invitation, err := s.Store.GetInvitation(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	switch Status(*invitation, now) {
+	case models.InvitationStatusAccepted:
+		return nil, &ConflictError{Message: "La invitación ya fue aceptada"}
+	case models.InvitationStatusRevoked:
+		return nil, &ConflictError{Message: "La invitación ya fue revocada"}
+	}
+
+	invitation.RevokedAt = &now
+	invitation.UpdatedAt = now
+	if err := s.Store.UpdateInvitation(*invitation); err != nil {
+		return nil, err
+	}
+
+	public := Public(*invitation, now)
+	return &public, nil
+}
+
+// Lookup devuelve la invitación pendiente del token, para mostrar el formulario de alta
+func (s *Service) Lookup(token string) (*models.Invitation, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, fmt.Errorf("El token es requerido")
+	}
+	invitation, err := s.Store.GetInvitationByTokenHash(HashToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("La invitación no existe o el enlace ya no es válido")
+	}
+
+	now := time.Now()
+	switch Status(*invitation, now) {
+	case models.InvitationStatusAccepted:
+		return nil, fmt.Errorf("La invitación ya fue aceptada")
+	case models.InvitationStatusRevoked:
+		return nil, fmt.Errorf("La invitación fue revocada")
+	case models.InvitationStatusExpired:
+		return nil, fmt.Errorf("La invitación caducó; pida que se la reenvíen")
+	}
+
+	public := Public(*invitation, now)
+	return &public, nil
+}
+
+// Accept crea la cuenta del invitado con su contraseña y consume el token
+func (s *Service) Accept(req models.AcceptInvitationRequest) (*models.User, error) {
+	invitation, err := s.Lookup(req.Token)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.ValidatePassword(req.Password); err != nil {
+		return nil, err
+	}
+
+	user := models.User{
+		ID:         uuid.New().String(),
+		Email:      invitation.Email,
+		FirstName:  strings.TrimSpace(req.FirstName),
+		LastName:   strings.TrimSpace(req.LastName),
+		Role:       invitation.Role,
+		Department: invitation.Department,
+		Active:     true,
+	}
+	if user.FirstName == "" {
+		user.FirstName = invitation.FirstName
+	}
+	if user.LastName == "" {
+		user.LastName = invitation.LastName
+	}
+	if user.FirstName == "" || user.LastName == "" {
+		return nil, fmt.Errorf("El nombre y el apellido son requeridos")
+	}
+	if _, err := s.Store.GetUserByEmail(user.Email); err == nil {
+		return nil, &ConflictError{Message: fmt.Sprintf("Ya existe un usuario con el email %s", user.Email)}
+	}
+
+	user.Password, err = utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	if err := s.Store.AcceptInvitation(invitation.ID, user); err != nil {
+		return nil, &ConflictError{Message: fmt.Sprintf("No se pudo aceptar la invitación: %v", err)}
+	}
+
+	user.Password = ""
+	return &user, nil
+}
+
+// pendingFor devuelve la invitación pendiente del email, o nil
+func (s *Service) pendingFor(email string) (*models.Invitation, error) {
+	list, err := s.Store.GetInvitations()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range list {
+		if list[i].Email == email && Status(list[i], now) == models.InvitationStatusPending {
+			return &list[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// deliver envía el enlace de la invitación por correo
+func (s *Service) deliver(invitation models.Invitation, token string) *Result {
+	result := &Result{Invitation: Public(invitation, time.Now())}
+
+	link := token
+	if s.AcceptURL != "" {
+		link = strings.ReplaceAll(s.AcceptURL, TokenPlaceholder, token)
+	}
+
+	if s.Mailer.Enabled() && s.AcceptURL != "" {
+		name := invitation.FirstName
+		if name == "" {
+			name = invitation.Email
+		}
+		subject := "Invitación para unirse a GrowDesk"
+		body := fmt.Sprintf("Hola %s,\n\nTe invitaron a unirte a GrowDesk. Para crear tu cuenta y elegir tu contraseña, abre este enlace:\n\n%s\n\nEl enlace se puede usar una sola vez y vence el %s.\n\nSi no esperabas esta invitación, puedes ignorar este correo.\n",
+			name, link, invitation.ExpiresAt.Format("02/01/2006 15:04"))
+
+		if err := s.Mailer.Send(invitation.Email, subject, body); err != nil {
+			log.Printf("Error al enviar invitación %s: %v", invitation.ID, err)
+		} else {
+			result.EmailSent = true
+		}
+	}
+
+	if !result.EmailSent {
+		result.Link = link
+	}
+	return result
+}
+
+// newToken genera un token aleatorio para el enlace de la invitación
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,222 @@
+package kb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// Límites de los votos de utilidad y del informe de uso de las FAQs
+const (
+	MaxCommentLength   = 1000
+	DefaultStaleMonths = 6
+	reportComments     = 5 // Comentarios recientes incluidos por FAQ
+)
+
+// Criterios de orden del informe de uso de las FAQs
+const (
+	SortByViews       = "views"
+	SortByHelpfulness = "helpfulness"
+	SortByTickets     = "tickets"
+)
+
+// ValidSort indica si el criterio de orden del informe es válido
+func ValidSort(sortBy string) bool {
+	switch sortBy {
+	case SortByViews, SortByHelpfulness, SortByTickets:
+		return true
+	}
+	return false
+}
+
+// ValidateVote comprueba un voto del widget y normaliza el comentario
+func ValidateVote(req *models.FAQVoteRequest) error {
+	req.SessionID = strings.TrimSpace(req.SessionID)
+	req.Comment = strings.TrimSpace(req.Comment)
+
+	if req.SessionID == "" || req.FAQID <= 0 {
+		return fmt.Errorf("La sesión y la FAQ son requeridas")
+	}
+	if req.Helpful == nil {
+		return fmt.Errorf("Indique si la FAQ le resultó útil")
+	}
+	if len([]rune(req.Comment)) > MaxCommentLength {
+		return fmt.Errorf("El comentario no puede superar los %d caracteres", MaxCommentLength)
+	}
+	return nil
+}
+
+// VoteLimiter limita en memoria cuántos votos puede enviar una sesión del widget en
+// una ventana de tiempo. Cambiar un voto también cuenta.
+type VoteLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	votes     map[string][]time.Time
+	lastSweep time.Time
+}
+
+// NewVoteLimiter crea un limitador de limit votos por sesión cada window. Con un límite
+// menor que 1 no se limita.
+func NewVoteLimiter(limit int, window time.Duration) *VoteLimiter {
+	if window <= 0 {
+		window = time.Hour
+	}
+
+	return &VoteLimiter{
+		limit:  limit,
+		window: window,
+		votes:  make(map[string][]time.Time),
+	}
+}
+
+// Allow registra un voto de la sesión y devuelve false si supera el límite
+func (l *VoteLimiter) Allow(sessionID string, now time.Time) bool {
+	if l == nil || l.limit < 1 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := now.Add(-l.window)
+
+	// Olvidar de vez en cuando las sesiones sin votos recientes
+	if now.Sub(l.lastSweep) > l.window {
+		for session, times := range l.votes {
+			if len(times) == 0 || times[len(times)-1].Before(cutoff) {
+				delete(l.votes, session)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	recent := make([]time.Time, 0, len(l.votes[sessionID])+1)
+	for _, at := range l.votes[sessionID] {
+		if at.After(cutoff) {
+			recent = append(recent, at)
+		}
+	}
+	if len(recent) >= l.limit {
+		l.votes[sessionID] = recent
+		return false
+	}
+
+	l.votes[sessionID] = append(recent, now)
+	return true
+}
+
+// StaleBefore devuelve el instante anterior al cual una FAQ sin cambios se considera
+// desactualizada
+func StaleBefore(months int, now time.Time) time.Time {
+	return now.AddDate(0, -months, 0)
+}
+
+// Analytics resume las visitas y los votos de las FAQs. Se incluyen las FAQs publicadas
+// y las que tuvieron actividad; solo las publicadas se marcan como desactualizadas si
+// no cambian desde staleBefore.
+func Analytics(articles []models.Article, views []models.FAQView, votes []models.FAQVote, staleBefore time.Time, sortBy string) models.FAQAnalyticsReport {
+	report := models.FAQAnalyticsReport{FAQs: make([]models.FAQAnalytics, 0)}
+	byFAQ := make(map[int]*models.FAQAnalytics)
+	known := make(map[int]models.Article, len(articles))
+	for _, article := range articles {
+		known[article.ID] = article
+	}
+
+	summaryFor := func(articleID int) *models.FAQAnalytics {
+		summary, ok := byFAQ[articleID]
+		if !ok {
+			article := known[articleID]
+			summary = &models.FAQAnalytics{
+				FAQID:     articleID,
+				Title:     article.Title,
+				Slug:      article.Slug,
+				Status:    article.Status,
+				UpdatedAt: article.UpdatedAt,
+				Comments:  make([]models.FAQComment, 0),
+			}
+			byFAQ[articleID] = summary
+		}
+		return summary
+	}
+
+	for _, article := range articles {
+		if article.PublishedRevision > 0 {
+			summaryFor(article.ID)
+		}
+	}
+
+	ticketSessions := make(map[string]bool)
+	for _, view := range views {
+		if _, ok := known[view.ArticleID]; !ok {
+			continue
+		}
+		summary := summaryFor(view.ArticleID)
+		summary.Views += view.Count
+		summary.Viewers++
+		report.Views += view.Count
+		if view.TicketID != "" {
+			summary.Tickets++
+			ticketSessions[view.SessionID] = true
+		}
+	}
+	report.Tickets = len(ticketSessions)
+
+	// Los votos llegan de más reciente a más antiguo
+	for _, vote := range votes {
+		if _, ok := known[vote.ArticleID]; !ok {
+			continue
+		}
+		summary := summaryFor(vote.ArticleID)
+		if vote.Helpful {
+			summary.Helpful++
+		} else {
+			summary.NotHelpful++
+		}
+		report.Votes++
+		if vote.Comment != "" && len(summary.Comments) < reportComments {
+			summary.Comments = append(summary.Comments, models.FAQComment{
+				Helpful:   vote.Helpful,
+				Comment:   vote.Comment,
+				CreatedAt: vote.UpdatedAt,
+			})
+		}
+	}
+
+	for id, summary := range byFAQ {
+		summary.Helpfulness = percentage(summary.Helpful, summary.Helpful+summary.NotHelpful)
+		summary.TicketRate = percentage(summary.Tickets, summary.Viewers)
+		if known[id].PublishedRevision > 0 && known[id].UpdatedAt.Before(staleBefore) {
+			summary.Stale = true
+			report.Stale++
+		}
+		report.FAQs = append(report.FAQs, *summary)
+	}
+
+	sort.Slice(report.FAQs, func(i, j int) bool {
+		a, b := report.FAQs[i], report.FAQs[j]
+		switch sortBy {
+		case SortByHelpfulness:
+			if a.Helpfulness != b.Helpfulness {
+				return a.Helpfulness > b.Helpfulness
+			}
+			if a.Helpful != b.Helpful {
+				return a.Helpful > b.Helpful
+			}
+		case SortByTickets:
+			if a.Tickets != b.Tickets {
+				return a.Tickets > b.Tickets
+			}
+		}
+		if a.Views != b.Views {
+			return a.Views > b.Views
+		}
+		return a.FAQID < b.FAQID
+	})
+
+	return report
+}
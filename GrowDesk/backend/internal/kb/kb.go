@@ -0,0 +1,382 @@
+// Package kb implementa el flujo editorial de la base de conocimiento: slugs, revisiones,
+// publicación y diferencias entre versiones de un artículo.
+package kb
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// Límites de los artículos
+const (
+	MaxTitleLength = 200
+	MaxSlugLength  = 120
+	MaxBodyLength  = 100000
+)
+
+// maxDiffCells limita el tamaño de la tabla del diff (líneas origen × líneas destino)
+const maxDiffCells = 4000000
+
+// slugPattern reconoce slugs en minúsculas separados por guiones
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// transitions son los cambios de estado permitidos. Un artículo publicado vuelve a
+// borrador al editarse (Revise) o al retirarse (Unpublish).
+var transitions = map[string][]string{
+	models.ArticleStatusDraft:  {models.ArticleStatusReview, models.ArticleStatusPublished},
+	models.ArticleStatusReview: {models.ArticleStatusDraft, models.ArticleStatusPublished},
+}
+
+// accents traduce las letras acentuadas más comunes para los slugs
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "ä", "a", "â", "a", "ã", "a",
+	"é", "e", "è", "e", "ë", "e", "ê", "e",
+	"í", "i", "ì", "i", "ï", "i", "î", "i",
+	"ó", "o", "ò", "o", "ö", "o", "ô", "o", "õ", "o",
+	"ú", "u", "ù", "u", "ü", "u", "û", "u",
+	"ñ", "n", "ç", "c",
+)
+
+// Slugify genera un slug a partir de un título ("¿Cómo cambio mi contraseña?" →
+// "como-cambio-mi-contrasena")
+func Slugify(text string) string {
+	text = accents.Replace(strings.ToLower(text))
+
+	var b strings.Builder
+	dash := false
+	for _, r := range text {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			if b.Len() >= MaxSlugLength {
+				break
+			}
+			continue
+		}
+		dash = true
+	}
+
+	slug := strings.Trim(b.String(), "-")
+	if slug == "" {
+		return "articulo"
+	}
+	return slug
+}
+
+// UniqueSlug devuelve base o, si ya está en uso, base-2, base-3...
+func UniqueSlug(base string, taken func(slug string) bool) string {
+	slug := base
+	for n := 2; taken(slug); n++ {
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+	return slug
+}
+
+// ValidStatus indica si el estado es uno de los admitidos
+func ValidStatus(status string) bool {
+	switch status {
+	case models.ArticleStatusDraft, models.ArticleStatusReview, models.ArticleStatusPublished:
+		return true
+	}
+	return false
+}
+
+// CanTransition indica si un artículo puede pasar del estado from al estado to
+func CanTransition(from, to string) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate comprueba y normaliza el contenido de un artículo. Un slug vacío se genera
+// después a partir del título.
+func Validate(req *models.ArticleRequest) error {
+	req.Title = strings.TrimSpace(req.Title)
+	req.Slug = strings.TrimSpace(req.Slug)
+	req.CategoryID = strings.TrimSpace(req.CategoryID)
+	req.Note = strings.TrimSpace(req.Note)
+
+	if req.Title == "" {
+		return fmt.Errorf("El título del artículo es requerido")
+	}
+	if len([]rune(req.Title)) > MaxTitleLength {
+		return fmt.Errorf("El título no puede superar los %d caracteres", MaxTitleLength)
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		return fmt.Errorf("El contenido del artículo es requerido")
+	}
+	if len(req.Body) > MaxBodyLength {
+		return fmt.Errorf("El contenido no puede superar los %d caracteres", MaxBodyLength)
+	}
+	if req.Slug != "" && (len(req.Slug) > MaxSlugLength || !slugPattern.MatchString(req.Slug)) {
+		return fmt.Errorf("Slug inválido: use minúsculas, números y guiones")
+	}
+	if req.Locale != "" {
+		locale, ok := NormalizeLocale(req.Locale)
+		if !ok || !IsSupported(locale) {
+			return fmt.Errorf("Idioma no admitido: %s", req.Locale)
+		}
+		req.Locale = locale
+	}
+	return nil
+}
+
+// New crea un artículo en borrador con su primera revisión. Sin idioma indicado, el
+// artículo se escribe en el idioma por defecto.
+func New(req models.ArticleRequest, authorID string, now time.Time) (models.Article, models.ArticleRevision) {
+	if req.Locale == "" {
+		req.Locale = DefaultLocale
+	}
+	article := models.Article{
+		Slug:       req.Slug,
+		Title:      req.Title,
+		Body:       req.Body,
+		CategoryID: req.CategoryID,
+		Locale:     req.Locale,
+		Status:     models.ArticleStatusDraft,
+		Revision:   1,
+		AuthorID:   authorID,
+		EditorID:   authorID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	return article, revisionOf(article, req.Note)
+}
+
+// Revise aplica una edición al artículo y devuelve la nueva revisión. Si el artículo
+// estaba publicado vuelve a borrador; el widget sigue mostrando la revisión publicada
+// hasta que se publique la nueva.
+func Revise(article *models.Article, req models.ArticleRequest, editorID string, now time.Time) models.ArticleRevision {
+	if req.Slug != "" {
+		article.Slug = req.Slug
+	}
+	if req.Locale != "" {
+		article.Locale = req.Locale
+	}
+	if article.CategoryID != req.CategoryID {
+		article.LegacyCategory = ""
+	}
+	article.Title = req.Title
+	article.Body = req.Body
+	article.CategoryID = req.CategoryID
+	article.Revision++
+	article.EditorID = editorID
+	article.UpdatedAt = now
+	if article.Status == models.ArticleStatusPublished {
+		article.Status = models.ArticleStatusDraft
+	}
+	return revisionOf(*article, req.Note)
+}
+
+// Rollback restaura el contenido de una revisión anterior como una revisión nueva;
+// el historial nunca se reescribe
+func Rollback(article *models.Article, target models.ArticleRevision, editorID, note string, now time.Time) models.ArticleRevision {
+	if note == "" {
+		note = fmt.Sprintf("Restaurada la revisión %d", target.Revision)
+	}
+	return Revise(article, models.ArticleRequest{
+		Title:      target.Title,
+		Body:       target.Body,
+		CategoryID: target.CategoryID,
+		Note:       note,
+	}, editorID, now)
+}
+
+// Publish publica la revisión actual del artículo
+func Publish(article *models.Article, userID string, now time.Time) {
+	article.Status = models.ArticleStatusPublished
+	article.PublishedRevision = article.Revision
+	article.PublishedBy = userID
+	article.PublishedAt = &now
+	article.UpdatedAt = now
+}
+
+// Unpublish retira el artículo del widget
+func Unpublish(article *models.Article, now time.Time) {
+	if article.Status == models.ArticleStatusPublished {
+		article.Status = models.ArticleStatusDraft
+	}
+	article.PublishedRevision = 0
+	article.PublishedBy = ""
+	article.PublishedAt = nil
+	article.UpdatedAt = now
+}
+
+// revisionOf crea la revisión con el contenido actual del artículo
+func revisionOf(article models.Article, note string) models.ArticleRevision {
+	return models.ArticleRevision{
+		ArticleID:  article.ID,
+		Revision:   article.Revision,
+		Title:      article.Title,
+		Body:       article.Body,
+		CategoryID: article.CategoryID,
+		EditorID:   article.EditorID,
+		Note:       note,
+		CreatedAt:  article.UpdatedAt,
+	}
+}
+
+// CompareRevisions calcula la diferencia entre dos revisiones de un artículo
+func CompareRevisions(from, to models.ArticleRevision) models.ArticleDiff {
+	diff := models.ArticleDiff{
+		ArticleID: to.ArticleID,
+		From:      from.Revision,
+		To:        to.Revision,
+		Title:     Diff(from.Title, to.Title),
+		Body:      Diff(from.Body, to.Body),
+	}
+	if from.CategoryID != to.CategoryID {
+		diff.FromCategoryID = from.CategoryID
+		diff.ToCategoryID = to.CategoryID
+	}
+	return diff
+}
+
+// Diff compara dos textos línea a línea (subsecuencia común más larga)
+func Diff(from, to string) []models.DiffLine {
+	a := splitLines(from)
+	b := splitLines(to)
+
+	// Textos muy grandes: se muestran como reemplazo completo
+	if len(a)*len(b) > maxDiffCells {
+		result := make([]models.DiffLine, 0, len(a)+len(b))
+		for _, line := range a {
+			result = append(result, models.DiffLine{Op: models.DiffDelete, Text: line})
+		}
+		for _, line := range b {
+			result = append(result, models.DiffLine{Op: models.DiffInsert, Text: line})
+		}
+		return result
+	}
+
+	// lcs[i][j] = longitud de la subsecuencia común más larga de a[i:] y b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	result := make([]models.DiffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, models.DiffLine{Op: models.DiffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, models.DiffLine{Op: models.DiffDelete, Text: a[i]})
+			i++
+		default:
+			result = append(result, models.DiffLine{Op: models.DiffInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		result = append(result, models.DiffLine{Op: models.DiffDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		result = append(result, models.DiffLine{Op: models.DiffInsert, Text: b[j]})
+	}
+	return result
+}
+
+// splitLines separa un texto en líneas; un texto vacío no tiene líneas
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
+
+// FromFAQ convierte una FAQ anterior a la base de conocimiento en un artículo con su
+// primera revisión, conservando su ID. La categoría de texto libre se enlaza con la
+// categoría del mismo nombre (o ID); si no hay ninguna se guarda en LegacyCategory.
+func FromFAQ(faq models.FAQ, categories []models.Category) (models.Article, models.ArticleRevision) {
+	now := time.Now()
+	if faq.CreatedAt.IsZero() {
+		faq.CreatedAt = now
+	}
+	if faq.UpdatedAt.IsZero() {
+		faq.UpdatedAt = faq.CreatedAt
+	}
+
+	article := models.Article{
+		ID:        faq.ID,
+		Slug:      Slugify(faq.Question),
+		Locale:    DefaultLocale,
+		Title:     faq.Question,
+		Body:      faq.Answer,
+		Status:    models.ArticleStatusDraft,
+		Revision:  1,
+		CreatedAt: faq.CreatedAt,
+		UpdatedAt: faq.UpdatedAt,
+	}
+	article.CategoryID, article.LegacyCategory = MatchCategory(faq.Category, categories)
+	if faq.IsPublished {
+		article.Status = models.ArticleStatusPublished
+		article.PublishedRevision = 1
+		article.PublishedAt = &faq.UpdatedAt
+	}
+
+	return article, revisionOf(article, "Migrada desde FAQ")
+}
+
+// MatchCategory busca la categoría con el ID o el nombre indicado (sin distinguir
+// mayúsculas). Devuelve el ID encontrado o, si no hay ninguna, el texto original.
+func MatchCategory(category string, categories []models.Category) (string, string) {
+	category = strings.TrimSpace(category)
+	if category == "" {
+		return "", ""
+	}
+	for _, c := range categories {
+		if c.ID == category {
+			return c.ID, ""
+		}
+	}
+	for _, c := range categories {
+		if strings.EqualFold(c.Name, category) {
+			return c.ID, ""
+		}
+	}
+	return "", category
+}
+
+// ToFAQ convierte un artículo en la vista de compatibilidad de FAQ. categoryName es el
+// nombre de la categoría enlazada, si la hay.
+func ToFAQ(article models.Article, categoryName string) models.FAQ {
+	category := categoryName
+	if category == "" {
+		category = article.LegacyCategory
+	}
+	return models.FAQ{
+		ID:          article.ID,
+		Question:    article.Title,
+		Answer:      article.Body,
+		Category:    category,
+		IsPublished: article.PublishedRevision > 0,
+		Locale:      article.Locale,
+		CreatedAt:   article.CreatedAt,
+		UpdatedAt:   article.UpdatedAt,
+	}
+}
@@ -0,0 +1,255 @@
+package kb
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// DefaultLocale es el idioma de los artículos que no indican otro (y de las FAQs migradas)
+const DefaultLocale = "es"
+
+// SupportedLocales son los idiomas en los que se mantiene la base de conocimiento.
+// Se configura al arrancar el servidor (KB_LOCALES).
+var SupportedLocales = []string{"es", "en"}
+
+// localePattern reconoce etiquetas de idioma BCP 47 simplificadas ("es", "en-us", "pt-br")
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(?:-[a-z0-9]{2,8})*$`)
+
+// NormalizeLocale normaliza una etiqueta de idioma ("en_US" → "en-us"). Devuelve false
+// si no es válida.
+func NormalizeLocale(tag string) (string, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if !localePattern.MatchString(tag) {
+		return "", false
+	}
+	return tag, true
+}
+
+// baseLocale devuelve el idioma sin región ("en-us" → "en")
+func baseLocale(locale string) string {
+	if i := strings.IndexByte(locale, '-'); i > 0 {
+		return locale[:i]
+	}
+	return locale
+}
+
+// ParseLocales convierte una lista separada por comas en idiomas normalizados, sin
+// repetidos. El idioma por defecto siempre se incluye.
+func ParseLocales(list string) []string {
+	locales := []string{DefaultLocale}
+	seen := map[string]bool{DefaultLocale: true}
+	for _, tag := range strings.Split(list, ",") {
+		if locale, ok := NormalizeLocale(tag); ok && !seen[locale] {
+			seen[locale] = true
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
+
+// IsSupported indica si el idioma está entre los admitidos
+func IsSupported(locale string) bool {
+	for _, supported := range SupportedLocales {
+		if supported == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAcceptLanguage devuelve los idiomas de una cabecera Accept-Language ordenados
+// por preferencia (q). Se ignoran "*" y las entradas con q=0.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	entries := make([]weighted, 0)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		locale, ok := NormalizeLocale(fields[0])
+		if !ok {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if value, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = value
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		entries = append(entries, weighted{locale: locale, q: q})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].q > entries[j].q })
+
+	locales := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		locales = append(locales, entry.locale)
+	}
+	return locales
+}
+
+// FallbackChain construye la cadena de idiomas a probar: cada idioma preferido seguido
+// de su idioma base ("en-us", "en"), sin repetidos. Solo se conservan los idiomas
+// admitidos. Si ninguno lo es, la cadena queda vacía y se muestra el idioma original.
+func FallbackChain(preferred []string) []string {
+	chain := make([]string, 0, len(preferred)*2)
+	seen := make(map[string]bool)
+	add := func(locale string) {
+		if !seen[locale] && IsSupported(locale) {
+			seen[locale] = true
+			chain = append(chain, locale)
+		}
+	}
+	for _, locale := range preferred {
+		add(locale)
+		add(baseLocale(locale))
+	}
+	return chain
+}
+
+// Localize sustituye el contenido publicado de un artículo por la primera traducción
+// publicada de la cadena de idiomas. Si la cadena llega antes al idioma original del
+// artículo, o no hay ninguna traducción publicada, el artículo no cambia.
+// translations está indexado por idioma.
+func Localize(article *models.Article, translations map[string]models.ArticleTranslation, chain []string) {
+	for _, locale := range chain {
+		if locale == article.Locale {
+			return
+		}
+		translation, ok := translations[locale]
+		if !ok || translation.PublishedAt == nil {
+			continue
+		}
+		article.Title = translation.PublishedTitle
+		article.Body = translation.PublishedBody
+		article.Locale = locale
+		return
+	}
+}
+
+// ValidateTranslation comprueba y normaliza el contenido de una traducción
+func ValidateTranslation(req *models.ArticleTranslationRequest) error {
+	req.Title = strings.TrimSpace(req.Title)
+
+	if req.Title == "" {
+		return fmt.Errorf("El título de la traducción es requerido")
+	}
+	if len([]rune(req.Title)) > MaxTitleLength {
+		return fmt.Errorf("El título no puede superar los %d caracteres", MaxTitleLength)
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		return fmt.Errorf("El contenido de la traducción es requerido")
+	}
+	if len(req.Body) > MaxBodyLength {
+		return fmt.Errorf("El contenido no puede superar los %d caracteres", MaxBodyLength)
+	}
+	if req.SourceRevision < 0 {
+		return fmt.Errorf("Revisión de origen inválida")
+	}
+	return nil
+}
+
+// Translate crea o edita la traducción de un artículo. Si la traducción estaba
+// publicada vuelve a borrador; el widget sigue mostrando la versión publicada hasta
+// que se publique la nueva.
+func Translate(existing *models.ArticleTranslation, article models.Article, locale string, req models.ArticleTranslationRequest, translatorID string, now time.Time) models.ArticleTranslation {
+	translation := models.ArticleTranslation{
+		ArticleID: article.ID,
+		Locale:    locale,
+		Status:    models.ArticleStatusDraft,
+		CreatedAt: now,
+	}
+	if existing != nil {
+		translation = *existing
+	}
+
+	translation.Title = req.Title
+	translation.Body = req.Body
+	translation.SourceRevision = req.SourceRevision
+	if translation.SourceRevision == 0 || translation.SourceRevision > article.Revision {
+		translation.SourceRevision = article.Revision
+	}
+	translation.TranslatorID = translatorID
+	translation.UpdatedAt = now
+	if translation.Status == models.ArticleStatusPublished {
+		translation.Status = models.ArticleStatusDraft
+	}
+	return translation
+}
+
+// PublishTranslation publica la versión de trabajo de la traducción
+func PublishTranslation(translation *models.ArticleTranslation, userID string, now time.Time) {
+	translation.Status = models.ArticleStatusPublished
+	translation.PublishedTitle = translation.Title
+	translation.PublishedBody = translation.Body
+	translation.PublishedBy = userID
+	translation.PublishedAt = &now
+	translation.UpdatedAt = now
+}
+
+// UnpublishTranslation retira la traducción del widget
+func UnpublishTranslation(translation *models.ArticleTranslation, now time.Time) {
+	if translation.Status == models.ArticleStatusPublished {
+		translation.Status = models.ArticleStatusDraft
+	}
+	translation.PublishedTitle = ""
+	translation.PublishedBody = ""
+	translation.PublishedBy = ""
+	translation.PublishedAt = nil
+	translation.UpdatedAt = now
+}
+
+// TranslationGaps devuelve las traducciones pendientes de los artículos en los idiomas
+// indicados: las que no existen, las que no están publicadas y las que se tradujeron de
+// una revisión anterior a la publicada del artículo. translations está indexado por
+// artículo y por idioma.
+func TranslationGaps(articles []models.Article, translations map[int]map[string]models.ArticleTranslation, locales []string) []models.TranslationGap {
+	gaps := make([]models.TranslationGap, 0)
+	for _, article := range articles {
+		for _, locale := range locales {
+			if locale == article.Locale {
+				continue
+			}
+
+			gap := models.TranslationGap{
+				ArticleID:         article.ID,
+				Slug:              article.Slug,
+				Title:             article.Title,
+				ArticleStatus:     article.Status,
+				ArticleLocale:     article.Locale,
+				Locale:            locale,
+				PublishedRevision: article.PublishedRevision,
+			}
+
+			translation, ok := translations[article.ID][locale]
+			switch {
+			case !ok:
+				gap.Reason = models.TranslationMissing
+			case translation.PublishedAt == nil:
+				gap.Reason = models.TranslationUnpublished
+				gap.SourceRevision = translation.SourceRevision
+			case translation.SourceRevision < article.PublishedRevision:
+				gap.Reason = models.TranslationOutdated
+				gap.SourceRevision = translation.SourceRevision
+			default:
+				continue
+			}
+			gaps = append(gaps, gap)
+		}
+	}
+	return gaps
+}
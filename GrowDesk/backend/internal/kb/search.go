@@ -0,0 +1,201 @@
+package kb
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// Límites de las sugerencias de FAQs
+const (
+	MinQueryLength     = 3
+	MaxQueryLength     = 2000
+	DefaultSuggestions = 3
+	MaxSuggestions     = 10
+)
+
+// Parámetros de BM25. El título cuenta titleWeight veces para que una coincidencia en
+// la pregunta pese más que una en la respuesta.
+const (
+	bm25K1      = 1.2
+	bm25B       = 0.75
+	titleWeight = 3
+)
+
+// stopwords son palabras demasiado frecuentes para distinguir artículos (español e inglés)
+var stopwords = map[string]bool{
+	"de": true, "la": true, "el": true, "en": true, "y": true, "a": true, "que": true, "los": true,
+	"las": true, "del": true, "un": true, "una": true, "por": true, "con": true, "para": true,
+	"es": true, "se": true, "al": true, "lo": true, "mi": true, "me": true, "no": true, "su": true,
+	"como": true, "puedo": true, "hola": true, "o": true, "le": true, "tu": true,
+	"the": true, "an": true, "and": true, "or": true, "to": true, "of": true, "in": true, "is": true,
+	"it": true, "my": true, "i": true, "on": true, "for": true, "how": true, "do": true, "can": true,
+	"with": true, "hi": true, "hello": true, "be": true, "this": true, "that": true,
+}
+
+// ScoredArticle es un artículo con su relevancia para una consulta
+type ScoredArticle struct {
+	Article models.Article
+	Score   float64
+}
+
+// Tokenize divide un texto en términos normalizados: minúsculas, sin acentos, sin
+// palabras vacías y con el plural simple ("tickets" → "ticket") reducido
+func Tokenize(text string) []string {
+	text = accents.Replace(strings.ToLower(text))
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if len(word) < 2 || stopwords[word] {
+			continue
+		}
+		if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+			word = word[:len(word)-1]
+		}
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// Rank ordena los artículos por relevancia (BM25 sobre título y cuerpo) para la
+// consulta y devuelve como mucho limit artículos con puntuación positiva
+func Rank(articles []models.Article, query string, limit int) []ScoredArticle {
+	queryTerms := uniqueTerms(Tokenize(query))
+	if len(queryTerms) == 0 || len(articles) == 0 {
+		return []ScoredArticle{}
+	}
+
+	// Frecuencia de cada término por documento y número de documentos que lo contienen
+	frequencies := make([]map[string]int, len(articles))
+	lengths := make([]int, len(articles))
+	documents := make(map[string]int)
+	totalLength := 0
+	for i, article := range articles {
+		frequencies[i] = make(map[string]int)
+		for _, term := range Tokenize(article.Title) {
+			frequencies[i][term] += titleWeight
+			lengths[i] += titleWeight
+		}
+		for _, term := range Tokenize(article.Body) {
+			frequencies[i][term]++
+			lengths[i]++
+		}
+		for term := range frequencies[i] {
+			documents[term]++
+		}
+		totalLength += lengths[i]
+	}
+
+	count := float64(len(articles))
+	averageLength := float64(totalLength) / count
+	if averageLength == 0 {
+		averageLength = 1
+	}
+
+	scored := make([]ScoredArticle, 0)
+	for i, article := range articles {
+		score := 0.0
+		for _, term := range queryTerms {
+			frequency := float64(frequencies[i][term])
+			if frequency == 0 {
+				continue
+			}
+			n := float64(documents[term])
+			idf := math.Log(1 + (count-n+0.5)/(n+0.5))
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(lengths[i])/averageLength)
+			score += idf * frequency * (bm25K1 + 1) / (frequency + norm)
+		}
+		if score > 0 {
+			scored = append(scored, ScoredArticle{Article: article, Score: math.Round(score*1000) / 1000})
+		}
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].Score != scored[j].Score {
+			return scored[i].Score > scored[j].Score
+		}
+		return scored[i].Article.ID < scored[j].Article.ID
+	})
+
+	if limit > 0 && len(scored) > limit {
+		scored = scored[:limit]
+	}
+	return scored
+}
+
+// uniqueTerms elimina los términos repetidos de la consulta
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool)
+	unique := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
+
+// DeflectionReport resume las sugerencias de FAQs: en cuántas sesiones se sugirió
+// cada FAQ y en cuántas resolvió el problema. titles traduce IDs de FAQ a títulos.
+func DeflectionReport(deflections []models.FAQDeflection, titles map[int]string) models.DeflectionReport {
+	report := models.DeflectionReport{FAQs: make([]models.DeflectionSummary, 0)}
+	sessions := make(map[string]bool)
+	byFAQ := make(map[int]*models.DeflectionSummary)
+
+	for _, deflection := range deflections {
+		if _, ok := sessions[deflection.SessionID]; !ok {
+			sessions[deflection.SessionID] = false
+		}
+		if deflection.SolvedAt != nil {
+			sessions[deflection.SessionID] = true
+		}
+
+		summary, ok := byFAQ[deflection.ArticleID]
+		if !ok {
+			summary = &models.DeflectionSummary{FAQID: deflection.ArticleID, Title: titles[deflection.ArticleID]}
+			byFAQ[deflection.ArticleID] = summary
+		}
+		summary.Shown++
+		if deflection.SolvedAt != nil {
+			summary.Solved++
+		}
+	}
+
+	report.Sessions = len(sessions)
+	for _, solved := range sessions {
+		if solved {
+			report.SolvedSessions++
+		}
+	}
+	report.Rate = percentage(report.SolvedSessions, report.Sessions)
+
+	for _, summary := range byFAQ {
+		summary.Rate = percentage(summary.Solved, summary.Shown)
+		report.FAQs = append(report.FAQs, *summary)
+	}
+
+	// Primero las FAQs más sugeridas
+	sort.Slice(report.FAQs, func(i, j int) bool {
+		if report.FAQs[i].Shown != report.FAQs[j].Shown {
+			return report.FAQs[i].Shown > report.FAQs[j].Shown
+		}
+		return report.FAQs[i].FAQID < report.FAQs[j].FAQID
+	})
+
+	return report
+}
+
+// percentage devuelve part/total en porcentaje con dos decimales
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*10000/float64(total)) / 100
+}
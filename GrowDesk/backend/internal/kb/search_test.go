@@ -0,0 +1,78 @@
+package kb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"¿Cómo restablezco mi contraseña?", []string{"restablezco", "contrasena"}},
+		{"Tickets y facturas", []string{"ticket", "factura"}},
+		{"The API is down", []string{"api", "down"}},
+		{"access gas", []string{"access", "gas"}},
+		{"error-500 en el pago", []string{"error", "500", "pago"}},
+		{"", []string{}},
+		{"de la y a", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %v, se esperaba %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	articles := []models.Article{
+		{ID: 1, Title: "Restablecer la contraseña", Body: "Pasos para cambiar una contraseña olvidada."},
+		{ID: 2, Title: "Facturación", Body: "Descargar facturas y recibos del mes."},
+		{ID: 3, Title: "Datos de la cuenta", Body: "Actualice el correo, el teléfono o la contraseña."},
+		{ID: 4, Title: "Horarios de atención", Body: "Atendemos de lunes a viernes."},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []int
+	}{
+		{"el título pesa más que el cuerpo", "olvidé mi contraseña", 0, []int{1, 3}},
+		{"plural y acentos", "FACTURAS", 0, []int{2}},
+		{"límite", "contraseña", 1, []int{1}},
+		{"sin coincidencias", "envíos internacionales", 0, []int{}},
+		{"solo palabras vacías", "como puedo", 0, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := make([]int, 0)
+			for _, scored := range Rank(articles, tt.query, tt.limit) {
+				ids = append(ids, scored.Article.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("Rank(%q) = %v, se esperaba %v", tt.query, ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankTiesByID(t *testing.T) {
+	articles := []models.Article{
+		{ID: 7, Title: "Cambiar la contraseña"},
+		{ID: 5, Title: "Cambiar la contraseña"},
+	}
+
+	ranked := Rank(articles, "contraseña", 0)
+	if len(ranked) != 2 || ranked[0].Article.ID != 5 || ranked[1].Article.ID != 7 {
+		t.Fatalf("Rank = %+v, se esperaba [5 7]", ranked)
+	}
+	if ranked[0].Score != ranked[1].Score {
+		t.Errorf("puntuaciones %v y %v, se esperaban iguales", ranked[0].Score, ranked[1].Score)
+	}
+}
@@ -0,0 +1,82 @@
+// Package macros reemplaza los marcadores de las respuestas predefinidas de los agentes.
+package macros
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// placeholderPattern reconoce marcadores como {{customer.name}} o {{ ticket.id }}
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z]+\.[A-Za-z]+)\s*\}\}`)
+
+// Placeholders son los marcadores admitidos en el texto de una macro
+var Placeholders = []string{
+	"ticket.id",
+	"ticket.title",
+	"ticket.status",
+	"ticket.priority",
+	"ticket.category",
+	"customer.name",
+	"customer.firstName",
+	"customer.email",
+	"agent.name",
+	"agent.firstName",
+	"agent.lastName",
+	"agent.email",
+}
+
+// Validate comprueba que el texto solo use marcadores admitidos
+func Validate(content string) error {
+	known := make(map[string]bool, len(Placeholders))
+	for _, name := range Placeholders {
+		known[name] = true
+	}
+
+	unknown := make([]string, 0)
+	for _, match := range placeholderPattern.FindAllStringSubmatch(content, -1) {
+		if !known[match[1]] {
+			unknown = append(unknown, match[1])
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("Marcadores desconocidos: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// Render reemplaza los marcadores con los datos del ticket y del agente. El agente puede
+// ser nil (p. ej. con autenticación simulada); sus marcadores quedan vacíos.
+func Render(content string, ticket models.Ticket, agent *models.User) string {
+	values := map[string]string{
+		"ticket.id":          ticket.ID,
+		"ticket.title":       ticket.Title,
+		"ticket.status":      ticket.Status,
+		"ticket.priority":    ticket.Priority,
+		"ticket.category":    ticket.Category,
+		"customer.name":      ticket.Customer.Name,
+		"customer.firstName": firstWord(ticket.Customer.Name),
+		"customer.email":     ticket.Customer.Email,
+	}
+	if agent != nil {
+		values["agent.name"] = strings.TrimSpace(agent.FirstName + " " + agent.LastName)
+		values["agent.firstName"] = agent.FirstName
+		values["agent.lastName"] = agent.LastName
+		values["agent.email"] = agent.Email
+	}
+
+	return placeholderPattern.ReplaceAllStringFunc(content, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		return values[name]
+	})
+}
+
+// firstWord devuelve la primera palabra de un nombre
+func firstWord(name string) string {
+	if fields := strings.Fields(name); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+// Package mailer envía correos electrónicos de texto a través de un servidor SMTP.
+package mailer
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mailer envía correos con la configuración SMTP indicada. Un Mailer nil no envía nada.
+type Mailer struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+}
+
+// New crea un Mailer. Si host o from están vacíos devuelve nil y los correos se descartan.
+func New(host string, port int, username, password, from string) *Mailer {
+	if host == "" || from == "" {
+		return nil
+	}
+	return &Mailer{host: host, port: port, username: username, password: password, from: from}
+}
+
+// Enabled indica si hay un servidor SMTP configurado
+func (m *Mailer) Enabled() bool {
+	return m != nil
+}
+
+// Send envía un correo de texto plano a un destinatario
+func (m *Mailer) Send(to, subject, body string) error {
+	if m == nil {
+		return nil
+	}
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return fmt.Errorf("destinatario o asunto inválido")
+	}
+
+	headers := []string{
+		"From: " + m.from,
+		"To: " + to,
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Transfer-Encoding: 8bit",
+	}
+	message := strings.Join(headers, "\r\n") + "\r\n\r\n" + strings.ReplaceAll(body, "\n", "\r\n")
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	// El remitente puede incluir un nombre ("Soporte <soporte@ejemplo.com>")
+	sender := m.from
+	if address, err := mail.ParseAddress(m.from); err == nil {
+		sender = address.Address
+	}
+
+	addr := net.JoinHostPort(m.host, strconv.Itoa(m.port))
+	if err := smtp.SendMail(addr, auth, sender, []string{to}, []byte(message)); err != nil {
+		return fmt.Errorf("error al enviar correo a %s: %v", to, err)
+	}
+	return nil
+}
@@ -2,9 +2,12 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 	"strings"
 
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
 	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
 )
 
@@ -16,6 +19,8 @@ const (
 	UserIDKey ContextKey = "userID"
 	EmailKey  ContextKey = "email"
 	RoleKey   ContextKey = "role"
+	// ChallengeKey marca las solicitudes autenticadas con un token de desafío
+	ChallengeKey ContextKey = "challenge"
 )
 
 // ExtractToken extrae el token JWT del encabezado de autorización
@@ -39,30 +44,98 @@ func ExtractToken(r *http.Request) string {
 
 // Middleware de autenticación para validar tokens JWT
 func Auth(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Extraer token de la solicitud
-		tokenString := ExtractToken(r)
+	return SessionAuth(nil)(next)
+}
 
-		// Comprobar si el token existe
-		if tokenString == "" {
-			http.Error(w, "No autorizado: No se proporcionó un token", http.StatusUnauthorized)
-			return
-		}
+// SessionAuth valida tokens JWT como Auth y además rechaza los emitidos con una versión
+// de sesión anterior a la del usuario, por ejemplo antes de cambiar su contraseña.
+// currentVersion devuelve la versión vigente. Si no se puede comprobar la sesión se
+// rechaza el token: 401 si el usuario no existe y 503 si falla la consulta.
+func SessionAuth(currentVersion func(userID string) (int, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Extraer token de la solicitud
+			tokenString := ExtractToken(r)
+
+			// Comprobar si el token existe
+			if tokenString == "" {
+				http.Error(w, "No autorizado: No se proporcionó un token", http.StatusUnauthorized)
+				return
+			}
+
+			// Validar token
+			claims, err := utils.ValidateToken(tokenString)
+			if err != nil {
+				http.Error(w, "No autorizado: Token inválido", http.StatusUnauthorized)
+				return
+			}
+
+			// Rechazar sesiones cerradas
+			if currentVersion != nil {
+				version, err := currentVersion(claims.UserID)
+				switch {
+				case errors.Is(err, models.ErrUserNotFound):
+					http.Error(w, "No autorizado: Usuario no encontrado", http.StatusUnauthorized)
+					return
+				case err != nil:
+					log.Printf("Error al verificar la sesión del usuario %s: %v", claims.UserID, err)
+					http.Error(w, "No se pudo verificar la sesión", http.StatusServiceUnavailable)
+					return
+				case version != claims.SessionVersion:
+					http.Error(w, "No autorizado: La sesión fue cerrada", http.StatusUnauthorized)
+					return
+				}
+			}
+
+			// Agregar reclamaciones al contexto
+			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+			ctx = context.WithValue(ctx, EmailKey, claims.Email)
+			ctx = context.WithValue(ctx, RoleKey, claims.Role)
+
+			// Llamar al siguiente controlador con el contexto actualizado
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
-		// Validar token
-		claims, err := utils.ValidateToken(tokenString)
-		if err != nil {
-			http.Error(w, "No autorizado: Token inválido", http.StatusUnauthorized)
-			return
-		}
+// AllowChallenge acepta, además de las sesiones que valida auth, los tokens de desafío
+// del inicio de sesión en dos pasos. Sirve para que quien debe activar el segundo factor
+// pueda hacerlo antes de tener sesión; el contexto lleva ChallengeKey en true.
+func AllowChallenge(currentVersion func(userID string) (int, error), auth func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		authenticated := auth(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := utils.ValidateChallengeToken(ExtractToken(r))
+			if err != nil {
+				authenticated.ServeHTTP(w, r)
+				return
+			}
+
+			// Rechazar desafíos emitidos antes de cambiar la contraseña
+			if version, err := currentVersion(claims.UserID); err != nil || version != claims.SessionVersion {
+				http.Error(w, "No autorizado: Token inválido", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+			ctx = context.WithValue(ctx, EmailKey, claims.Email)
+			ctx = context.WithValue(ctx, RoleKey, claims.Role)
+			ctx = context.WithValue(ctx, ChallengeKey, true)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
-		// Agregar reclamaciones al contexto
-		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
-		ctx = context.WithValue(ctx, EmailKey, claims.Email)
-		ctx = context.WithValue(ctx, RoleKey, claims.Role)
+// TokenFromQuery copia el token de ?token= al encabezado de autorización cuando este
+// falta. Sirve para los WebSocket, a los que los navegadores no pueden agregar encabezados.
+func TokenFromQuery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
 
-		// Llamar al siguiente controlador con el contexto actualizado
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r)
 	})
 }
 
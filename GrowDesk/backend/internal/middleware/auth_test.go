@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+func TestSessionAuthFailsClosed(t *testing.T) {
+	token, err := utils.GenerateSessionToken("user-1", "agente@example.com", "agent", 2)
+	if err != nil {
+		t.Fatalf("GenerateSessionToken: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		version func(userID string) (int, error)
+		want    int
+	}{
+		{"sesión vigente", func(string) (int, error) { return 2, nil }, http.StatusOK},
+		{"sesión cerrada", func(string) (int, error) { return 3, nil }, http.StatusUnauthorized},
+		{"usuario eliminado", func(id string) (int, error) { return 0, fmt.Errorf("%w: %s", models.ErrUserNotFound, id) }, http.StatusUnauthorized},
+		{"almacenamiento no disponible", func(string) (int, error) { return 0, errors.New("conexión rechazada") }, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := SessionAuth(tt.version)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/auth/me", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("código %d, se esperaba %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,14 @@
 package models
 
 import (
+	"errors"
 	"time"
 )
 
+// ErrUserNotFound indica que el usuario no existe; permite distinguirlo de un error
+// al consultar el almacenamiento
+var ErrUserNotFound = errors.New("usuario no encontrado")
+
 // User representa un usuario en el sistema
 type User struct {
 	ID         string    `json:"id"`
@@ -19,6 +24,8 @@ type User struct {
 	Position   string    `json:"position,omitempty"`
 	Phone      string    `json:"phone,omitempty"`
 	Language   string    `json:"language,omitempty"`
+	// SessionVersion aumenta al cambiar la contraseña; invalida los tokens emitidos antes
+	SessionVersion int `json:"sessionVersion,omitempty"`
 }
 
 // LoginRequest representa los datos de la solicitud de inicio de sesión
@@ -41,6 +48,131 @@ type AuthResponse struct {
 	User  User   `json:"user"`
 }
 
+// Estados de una invitación
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
+
+// Invitation es la invitación de un administrador para que una persona cree su cuenta
+// con su propia contraseña. El enlace lleva un token de un solo uso que caduca; solo se
+// guarda su hash.
+type Invitation struct {
+	ID         string     `json:"id"`
+	Email      string     `json:"email"`
+	FirstName  string     `json:"firstName,omitempty"`
+	LastName   string     `json:"lastName,omitempty"`
+	Role       string     `json:"role"`
+	Department string     `json:"department,omitempty"`
+	TokenHash  string     `json:"tokenHash,omitempty"` // Solo se guarda; nunca se devuelve
+	Status     string     `json:"status"`              // Calculado al consultar
+	InvitedBy  string     `json:"invitedBy,omitempty"`
+	SendCount  int        `json:"sendCount"`
+	SentAt     time.Time  `json:"sentAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	AcceptedAt *time.Time `json:"acceptedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	UserID     string     `json:"userId,omitempty"` // Usuario creado al aceptar
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// InvitationRequest son los datos de una invitación nueva
+type InvitationRequest struct {
+	Email      string `json:"email"`
+	FirstName  string `json:"firstName,omitempty"`
+	LastName   string `json:"lastName,omitempty"`
+	Role       string `json:"role"`
+	Department string `json:"department,omitempty"`
+}
+
+// AcceptInvitationRequest son los datos con los que el invitado crea su cuenta
+type AcceptInvitationRequest struct {
+	Token     string `json:"token"`
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+	Password  string `json:"password"`
+}
+
+// PasswordReset es una solicitud para restablecer una contraseña olvidada. Solo se
+// guarda el hash del token enviado por correo.
+type PasswordReset struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"userId"`
+	TokenHash string     `json:"tokenHash"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// ForgotPasswordRequest pide un enlace para restablecer la contraseña
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest establece una contraseña nueva con el token del enlace
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// ChangePasswordRequest cambia la contraseña del usuario autenticado
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+// TwoFactor es la autenticación en dos pasos (TOTP) de un usuario. El secreto y los
+// hashes de los códigos de recuperación no se devuelven en las respuestas.
+type TwoFactor struct {
+	UserID        string     `json:"userId"`
+	Secret        string     `json:"secret,omitempty"`
+	Enabled       bool       `json:"enabled"`
+	RecoveryCodes []string   `json:"recoveryCodes,omitempty"` // Hashes de los códigos sin usar
+	LastUsedStep  int64      `json:"lastUsedStep,omitempty"`  // Último intervalo TOTP aceptado
+	EnabledAt     *time.Time `json:"enabledAt,omitempty"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// TwoFactorStatus es el estado de la autenticación en dos pasos de un usuario
+type TwoFactorStatus struct {
+	Enabled           bool       `json:"enabled"`
+	Required          bool       `json:"required"` // Obligatoria para el rol del usuario
+	EnabledAt         *time.Time `json:"enabledAt,omitempty"`
+	RecoveryCodesLeft int        `json:"recoveryCodesLeft"`
+}
+
+// TwoFactorSetup es el secreto nuevo para registrar en la aplicación de autenticación
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"` // URI otpauth:// para el código QR
+}
+
+// TwoFactorCodeRequest confirma una operación con un código TOTP o de recuperación
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recoveryCode,omitempty"`
+	Password     string `json:"password,omitempty"`
+}
+
+// TwoFactorVerifyRequest completa el inicio de sesión en dos pasos
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code,omitempty"`
+	RecoveryCode   string `json:"recoveryCode,omitempty"`
+}
+
+// LoginChallenge es la respuesta del primer paso del inicio de sesión cuando falta el
+// segundo factor. SetupRequired indica que el rol lo exige y el usuario aún no lo activó.
+type LoginChallenge struct {
+	TwoFactorRequired bool      `json:"twoFactorRequired"`
+	SetupRequired     bool      `json:"setupRequired,omitempty"`
+	ChallengeToken    string    `json:"challengeToken"`
+	ExpiresAt         time.Time `json:"expiresAt"`
+}
+
 // Ticket representa un ticket de soporte
 type Ticket struct {
 	ID          string    `json:"id"`
@@ -62,6 +194,16 @@ type Ticket struct {
 	WidgetID    string    `json:"widgetId,omitempty"`
 	Department  string    `json:"department,omitempty"`
 	Metadata    *Metadata `json:"metadata,omitempty"`
+
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
+	Tags         []string               `json:"tags,omitempty"`
+	MergedInto   string                 `json:"mergedInto,omitempty"` // Ticket destino si este ticket fue fusionado
+	Links        []TicketLink           `json:"links,omitempty"`      // Solo se completa al consultar un ticket
+	Watchers     []TicketWatcher        `json:"watchers,omitempty"`   // Solo se completa al consultar un ticket
+
+	// Valores heredados de la categoría al crear el ticket
+	AssigneeGroup string `json:"assigneeGroup,omitempty"`
+	SLAPolicy     string `json:"slaPolicy,omitempty"`
 }
 
 // Customer representa a un cliente de un ticket
@@ -76,6 +218,7 @@ type Message struct {
 	Content    string    `json:"content"`
 	IsClient   bool      `json:"isClient"`
 	IsInternal bool      `json:"isInternal,omitempty"`
+	Visibility string    `json:"visibility,omitempty"` // public, internal o system; ver NormalizeVisibility
 	Timestamp  time.Time `json:"timestamp"`
 	CreatedAt  time.Time `json:"createdAt,omitempty"`
 	UserID     string    `json:"userId,omitempty"`
@@ -83,11 +226,63 @@ type Message struct {
 	UserEmail  string    `json:"userEmail,omitempty"`
 }
 
+// Visibilidad de los mensajes de un ticket
+const (
+	MessageVisibilityPublic   = "public"   // Respuesta visible para el cliente
+	MessageVisibilityInternal = "internal" // Nota interna, solo para agentes
+	MessageVisibilitySystem   = "system"   // Evento del sistema, solo para agentes
+)
+
+// Destinatarios de una lectura de mensajes
+const (
+	MessageAudienceAgent    = "agent"    // Agentes y administradores: todos los mensajes
+	MessageAudienceCustomer = "customer" // Clientes y widget: solo respuestas públicas
+)
+
+// NormalizeVisibility completa Visibility a partir de IsInternal en los mensajes
+// guardados antes de existir el campo, y mantiene IsInternal para los clientes que
+// solo conocen ese indicador: todo mensaje no público se marca como interno.
+func (m *Message) NormalizeVisibility() {
+	switch m.Visibility {
+	case MessageVisibilityPublic, MessageVisibilityInternal, MessageVisibilitySystem:
+	default:
+		if m.IsInternal {
+			m.Visibility = MessageVisibilityInternal
+		} else {
+			m.Visibility = MessageVisibilityPublic
+		}
+	}
+	m.IsInternal = m.Visibility != MessageVisibilityPublic
+}
+
+// VisibleTo indica si el mensaje puede mostrarse al destinatario indicado. Un
+// destinatario desconocido se trata como cliente.
+func (m Message) VisibleTo(audience string) bool {
+	if audience == MessageAudienceAgent {
+		return true
+	}
+	m.NormalizeVisibility()
+	return m.Visibility == MessageVisibilityPublic
+}
+
+// FilterMessages devuelve los mensajes visibles para el destinatario, con la visibilidad normalizada
+func FilterMessages(messages []Message, audience string) []Message {
+	result := make([]Message, 0, len(messages))
+	for _, message := range messages {
+		if message.VisibleTo(audience) {
+			message.NormalizeVisibility()
+			result = append(result, message)
+		}
+	}
+	return result
+}
+
 // NewMessageRequest representa una solicitud para agregar un nuevo mensaje
 type NewMessageRequest struct {
 	Content    string `json:"content"`
 	IsClient   bool   `json:"isClient"`
 	IsInternal bool   `json:"isInternal,omitempty"`
+	Visibility string `json:"visibility,omitempty"` // public o internal; tiene prioridad sobre IsInternal
 	UserID     string `json:"userId,omitempty"`
 	UserName   string `json:"userName,omitempty"`
 	UserEmail  string `json:"userEmail,omitempty"`
@@ -101,31 +296,431 @@ type TicketUpdateRequest struct {
 	Category   string `json:"category,omitempty"`
 	Department string `json:"department,omitempty"`
 	Subject    string `json:"subject,omitempty"`
+
+	// CustomFields se combina con los valores actuales; un valor null elimina el campo
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
 }
 
-// Category representa una categoría de ticket
+// Category representa una categoría de ticket. Las categorías forman un árbol
+// (ParentID) y se ordenan entre hermanas por Position. Una categoría archivada
+// (Active = false) se conserva para los tickets existentes.
 type Category struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description,omitempty"`
-	Color       string    `json:"color,omitempty"`
-	Icon        string    `json:"icon,omitempty"`
-	Active      bool      `json:"active"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Color       string           `json:"color,omitempty"`
+	Icon        string           `json:"icon,omitempty"`
+	ParentID    string           `json:"parentId,omitempty"`
+	Position    int              `json:"position"`
+	Defaults    CategoryDefaults `json:"defaults"`
+	Active      bool             `json:"active"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	UpdatedAt   time.Time        `json:"updatedAt"`
+}
+
+// CategoryDefaults son los valores que una categoría aplica a los tickets nuevos. Los
+// valores vacíos se heredan de la categoría padre.
+type CategoryDefaults struct {
+	Department    string `json:"department,omitempty"`
+	Priority      string `json:"priority,omitempty"`
+	AssigneeGroup string `json:"assigneeGroup,omitempty"`
+	SLAPolicy     string `json:"slaPolicy,omitempty"`
+}
+
+// CategoryNode es una categoría con sus subcategorías, para mostrar el árbol
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
+}
+
+// Roles de los miembros de un equipo
+const (
+	TeamRoleLead   = "lead"
+	TeamRoleMember = "member"
+)
+
+// Team representa un equipo o departamento de agentes. Su ID es el valor que guardan
+// los campos Department de usuarios y tickets, y define la cola de tickets del equipo.
+type Team struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Description     string            `json:"description,omitempty"`
+	BusinessHoursID string            `json:"businessHoursId,omitempty"`
+	Queue           TeamQueueSettings `json:"queue"`
+	Members         []TeamMember      `json:"members"`
+	Active          bool              `json:"active"`
+	CreatedAt       time.Time         `json:"createdAt"`
+	UpdatedAt       time.Time         `json:"updatedAt"`
+}
+
+// TeamMember representa la pertenencia de un usuario a un equipo
+type TeamMember struct {
+	UserID   string    `json:"userId"`
+	Role     string    `json:"role"` // lead o member
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+// TeamQueueSettings configura la cola de tickets de un equipo
+type TeamQueueSettings struct {
+	DefaultPriority string `json:"defaultPriority,omitempty"` // Prioridad de los tickets nuevos sin prioridad
+	Order           string `json:"order,omitempty"`           // oldest, newest o priority
+	IncludeResolved bool   `json:"includeResolved"`           // Mostrar tickets resueltos y cerrados en la cola
+}
+
+// TeamQueue es la cola de tickets de un equipo
+type TeamQueue struct {
+	Team       Team     `json:"team"`
+	Total      int      `json:"total"`
+	Unassigned int      `json:"unassigned"`
+	Tickets    []Ticket `json:"tickets"`
+}
+
+// Estados de un agente
+const (
+	AgentStatusOnline  = "online"
+	AgentStatusAway    = "away"
+	AgentStatusMeeting = "meeting"
+	AgentStatusOffline = "offline"
+)
+
+// Tipos de habilidad de un agente
+const (
+	SkillKindLanguage = "language"
+	SkillKindProduct  = "product"
+)
+
+// AgentSkill es una habilidad de un agente (un idioma o un producto) con su nivel, de
+// 1 (básico) a 5 (experto)
+type AgentSkill struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind"`
+	Level int    `json:"level"`
+}
+
+// AgentProfile guarda lo que necesitan el enrutamiento y la planificación de un agente.
+// Sin Status el estado se deriva de su presencia.
+type AgentProfile struct {
+	UserID     string       `json:"userId"`
+	Status     string       `json:"status,omitempty"`
+	MaxChats   int          `json:"maxChats"`   // 0 sin límite
+	MaxTickets int          `json:"maxTickets"` // 0 sin límite
+	Skills     []AgentSkill `json:"skills"`
+	UpdatedAt  time.Time    `json:"updatedAt"`
 }
 
-// FAQ representa una pregunta frecuente
+// Agent es un agente con su perfil, su estado efectivo y su carga actual
+type Agent struct {
+	UserID       string       `json:"userId"`
+	Name         string       `json:"name"`
+	Email        string       `json:"email"`
+	Role         string       `json:"role"`
+	Department   string       `json:"department,omitempty"`
+	Status       string       `json:"status"`
+	StatusSource string       `json:"statusSource"` // manual o presence
+	LastSeen     *time.Time   `json:"lastSeen,omitempty"`
+	MaxChats     int          `json:"maxChats"`
+	MaxTickets   int          `json:"maxTickets"`
+	ActiveChats  int          `json:"activeChats"` // Tickets del widget asignados y abiertos
+	OpenTickets  int          `json:"openTickets"` // Todos los tickets asignados y abiertos
+	Available    bool         `json:"available"`   // En línea y con capacidad libre
+	Skills       []AgentSkill `json:"skills"`
+}
+
+// FAQ representa una pregunta frecuente. Se mantiene como vista de compatibilidad de
+// los artículos de la base de conocimiento (Question = título, Answer = cuerpo).
 type FAQ struct {
 	ID          int       `json:"id"`
 	Question    string    `json:"question"`
 	Answer      string    `json:"answer"`
 	Category    string    `json:"category"`
 	IsPublished bool      `json:"isPublished"`
+	Locale      string    `json:"locale,omitempty"` // Idioma del contenido devuelto
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
 }
 
+// Estados del flujo editorial de los artículos
+const (
+	ArticleStatusDraft     = "draft"
+	ArticleStatusReview    = "review"
+	ArticleStatusPublished = "published"
+)
+
+// Article representa un artículo de la base de conocimiento. Title, Body y CategoryID
+// son la versión de trabajo (la última revisión); el widget muestra la revisión
+// publicada (PublishedRevision), que puede ser anterior.
+type Article struct {
+	ID                int        `json:"id"`
+	Slug              string     `json:"slug"`
+	Locale            string     `json:"locale"` // Idioma original del artículo
+	Title             string     `json:"title"`
+	Body              string     `json:"body"` // Markdown
+	CategoryID        string     `json:"categoryId,omitempty"`
+	LegacyCategory    string     `json:"legacyCategory,omitempty"` // Categoría de texto libre de una FAQ migrada sin categoría equivalente
+	Status            string     `json:"status"`                   // draft, review o published
+	Revision          int        `json:"revision"`
+	PublishedRevision int        `json:"publishedRevision,omitempty"` // 0 = no publicado
+	AuthorID          string     `json:"authorId,omitempty"`
+	EditorID          string     `json:"editorId,omitempty"` // Autor de la última revisión
+	PublishedBy       string     `json:"publishedBy,omitempty"`
+	PublishedAt       *time.Time `json:"publishedAt,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+}
+
+// ArticleRevision es una versión guardada del contenido de un artículo
+type ArticleRevision struct {
+	ArticleID  int       `json:"articleId"`
+	Revision   int       `json:"revision"`
+	Title      string    `json:"title"`
+	Body       string    `json:"body"`
+	CategoryID string    `json:"categoryId,omitempty"`
+	EditorID   string    `json:"editorId,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// ArticleRequest representa la creación o edición de un artículo. Revision es la
+// revisión que el editor tenía al empezar; si no coincide con la actual, la edición
+// se rechaza para no pisar cambios ajenos.
+type ArticleRequest struct {
+	Title      string `json:"title"`
+	Slug       string `json:"slug"`
+	Body       string `json:"body"`
+	CategoryID string `json:"categoryId"`
+	Locale     string `json:"locale"`
+	Note       string `json:"note"`
+	Revision   int    `json:"revision,omitempty"`
+}
+
+// ArticleStatusRequest representa un cambio de estado del flujo editorial
+type ArticleStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// ArticleRollbackRequest representa la restauración de una revisión anterior
+type ArticleRollbackRequest struct {
+	Revision int    `json:"revision"`
+	Note     string `json:"note"`
+}
+
+// ArticleFilter representa los filtros del listado de artículos
+type ArticleFilter struct {
+	Status     string
+	CategoryID string
+}
+
+// ArticleTranslation es la traducción de un artículo a otro idioma. Tiene su propio
+// estado de publicación: Title y Body son la versión de trabajo y PublishedTitle y
+// PublishedBody lo que muestra el widget (PublishedAt nil = no publicada).
+type ArticleTranslation struct {
+	ArticleID      int        `json:"articleId"`
+	Locale         string     `json:"locale"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body"`           // Markdown
+	Status         string     `json:"status"`         // draft, review o published
+	SourceRevision int        `json:"sourceRevision"` // Revisión del artículo que se tradujo
+	PublishedTitle string     `json:"publishedTitle,omitempty"`
+	PublishedBody  string     `json:"publishedBody,omitempty"`
+	TranslatorID   string     `json:"translatorId,omitempty"`
+	PublishedBy    string     `json:"publishedBy,omitempty"`
+	PublishedAt    *time.Time `json:"publishedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// ArticleTranslationRequest representa la creación o edición de una traducción.
+// SourceRevision es la revisión del artículo de la que se tradujo (por defecto, la actual).
+type ArticleTranslationRequest struct {
+	Title          string `json:"title"`
+	Body           string `json:"body"`
+	SourceRevision int    `json:"sourceRevision,omitempty"`
+}
+
+// Motivos por los que a un artículo le falta una traducción
+const (
+	TranslationMissing     = "missing"     // No existe
+	TranslationUnpublished = "unpublished" // Existe pero no está publicada
+	TranslationOutdated    = "outdated"    // Se tradujo de una revisión anterior a la publicada
+)
+
+// TranslationGap representa una traducción pendiente de un artículo
+type TranslationGap struct {
+	ArticleID         int    `json:"articleId"`
+	Slug              string `json:"slug"`
+	Title             string `json:"title"`
+	ArticleStatus     string `json:"articleStatus"`
+	ArticleLocale     string `json:"articleLocale"`
+	Locale            string `json:"locale"`
+	Reason            string `json:"reason"`
+	PublishedRevision int    `json:"publishedRevision,omitempty"`
+	SourceRevision    int    `json:"sourceRevision,omitempty"`
+}
+
+// FAQSuggestion es una FAQ publicada sugerida al visitante del widget a partir del
+// borrador de su mensaje, antes de crear un ticket
+type FAQSuggestion struct {
+	FAQ
+	Slug  string  `json:"slug"`
+	Score float64 `json:"score"` // Relevancia (BM25); solo sirve para comparar sugerencias entre sí
+}
+
+// FAQSuggestResponse es la respuesta de /widget/faqs/suggest. El widget debe reenviar
+// SessionID en las siguientes sugerencias y al indicar que una FAQ resolvió su problema.
+type FAQSuggestResponse struct {
+	SessionID   string          `json:"sessionId"`
+	Suggestions []FAQSuggestion `json:"suggestions"`
+}
+
+// FAQDeflection registra que una FAQ se sugirió en una sesión del widget y si el
+// visitante indicó que resolvió su problema (SolvedAt)
+type FAQDeflection struct {
+	SessionID string     `json:"sessionId"`
+	ArticleID int        `json:"faqId"`
+	WidgetID  string     `json:"widgetId,omitempty"`
+	Query     string     `json:"query,omitempty"` // Borrador con el que se sugirió por primera vez
+	Locale    string     `json:"locale,omitempty"`
+	ShownAt   time.Time  `json:"shownAt"`
+	SolvedAt  *time.Time `json:"solvedAt,omitempty"`
+}
+
+// FAQSolvedRequest indica que una FAQ sugerida resolvió el problema del visitante
+type FAQSolvedRequest struct {
+	SessionID string `json:"sessionId"`
+	FAQID     int    `json:"faqId"`
+}
+
+// DeflectionFilter restringe los registros de deflexión por fecha en que se sugirieron
+type DeflectionFilter struct {
+	From time.Time // Desde este instante (inclusive)
+	To   time.Time // Antes de este instante
+}
+
+// DeflectionSummary resume las sugerencias de una FAQ
+type DeflectionSummary struct {
+	FAQID  int     `json:"faqId"`
+	Title  string  `json:"title"`
+	Shown  int     `json:"shown"`  // Sesiones en las que se sugirió
+	Solved int     `json:"solved"` // Sesiones en las que resolvió el problema
+	Rate   float64 `json:"rate"`   // Porcentaje de deflexión
+}
+
+// DeflectionReport es el informe de deflexión de tickets por FAQ en un período
+type DeflectionReport struct {
+	From           string              `json:"from,omitempty"`
+	To             string              `json:"to,omitempty"`
+	Sessions       int                 `json:"sessions"`       // Sesiones con alguna sugerencia
+	SolvedSessions int                 `json:"solvedSessions"` // Sesiones resueltas por una FAQ
+	Rate           float64             `json:"rate"`
+	FAQs           []DeflectionSummary `json:"faqs"`
+}
+
+// FAQView registra las veces que un visitante abrió una FAQ en una sesión del widget.
+// TicketID indica que el visitante creó un ticket en esa sesión después de verla.
+type FAQView struct {
+	SessionID     string    `json:"sessionId"`
+	ArticleID     int       `json:"faqId"`
+	WidgetID      string    `json:"widgetId,omitempty"`
+	Locale        string    `json:"locale,omitempty"`
+	Count         int       `json:"count"`
+	FirstViewedAt time.Time `json:"firstViewedAt"`
+	LastViewedAt  time.Time `json:"lastViewedAt"`
+	TicketID      string    `json:"ticketId,omitempty"`
+}
+
+// FAQViewRequest indica que el visitante abrió una FAQ
+type FAQViewRequest struct {
+	SessionID string `json:"sessionId"`
+	FAQID     int    `json:"faqId"`
+}
+
+// FAQVote es la respuesta de un visitante a "¿Te resultó útil?". Cada sesión tiene un
+// solo voto por FAQ; votar de nuevo lo reemplaza.
+type FAQVote struct {
+	SessionID string    `json:"sessionId"`
+	ArticleID int       `json:"faqId"`
+	WidgetID  string    `json:"widgetId,omitempty"`
+	Helpful   bool      `json:"helpful"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// FAQVoteRequest es el voto enviado desde el widget; Helpful es obligatorio
+type FAQVoteRequest struct {
+	SessionID string `json:"sessionId"`
+	FAQID     int    `json:"faqId"`
+	Helpful   *bool  `json:"helpful"`
+	Comment   string `json:"comment"`
+}
+
+// FAQActivityFilter restringe las visitas y los votos de FAQs por fecha
+type FAQActivityFilter struct {
+	From time.Time // Desde este instante (inclusive)
+	To   time.Time // Antes de este instante
+}
+
+// FAQComment es un comentario dejado al votar una FAQ
+type FAQComment struct {
+	Helpful   bool      `json:"helpful"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// FAQAnalytics resume el uso de una FAQ en el widget
+type FAQAnalytics struct {
+	FAQID       int          `json:"faqId"`
+	Title       string       `json:"title"`
+	Slug        string       `json:"slug"`
+	Status      string       `json:"status"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+	Views       int          `json:"views"`       // Veces que se abrió
+	Viewers     int          `json:"viewers"`     // Sesiones que la abrieron
+	Helpful     int          `json:"helpful"`     // Votos "sí"
+	NotHelpful  int          `json:"notHelpful"`  // Votos "no"
+	Helpfulness float64      `json:"helpfulness"` // Porcentaje de votos "sí"
+	Tickets     int          `json:"tickets"`     // Sesiones que crearon un ticket después de verla
+	TicketRate  float64      `json:"ticketRate"`  // Porcentaje de sesiones con ticket
+	Stale       bool         `json:"stale"`       // Sin actualizar en StaleMonths meses
+	Comments    []FAQComment `json:"comments"`    // Comentarios más recientes
+}
+
+// FAQAnalyticsReport es el informe de uso de las FAQs en un período
+type FAQAnalyticsReport struct {
+	From        string         `json:"from,omitempty"`
+	To          string         `json:"to,omitempty"`
+	StaleMonths int            `json:"staleMonths"`
+	Views       int            `json:"views"`
+	Votes       int            `json:"votes"`
+	Tickets     int            `json:"tickets"`
+	Stale       int            `json:"stale"` // FAQs publicadas sin actualizar
+	FAQs        []FAQAnalytics `json:"faqs"`
+}
+
+// Operaciones de una línea de diferencia entre revisiones
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffLine es una línea de la diferencia entre dos textos
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// ArticleDiff representa la diferencia entre dos revisiones de un artículo
+type ArticleDiff struct {
+	ArticleID      int        `json:"articleId"`
+	From           int        `json:"from"`
+	To             int        `json:"to"`
+	Title          []DiffLine `json:"title"`
+	Body           []DiffLine `json:"body"`
+	FromCategoryID string     `json:"fromCategoryId,omitempty"`
+	ToCategoryID   string     `json:"toCategoryId,omitempty"`
+}
+
 // WidgetTicketRequest representa una solicitud de creación de ticket desde el widget
 type WidgetTicketRequest struct {
 	Name     string    `json:"name"`
@@ -158,6 +753,17 @@ type WebSocketMessage struct {
 	Messages []Message   `json:"messages,omitempty"`
 }
 
+// TicketEvent representa un evento de ticket enviado a la API del widget
+type TicketEvent struct {
+	Type        string    `json:"type"`
+	TicketID    string    `json:"ticketId"`
+	Message     *Message  `json:"message,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	MergedInto  string    `json:"mergedInto,omitempty"`  // Ticket destino en los eventos de fusión
+	SurveyToken string    `json:"surveyToken,omitempty"` // Token de la encuesta de satisfacción
+	Timestamp   time.Time `json:"timestamp"`
+}
+
 // ErrorResponse representa una respuesta de error
 type ErrorResponse struct {
 	Error string `json:"error"`
@@ -279,4 +885,447 @@ type TicketRequest struct {
 	UserEmail   string    `json:"userEmail,omitempty"`
 	IsClient    bool      `json:"isClient"`
 	Metadata    *Metadata `json:"metadata,omitempty"`
+
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
+}
+
+// BusinessHours representa un horario de atención para un widget o departamento
+type BusinessHours struct {
+	ID                   string        `json:"id"`
+	Name                 string        `json:"name"`
+	WidgetID             string        `json:"widgetId,omitempty"`
+	Department           string        `json:"department,omitempty"`
+	TimeZone             string        `json:"timeZone"`
+	Schedule             []DaySchedule `json:"schedule"`
+	Holidays             []string      `json:"holidays,omitempty"` // Fechas en formato YYYY-MM-DD
+	OfflineMessage       string        `json:"offlineMessage,omitempty"`
+	ExpectedResponseTime string        `json:"expectedResponseTime,omitempty"`
+	CreatedAt            time.Time     `json:"createdAt"`
+	UpdatedAt            time.Time     `json:"updatedAt"`
+}
+
+// DaySchedule representa el horario de un día de la semana
+type DaySchedule struct {
+	Day   int    `json:"day"`   // 0 = domingo, 6 = sábado
+	Open  string `json:"open"`  // Formato HH:MM
+	Close string `json:"close"` // Formato HH:MM
+}
+
+// AgentPresence representa la presencia en línea de un agente
+type AgentPresence struct {
+	UserID     string    `json:"userId"`
+	Name       string    `json:"name,omitempty"`
+	Email      string    `json:"email,omitempty"`
+	Department string    `json:"department,omitempty"`
+	LastSeen   time.Time `json:"lastSeen"`
+}
+
+// WidgetStatus representa la disponibilidad del chat en vivo para un widget
+type WidgetStatus struct {
+	Online               bool       `json:"online"`
+	LiveChatAvailable    bool       `json:"liveChatAvailable"`
+	WithinBusinessHours  bool       `json:"withinBusinessHours"`
+	AgentsOnline         int        `json:"agentsOnline"`
+	OfflineMode          bool       `json:"offlineMode"`
+	Message              string     `json:"message,omitempty"`
+	ExpectedResponseTime string     `json:"expectedResponseTime,omitempty"`
+	NextOpenAt           *time.Time `json:"nextOpenAt,omitempty"`
+	TimeZone             string     `json:"timeZone,omitempty"`
+}
+
+// Tipos de campo admitidos en formularios previos al chat
+const (
+	PreChatFieldText     = "text"
+	PreChatFieldEmail    = "email"
+	PreChatFieldSelect   = "select"
+	PreChatFieldCheckbox = "checkbox"
+	PreChatFieldTextarea = "textarea"
+)
+
+// PreChatForm representa el formulario que el visitante completa antes de iniciar el chat
+type PreChatForm struct {
+	ID          string         `json:"id"`
+	WidgetID    string         `json:"widgetId,omitempty"` // Vacío para el formulario por defecto
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Fields      []PreChatField `json:"fields"`
+	Active      bool           `json:"active"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+}
+
+// PreChatField representa un campo de un formulario previo al chat
+type PreChatField struct {
+	Key         string   `json:"key"`
+	Label       string   `json:"label"`
+	Type        string   `json:"type"`
+	Required    bool     `json:"required"`
+	Placeholder string   `json:"placeholder,omitempty"`
+	Options     []string `json:"options,omitempty"` // Solo para campos select
+	MinLength   int      `json:"minLength,omitempty"`
+	MaxLength   int      `json:"maxLength,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"` // Expresión regular opcional
+	// MapTo indica dónde se guarda el valor en el ticket: name, email, subject, message,
+	// priority, department, category, tags (texto separado por comas; en checkbox, la clave
+	// del campo si está marcado) o customField:<clave>
+	MapTo string `json:"mapTo,omitempty"`
+}
+
+// Tipos de campos personalizados de tickets
+const (
+	CustomFieldString    = "string"
+	CustomFieldNumber    = "number"
+	CustomFieldDate      = "date" // Formato AAAA-MM-DD
+	CustomFieldEnum      = "enum"
+	CustomFieldMultiEnum = "multi_enum"
+	CustomFieldBoolean   = "boolean"
+)
+
+// CustomFieldDefinition define un campo personalizado de ticket creado por un administrador
+type CustomFieldDefinition struct {
+	ID          string    `json:"id"`
+	Key         string    `json:"key"`
+	Label       string    `json:"label"`
+	Type        string    `json:"type"`
+	CategoryID  string    `json:"categoryId,omitempty"` // Vacío: aplica a todas las categorías
+	Required    bool      `json:"required"`
+	Options     []string  `json:"options,omitempty"` // Solo para enum y multi_enum
+	Description string    `json:"description,omitempty"`
+	Position    int       `json:"position"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// TicketFilter contiene los filtros y el orden para listar tickets
+type TicketFilter struct {
+	Status       string
+	Priority     string
+	CategoryID   string
+	AssignedTo   string
+	Department   string
+	Source       string
+	Tags         []string // El ticket debe tener todas las etiquetas
+	WatchedBy    string   // El usuario indicado sigue el ticket
+	CustomFields []CustomFieldFilter
+
+	// SortBy es un campo estándar (createdAt, updatedAt, priority, status, title)
+	// o un campo personalizado; SortField contiene su definición en ese caso
+	SortBy    string
+	SortField *CustomFieldDefinition
+	SortDesc  bool
+}
+
+// Operadores de filtro para campos personalizados
+const (
+	CustomFieldFilterEq  = "eq"  // Igualdad; en multi_enum, contiene el valor
+	CustomFieldFilterMin = "min" // Mayor o igual (number y date)
+	CustomFieldFilterMax = "max" // Menor o igual (number y date)
+)
+
+// CustomFieldFilter filtra tickets por el valor de un campo personalizado
+type CustomFieldFilter struct {
+	Key   string
+	Type  string
+	Op    string
+	Value interface{} // Valor ya normalizado al tipo del campo
+}
+
+// Tag representa una etiqueta libre de tickets con su número de usos
+type Tag struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Color      string    `json:"color,omitempty"`
+	UsageCount int       `json:"usageCount"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// TagReport resume los tickets de una etiqueta por estado
+type TagReport struct {
+	Tag      string         `json:"tag"`
+	Total    int            `json:"total"`
+	ByStatus map[string]int `json:"byStatus"`
+}
+
+// TicketTagsRequest representa una solicitud para añadir o quitar etiquetas de tickets
+type TicketTagsRequest struct {
+	TicketIDs []string `json:"ticketIds,omitempty"` // Solo para operaciones masivas
+	Add       []string `json:"add,omitempty"`
+	Remove    []string `json:"remove,omitempty"`
+	Tags      []string `json:"tags,omitempty"` // Atajo para /api/tickets/:id/tags
+}
+
+// Tipos de actividad registrados sobre tickets
+const (
+	ActivityTicketBulkUpdate = "ticket_bulk_update"
+	ActivityTicketBulkDelete = "ticket_bulk_delete"
+	ActivityTicketMerged     = "ticket_merged"
+	ActivityTicketSplit      = "ticket_split"
+
+	ActivityTicketIncidentResolved = "ticket_incident_resolved"
+	ActivityTicketMacroApplied     = "ticket_macro_applied"
+	ActivityAutomationRuleApplied  = "automation_rule_applied"
+)
+
+// BulkTicketChanges describe los cambios de una operación masiva; solo se aplican los campos indicados
+type BulkTicketChanges struct {
+	Status     string   `json:"status,omitempty"`
+	Priority   string   `json:"priority,omitempty"`
+	AssignedTo *string  `json:"assignedTo,omitempty"` // Una cadena vacía quita la asignación
+	CategoryID string   `json:"categoryId,omitempty"`
+	Category   string   `json:"category,omitempty"` // Nombre de la categoría, resuelto a partir de CategoryID
+	AddTags    []string `json:"addTags,omitempty"`
+	RemoveTags []string `json:"removeTags,omitempty"`
+	Delete     bool     `json:"delete,omitempty"`
+}
+
+// BulkTicketRequest representa una operación masiva sobre una lista de tickets o un filtro
+type BulkTicketRequest struct {
+	TicketIDs     []string          `json:"ticketIds,omitempty"`
+	Filter        string            `json:"filter,omitempty"` // Misma sintaxis que GET /api/tickets, p. ej. "status=open&tags=vip"
+	Changes       BulkTicketChanges `json:"changes"`
+	DryRun        bool              `json:"dryRun,omitempty"`
+	Transactional bool              `json:"transactional,omitempty"` // Todo o nada: si un ticket falla no se aplica ningún cambio
+}
+
+// BulkTicketResult representa el resultado de una operación masiva para un ticket
+type BulkTicketResult struct {
+	TicketID string `json:"ticketId"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+}
+
+// BulkTicketResponse resume una operación masiva sobre tickets
+type BulkTicketResponse struct {
+	DryRun     bool               `json:"dryRun"`
+	Matched    int                `json:"matched"`
+	Updated    int                `json:"updated"`
+	Failed     int                `json:"failed"`
+	RolledBack bool               `json:"rolledBack,omitempty"`
+	Results    []BulkTicketResult `json:"results"`
+}
+
+// MergeTicketsRequest representa una solicitud para fusionar tickets en el ticket de la URL
+type MergeTicketsRequest struct {
+	SourceIDs []string `json:"sourceIds"`
+}
+
+// SplitTicketRequest representa una solicitud para mover mensajes de un ticket a uno nuevo
+type SplitTicketRequest struct {
+	MessageIDs []string `json:"messageIds"`
+	Title      string   `json:"title,omitempty"`
+}
+
+// Tipos de relación entre tickets. Cada relación se guarda en una sola dirección
+// (duplicate_of, related_to, child_of, blocks) y desde el otro ticket se muestra
+// con el tipo inverso.
+const (
+	TicketLinkDuplicateOf  = "duplicate_of"
+	TicketLinkDuplicatedBy = "duplicated_by"
+	TicketLinkRelatedTo    = "related_to"
+	TicketLinkChildOf      = "child_of"
+	TicketLinkParentOf     = "parent_of"
+	TicketLinkBlocks       = "blocks"
+	TicketLinkBlockedBy    = "blocked_by"
+)
+
+// TicketLink representa una relación entre dos tickets
+type TicketLink struct {
+	ID             string    `json:"id"`
+	TicketID       string    `json:"ticketId"`
+	LinkedTicketID string    `json:"linkedTicketId"`
+	Type           string    `json:"type"`
+	CreatedBy      string    `json:"createdBy,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+
+	// Datos del ticket enlazado, solo en las respuestas de la API
+	LinkedTicketTitle  string `json:"linkedTicketTitle,omitempty"`
+	LinkedTicketStatus string `json:"linkedTicketStatus,omitempty"`
+}
+
+// TicketLinkRequest representa una solicitud para enlazar un ticket con otro
+type TicketLinkRequest struct {
+	LinkedTicketID string `json:"linkedTicketId"`
+	Type           string `json:"type"`
+}
+
+// ResolveIncidentRequest resuelve un ticket padre y, opcionalmente, todos sus hijos
+// enviando a cada uno la misma respuesta
+type ResolveIncidentRequest struct {
+	Message         string `json:"message,omitempty"`
+	ResolveChildren bool   `json:"resolveChildren"`
+	Status          string `json:"status,omitempty"` // resolved (por defecto) o closed
+}
+
+// TicketWatcher representa a un usuario que sigue un ticket y recibe notificaciones de sus cambios
+type TicketWatcher struct {
+	TicketID  string    `json:"ticketId"`
+	UserID    string    `json:"userId"`
+	AddedBy   string    `json:"addedBy,omitempty"` // Usuario que lo añadió (él mismo, otro agente o quien lo mencionó)
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TicketWatchersRequest es el cuerpo para añadir seguidores a un ticket
+type TicketWatchersRequest struct {
+	UserIDs []string `json:"userIds"` // Vacío: el usuario actual
+}
+
+// TicketActions son cambios que se aplican a un ticket al usar una macro; solo se
+// aplican los campos indicados
+type TicketActions struct {
+	Status     string   `json:"status,omitempty"`
+	Priority   string   `json:"priority,omitempty"`
+	AssignedTo *string  `json:"assignedTo,omitempty"` // "" deja el ticket sin asignar
+	AddTags    []string `json:"addTags,omitempty"`
+	RemoveTags []string `json:"removeTags,omitempty"`
+}
+
+// Macro es una respuesta predefinida para agentes con marcadores como {{customer.name}}
+// y acciones opcionales sobre el ticket
+type Macro struct {
+	ID         string        `json:"id"`
+	Name       string        `json:"name"`
+	Content    string        `json:"content,omitempty"`
+	IsInternal bool          `json:"isInternal,omitempty"` // El mensaje se agrega como nota interna
+	Shared     bool          `json:"shared"`               // Visible para todos los agentes; si no, solo para su propietario
+	OwnerID    string        `json:"ownerId,omitempty"`
+	Actions    TicketActions `json:"actions"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
+}
+
+// MacroResult es el resultado de aplicar (o previsualizar) una macro sobre un ticket
+type MacroResult struct {
+	Ticket  *Ticket  `json:"ticket"`
+	Content string   `json:"content,omitempty"` // Texto con los marcadores ya reemplazados
+	Message *Message `json:"message,omitempty"` // Mensaje agregado; vacío en la vista previa
+}
+
+// Tipos de notificación para usuarios
+const (
+	NotificationTypeAutomation = "automation"
+	NotificationTypeMention    = "mention"
+	NotificationTypeWatch      = "ticket_watch"
+)
+
+// Eventos de ticket que disparan reglas de automatización
+const (
+	AutomationEventTicketCreated = "ticket_created"
+	AutomationEventTicketUpdated = "ticket_updated"
+	AutomationEventMessageAdded  = "message_added"
+	AutomationEventScheduled     = "scheduled" // Evaluado periódicamente sobre todos los tickets
+)
+
+// AutomationCondition compara un campo del ticket (o del mensaje que disparó la regla)
+// con un valor. Ver el paquete automation para los campos y operadores admitidos.
+type AutomationCondition struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value,omitempty"`
+}
+
+// AutomationActions son las acciones de una regla; solo se ejecutan las indicadas
+type AutomationActions struct {
+	TicketActions
+	CategoryID   string                 `json:"categoryId,omitempty"`
+	Department   string                 `json:"department,omitempty"`
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
+	MacroID      string                 `json:"macroId,omitempty"`    // Responde con el texto de una macro compartida
+	Notify       []string               `json:"notify,omitempty"`     // IDs de usuarios; "assignee" es el agente asignado
+	WebhookURL   string                 `json:"webhookUrl,omitempty"` // Recibe un POST con el evento y el ticket
+}
+
+// AutomationRule es una regla definida por un administrador: cuando ocurre uno de sus
+// eventos y se cumplen sus condiciones, se ejecutan sus acciones
+type AutomationRule struct {
+	ID             string                `json:"id"`
+	Name           string                `json:"name"`
+	Description    string                `json:"description,omitempty"`
+	Events         []string              `json:"events"`
+	Match          string                `json:"match,omitempty"` // all (por defecto) o any
+	Conditions     []AutomationCondition `json:"conditions"`
+	Actions        AutomationActions     `json:"actions"`
+	Position       int                   `json:"position"`
+	Active         bool                  `json:"active"`
+	StopProcessing bool                  `json:"stopProcessing,omitempty"` // No evaluar reglas posteriores si esta se aplica
+	CreatedAt      time.Time             `json:"createdAt"`
+	UpdatedAt      time.Time             `json:"updatedAt"`
+}
+
+// AutomationRuleRun registra que una regla programada ya se aplicó a un ticket. Se
+// elimina cuando el ticket deja de cumplir las condiciones, para que la regla pueda
+// volver a aplicarse más adelante.
+type AutomationRuleRun struct {
+	RuleID   string    `json:"ruleId"`
+	TicketID string    `json:"ticketId"`
+	FiredAt  time.Time `json:"firedAt"`
+}
+
+// AutomationWebhookPayload es el cuerpo enviado a la URL de webhook de una regla
+type AutomationWebhookPayload struct {
+	Event     string    `json:"event"`
+	RuleID    string    `json:"ruleId"`
+	RuleName  string    `json:"ruleName"`
+	Ticket    Ticket    `json:"ticket"`
+	Message   *Message  `json:"message,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SatisfactionSurvey es una encuesta de satisfacción (CSAT) enviada al cliente al
+// resolverse un ticket. Rating es nil mientras no se responda.
+type SatisfactionSurvey struct {
+	ID          string     `json:"id"`
+	TokenHash   string     `json:"tokenHash,omitempty"` // Solo se guarda; nunca se devuelve
+	TicketID    string     `json:"ticketId"`
+	AgentID     string     `json:"agentId,omitempty"`
+	CategoryID  string     `json:"categoryId,omitempty"`
+	Category    string     `json:"category,omitempty"`
+	Rating      *int       `json:"rating,omitempty"` // 1 a 5
+	Comment     string     `json:"comment,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	RespondedAt *time.Time `json:"respondedAt,omitempty"`
+}
+
+// SurveyFilter restringe las encuestas consultadas; los campos vacíos no filtran
+type SurveyFilter struct {
+	TicketID string
+	From     time.Time // Encuestas enviadas desde este instante (inclusive)
+	To       time.Time // Encuestas enviadas antes de este instante
+}
+
+// SurveyResponseRequest es la respuesta del cliente a una encuesta
+type SurveyResponseRequest struct {
+	Rating  int    `json:"rating"`
+	Comment string `json:"comment"`
+}
+
+// PublicSurvey es la información de una encuesta visible para el cliente
+type PublicSurvey struct {
+	TicketID    string `json:"ticketId"`
+	TicketTitle string `json:"ticketTitle"`
+	Answered    bool   `json:"answered"`
+	Expired     bool   `json:"expired"`
+	Rating      *int   `json:"rating,omitempty"`
+}
+
+// CSATSummary resume las encuestas de un grupo (agente, categoría o período)
+type CSATSummary struct {
+	Key           string  `json:"key"`
+	Label         string  `json:"label"`
+	Sent          int     `json:"sent"`
+	Responses     int     `json:"responses"`
+	ResponseRate  float64 `json:"responseRate"`  // Porcentaje de encuestas respondidas
+	AverageRating float64 `json:"averageRating"` // Valoración media de 1 a 5
+	Score         float64 `json:"score"`         // CSAT: porcentaje de valoraciones 4 o 5
+}
+
+// CSATReport es el informe de satisfacción de un período agrupado por agente, categoría o período
+type CSATReport struct {
+	From    string        `json:"from,omitempty"`
+	To      string        `json:"to,omitempty"`
+	GroupBy string        `json:"groupBy"`
+	Overall CSATSummary   `json:"overall"`
+	Groups  []CSATSummary `json:"groups"`
 }
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMessageVisibleTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  Message
+		audience string
+		want     bool
+	}{
+		{"pública para cliente", Message{Visibility: MessageVisibilityPublic}, MessageAudienceCustomer, true},
+		{"pública para agente", Message{Visibility: MessageVisibilityPublic}, MessageAudienceAgent, true},
+		{"interna para cliente", Message{Visibility: MessageVisibilityInternal}, MessageAudienceCustomer, false},
+		{"interna para agente", Message{Visibility: MessageVisibilityInternal}, MessageAudienceAgent, true},
+		{"sistema para cliente", Message{Visibility: MessageVisibilitySystem}, MessageAudienceCustomer, false},
+		{"sistema para agente", Message{Visibility: MessageVisibilitySystem}, MessageAudienceAgent, true},
+		{"nota anterior a la visibilidad para cliente", Message{IsInternal: true}, MessageAudienceCustomer, false},
+		{"respuesta anterior a la visibilidad para cliente", Message{}, MessageAudienceCustomer, true},
+		{"visibilidad desconocida con IsInternal", Message{Visibility: "otra", IsInternal: true}, MessageAudienceCustomer, false},
+		{"interna para destinatario desconocido", Message{Visibility: MessageVisibilityInternal}, "", false},
+		{"sistema para destinatario desconocido", Message{Visibility: MessageVisibilitySystem}, "visitor", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.message.VisibleTo(tt.audience); got != tt.want {
+				t.Errorf("VisibleTo(%q) = %v, se esperaba %v", tt.audience, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterMessages(t *testing.T) {
+	messages := []Message{
+		{ID: "public", Visibility: MessageVisibilityPublic},
+		{ID: "internal", Visibility: MessageVisibilityInternal},
+		{ID: "system", Visibility: MessageVisibilitySystem},
+		{ID: "legacy-note", IsInternal: true},
+		{ID: "legacy-reply"},
+	}
+
+	tests := []struct {
+		audience string
+		want     []string
+	}{
+		{MessageAudienceCustomer, []string{"public", "legacy-reply"}},
+		{"", []string{"public", "legacy-reply"}},
+		{MessageAudienceAgent, []string{"public", "internal", "system", "legacy-note", "legacy-reply"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.audience, func(t *testing.T) {
+			filtered := FilterMessages(messages, tt.audience)
+
+			ids := make([]string, 0, len(filtered))
+			for _, message := range filtered {
+				ids = append(ids, message.ID)
+				if message.Visibility == "" {
+					t.Errorf("el mensaje %s no tiene la visibilidad normalizada", message.ID)
+				}
+				if message.IsInternal != (message.Visibility != MessageVisibilityPublic) {
+					t.Errorf("el mensaje %s tiene IsInternal=%v con visibilidad %s", message.ID, message.IsInternal, message.Visibility)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("FilterMessages(%q) = %v, se esperaba %v", tt.audience, ids, tt.want)
+			}
+		})
+	}
+
+	// El filtrado no modifica los mensajes originales
+	if messages[3].Visibility != "" {
+		t.Errorf("FilterMessages modificó el mensaje original: %+v", messages[3])
+	}
+}
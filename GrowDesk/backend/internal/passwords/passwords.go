@@ -0,0 +1,169 @@
+// Package passwords gestiona el cambio y el restablecimiento de contraseñas: el usuario
+// pide un enlace con un token de un solo uso que caduca, elige una contraseña nueva y
+// todas sus sesiones abiertas se cierran.
+package passwords
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/mailer"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// DefaultTTL es la vigencia por defecto del enlace de restablecimiento
+const DefaultTTL = time.Hour
+
+// TokenPlaceholder se reemplaza por el token en la URL de restablecimiento
+const TokenPlaceholder = "{token}"
+
+// ErrWrongPassword indica que la contraseña actual no es correcta
+var ErrWrongPassword = errors.New("La contraseña actual es incorrecta")
+
+// Service envía enlaces de restablecimiento y cambia contraseñas
+type Service struct {
+	Store    data.DataStore
+	Mailer   *mailer.Mailer
+	ResetURL string // URL de restablecimiento para el correo, con {token}
+	TTL      time.Duration
+}
+
+// NewService crea un servicio de contraseñas
+func NewService(store data.DataStore, m *mailer.Mailer, resetURL string, ttl time.Duration) *Service {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Service{Store: store, Mailer: m, ResetURL: resetURL, TTL: ttl}
+}
+
+// HashToken devuelve el hash con el que se guarda el token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Forgot envía un enlace para restablecer la contraseña del usuario con ese email. No
+// indica si el email existe: con un email desconocido no hace nada y no falla.
+func (s *Service) Forgot(email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return fmt.Errorf("El email es requerido")
+	}
+
+	user, err := s.Store.GetUserByEmail(email)
+	if err != nil {
+		return nil
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return fmt.Errorf("error al generar token: %v", err)
+	}
+
+	now := time.Now()
+	reset := models.PasswordReset{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		TokenHash: HashToken(token),
+		ExpiresAt: now.Add(s.TTL),
+		CreatedAt: now,
+	}
+	if err := s.Store.CreatePasswordReset(reset); err != nil {
+		return err
+	}
+
+	// Enviar en segundo plano para que el tiempo de respuesta no revele si el email existe
+	go s.deliver(*user, reset, token)
+	return nil
+}
+
+// Reset establece la contraseña nueva con el token del enlace, consume el token y
+// cierra todas las sesiones del usuario
+func (s *Service) Reset(req models.ResetPasswordRequest) error {
+	token := strings.TrimSpace(req.Token)
+	if token == "" {
+		return fmt.Errorf("El token es requerido")
+	}
+	reset, err := s.Store.GetPasswordResetByTokenHash(HashToken(token))
+	if err != nil || reset.UsedAt != nil {
+		return fmt.Errorf("El enlace no es válido o ya se usó; solicite uno nuevo")
+	}
+	if time.Now().After(reset.ExpiresAt) {
+		return fmt.Errorf("El enlace caducó; solicite uno nuevo")
+	}
+	if err := utils.ValidatePassword(req.Password); err != nil {
+		return err
+	}
+
+	passwordHash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+	if err := s.Store.ResetPassword(reset.ID, passwordHash); err != nil {
+		return fmt.Errorf("El enlace no es válido o ya se usó; solicite uno nuevo")
+	}
+	return nil
+}
+
+// Change cambia la contraseña del usuario si la actual es correcta. Las demás sesiones
+// del usuario se cierran.
+func (s *Service) Change(userID string, req models.ChangePasswordRequest) error {
+	stored, err := s.Store.GetUserPasswordHash(userID)
+	if err != nil {
+		return err
+	}
+	if !utils.CheckPassword(stored, req.CurrentPassword) {
+		return ErrWrongPassword
+	}
+	if err := utils.ValidatePassword(req.NewPassword); err != nil {
+		return err
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return fmt.Errorf("La nueva contraseña debe ser distinta de la actual")
+	}
+
+	passwordHash, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	return s.Store.UpdateUserPassword(userID, passwordHash)
+}
+
+// deliver envía el enlace de restablecimiento por correo
+func (s *Service) deliver(user models.User, reset models.PasswordReset, token string) {
+	if !s.Mailer.Enabled() || s.ResetURL == "" {
+		log.Printf("No se envió el restablecimiento de contraseña %s: falta configurar el correo o PASSWORD_RESET_URL", reset.ID)
+		return
+	}
+
+	name := user.FirstName
+	if name == "" {
+		name = user.Email
+	}
+	link := strings.ReplaceAll(s.ResetURL, TokenPlaceholder, token)
+	subject := "Restablecer tu contraseña de GrowDesk"
+	body := fmt.Sprintf("Hola %s,\n\nRecibimos una solicitud para restablecer tu contraseña. Para elegir una nueva, abre este enlace:\n\n%s\n\nEl enlace se puede usar una sola vez y vence el %s. Al cambiar la contraseña se cerrarán tus sesiones abiertas.\n\nSi no lo solicitaste, puedes ignorar este correo; tu contraseña no cambiará.\n",
+		name, link, reset.ExpiresAt.Format("02/01/2006 15:04"))
+
+	if err := s.Mailer.Send(user.Email, subject, body); err != nil {
+		log.Printf("Error al enviar restablecimiento de contraseña %s: %v", reset.ID, err)
+	}
+}
+
+// newToken genera un token aleatorio para el enlace de restablecimiento
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
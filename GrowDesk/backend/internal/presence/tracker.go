@@ -0,0 +1,75 @@
+package presence
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/teams"
+)
+
+// DefaultTTL es el tiempo sin latidos tras el cual un agente se considera desconectado
+const DefaultTTL = 2 * time.Minute
+
+// Tracker mantiene en memoria la presencia de los agentes a partir de latidos periódicos
+type Tracker struct {
+	mu     sync.RWMutex
+	ttl    time.Duration
+	agents map[string]models.AgentPresence
+}
+
+// NewTracker crea un registro de presencia con el TTL indicado
+func NewTracker(ttl time.Duration) *Tracker {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	return &Tracker{
+		ttl:    ttl,
+		agents: make(map[string]models.AgentPresence),
+	}
+}
+
+// Heartbeat registra que un agente sigue conectado
+func (t *Tracker) Heartbeat(agent models.AgentPresence) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	agent.LastSeen = time.Now()
+	t.agents[agent.UserID] = agent
+}
+
+// SetOffline marca a un agente como desconectado de inmediato
+func (t *Tracker) SetOffline(userID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.agents, userID)
+}
+
+// Online devuelve los agentes conectados. Si department no está vacío, solo se
+// incluyen los agentes de ese departamento (o equipo) y los que no tienen departamento
+// asignado.
+func (t *Tracker) Online(department string) []models.AgentPresence {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	cutoff := time.Now().Add(-t.ttl)
+	online := make([]models.AgentPresence, 0)
+	for _, agent := range t.agents {
+		if agent.LastSeen.Before(cutoff) {
+			continue
+		}
+		if department != "" && agent.Department != "" && teams.Key(agent.Department) != teams.Key(department) {
+			continue
+		}
+		online = append(online, agent)
+	}
+
+	sort.Slice(online, func(i, j int) bool {
+		return online[i].UserID < online[j].UserID
+	})
+
+	return online
+}
@@ -0,0 +1,51 @@
+// Package tags normaliza las etiquetas libres de los tickets.
+package tags
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxLength es la longitud máxima de una etiqueta
+const MaxLength = 50
+
+// Normalize limpia una etiqueta: minúsculas, sin espacios sobrantes y con longitud acotada.
+// Devuelve "" si la etiqueta queda vacía.
+func Normalize(name string) string {
+	name = strings.Join(strings.Fields(strings.ToLower(name)), " ")
+	if utf8.RuneCountInString(name) > MaxLength {
+		name = string([]rune(name)[:MaxLength])
+		name = strings.TrimSpace(name)
+	}
+	return name
+}
+
+// NormalizeAll normaliza una lista de etiquetas descartando vacías y duplicadas
+func NormalizeAll(names []string) []string {
+	result := make([]string, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = Normalize(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	return result
+}
+
+// Split separa una lista de etiquetas escrita como texto ("facturación, urgente")
+func Split(value string) []string {
+	return NormalizeAll(strings.Split(value, ","))
+}
+
+// Contains indica si la lista incluye la etiqueta
+func Contains(list []string, name string) bool {
+	for _, item := range list {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
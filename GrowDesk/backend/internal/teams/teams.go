@@ -0,0 +1,216 @@
+// Package teams reúne las reglas de los equipos de agentes: la relación entre los
+// equipos y los departamentos de usuarios y tickets, los miembros y responsables, la
+// cola de tickets de cada equipo y los permisos de los responsables sobre ella.
+package teams
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/kb"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// DefaultTeamID es el departamento que reciben los tickets del widget sin departamento
+const DefaultTeamID = "soporte"
+
+// Órdenes de la cola de tickets de un equipo
+const (
+	OrderOldest   = "oldest"
+	OrderNewest   = "newest"
+	OrderPriority = "priority"
+)
+
+// Key normaliza un departamento para compararlo con el ID de un equipo. Los
+// departamentos guardados como texto libre ("Tecnología") corresponden al equipo
+// "tecnologia".
+func Key(department string) string {
+	if strings.TrimSpace(department) == "" {
+		return ""
+	}
+	return kb.Slugify(department)
+}
+
+// Find devuelve el equipo al que corresponde el departamento, o nil
+func Find(list []models.Team, department string) *models.Team {
+	key := Key(department)
+	if key == "" {
+		return nil
+	}
+	for i := range list {
+		if list[i].ID == key {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+// Resolve devuelve el ID del equipo activo al que corresponde el departamento. Un
+// departamento vacío es válido y se devuelve vacío.
+func Resolve(list []models.Team, department string) (string, error) {
+	if strings.TrimSpace(department) == "" {
+		return "", nil
+	}
+	team := Find(list, department)
+	if team == nil {
+		return "", fmt.Errorf("Equipo no encontrado: %s", department)
+	}
+	if !team.Active {
+		return "", fmt.Errorf("El equipo %s está archivado", team.Name)
+	}
+	return team.ID, nil
+}
+
+// InTeam indica si el departamento corresponde al equipo
+func InTeam(team models.Team, department string) bool {
+	return Key(department) == team.ID
+}
+
+// MemberRole devuelve el rol del usuario en el equipo, o "" si no es miembro
+func MemberRole(team models.Team, userID string) string {
+	for _, member := range team.Members {
+		if member.UserID == userID {
+			return member.Role
+		}
+	}
+	return ""
+}
+
+// IsLead indica si el usuario es responsable del equipo
+func IsLead(team models.Team, userID string) bool {
+	return MemberRole(team, userID) == models.TeamRoleLead
+}
+
+// ValidRole indica si el rol de miembro es válido
+func ValidRole(role string) bool {
+	return role == models.TeamRoleLead || role == models.TeamRoleMember
+}
+
+// ValidOrder indica si el orden de la cola es válido
+func ValidOrder(order string) bool {
+	switch order {
+	case "", OrderOldest, OrderNewest, OrderPriority:
+		return true
+	}
+	return false
+}
+
+// CanManage indica si el usuario puede gestionar (asignar a otros agentes o transferir)
+// los tickets del departamento: los administradores gestionan todos y los responsables
+// solo los de sus equipos
+func CanManage(list []models.Team, role, userID, department string) bool {
+	if role == "admin" {
+		return true
+	}
+	team := Find(list, department)
+	return team != nil && IsLead(*team, userID)
+}
+
+// CanAssign indica si el usuario puede asignar el ticket a assignee. Cualquier agente
+// puede tomar un ticket sin asignar; asignarlo a otro agente, quitarle la asignación o
+// quitárselo a otro requiere poder gestionar el ticket.
+func CanAssign(list []models.Team, role, userID string, ticket models.Ticket, assignee string) bool {
+	if assignee == ticket.AssignedTo || (assignee == userID && ticket.AssignedTo == "") {
+		return true
+	}
+	return CanManage(list, role, userID, ticket.Department)
+}
+
+// Apply guarda en el ticket el ID del equipo de su departamento y completa la prioridad
+// con la de la cola del equipo. La prioridad elegida al crear el ticket tiene prioridad.
+func Apply(list []models.Team, ticket *models.Ticket) {
+	team := Find(list, ticket.Department)
+	if team == nil {
+		return
+	}
+	ticket.Department = team.ID
+	if ticket.Priority == "" {
+		ticket.Priority = team.Queue.DefaultPriority
+	}
+}
+
+// QueueFilter devuelve el orden de la consulta de tickets para la cola del equipo
+func QueueFilter(settings models.TeamQueueSettings) models.TicketFilter {
+	switch settings.Order {
+	case OrderNewest:
+		return models.TicketFilter{SortBy: "createdAt", SortDesc: true}
+	case OrderPriority:
+		return models.TicketFilter{SortBy: "priority", SortDesc: true}
+	}
+	return models.TicketFilter{SortBy: "createdAt"}
+}
+
+// Queue arma la cola del equipo a partir de los tickets ya ordenados. Los tickets
+// fusionados no se muestran, ni los resueltos y cerrados salvo que la cola los incluya.
+func Queue(team models.Team, tickets []models.Ticket) models.TeamQueue {
+	queue := models.TeamQueue{Team: team, Tickets: make([]models.Ticket, 0)}
+	for _, ticket := range tickets {
+		if !InTeam(team, ticket.Department) || ticket.MergedInto != "" {
+			continue
+		}
+		if !team.Queue.IncludeResolved && (ticket.Status == "resolved" || ticket.Status == "closed") {
+			continue
+		}
+		if ticket.AssignedTo == "" {
+			queue.Unassigned++
+		}
+		queue.Tickets = append(queue.Tickets, ticket)
+	}
+	queue.Total = len(queue.Tickets)
+	return queue
+}
+
+// FromDepartments crea un equipo por cada departamento usado por los agentes, con ellos
+// como miembros, más los departamentos indicados. Sirve para crear los equipos
+// iniciales a partir de los departamentos de texto libre.
+func FromDepartments(users []models.User, departments []string, now time.Time) []models.Team {
+	byID := make(map[string]*models.Team)
+	order := make([]string, 0)
+
+	add := func(department string) *models.Team {
+		id := Key(department)
+		if id == "" {
+			return nil
+		}
+		team, ok := byID[id]
+		if !ok {
+			team = &models.Team{
+				ID:        id,
+				Name:      strings.TrimSpace(department),
+				Members:   make([]models.TeamMember, 0),
+				Active:    true,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			byID[id] = team
+			order = append(order, id)
+		}
+		return team
+	}
+
+	for _, user := range users {
+		if user.Role == "customer" {
+			continue
+		}
+		if team := add(user.Department); team != nil {
+			team.Members = append(team.Members, models.TeamMember{
+				UserID:   user.ID,
+				Role:     models.TeamRoleMember,
+				JoinedAt: now,
+			})
+		}
+	}
+
+	for _, department := range departments {
+		add(department)
+	}
+
+	sort.Strings(order)
+	list := make([]models.Team, 0, len(order))
+	for _, id := range order {
+		list = append(list, *byID[id])
+	}
+	return list
+}
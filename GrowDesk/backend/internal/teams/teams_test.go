@@ -0,0 +1,77 @@
+package teams
+
+import (
+	"testing"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// testTeams son dos equipos, cada uno con un responsable y un miembro
+var testTeams = []models.Team{
+	{ID: "soporte", Name: "Soporte", Active: true, Members: []models.TeamMember{
+		{UserID: "lead-soporte", Role: models.TeamRoleLead},
+		{UserID: "agent-soporte", Role: models.TeamRoleMember},
+	}},
+	{ID: "tecnologia", Name: "Tecnología", Active: true, Members: []models.TeamMember{
+		{UserID: "lead-tecnologia", Role: models.TeamRoleLead},
+	}},
+}
+
+func TestCanManage(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		userID     string
+		department string
+		want       bool
+	}{
+		{"administrador", "admin", "admin-1", "ventas", true},
+		{"administrador sin departamento", "admin", "admin-1", "", true},
+		{"responsable del equipo", "agent", "lead-soporte", "soporte", true},
+		{"departamento en texto libre", "agent", "lead-tecnologia", "Tecnología", true},
+		{"responsable de otro equipo", "agent", "lead-tecnologia", "soporte", false},
+		{"miembro del equipo", "agent", "agent-soporte", "soporte", false},
+		{"departamento sin equipo", "agent", "lead-soporte", "ventas", false},
+		{"sin departamento", "agent", "lead-soporte", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanManage(testTeams, tt.role, tt.userID, tt.department); got != tt.want {
+				t.Errorf("CanManage(%s, %s, %q) = %v, se esperaba %v", tt.role, tt.userID, tt.department, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanAssign(t *testing.T) {
+	unassigned := models.Ticket{ID: "TICKET-1", Department: "soporte"}
+	assigned := models.Ticket{ID: "TICKET-2", Department: "soporte", AssignedTo: "agent-otro"}
+
+	tests := []struct {
+		name     string
+		role     string
+		userID   string
+		ticket   models.Ticket
+		assignee string
+		want     bool
+	}{
+		{"tomar un ticket sin asignar", "agent", "agent-soporte", unassigned, "agent-soporte", true},
+		{"sin cambios", "agent", "agent-soporte", assigned, "agent-otro", true},
+		{"asignar a otro agente", "agent", "agent-soporte", unassigned, "agent-otro", false},
+		{"quitar un ticket a otro agente", "agent", "agent-soporte", assigned, "agent-soporte", false},
+		{"quitar la asignación", "agent", "agent-soporte", assigned, "", false},
+		{"responsable asigna en su equipo", "agent", "lead-soporte", unassigned, "agent-soporte", true},
+		{"responsable reasigna en su equipo", "agent", "lead-soporte", assigned, "", true},
+		{"responsable de otro equipo", "agent", "lead-tecnologia", assigned, "lead-tecnologia", false},
+		{"administrador", "admin", "admin-1", assigned, "agent-soporte", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanAssign(testTeams, tt.role, tt.userID, tt.ticket, tt.assignee); got != tt.want {
+				t.Errorf("CanAssign(%s, %s, %q → %q) = %v, se esperaba %v", tt.role, tt.userID, tt.ticket.AssignedTo, tt.assignee, got, tt.want)
+			}
+		})
+	}
+}
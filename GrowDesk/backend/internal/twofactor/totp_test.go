@@ -0,0 +1,72 @@
+package twofactor
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret es la clave de los vectores de prueba SHA-1 del RFC 6238 (apéndice B)
+var rfcSecret = secretEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s, se esperaba %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeInvalidSecret(t *testing.T) {
+	if _, err := Code("no es base32!", 1); err == nil {
+		t.Error("se esperaba un error con un secreto inválido")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	// 287082 es el código del intervalo 1 (segundos 30 a 59)
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		now      int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"intervalo actual", rfcSecret, "287082", 59, 1, true},
+		{"intervalo anterior", rfcSecret, "287082", 89, 1, true},
+		{"intervalo siguiente", rfcSecret, "287082", 29, 1, true},
+		{"fuera del desfase", rfcSecret, "287082", 90, 0, false},
+		{"con espacios", rfcSecret, " 287 082 ", 59, 1, true},
+		{"secreto en minúsculas", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "287082", 59, 1, true},
+		{"código incorrecto", rfcSecret, "287083", 59, 0, false},
+		{"código corto", rfcSecret, "28708", 59, 0, false},
+		{"código largo", rfcSecret, "2870820", 59, 0, false},
+		{"vacío", rfcSecret, "", 59, 0, false},
+		{"secreto inválido", "no es base32!", "287082", 59, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Match(tt.secret, tt.code, time.Unix(tt.now, 0))
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Match(%q) = (%d, %v), se esperaba (%d, %v)", tt.code, step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+// Package twofactor gestiona la autenticación en dos pasos con códigos TOTP (RFC 6238):
+// el registro del secreto en una aplicación de autenticación, la verificación de códigos
+// en el inicio de sesión y los códigos de recuperación de un solo uso.
+package twofactor
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/utils"
+)
+
+// Límites del segundo factor
+const (
+	// ChallengeTTL es la vigencia del token de desafío entre los dos pasos del inicio de sesión
+	ChallengeTTL = 5 * time.Minute
+	// RecoveryCodeCount es cuántos códigos de recuperación se generan
+	RecoveryCodeCount = 10
+	// maxFailures es cuántos códigos erróneos se admiten por usuario en failureWindow
+	maxFailures   = 5
+	failureWindow = 5 * time.Minute
+)
+
+// Errores del segundo factor; sus mensajes se muestran al usuario
+var (
+	ErrInvalidCode     = errors.New("El código no es válido")
+	ErrTooManyAttempts = errors.New("Demasiados códigos incorrectos; espere unos minutos")
+	ErrAlreadyEnabled  = errors.New("La autenticación en dos pasos ya está activada")
+	ErrNotEnabled      = errors.New("La autenticación en dos pasos no está activada")
+	ErrNotSetUp        = errors.New("Primero genere el secreto de la aplicación de autenticación")
+	ErrRequired        = errors.New("La autenticación en dos pasos es obligatoria para su rol")
+	ErrWrongPassword   = errors.New("La contraseña es incorrecta")
+)
+
+// recoveryEncoding codifica los códigos de recuperación en minúsculas y sin relleno
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// Service activa, verifica y desactiva la autenticación en dos pasos
+type Service struct {
+	Store         data.DataStore
+	Issuer        string          // Nombre que muestra la aplicación de autenticación
+	RequiredRoles map[string]bool // Roles que deben usar el segundo factor
+
+	mu       sync.Mutex
+	failures map[string][]time.Time
+}
+
+// NewService crea un servicio de autenticación en dos pasos. requiredRoles es una lista
+// de roles separados por comas que deben usar el segundo factor.
+func NewService(store data.DataStore, issuer, requiredRoles string) *Service {
+	if issuer == "" {
+		issuer = "GrowDesk"
+	}
+
+	roles := make(map[string]bool)
+	for _, role := range strings.Split(requiredRoles, ",") {
+		if role = strings.ToLower(strings.TrimSpace(role)); role != "" {
+			roles[role] = true
+		}
+	}
+
+	return &Service{
+		Store:         store,
+		Issuer:        issuer,
+		RequiredRoles: roles,
+		failures:      make(map[string][]time.Time),
+	}
+}
+
+// Required indica si el rol debe usar el segundo factor
+func (s *Service) Required(role string) bool {
+	return s.RequiredRoles[role]
+}
+
+// Status devuelve el estado del segundo factor del usuario
+func (s *Service) Status(user models.User) (*models.TwoFactorStatus, error) {
+	twoFactor, err := s.Store.GetTwoFactor(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.TwoFactorStatus{
+		Enabled:  twoFactor.Enabled,
+		Required: s.Required(user.Role),
+	}
+	if twoFactor.Enabled {
+		status.EnabledAt = twoFactor.EnabledAt
+		status.RecoveryCodesLeft = len(twoFactor.RecoveryCodes)
+	}
+	return status, nil
+}
+
+// Setup genera un secreto nuevo para registrar en la aplicación de autenticación. El
+// segundo factor no se activa hasta confirmar un código con Enable.
+func (s *Service) Setup(user models.User) (*models.TwoFactorSetup, error) {
+	twoFactor, err := s.Store.GetTwoFactor(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.Enabled {
+		return nil, ErrAlreadyEnabled
+	}
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Store.SaveTwoFactor(models.TwoFactor{UserID: user.ID, Secret: secret}); err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: ProvisioningURI(s.Issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable activa el segundo factor con un código de la aplicación y devuelve los códigos
+// de recuperación. Es la única vez que los códigos se muestran.
+func (s *Service) Enable(userID, code string) ([]string, error) {
+	twoFactor, err := s.Store.GetTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.Enabled {
+		return nil, ErrAlreadyEnabled
+	}
+	if twoFactor.Secret == "" {
+		return nil, ErrNotSetUp
+	}
+
+	now := time.Now()
+	if !s.allowAttempt(userID, now) {
+		return nil, ErrTooManyAttempts
+	}
+	step, ok := Match(twoFactor.Secret, code, now)
+	if !ok {
+		s.recordFailure(userID, now)
+		return nil, ErrInvalidCode
+	}
+	s.clearFailures(userID)
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	twoFactor.Enabled = true
+	twoFactor.EnabledAt = &now
+	twoFactor.RecoveryCodes = hashes
+	twoFactor.LastUsedStep = step
+	if err := s.Store.SaveTwoFactor(*twoFactor); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Verify comprueba un código de la aplicación o un código de recuperación. Cada código
+// sirve una sola vez y tras varios errores seguidos se rechazan los intentos por un tiempo.
+func (s *Service) Verify(userID string, req models.TwoFactorCodeRequest) error {
+	twoFactor, err := s.Store.GetTwoFactor(userID)
+	if err != nil {
+		return err
+	}
+	if !twoFactor.Enabled {
+		return ErrNotEnabled
+	}
+
+	now := time.Now()
+	if !s.allowAttempt(userID, now) {
+		return ErrTooManyAttempts
+	}
+
+	valid := false
+	switch {
+	case strings.TrimSpace(req.Code) != "":
+		if step, ok := Match(twoFactor.Secret, req.Code, now); ok {
+			valid = s.Store.ConsumeTwoFactorStep(userID, step) == nil
+		}
+	case strings.TrimSpace(req.RecoveryCode) != "":
+		valid = s.Store.ConsumeRecoveryCode(userID, hashRecoveryCode(req.RecoveryCode)) == nil
+	}
+	if !valid {
+		s.recordFailure(userID, now)
+		return ErrInvalidCode
+	}
+
+	s.clearFailures(userID)
+	return nil
+}
+
+// Disable desactiva el segundo factor del usuario. Exige la contraseña y un código, y no
+// se permite si el rol del usuario lo hace obligatorio.
+func (s *Service) Disable(user models.User, req models.TwoFactorCodeRequest) error {
+	if s.Required(user.Role) {
+		return ErrRequired
+	}
+
+	stored, err := s.Store.GetUserPasswordHash(user.ID)
+	if err != nil {
+		return err
+	}
+	if !utils.CheckPassword(stored, req.Password) {
+		return ErrWrongPassword
+	}
+	if err := s.Verify(user.ID, req); err != nil {
+		return err
+	}
+
+	return s.Store.DeleteTwoFactor(user.ID)
+}
+
+// RegenerateRecoveryCodes reemplaza los códigos de recuperación tras verificar un código
+func (s *Service) RegenerateRecoveryCodes(userID string, req models.TwoFactorCodeRequest) ([]string, error) {
+	if err := s.Verify(userID, req); err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := s.Store.GetTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	twoFactor.RecoveryCodes = hashes
+	if err := s.Store.SaveTwoFactor(*twoFactor); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Reset quita el segundo factor de un usuario, por ejemplo si perdió su dispositivo. Lo
+// usa un administrador; el usuario deberá volver a configurarlo.
+func (s *Service) Reset(userID string) error {
+	s.clearFailures(userID)
+	return s.Store.DeleteTwoFactor(userID)
+}
+
+// allowAttempt indica si el usuario puede probar otro código
+func (s *Service) allowAttempt(userID string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := now.Add(-failureWindow)
+	recent := s.failures[userID][:0]
+	for _, at := range s.failures[userID] {
+		if at.After(cutoff) {
+			recent = append(recent, at)
+		}
+	}
+	if len(recent) == 0 {
+		delete(s.failures, userID)
+		return true
+	}
+	s.failures[userID] = recent
+	return len(recent) < maxFailures
+}
+
+// recordFailure registra un código erróneo del usuario
+func (s *Service) recordFailure(userID string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[userID] = append(s.failures[userID], now)
+}
+
+// clearFailures olvida los códigos erróneos del usuario
+func (s *Service) clearFailures(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failures, userID)
+}
+
+// newRecoveryCodes genera los códigos de recuperación y sus hashes
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	hashes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		code := recoveryEncoding.EncodeToString(buf)
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode devuelve el hash con el que se guarda un código de recuperación; ignora
+// mayúsculas, espacios y guiones
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Longitudes admitidas de una contraseña; bcrypt solo usa los primeros 72 bytes
+const (
+	MinPasswordLength = 8
+	MaxPasswordBytes  = 72
+)
+
+// bcryptPrefix identifica las contraseñas ya hasheadas
+const bcryptPrefix = "$2"
+
+// PasswordPolicy son los requisitos de fortaleza de las contraseñas nuevas
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// passwordPolicy es la política vigente; se configura al iniciar el servidor
+var passwordPolicy = PasswordPolicy{MinLength: MinPasswordLength}
+
+// SetPasswordPolicy reemplaza la política de contraseñas. Una longitud mínima menor
+// que MinPasswordLength se eleva a ese valor.
+func SetPasswordPolicy(policy PasswordPolicy) {
+	if policy.MinLength < MinPasswordLength {
+		policy.MinLength = MinPasswordLength
+	}
+	passwordPolicy = policy
+}
+
+// HashPassword devuelve el hash bcrypt de la contraseña
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("error al hashear contraseña: %v", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword indica si la contraseña corresponde al hash guardado. Las contraseñas
+// guardadas en texto plano antes de usar hashes se comparan directamente.
+func CheckPassword(stored, password string) bool {
+	if stored == "" {
+		return false
+	}
+	if !strings.HasPrefix(stored, bcryptPrefix) {
+		return stored == password
+	}
+	return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+}
+
+// ValidatePassword comprueba que la contraseña cumpla la política vigente
+func ValidatePassword(password string) error {
+	policy := passwordPolicy
+
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		return fmt.Errorf("La contraseña debe tener al menos %d caracteres", policy.MinLength)
+	}
+	if len(password) > MaxPasswordBytes {
+		return fmt.Errorf("La contraseña no puede superar los %d bytes", MaxPasswordBytes)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	missing := make([]string, 0, 4)
+	if policy.RequireUpper && !upper {
+		missing = append(missing, "una mayúscula")
+	}
+	if policy.RequireLower && !lower {
+		missing = append(missing, "una minúscula")
+	}
+	if policy.RequireDigit && !digit {
+		missing = append(missing, "un número")
+	}
+	if policy.RequireSymbol && !symbol {
+		missing = append(missing, "un símbolo")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("La contraseña debe incluir al menos %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePassword(t *testing.T) {
+	previous := passwordPolicy
+	t.Cleanup(func() { passwordPolicy = previous })
+
+	strict := PasswordPolicy{MinLength: 10, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true}
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		wantErr  string
+	}{
+		{"mínimo por defecto", PasswordPolicy{}, "12345678", ""},
+		{"corta", PasswordPolicy{}, "1234567", "al menos 8 caracteres"},
+		{"mínimo inferior al permitido", PasswordPolicy{MinLength: 4}, "abcde", "al menos 8 caracteres"},
+		{"cuenta caracteres, no bytes", PasswordPolicy{}, "ñññññññ", "al menos 8 caracteres"},
+		{"caracteres multibyte", PasswordPolicy{}, "ññññññññ", ""},
+		{"límite de bcrypt", PasswordPolicy{}, strings.Repeat("a", MaxPasswordBytes), ""},
+		{"supera el límite de bcrypt", PasswordPolicy{}, strings.Repeat("a", MaxPasswordBytes+1), "no puede superar los 72 bytes"},
+		{"política estricta cumplida", strict, "Segura-2026", ""},
+		{"sin mayúscula", strict, "segura-2026", "una mayúscula"},
+		{"sin minúscula", strict, "SEGURA-2026", "una minúscula"},
+		{"sin número", strict, "Segura-abcd", "un número"},
+		{"sin símbolo", strict, "Segura2026x", "un símbolo"},
+		{"espacio como símbolo", strict, "Segura 2026", ""},
+		{"varios requisitos", strict, "segurasegura", "una mayúscula, un número, un símbolo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetPasswordPolicy(tt.policy)
+			err := ValidatePassword(tt.password)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidatePassword(%q) = %v, se esperaba nil", tt.password, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidatePassword(%q) = %v, se esperaba un error con %q", tt.password, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -13,6 +13,8 @@ const (
 	jwtSecret = "your-super-secret-key-change-in-production"
 	// El token expira en 24 horas
 	tokenExpiration = 24 * time.Hour
+	// Propósito de los tokens de desafío del inicio de sesión en dos pasos
+	ChallengePurpose = "2fa"
 )
 
 // Claims define las reclamaciones personalizadas para JWT
@@ -20,19 +22,25 @@ type Claims struct {
 	UserID string `json:"userID"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+	// SessionVersion es la versión de sesión del usuario al emitir el token
+	SessionVersion int `json:"sessionVersion,omitempty"`
+	// Purpose marca los tokens que no son de sesión, como los de desafío
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken crea un nuevo token JWT para un usuario
-func GenerateToken(userID, email, role string) (string, error) {
+// GenerateSessionToken crea un token JWT con la versión de sesión actual del usuario;
+// deja de ser válido cuando la versión cambia
+func GenerateSessionToken(userID, email, role string, sessionVersion int) (string, error) {
 	// Establecer el tiempo de expiración
 	expirationTime := time.Now().Add(tokenExpiration)
 
 	// Crear las reclamaciones de JWT
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:         userID,
+		Email:          email,
+		Role:           role,
+		SessionVersion: sessionVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -51,8 +59,59 @@ func GenerateToken(userID, email, role string) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateToken valida un token JWT y devuelve las reclamaciones
+// GenerateChallengeToken crea el token de desafío que entrega el primer paso del inicio
+// de sesión; solo sirve para completar el segundo factor y no como sesión
+func GenerateChallengeToken(userID, email, role string, sessionVersion int, ttl time.Duration) (string, time.Time, error) {
+	expirationTime := time.Now().Add(ttl)
+
+	claims := &Claims{
+		UserID:         userID,
+		Email:          email,
+		Role:           role,
+		SessionVersion: sessionVersion,
+		Purpose:        ChallengePurpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(jwtSecret))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error firmando el token: %w", err)
+	}
+
+	return tokenString, expirationTime, nil
+}
+
+// ValidateChallengeToken valida un token de desafío y devuelve las reclamaciones
+func ValidateChallengeToken(tokenString string) (*Claims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != ChallengePurpose {
+		return nil, fmt.Errorf("el token no es de desafío")
+	}
+	return claims, nil
+}
+
+// ValidateToken valida un token JWT de sesión y devuelve las reclamaciones. Rechaza los
+// tokens con otro propósito, como los de desafío.
 func ValidateToken(tokenString string) (*Claims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, fmt.Errorf("el token no es de sesión")
+	}
+	return claims, nil
+}
+
+// parseToken verifica la firma y la vigencia de un token JWT
+func parseToken(tokenString string) (*Claims, error) {
 	// Comprobar si el token está vacío
 	if tokenString == "" {
 		return nil, fmt.Errorf("el token está vacío")
@@ -0,0 +1,192 @@
+// Package watchers notifica a los seguidores de un ticket y procesa las menciones
+// (@usuario) de las notas internas.
+package watchers
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/data"
+	"github.com/hmdev/GrowDeskV2/GrowDesk/backend/internal/models"
+)
+
+// mentionPattern reconoce @usuario o @correo completo. La mención debe ir al inicio del
+// texto o tras un carácter que no forme parte de una palabra, para no confundirla con un correo.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}._%+-])@([\p{L}\p{N}._%+-]+(?:@[\p{L}\p{N}.-]+)?)`)
+
+// Service notifica a los seguidores de los tickets. Un Service nil no hace nada.
+type Service struct {
+	Store data.DataStore
+}
+
+// NewService crea un servicio de seguidores
+func NewService(store data.DataStore) *Service {
+	return &Service{Store: store}
+}
+
+// ParseMentions devuelve los IDs de los agentes mencionados en un texto, sin repetir.
+// Un agente se menciona con la parte local de su correo (@ana.garcia), con el correo
+// completo (@ana.garcia@empresa.com) o con nombre y apellido unidos por un punto
+// (@ana.garcia). Los clientes y los usuarios inactivos no se pueden mencionar.
+func ParseMentions(content string, users []models.User) []string {
+	handles := make(map[string]string)
+	for _, user := range users {
+		if !user.Active || user.Role == "customer" {
+			continue
+		}
+		email := strings.ToLower(user.Email)
+		handles[email] = user.ID
+		if local, _, found := strings.Cut(email, "@"); found {
+			handles[local] = user.ID
+		}
+		if user.FirstName != "" && user.LastName != "" {
+			name := strings.ToLower(user.FirstName + "." + user.LastName)
+			if _, taken := handles[name]; !taken {
+				handles[name] = user.ID
+			}
+		}
+	}
+
+	mentioned := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		// Quitar la puntuación final ("@ana.garcia, revisa esto.")
+		handle := strings.ToLower(strings.TrimRight(match[1], ".-"))
+		userID, ok := handles[handle]
+		if !ok || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		mentioned = append(mentioned, userID)
+	}
+	return mentioned
+}
+
+// Notify envía una notificación a los seguidores de un ticket, salvo al usuario que
+// provocó el cambio
+func (s *Service) Notify(ticketID, actorID, message string) {
+	if s == nil {
+		return
+	}
+
+	watchers, err := s.Store.GetTicketWatchers(ticketID)
+	if err != nil {
+		log.Printf("Error al obtener seguidores del ticket %s: %v", ticketID, err)
+		return
+	}
+
+	for _, watcher := range watchers {
+		if watcher.UserID == actorID {
+			continue
+		}
+		s.send(watcher.UserID, models.NotificationTypeWatch, ticketID, message)
+	}
+}
+
+// MessageAdded notifica un mensaje nuevo a los seguidores del ticket. En las notas
+// internas, los agentes mencionados pasan a seguir el ticket y reciben una notificación
+// de mención en lugar de la notificación general.
+func (s *Service) MessageAdded(ticketID string, message models.Message) {
+	if s == nil {
+		return
+	}
+
+	ticket, err := s.Store.GetTicket(ticketID)
+	if err != nil {
+		return
+	}
+
+	mentioned := make(map[string]bool)
+	if message.IsInternal {
+		users, err := s.Store.GetUsers()
+		if err != nil {
+			log.Printf("Error al obtener usuarios para menciones: %v", err)
+		} else {
+			userIDs := ParseMentions(message.Content, users)
+			if len(userIDs) > 0 {
+				if _, err := s.Store.AddTicketWatchers(ticket.ID, userIDs, message.UserID); err != nil {
+					log.Printf("Error al añadir seguidores mencionados al ticket %s: %v", ticket.ID, err)
+				}
+			}
+
+			author := message.UserName
+			if author == "" {
+				author = "Un agente"
+			}
+			for _, userID := range userIDs {
+				mentioned[userID] = true
+				if userID == message.UserID {
+					continue
+				}
+				s.send(userID, models.NotificationTypeMention, ticket.ID,
+					fmt.Sprintf("%s te mencionó en el ticket %s - %s", author, ticket.ID, ticket.Title))
+			}
+		}
+	}
+
+	var text string
+	switch {
+	case message.IsInternal:
+		text = fmt.Sprintf("Nueva nota interna en el ticket %s - %s", ticket.ID, ticket.Title)
+	case message.IsClient:
+		text = fmt.Sprintf("Nuevo mensaje del cliente en el ticket %s - %s", ticket.ID, ticket.Title)
+	default:
+		text = fmt.Sprintf("Nueva respuesta en el ticket %s - %s", ticket.ID, ticket.Title)
+	}
+
+	watchers, err := s.Store.GetTicketWatchers(ticket.ID)
+	if err != nil {
+		log.Printf("Error al obtener seguidores del ticket %s: %v", ticket.ID, err)
+		return
+	}
+	for _, watcher := range watchers {
+		if watcher.UserID == message.UserID || mentioned[watcher.UserID] {
+			continue
+		}
+		s.send(watcher.UserID, models.NotificationTypeWatch, ticket.ID, text)
+	}
+}
+
+// TicketUpdated notifica a los seguidores los cambios de estado, prioridad y asignación
+// de un ticket
+func (s *Service) TicketUpdated(previous, updated models.Ticket, actorID string) {
+	if s == nil {
+		return
+	}
+
+	changes := make([]string, 0)
+	if previous.Status != updated.Status {
+		changes = append(changes, "estado: "+updated.Status)
+	}
+	if previous.Priority != updated.Priority {
+		changes = append(changes, "prioridad: "+updated.Priority)
+	}
+	if previous.AssignedTo != updated.AssignedTo {
+		if updated.AssignedTo == "" {
+			changes = append(changes, "sin asignar")
+		} else {
+			changes = append(changes, "asignado a: "+updated.AssignedTo)
+		}
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	s.Notify(updated.ID, actorID, fmt.Sprintf("Ticket %s actualizado (%s) - %s", updated.ID, strings.Join(changes, "; "), updated.Title))
+}
+
+// send crea una notificación para un usuario sobre un ticket
+func (s *Service) send(userID, notificationType, ticketID, message string) {
+	notification := models.Notification{
+		UserID:      userID,
+		Message:     message,
+		Type:        notificationType,
+		RelatedID:   ticketID,
+		RelatedType: "ticket",
+	}
+	if err := s.Store.CreateNotification(notification); err != nil {
+		log.Printf("Error al notificar a %s sobre el ticket %s: %v", userID, ticketID, err)
+	}
+}
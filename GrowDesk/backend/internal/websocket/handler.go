@@ -69,12 +69,12 @@ func ChatHandler(store *data.Store) http.HandlerFunc {
 			fmt.Printf("Error al enviar el mensaje de bienvenida: %v\n", err)
 		}
 
-		// Enviar historial de mensajes
+		// Enviar historial de mensajes (la conexión es del cliente: sin notas internas)
 		if ticket != nil && len(ticket.Messages) > 0 {
 			historyMsg := models.WebSocketMessage{
 				Type:     "message_history",
 				TicketID: ticketID,
-				Messages: ticket.Messages,
+				Messages: models.FilterMessages(ticket.Messages, models.MessageAudienceCustomer),
 			}
 
 			if err := conn.WriteJSON(historyMsg); err != nil {
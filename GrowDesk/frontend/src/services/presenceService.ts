@@ -0,0 +1,37 @@
+import apiClient from '@/api/client';
+
+// Intervalo de latidos; debe ser menor que el TTL de presencia del backend (2 minutos)
+const HEARTBEAT_INTERVAL_MS = 45000;
+
+let heartbeatTimer: ReturnType<typeof setInterval> | null = null;
+
+const sendHeartbeat = async () => {
+  try {
+    await apiClient.post('/agents/presence', {});
+  } catch (error: any) {
+    console.error('Error al enviar latido de presencia:', error.message);
+  }
+};
+
+// Inicia el envío periódico de latidos para marcar al agente como conectado
+export const startPresenceHeartbeat = () => {
+  if (heartbeatTimer) {
+    return;
+  }
+  sendHeartbeat();
+  heartbeatTimer = setInterval(sendHeartbeat, HEARTBEAT_INTERVAL_MS);
+};
+
+// Detiene los latidos y marca al agente como desconectado
+export const stopPresenceHeartbeat = async () => {
+  if (!heartbeatTimer) {
+    return;
+  }
+  clearInterval(heartbeatTimer);
+  heartbeatTimer = null;
+  try {
+    await apiClient.post('/agents/presence', { status: 'offline' });
+  } catch (error: any) {
+    console.error('Error al marcar al agente como desconectado:', error.message);
+  }
+};
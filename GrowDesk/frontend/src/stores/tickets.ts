@@ -13,7 +13,7 @@ export interface Ticket {
   id: string
   title: string
   description: string
-  status: 'open' | 'assigned' | 'in_progress' | 'resolved' | 'closed'
+  status: 'open' | 'assigned' | 'in_progress' | 'pending' | 'resolved' | 'closed'
   priority: 'LOW' | 'MEDIUM' | 'HIGH' | 'URGENT'
   category: string
   createdBy: string
@@ -21,6 +21,20 @@ export interface Ticket {
   createdAt: string
   updatedAt: string
   tags?: Tag[] | string[]
+  customFields?: Record<string, string | number | boolean | string[]>
+  mergedInto?: string
+  links?: TicketLink[]
+}
+
+export interface TicketLink {
+  id: string
+  ticketId: string
+  linkedTicketId: string
+  type: 'duplicate_of' | 'duplicated_by' | 'related_to' | 'parent_of' | 'child_of' | 'blocks' | 'blocked_by'
+  createdBy?: string
+  createdAt: string
+  linkedTicketTitle?: string
+  linkedTicketStatus?: string
 }
 
 export interface Tag {
@@ -159,13 +173,14 @@ export const useTicketStore = defineStore('tickets', {
   },
 
   actions: {
-    async fetchTickets() {
+    // params admite filtros y orden del listado, p. ej. { status: 'open', 'cf.contractTier': 'gold', sort: 'cf.orderNumber', order: 'desc' }
+    async fetchTickets(params?: Record<string, string>) {
       this.loading = true
       this.error = null
       
       try {
         console.log('Comenzando fetchTickets - Llamando API...')
-        const response = await apiClient.get('/tickets')
+        const response = await apiClient.get('/tickets', { params })
         console.log('Respuesta API fetchTickets:', response.data)
         this.tickets = response.data
         
@@ -692,6 +707,7 @@ export const useTicketStore = defineStore('tickets', {
         'open': 'Abierto',
         'assigned': 'Asignado',
         'in_progress': 'En Progreso',
+        'pending': 'Pendiente',
         'resolved': 'Resuelto',
         'closed': 'Cerrado'
       }